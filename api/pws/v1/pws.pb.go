@@ -0,0 +1,715 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: api/pws/v1/pws.proto
+
+package pwsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Station is a weather station that has submitted observations.
+type Station struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	StationId            string                 `protobuf:"bytes,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	LastSeen             *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	SubmissionsPerMinute float64                `protobuf:"fixed64,3,opt,name=submissions_per_minute,json=submissionsPerMinute,proto3" json:"submissions_per_minute,omitempty"`
+	Realtime             bool                   `protobuf:"varint,4,opt,name=realtime,proto3" json:"realtime,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Station) Reset() {
+	*x = Station{}
+	mi := &file_api_pws_v1_pws_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Station) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Station) ProtoMessage() {}
+
+func (x *Station) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pws_v1_pws_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Station.ProtoReflect.Descriptor instead.
+func (*Station) Descriptor() ([]byte, []int) {
+	return file_api_pws_v1_pws_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Station) GetStationId() string {
+	if x != nil {
+		return x.StationId
+	}
+	return ""
+}
+
+func (x *Station) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *Station) GetSubmissionsPerMinute() float64 {
+	if x != nil {
+		return x.SubmissionsPerMinute
+	}
+	return 0
+}
+
+func (x *Station) GetRealtime() bool {
+	if x != nil {
+		return x.Realtime
+	}
+	return false
+}
+
+// Observation is a normalized weather station observation. All values use
+// metric units. Values that the station did not measure are not set.
+type Observation struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	StationId                string                 `protobuf:"bytes,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	Time                     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	ReceivedAt               *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=received_at,json=receivedAt,proto3" json:"received_at,omitempty"`
+	TemperatureCelsius       *float64               `protobuf:"fixed64,4,opt,name=temperature_celsius,json=temperatureCelsius,proto3,oneof" json:"temperature_celsius,omitempty"`
+	DewPointCelsius          *float64               `protobuf:"fixed64,5,opt,name=dew_point_celsius,json=dewPointCelsius,proto3,oneof" json:"dew_point_celsius,omitempty"`
+	HumidityPercent          *float64               `protobuf:"fixed64,6,opt,name=humidity_percent,json=humidityPercent,proto3,oneof" json:"humidity_percent,omitempty"`
+	IndoorTemperatureCelsius *float64               `protobuf:"fixed64,7,opt,name=indoor_temperature_celsius,json=indoorTemperatureCelsius,proto3,oneof" json:"indoor_temperature_celsius,omitempty"`
+	IndoorHumidityPercent    *float64               `protobuf:"fixed64,8,opt,name=indoor_humidity_percent,json=indoorHumidityPercent,proto3,oneof" json:"indoor_humidity_percent,omitempty"`
+	BarometricPressureHpa    *float64               `protobuf:"fixed64,9,opt,name=barometric_pressure_hpa,json=barometricPressureHpa,proto3,oneof" json:"barometric_pressure_hpa,omitempty"`
+	WindSpeedKph             *float64               `protobuf:"fixed64,10,opt,name=wind_speed_kph,json=windSpeedKph,proto3,oneof" json:"wind_speed_kph,omitempty"`
+	WindGustSpeedKph         *float64               `protobuf:"fixed64,11,opt,name=wind_gust_speed_kph,json=windGustSpeedKph,proto3,oneof" json:"wind_gust_speed_kph,omitempty"`
+	WindDirectionDegrees     *float64               `protobuf:"fixed64,12,opt,name=wind_direction_degrees,json=windDirectionDegrees,proto3,oneof" json:"wind_direction_degrees,omitempty"`
+	RainPastHourMm           *float64               `protobuf:"fixed64,13,opt,name=rain_past_hour_mm,json=rainPastHourMm,proto3,oneof" json:"rain_past_hour_mm,omitempty"`
+	RainTodayMm              *float64               `protobuf:"fixed64,14,opt,name=rain_today_mm,json=rainTodayMm,proto3,oneof" json:"rain_today_mm,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *Observation) Reset() {
+	*x = Observation{}
+	mi := &file_api_pws_v1_pws_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Observation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Observation) ProtoMessage() {}
+
+func (x *Observation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pws_v1_pws_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Observation.ProtoReflect.Descriptor instead.
+func (*Observation) Descriptor() ([]byte, []int) {
+	return file_api_pws_v1_pws_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Observation) GetStationId() string {
+	if x != nil {
+		return x.StationId
+	}
+	return ""
+}
+
+func (x *Observation) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Observation) GetReceivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReceivedAt
+	}
+	return nil
+}
+
+func (x *Observation) GetTemperatureCelsius() float64 {
+	if x != nil && x.TemperatureCelsius != nil {
+		return *x.TemperatureCelsius
+	}
+	return 0
+}
+
+func (x *Observation) GetDewPointCelsius() float64 {
+	if x != nil && x.DewPointCelsius != nil {
+		return *x.DewPointCelsius
+	}
+	return 0
+}
+
+func (x *Observation) GetHumidityPercent() float64 {
+	if x != nil && x.HumidityPercent != nil {
+		return *x.HumidityPercent
+	}
+	return 0
+}
+
+func (x *Observation) GetIndoorTemperatureCelsius() float64 {
+	if x != nil && x.IndoorTemperatureCelsius != nil {
+		return *x.IndoorTemperatureCelsius
+	}
+	return 0
+}
+
+func (x *Observation) GetIndoorHumidityPercent() float64 {
+	if x != nil && x.IndoorHumidityPercent != nil {
+		return *x.IndoorHumidityPercent
+	}
+	return 0
+}
+
+func (x *Observation) GetBarometricPressureHpa() float64 {
+	if x != nil && x.BarometricPressureHpa != nil {
+		return *x.BarometricPressureHpa
+	}
+	return 0
+}
+
+func (x *Observation) GetWindSpeedKph() float64 {
+	if x != nil && x.WindSpeedKph != nil {
+		return *x.WindSpeedKph
+	}
+	return 0
+}
+
+func (x *Observation) GetWindGustSpeedKph() float64 {
+	if x != nil && x.WindGustSpeedKph != nil {
+		return *x.WindGustSpeedKph
+	}
+	return 0
+}
+
+func (x *Observation) GetWindDirectionDegrees() float64 {
+	if x != nil && x.WindDirectionDegrees != nil {
+		return *x.WindDirectionDegrees
+	}
+	return 0
+}
+
+func (x *Observation) GetRainPastHourMm() float64 {
+	if x != nil && x.RainPastHourMm != nil {
+		return *x.RainPastHourMm
+	}
+	return 0
+}
+
+func (x *Observation) GetRainTodayMm() float64 {
+	if x != nil && x.RainTodayMm != nil {
+		return *x.RainTodayMm
+	}
+	return 0
+}
+
+type ListStationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStationsRequest) Reset() {
+	*x = ListStationsRequest{}
+	mi := &file_api_pws_v1_pws_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStationsRequest) ProtoMessage() {}
+
+func (x *ListStationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pws_v1_pws_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStationsRequest.ProtoReflect.Descriptor instead.
+func (*ListStationsRequest) Descriptor() ([]byte, []int) {
+	return file_api_pws_v1_pws_proto_rawDescGZIP(), []int{2}
+}
+
+type ListStationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stations      []*Station             `protobuf:"bytes,1,rep,name=stations,proto3" json:"stations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStationsResponse) Reset() {
+	*x = ListStationsResponse{}
+	mi := &file_api_pws_v1_pws_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStationsResponse) ProtoMessage() {}
+
+func (x *ListStationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pws_v1_pws_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStationsResponse.ProtoReflect.Descriptor instead.
+func (*ListStationsResponse) Descriptor() ([]byte, []int) {
+	return file_api_pws_v1_pws_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListStationsResponse) GetStations() []*Station {
+	if x != nil {
+		return x.Stations
+	}
+	return nil
+}
+
+type GetLatestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StationId     string                 `protobuf:"bytes,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestRequest) Reset() {
+	*x = GetLatestRequest{}
+	mi := &file_api_pws_v1_pws_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestRequest) ProtoMessage() {}
+
+func (x *GetLatestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pws_v1_pws_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestRequest) Descriptor() ([]byte, []int) {
+	return file_api_pws_v1_pws_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetLatestRequest) GetStationId() string {
+	if x != nil {
+		return x.StationId
+	}
+	return ""
+}
+
+type GetLatestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Observation   *Observation           `protobuf:"bytes,1,opt,name=observation,proto3" json:"observation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestResponse) Reset() {
+	*x = GetLatestResponse{}
+	mi := &file_api_pws_v1_pws_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestResponse) ProtoMessage() {}
+
+func (x *GetLatestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pws_v1_pws_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestResponse.ProtoReflect.Descriptor instead.
+func (*GetLatestResponse) Descriptor() ([]byte, []int) {
+	return file_api_pws_v1_pws_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetLatestResponse) GetObservation() *Observation {
+	if x != nil {
+		return x.Observation
+	}
+	return nil
+}
+
+type StreamObservationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only stream observations from this station. If empty, observations from
+	// all stations are streamed.
+	StationId     string `protobuf:"bytes,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamObservationsRequest) Reset() {
+	*x = StreamObservationsRequest{}
+	mi := &file_api_pws_v1_pws_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamObservationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamObservationsRequest) ProtoMessage() {}
+
+func (x *StreamObservationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pws_v1_pws_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamObservationsRequest.ProtoReflect.Descriptor instead.
+func (*StreamObservationsRequest) Descriptor() ([]byte, []int) {
+	return file_api_pws_v1_pws_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StreamObservationsRequest) GetStationId() string {
+	if x != nil {
+		return x.StationId
+	}
+	return ""
+}
+
+type StreamObservationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Observation   *Observation           `protobuf:"bytes,1,opt,name=observation,proto3" json:"observation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamObservationsResponse) Reset() {
+	*x = StreamObservationsResponse{}
+	mi := &file_api_pws_v1_pws_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamObservationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamObservationsResponse) ProtoMessage() {}
+
+func (x *StreamObservationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_pws_v1_pws_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamObservationsResponse.ProtoReflect.Descriptor instead.
+func (*StreamObservationsResponse) Descriptor() ([]byte, []int) {
+	return file_api_pws_v1_pws_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StreamObservationsResponse) GetObservation() *Observation {
+	if x != nil {
+		return x.Observation
+	}
+	return nil
+}
+
+var File_api_pws_v1_pws_proto protoreflect.FileDescriptor
+
+var file_api_pws_v1_pws_proto_rawDesc = string([]byte{
+	0x0a, 0x14, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x77, 0x73, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x77, 0x73,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x70, 0x77, 0x73, 0x2e, 0x76, 0x31, 0x1a, 0x1f,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xb3, 0x01, 0x0a, 0x07, 0x53, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x73,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x37, 0x0a, 0x09, 0x6c, 0x61,
+	0x73, 0x74, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x53,
+	0x65, 0x65, 0x6e, 0x12, 0x34, 0x0a, 0x16, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x14, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x50, 0x65, 0x72, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x61,
+	0x6c, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x61,
+	0x6c, 0x74, 0x69, 0x6d, 0x65, 0x22, 0xe8, 0x07, 0x0a, 0x0b, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04,
+	0x74, 0x69, 0x6d, 0x65, 0x12, 0x3b, 0x0a, 0x0b, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64,
+	0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x41,
+	0x74, 0x12, 0x34, 0x0a, 0x13, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x5f, 0x63, 0x65, 0x6c, 0x73, 0x69, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00,
+	0x52, 0x12, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x43, 0x65, 0x6c,
+	0x73, 0x69, 0x75, 0x73, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x11, 0x64, 0x65, 0x77, 0x5f, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x5f, 0x63, 0x65, 0x6c, 0x73, 0x69, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x48, 0x01, 0x52, 0x0f, 0x64, 0x65, 0x77, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x43, 0x65,
+	0x6c, 0x73, 0x69, 0x75, 0x73, 0x88, 0x01, 0x01, 0x12, 0x2e, 0x0a, 0x10, 0x68, 0x75, 0x6d, 0x69,
+	0x64, 0x69, 0x74, 0x79, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x01, 0x48, 0x02, 0x52, 0x0f, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x50, 0x65,
+	0x72, 0x63, 0x65, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x41, 0x0a, 0x1a, 0x69, 0x6e, 0x64, 0x6f,
+	0x6f, 0x72, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x63,
+	0x65, 0x6c, 0x73, 0x69, 0x75, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03, 0x52, 0x18,
+	0x69, 0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x54, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x43, 0x65, 0x6c, 0x73, 0x69, 0x75, 0x73, 0x88, 0x01, 0x01, 0x12, 0x3b, 0x0a, 0x17, 0x69,
+	0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x5f, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x70,
+	0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x48, 0x04, 0x52, 0x15,
+	0x69, 0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x48, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x50, 0x65,
+	0x72, 0x63, 0x65, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x3b, 0x0a, 0x17, 0x62, 0x61, 0x72, 0x6f,
+	0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x5f,
+	0x68, 0x70, 0x61, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x48, 0x05, 0x52, 0x15, 0x62, 0x61, 0x72,
+	0x6f, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x50, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x48,
+	0x70, 0x61, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0e, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x73, 0x70,
+	0x65, 0x65, 0x64, 0x5f, 0x6b, 0x70, 0x68, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x48, 0x06, 0x52,
+	0x0c, 0x77, 0x69, 0x6e, 0x64, 0x53, 0x70, 0x65, 0x65, 0x64, 0x4b, 0x70, 0x68, 0x88, 0x01, 0x01,
+	0x12, 0x32, 0x0a, 0x13, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x67, 0x75, 0x73, 0x74, 0x5f, 0x73, 0x70,
+	0x65, 0x65, 0x64, 0x5f, 0x6b, 0x70, 0x68, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x48, 0x07, 0x52,
+	0x10, 0x77, 0x69, 0x6e, 0x64, 0x47, 0x75, 0x73, 0x74, 0x53, 0x70, 0x65, 0x65, 0x64, 0x4b, 0x70,
+	0x68, 0x88, 0x01, 0x01, 0x12, 0x39, 0x0a, 0x16, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x64, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x65, 0x67, 0x72, 0x65, 0x65, 0x73, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x08, 0x52, 0x14, 0x77, 0x69, 0x6e, 0x64, 0x44, 0x69, 0x72, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x67, 0x72, 0x65, 0x65, 0x73, 0x88, 0x01, 0x01, 0x12,
+	0x2e, 0x0a, 0x11, 0x72, 0x61, 0x69, 0x6e, 0x5f, 0x70, 0x61, 0x73, 0x74, 0x5f, 0x68, 0x6f, 0x75,
+	0x72, 0x5f, 0x6d, 0x6d, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x01, 0x48, 0x09, 0x52, 0x0e, 0x72, 0x61,
+	0x69, 0x6e, 0x50, 0x61, 0x73, 0x74, 0x48, 0x6f, 0x75, 0x72, 0x4d, 0x6d, 0x88, 0x01, 0x01, 0x12,
+	0x27, 0x0a, 0x0d, 0x72, 0x61, 0x69, 0x6e, 0x5f, 0x74, 0x6f, 0x64, 0x61, 0x79, 0x5f, 0x6d, 0x6d,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x01, 0x48, 0x0a, 0x52, 0x0b, 0x72, 0x61, 0x69, 0x6e, 0x54, 0x6f,
+	0x64, 0x61, 0x79, 0x4d, 0x6d, 0x88, 0x01, 0x01, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x74, 0x65, 0x6d,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x63, 0x65, 0x6c, 0x73, 0x69, 0x75, 0x73,
+	0x42, 0x14, 0x0a, 0x12, 0x5f, 0x64, 0x65, 0x77, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x5f, 0x63,
+	0x65, 0x6c, 0x73, 0x69, 0x75, 0x73, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x68, 0x75, 0x6d, 0x69, 0x64,
+	0x69, 0x74, 0x79, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x42, 0x1d, 0x0a, 0x1b, 0x5f,
+	0x69, 0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x5f, 0x63, 0x65, 0x6c, 0x73, 0x69, 0x75, 0x73, 0x42, 0x1a, 0x0a, 0x18, 0x5f, 0x69,
+	0x6e, 0x64, 0x6f, 0x6f, 0x72, 0x5f, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x5f, 0x70,
+	0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x42, 0x1a, 0x0a, 0x18, 0x5f, 0x62, 0x61, 0x72, 0x6f, 0x6d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x5f, 0x68,
+	0x70, 0x61, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x73, 0x70, 0x65, 0x65,
+	0x64, 0x5f, 0x6b, 0x70, 0x68, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x67,
+	0x75, 0x73, 0x74, 0x5f, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x6b, 0x70, 0x68, 0x42, 0x19, 0x0a,
+	0x17, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x64, 0x65, 0x67, 0x72, 0x65, 0x65, 0x73, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x72, 0x61, 0x69,
+	0x6e, 0x5f, 0x70, 0x61, 0x73, 0x74, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x5f, 0x6d, 0x6d, 0x42, 0x10,
+	0x0a, 0x0e, 0x5f, 0x72, 0x61, 0x69, 0x6e, 0x5f, 0x74, 0x6f, 0x64, 0x61, 0x79, 0x5f, 0x6d, 0x6d,
+	0x22, 0x15, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x43, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x2b, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x77, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x08, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x31, 0x0a, 0x10,
+	0x47, 0x65, 0x74, 0x4c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22,
+	0x4a, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x0b, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x77, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b,
+	0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x3a, 0x0a, 0x19, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x53, 0x0a, 0x1a, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x0b, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x77, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0b, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x32, 0x80, 0x02, 0x0a,
+	0x12, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x49, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x1b, 0x2e, 0x70, 0x77, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1c, 0x2e, 0x70, 0x77, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x40,
+	0x0a, 0x09, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x12, 0x18, 0x2e, 0x70, 0x77,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x70, 0x77, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x4c, 0x61, 0x74, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x5d, 0x0a, 0x12, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x21, 0x2e, 0x70, 0x77, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x70, 0x77, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42,
+	0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x6f,
+	0x73, 0x68, 0x75, 0x61, 0x73, 0x69, 0x6e, 0x67, 0x2f, 0x70, 0x77, 0x73, 0x5f, 0x65, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x65, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x77, 0x73, 0x2f, 0x76, 0x31,
+	0x3b, 0x70, 0x77, 0x73, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_api_pws_v1_pws_proto_rawDescOnce sync.Once
+	file_api_pws_v1_pws_proto_rawDescData []byte
+)
+
+func file_api_pws_v1_pws_proto_rawDescGZIP() []byte {
+	file_api_pws_v1_pws_proto_rawDescOnce.Do(func() {
+		file_api_pws_v1_pws_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_pws_v1_pws_proto_rawDesc), len(file_api_pws_v1_pws_proto_rawDesc)))
+	})
+	return file_api_pws_v1_pws_proto_rawDescData
+}
+
+var file_api_pws_v1_pws_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_api_pws_v1_pws_proto_goTypes = []any{
+	(*Station)(nil),                    // 0: pws.v1.Station
+	(*Observation)(nil),                // 1: pws.v1.Observation
+	(*ListStationsRequest)(nil),        // 2: pws.v1.ListStationsRequest
+	(*ListStationsResponse)(nil),       // 3: pws.v1.ListStationsResponse
+	(*GetLatestRequest)(nil),           // 4: pws.v1.GetLatestRequest
+	(*GetLatestResponse)(nil),          // 5: pws.v1.GetLatestResponse
+	(*StreamObservationsRequest)(nil),  // 6: pws.v1.StreamObservationsRequest
+	(*StreamObservationsResponse)(nil), // 7: pws.v1.StreamObservationsResponse
+	(*timestamppb.Timestamp)(nil),      // 8: google.protobuf.Timestamp
+}
+var file_api_pws_v1_pws_proto_depIdxs = []int32{
+	8, // 0: pws.v1.Station.last_seen:type_name -> google.protobuf.Timestamp
+	8, // 1: pws.v1.Observation.time:type_name -> google.protobuf.Timestamp
+	8, // 2: pws.v1.Observation.received_at:type_name -> google.protobuf.Timestamp
+	0, // 3: pws.v1.ListStationsResponse.stations:type_name -> pws.v1.Station
+	1, // 4: pws.v1.GetLatestResponse.observation:type_name -> pws.v1.Observation
+	1, // 5: pws.v1.StreamObservationsResponse.observation:type_name -> pws.v1.Observation
+	2, // 6: pws.v1.ObservationService.ListStations:input_type -> pws.v1.ListStationsRequest
+	4, // 7: pws.v1.ObservationService.GetLatest:input_type -> pws.v1.GetLatestRequest
+	6, // 8: pws.v1.ObservationService.StreamObservations:input_type -> pws.v1.StreamObservationsRequest
+	3, // 9: pws.v1.ObservationService.ListStations:output_type -> pws.v1.ListStationsResponse
+	5, // 10: pws.v1.ObservationService.GetLatest:output_type -> pws.v1.GetLatestResponse
+	7, // 11: pws.v1.ObservationService.StreamObservations:output_type -> pws.v1.StreamObservationsResponse
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_api_pws_v1_pws_proto_init() }
+func file_api_pws_v1_pws_proto_init() {
+	if File_api_pws_v1_pws_proto != nil {
+		return
+	}
+	file_api_pws_v1_pws_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_pws_v1_pws_proto_rawDesc), len(file_api_pws_v1_pws_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_pws_v1_pws_proto_goTypes,
+		DependencyIndexes: file_api_pws_v1_pws_proto_depIdxs,
+		MessageInfos:      file_api_pws_v1_pws_proto_msgTypes,
+	}.Build()
+	File_api_pws_v1_pws_proto = out.File
+	file_api_pws_v1_pws_proto_goTypes = nil
+	file_api_pws_v1_pws_proto_depIdxs = nil
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/pws/v1/pws.proto
+
+package pwsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ObservationService_ListStations_FullMethodName       = "/pws.v1.ObservationService/ListStations"
+	ObservationService_GetLatest_FullMethodName          = "/pws.v1.ObservationService/GetLatest"
+	ObservationService_StreamObservations_FullMethodName = "/pws.v1.ObservationService/StreamObservations"
+)
+
+// ObservationServiceClient is the client API for ObservationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ObservationService provides access to weather station observations received
+// by pws_exporter.
+type ObservationServiceClient interface {
+	// ListStations returns the stations that have submitted observations.
+	ListStations(ctx context.Context, in *ListStationsRequest, opts ...grpc.CallOption) (*ListStationsResponse, error)
+	// GetLatest returns the most recent observation for a station.
+	GetLatest(ctx context.Context, in *GetLatestRequest, opts ...grpc.CallOption) (*GetLatestResponse, error)
+	// StreamObservations streams new observations as they are received.
+	StreamObservations(ctx context.Context, in *StreamObservationsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamObservationsResponse], error)
+}
+
+type observationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewObservationServiceClient(cc grpc.ClientConnInterface) ObservationServiceClient {
+	return &observationServiceClient{cc}
+}
+
+func (c *observationServiceClient) ListStations(ctx context.Context, in *ListStationsRequest, opts ...grpc.CallOption) (*ListStationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStationsResponse)
+	err := c.cc.Invoke(ctx, ObservationService_ListStations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *observationServiceClient) GetLatest(ctx context.Context, in *GetLatestRequest, opts ...grpc.CallOption) (*GetLatestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLatestResponse)
+	err := c.cc.Invoke(ctx, ObservationService_GetLatest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *observationServiceClient) StreamObservations(ctx context.Context, in *StreamObservationsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamObservationsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ObservationService_ServiceDesc.Streams[0], ObservationService_StreamObservations_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamObservationsRequest, StreamObservationsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ObservationService_StreamObservationsClient = grpc.ServerStreamingClient[StreamObservationsResponse]
+
+// ObservationServiceServer is the server API for ObservationService service.
+// All implementations must embed UnimplementedObservationServiceServer
+// for forward compatibility.
+//
+// ObservationService provides access to weather station observations received
+// by pws_exporter.
+type ObservationServiceServer interface {
+	// ListStations returns the stations that have submitted observations.
+	ListStations(context.Context, *ListStationsRequest) (*ListStationsResponse, error)
+	// GetLatest returns the most recent observation for a station.
+	GetLatest(context.Context, *GetLatestRequest) (*GetLatestResponse, error)
+	// StreamObservations streams new observations as they are received.
+	StreamObservations(*StreamObservationsRequest, grpc.ServerStreamingServer[StreamObservationsResponse]) error
+	mustEmbedUnimplementedObservationServiceServer()
+}
+
+// UnimplementedObservationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedObservationServiceServer struct{}
+
+func (UnimplementedObservationServiceServer) ListStations(context.Context, *ListStationsRequest) (*ListStationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStations not implemented")
+}
+func (UnimplementedObservationServiceServer) GetLatest(context.Context, *GetLatestRequest) (*GetLatestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatest not implemented")
+}
+func (UnimplementedObservationServiceServer) StreamObservations(*StreamObservationsRequest, grpc.ServerStreamingServer[StreamObservationsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamObservations not implemented")
+}
+func (UnimplementedObservationServiceServer) mustEmbedUnimplementedObservationServiceServer() {}
+func (UnimplementedObservationServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeObservationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ObservationServiceServer will
+// result in compilation errors.
+type UnsafeObservationServiceServer interface {
+	mustEmbedUnimplementedObservationServiceServer()
+}
+
+func RegisterObservationServiceServer(s grpc.ServiceRegistrar, srv ObservationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedObservationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ObservationService_ServiceDesc, srv)
+}
+
+func _ObservationService_ListStations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ObservationServiceServer).ListStations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ObservationService_ListStations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ObservationServiceServer).ListStations(ctx, req.(*ListStationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ObservationService_GetLatest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ObservationServiceServer).GetLatest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ObservationService_GetLatest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ObservationServiceServer).GetLatest(ctx, req.(*GetLatestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ObservationService_StreamObservations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamObservationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ObservationServiceServer).StreamObservations(m, &grpc.GenericServerStream[StreamObservationsRequest, StreamObservationsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ObservationService_StreamObservationsServer = grpc.ServerStreamingServer[StreamObservationsResponse]
+
+// ObservationService_ServiceDesc is the grpc.ServiceDesc for ObservationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ObservationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pws.v1.ObservationService",
+	HandlerType: (*ObservationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListStations",
+			Handler:    _ObservationService_ListStations_Handler,
+		},
+		{
+			MethodName: "GetLatest",
+			Handler:    _ObservationService_GetLatest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamObservations",
+			Handler:       _ObservationService_StreamObservations_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/pws/v1/pws.proto",
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/export"
+	"github.com/joshuasing/pws_exporter/internal/remotewrite"
+	"github.com/joshuasing/pws_exporter/internal/store"
+)
+
+// runBackfill implements the "backfill" subcommand, which sends observations
+// from the observation store to a Prometheus remote write endpoint.
+func runBackfill(args []string) int {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	var (
+		storePath = fs.String("store", "", "SQLite observation store path")
+		url       = fs.String("url", "", "Remote write endpoint URL")
+		username  = fs.String("username", "", "Remote write basic authentication username")
+		password  = fs.String("password", "", "Remote write basic authentication password")
+		labels    = fs.String("labels", "", "Comma-separated list of name=value labels added to all series")
+		station   = fs.String("station", "", "Only backfill observations for this station ID")
+		from      = fs.String("from", "", "Start of the time range to backfill (RFC 3339)")
+		to        = fs.String("to", "", "End of the time range to backfill (RFC 3339)")
+	)
+	_ = fs.Parse(args)
+
+	if *storePath == "" || *url == "" {
+		fmt.Fprintln(os.Stderr, "backfill: -store and -url are required")
+		return 2
+	}
+	q := store.Query{StationID: *station}
+	var err error
+	if q.From, err = store.ParseTime(*from); err != nil {
+		fmt.Fprintf(os.Stderr, "backfill: invalid -from: %v\n", err)
+		return 2
+	}
+	if q.To, err = store.ParseTime(*to); err != nil {
+		fmt.Fprintf(os.Stderr, "backfill: invalid -to: %v\n", err)
+		return 2
+	}
+	extraLabels, err := parseLabels(*labels)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backfill: invalid -labels: %v\n", err)
+		return 2
+	}
+
+	s, err := store.Open(*storePath, store.Options{})
+	if err != nil {
+		slog.Error("Failed to open observation store", slog.Any("err", err))
+		return 1
+	}
+	defer s.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	client := &remotewrite.Client{
+		URL:        *url,
+		Username:   *username,
+		Password:   *password,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	n, err := export.RemoteWrite(ctx, s, q, client, extraLabels)
+	if err != nil {
+		slog.Error("Failed to backfill observations",
+			slog.Int("sent", n), slog.Any("err", err))
+		return 1
+	}
+	slog.Info("Backfilled observations", slog.Int("count", n))
+	return 0
+}
+
+// parseLabels parses a comma-separated list of name=value labels. The
+// __name__ and station_id labels are set for each series, and cannot be
+// overridden.
+func parseLabels(s string) ([]remotewrite.Label, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var labels []remotewrite.Label
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid label %q", pair)
+		}
+		if name == "__name__" || name == "station_id" {
+			return nil, fmt.Errorf("reserved label %q", name)
+		}
+		labels = append(labels, remotewrite.Label{Name: name, Value: value})
+	}
+	return labels, nil
+}
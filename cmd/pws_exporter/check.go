@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter"
+)
+
+// minCertValidity is the shortest generated TLS certificate validity period
+// accepted by the check subcommand. The certificate is renewed once 90% of
+// the period has passed, and renewal is checked hourly.
+const minCertValidity = 24 * time.Hour
+
+// runCheck implements the "check" subcommand, which validates the
+// configuration file and flags without starting the exporter: the
+// configuration file, the exporter IP address, the listen addresses, the
+// reachability of the upstream resolver and the TLS certificate settings.
+// It accepts the same flags as the exporter.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	flag.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	var (
+		offline = fs.Bool("offline", false, "Skip checks that require network access (upstream resolver)")
+		timeout = fs.Duration("timeout", 5*time.Second, "Timeout for the upstream resolver query")
+	)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pws_exporter check [flags]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		report(false, "config", *configFile, err.Error())
+		return 1
+	}
+	if *configFile != "" {
+		report(true, "config", *configFile, "valid")
+	}
+	ec, err := exporter.ResolveConfig(exporterConfig(cfg))
+	if err != nil {
+		report(false, "exporter", "", err.Error())
+		return 1
+	}
+	ipDetail := "set with -exporter"
+	if ec.ExporterIPDetected {
+		ipDetail = "detected, set -exporter if weather stations cannot reach this address"
+	}
+	report(true, "exporter", strings.TrimSpace(ec.ExporterIP+" "+ec.ExporterIPv6), ipDetail)
+
+	ok := checkListeners(ec)
+	if len(ec.DNSListeners) > 0 && !*offline {
+		ok = checkResolver(ec.UpstreamResolver, *timeout) && ok
+	}
+	ok = checkTLS(ec) && ok
+	if !ok {
+		return 1
+	}
+	fmt.Println("Configuration is valid.")
+	return 0
+}
+
+// checkListeners checks that the listen addresses of the servers that would
+// be started are valid, and that no two servers use the same address.
+func checkListeners(ec *exporter.EffectiveConfig) bool {
+	type listener struct {
+		name, network, address string
+	}
+	var listeners []listener
+	for _, l := range ec.DNSListeners {
+		listeners = append(listeners, listener{l.Name, "udp", l.Address})
+	}
+	switch {
+	case ec.SingleServer:
+		// The WU API is served by the metrics server.
+	default:
+		if ec.WUListenAddress != "" {
+			listeners = append(listeners, listener{"wu", "tcp", ec.WUListenAddress})
+		}
+		if ec.WUTLSListenAddress != "" && !ec.WUSinglePort {
+			listeners = append(listeners, listener{"wu_tls", "tcp", ec.WUTLSListenAddress})
+		}
+	}
+	if ec.SNMPListenAddress != "" {
+		listeners = append(listeners, listener{"snmp", "udp", ec.SNMPListenAddress})
+	}
+	if ec.ListenAddress != "" {
+		listeners = append(listeners, listener{"metrics", "tcp", ec.ListenAddress})
+	}
+	if ec.GRPCListenAddress != "" {
+		listeners = append(listeners, listener{"grpc", "tcp", ec.GRPCListenAddress})
+	}
+
+	ok := true
+	used := make(map[string]string, len(listeners))
+	for _, l := range listeners {
+		addr, err := resolveListenAddress(l.network, l.address)
+		if err != nil {
+			ok = false
+			report(false, "listen", l.name, err.Error())
+			continue
+		}
+		if other, found := used[l.network+" "+addr]; found {
+			ok = false
+			report(false, "listen", l.name, fmt.Sprintf("%s is also used by %s, use a different address", l.address, other))
+			continue
+		}
+		used[l.network+" "+addr] = l.name
+		report(true, "listen", l.name, l.network+" "+l.address)
+	}
+	return ok
+}
+
+// resolveListenAddress resolves a listen address, without listening on it.
+func resolveListenAddress(network, address string) (string, error) {
+	if network == "udp" {
+		addr, err := net.ResolveUDPAddr(network, address)
+		if err != nil {
+			return "", err
+		}
+		return addr.String(), nil
+	}
+	addr, err := net.ResolveTCPAddr(network, address)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
+
+// checkResolver checks that the upstream resolver answers queries for the
+// domains forwarded by the DNS server.
+func checkResolver(resolver string, timeout time.Duration) bool {
+	if resolver == "" {
+		report(false, "resolver", "", "no upstream resolver, set -resolver")
+		return false
+	}
+	domain := exporter.ForwardDomains()[0]
+	c := &dns.Client{Timeout: timeout}
+	ips, detail := query(c, resolver, domain, dns.TypeA)
+	if len(ips) == 0 {
+		report(false, "resolver", resolver, fmt.Sprintf("%s: %s, check -resolver", domain, detail))
+		return false
+	}
+	report(true, "resolver", resolver, domain+": "+detail)
+	return true
+}
+
+// checkTLS checks the generated TLS certificate settings, if the WU HTTPS
+// server would be started.
+func checkTLS(ec *exporter.EffectiveConfig) bool {
+	if !ec.WUTLS {
+		return true
+	}
+	if ec.CertValidity < minCertValidity {
+		report(false, "tls", "certificate", fmt.Sprintf("validity %s is too short, set -tls-cert-validity to at least %s",
+			ec.CertValidity, minCertValidity))
+		return false
+	}
+	report(true, "tls", "certificate", fmt.Sprintf("self-signed, valid for %s", ec.CertValidity))
+	return true
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+
+	"github.com/joshuasing/pws_exporter/internal/export"
+	"github.com/joshuasing/pws_exporter/internal/journal"
+	"github.com/joshuasing/pws_exporter/internal/store"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// runConvert implements the "convert" subcommand, which converts observations
+// from the observation store or the raw submissions recorded in a journal to
+// CSV, JSON or OpenMetrics text.
+func runConvert(args []string) int {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	var (
+		storePath   = fs.String("store", "", "SQLite observation store path")
+		journalPath = fs.String("journal", "", "Submission journal path")
+		format      = fs.String("format", "csv", "Output format (csv, json or openmetrics)")
+		output      = fs.String("output", "", "Output file path (written to stdout if empty)")
+		station     = fs.String("station", "", "Only convert observations for this station ID")
+		from        = fs.String("from", "", "Start of the time range to convert (RFC 3339)")
+		to          = fs.String("to", "", "End of the time range to convert (RFC 3339)")
+	)
+	_ = fs.Parse(args)
+
+	if (*storePath == "") == (*journalPath == "") {
+		fmt.Fprintln(os.Stderr, "convert: exactly one of -store or -journal is required")
+		return 2
+	}
+	q := store.Query{StationID: *station}
+	var err error
+	if q.From, err = store.ParseTime(*from); err != nil {
+		fmt.Fprintf(os.Stderr, "convert: invalid -from: %v\n", err)
+		return 2
+	}
+	if q.To, err = store.ParseTime(*to); err != nil {
+		fmt.Fprintf(os.Stderr, "convert: invalid -to: %v\n", err)
+		return 2
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			slog.Error("Failed to create output file", slog.Any("err", err))
+			return 1
+		}
+		defer out.Close()
+	}
+	w := bufio.NewWriter(out)
+	enc, err := export.NewEncoder(*format, w)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "convert: %v\n", err)
+		return 2
+	}
+
+	var n int
+	encode := func(o weather.Observation) error {
+		n++
+		return enc.Encode(o)
+	}
+	if *storePath != "" {
+		err = convertStore(*storePath, q, encode)
+	} else {
+		err = convertJournal(*journalPath, q, encode)
+	}
+	if err == nil {
+		err = enc.Close()
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		slog.Error("Failed to convert observations", slog.Any("err", err))
+		return 1
+	}
+	slog.Info("Converted observations",
+		slog.String("format", *format), slog.Int("count", n))
+	return 0
+}
+
+// convertStore calls fn for each stored observation matching the query.
+func convertStore(path string, q store.Query, fn func(weather.Observation) error) error {
+	s, err := store.Open(path, store.Options{})
+	if err != nil {
+		return fmt.Errorf("open observation store: %w", err)
+	}
+	defer s.Close()
+	return s.Observations(context.Background(), q, fn)
+}
+
+// convertJournal parses each raw submission recorded in the journal, and
+// calls fn for each observation matching the query. Fields that cannot be
+// parsed are ignored, as they are by the exporter.
+func convertJournal(path string, q store.Query, fn func(weather.Observation) error) error {
+	return journal.ReadFile(path, func(e journal.Entry) error {
+		values, err := url.ParseQuery(e.RawQuery)
+		if err != nil {
+			slog.Warn("Skipping malformed journal entry",
+				slog.Uint64("seq", e.Seq), slog.Any("err", err))
+			return nil
+		}
+		dm, _ := wu.ParseMeasurement(values, e.ReceivedAt)
+		o := weather.Observation{
+			StationID:   values.Get("ID"),
+			ReceivedAt:  e.ReceivedAt,
+			Measurement: dm,
+		}
+		if !q.Match(o) {
+			return nil
+		}
+		return fn(o)
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/joshuasing/pws_exporter/internal/export"
+	"github.com/joshuasing/pws_exporter/internal/store"
+)
+
+// runExport implements the "export" subcommand, which exports observations
+// from the observation store to files.
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	var (
+		storePath = fs.String("store", "", "SQLite observation store path")
+		format    = fs.String("format", "parquet", "Export format (parquet)")
+		output    = fs.String("output", ".", "Output directory")
+		station   = fs.String("station", "", "Only export observations for this station ID")
+		from      = fs.String("from", "", "Start of the time range to export (RFC 3339)")
+		to        = fs.String("to", "", "End of the time range to export (RFC 3339)")
+	)
+	_ = fs.Parse(args)
+
+	if *storePath == "" {
+		fmt.Fprintln(os.Stderr, "export: -store is required")
+		return 2
+	}
+	q := store.Query{StationID: *station}
+	var err error
+	if q.From, err = store.ParseTime(*from); err != nil {
+		fmt.Fprintf(os.Stderr, "export: invalid -from: %v\n", err)
+		return 2
+	}
+	if q.To, err = store.ParseTime(*to); err != nil {
+		fmt.Fprintf(os.Stderr, "export: invalid -to: %v\n", err)
+		return 2
+	}
+
+	s, err := store.Open(*storePath, store.Options{})
+	if err != nil {
+		slog.Error("Failed to open observation store", slog.Any("err", err))
+		return 1
+	}
+	defer s.Close()
+
+	switch *format {
+	case "parquet":
+		n, err := export.Parquet(context.Background(), s, q, *output)
+		if err != nil {
+			slog.Error("Failed to export observations", slog.Any("err", err))
+			return 1
+		}
+		slog.Info("Exported observations",
+			slog.String("format", *format),
+			slog.String("output", *output),
+			slog.Int("files", n))
+	default:
+		fmt.Fprintf(os.Stderr, "export: unsupported format %q\n", *format)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/joshuasing/pws_exporter/internal/logfile"
+	"github.com/joshuasing/pws_exporter/internal/syslog"
+)
+
+// setupLogging configures the default logger with the given level and format.
+// Logs are written to syslog or the log file if configured, otherwise stderr.
+// The returned closer closes the log output.
+func setupLogging(level, format string) (io.Closer, error) {
+	lvl, err := parseLogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	format = strings.ToLower(format)
+	if format != "text" && format != "json" {
+		return nil, fmt.Errorf("invalid log format: %s", format)
+	}
+
+	if *logSyslog != "" {
+		if *logFile != "" {
+			return nil, errors.New("-log-file and -log-syslog cannot be used together")
+		}
+		w, err := syslog.Dial(*logSyslog, "pws_exporter")
+		if err != nil {
+			return nil, fmt.Errorf("connect to syslog: %w", err)
+		}
+		slog.SetDefault(slog.New(syslog.NewHandler(w, func(w io.Writer) slog.Handler {
+			return newLogHandler(format, w, &slog.HandlerOptions{
+				Level: lvl,
+				// Syslog messages have their own timestamp.
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if len(groups) == 0 && a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				},
+			})
+		})))
+		return w, nil
+	}
+
+	var out io.WriteCloser = nopCloser{os.Stderr}
+	if *logFile != "" {
+		out, err = logfile.Open(*logFile, logfile.Options{
+			MaxSize:    *logMaxSize << 20,
+			MaxAge:     *logMaxAge,
+			MaxBackups: *logMaxBackups,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open log file: %w", err)
+		}
+	}
+	if format == "text" {
+		// Use the default handler, which writes using the log package.
+		log.SetOutput(out)
+		slog.SetLogLoggerLevel(lvl)
+		return out, nil
+	}
+	slog.SetDefault(slog.New(newLogHandler(format, out, &slog.HandlerOptions{Level: lvl})))
+	return out, nil
+}
+
+// newLogHandler returns a text or JSON log handler writing to w.
+func newLogHandler(format string, w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelError, fmt.Errorf("invalid log level: %s", level)
+	}
+}
@@ -21,117 +21,231 @@ package main
 
 import (
 	"context"
-	"errors"
 	"flag"
-	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gopkg.in/yaml.v3"
 
-	"github.com/joshuasing/pws_exporter/internal/exporter"
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter"
 )
 
 const defaultListenAddress = ":9452"
 
 var (
+	configFile         = flag.String("config", "", "Configuration file path")
 	logLevel           = flag.String("log", "info", "Log level")
-	listenAddress      = flag.String("listen", defaultListenAddress, "Listen address")
-	exporterAddress    = flag.String("exporter", "", "Exporter IP address")
-	upstreamResolver   = flag.String("resolver", "8.8.8.8:53", "Upstream DNS resolver")
-	dnsListenAddress   = flag.String("dns-listen", "", "DNS server listen address")
-	wuListenAddress    = flag.String("wu-listen", ":80", "WU HTTP server listen address")
-	wuTLSListenAddress = flag.String("wu-tls-listen", ":443", "WU HTTPS server listen address")
+	logFormat          = flag.String("log-format", "text", "Log format (text or json)")
+	logFile            = flag.String("log-file", "", "Log file path (logs are written to stderr if empty)")
+	logMaxSize         = flag.Int64("log-max-size", 100, "Log file size in MiB after which it is rotated (0 disables)")
+	logMaxAge          = flag.Duration("log-max-age", 0, "Log file age after which it is rotated (0 disables)")
+	logMaxBackups      = flag.Int("log-max-backups", 5, "Number of rotated log files to keep (0 keeps all)")
+	logSyslog          = flag.String("log-syslog", "", "Syslog server to send logs to (local, udp://host:port, tcp://host:port or unix:///path)")
+	listenAddress      = flag.String("listen", defaultListenAddress, "Listen address (\"off\" disables)")
+	exporterAddress    = flag.String("exporter", "", "Exporter IP address, or the CIDR or interface name to select it from (detected if empty)")
+	exporterIPv6       = flag.String("exporter-ipv6", "", "Exporter IPv6 address, or the CIDR or interface name to select it from, answered to AAAA queries (disabled if empty)")
+	upstreamResolver   = flag.String("resolver", "8.8.8.8:53", "Upstream DNS resolver (IPv6 addresses in brackets)")
+	dnsListenAddress   = flag.String("dns-listen", "", "DNS server listen addresses, comma-separated (the host may be an interface name)")
+	wuListenAddress    = flag.String("wu-listen", ":80", "WU HTTP server listen address (\"off\" disables)")
+	wuTLSListenAddress = flag.String("wu-tls-listen", ":443", "WU HTTPS server listen address (\"off\" disables)")
+	tlsCertValidity    = flag.Duration("tls-cert-validity", 10*365*24*time.Hour, "Validity period of the generated TLS certificate, which is renewed once 90% of the period has passed")
+	wuSinglePort       = flag.Bool("wu-single-port", false, "Serve WU HTTP and HTTPS on the WU HTTP server listen address")
+	singleServer       = flag.Bool("single-server", false, "Serve the submission APIs from the metrics HTTP server listen address")
+	reusePort          = flag.Bool("reuse-port", false, "Set SO_REUSEPORT on listeners, allowing zero-downtime restarts")
+	storePath          = flag.String("store", "", "SQLite observation store path (disabled if empty)")
+	storeRetention     = flag.Duration("store-retention", 0, "Observation store retention period (0 keeps observations forever)")
+	statePath          = flag.String("state-file", "", "File used to persist exporter state across restarts (disabled if empty)")
+	journalPath        = flag.String("journal", "", "Write-ahead journal of raw submissions (disabled if empty)")
+	historySize        = flag.Int("history-size", 100, "Number of observations kept in memory for each station")
+	rateWindow         = flag.Duration("rate-window", 30*time.Minute, "Rolling window that temperature and humidity rates of change are computed over")
+	weewxAddress       = flag.String("weewx", "", "WeeWX interceptor driver address to forward submissions to (host:port or unix:/path, disabled if empty)")
+	feedPath           = flag.String("feed", "", "Unix socket or named pipe path to write observations to as line-delimited JSON (disabled if empty)")
+	snmpListenAddress  = flag.String("snmp-listen", "", "SNMP agent listen address (disabled if empty)")
+	snmpCommunity      = flag.String("snmp-community", "public", "SNMP community string")
+	snmpRootOID        = flag.String("snmp-root-oid", "1.3.6.1.3.9452", "SNMP agent MIB subtree root OID")
+	grpcListenAddress  = flag.String("grpc-listen", "", "gRPC API listen address (disabled if empty)")
+	printConfig        = flag.Bool("print-config", false, "Print the effective configuration, with secrets redacted, and exit")
+	otlpEndpoint       = flag.String("otlp-endpoint", "", "OTLP/HTTP endpoint to export traces to, e.g. http://localhost:4318 (disabled if empty)")
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "backfill":
+			os.Exit(runBackfill(os.Args[2:]))
+		case "convert":
+			os.Exit(runConvert(os.Args[2:]))
+		case "probe":
+			os.Exit(runProbe(os.Args[2:]))
+		case "test-dns":
+			os.Exit(runTestDNS(os.Args[2:]))
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		}
+	}
+
 	flag.Parse()
+	if *printConfig {
+		os.Exit(runPrintConfig())
+	}
 	os.Exit(run())
 }
 
 func run() int {
-	lvl, err := parseLogLevel(*logLevel)
+	logOutput, err := setupLogging(*logLevel, *logFormat)
 	if err != nil {
-		slog.Error("Failed to parse log level", slog.Any("err", err))
+		slog.Error("Failed to set up logging", slog.Any("err", err))
 		return 1
 	}
-	slog.SetLogLoggerLevel(lvl)
+	defer logOutput.Close()
 
 	slog.Info("Starting WU Weather Station exporter")
 
+	if *otlpEndpoint != "" {
+		shutdownTracing, err := setupTracing(context.Background(), *otlpEndpoint)
+		if err != nil {
+			slog.Error("Failed to set up tracing", slog.Any("err", err))
+			return 1
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				slog.Error("Failed to flush traces", slog.Any("err", err))
+			}
+		}()
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		slog.Error("Failed to load configuration", slog.Any("err", err))
+		return 1
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	ex, err := exporter.NewExporter(exporter.Config{
-		ExporterIP:         *exporterAddress,
-		UpstreamResolver:   *upstreamResolver,
-		DNSListenAddress:   *dnsListenAddress,
-		WUListenAddress:    *wuListenAddress,
-		WUTLSListenAddress: *wuTLSListenAddress,
-	})
+	ex, err := exporter.NewExporter(exporterConfig(cfg))
 	if err != nil {
 		slog.Error("Failed to create exporter", slog.Any("err", err))
 		return 1
 	}
 
-	exErr := make(chan error)
+	// Reload the configuration and rotate the generated TLS certificate on
+	// SIGHUP.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 	go func() {
-		exErr <- ex.ListenAndServe()
-	}()
-
-	// Metrics handler
-	http.Handle("/metrics", promhttp.HandlerFor(ex.Registry(), promhttp.HandlerOpts{}))
-
-	// Run HTTP server in a goroutine
-	httpErr := make(chan error)
-	go func() {
-		srv := http.Server{
-			Addr:              *listenAddress,
-			ReadHeaderTimeout: 5 * time.Second,
+		for range hup {
+			if *configFile != "" {
+				if err := ex.Reload(); err != nil {
+					slog.Error("Failed to reload configuration", slog.Any("err", err))
+				}
+			}
+			if err := ex.RotateCertificate(); err != nil {
+				slog.Error("Failed to rotate TLS certificate", slog.Any("err", err))
+			}
 		}
-		slog.Info("Metrics HTTP server listening", slog.String("address", srv.Addr))
-		httpErr <- srv.ListenAndServe()
 	}()
 
-	select {
-	case <-ctx.Done():
-	case err = <-exErr:
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("Failed to start exporter", slog.Any("err", err))
-		}
+	// The exporter supervises all of its servers, and shuts them down
+	// together when ctx is done.
+	if err := ex.ListenAndServe(ctx); err != nil {
+		slog.Error("Exporter failed", slog.Any("err", err))
 		return 1
-	case err = <-httpErr:
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("Failed to start HTTP server", slog.Any("err", err))
-		}
-		if eerr := ex.Close(); eerr != nil {
-			slog.Error("Failed to close exporter", slog.Any("err", eerr))
-			return 1
-		}
-		if err != nil {
-			return 1
-		}
 	}
-
 	return 0
 }
 
-func parseLogLevel(level string) (slog.Level, error) {
-	switch strings.ToLower(level) {
-	case "debug":
-		return slog.LevelDebug, nil
-	case "info":
-		return slog.LevelInfo, nil
-	case "warn":
-		return slog.LevelWarn, nil
-	case "error":
-		return slog.LevelError, nil
-	default:
-		return slog.LevelError, fmt.Errorf("invalid log level: %s", level)
+// exporterConfig returns the exporter configuration for the flags and the
+// configuration file.
+func exporterConfig(cfg config.Config) exporter.Config {
+	return exporter.Config{
+		ListenAddress:      *listenAddress,
+		ExporterIP:         *exporterAddress,
+		ExporterIPv6:       *exporterIPv6,
+		UpstreamResolver:   *upstreamResolver,
+		DNSListenAddress:   *dnsListenAddress,
+		WUListenAddress:    *wuListenAddress,
+		WUTLSListenAddress: *wuTLSListenAddress,
+		WUSinglePort:       *wuSinglePort,
+		SingleServer:       *singleServer,
+		CertValidity:       *tlsCertValidity,
+		ReusePort:          *reusePort,
+		SocketActivation:   true,
+		FeedPath:           *feedPath,
+		SNMPListenAddress:  *snmpListenAddress,
+		SNMPCommunity:      *snmpCommunity,
+		SNMPRootOID:        *snmpRootOID,
+		GRPCListenAddress:  *grpcListenAddress,
+		Tenants:            cfg.Tenants,
+		Stations:           cfg.Stations,
+		Sites:              cfg.Sites,
+		StationIDs:         cfg.NormalizeStationIDs,
+		Relabel:            cfg.Relabel,
+		Admin:              cfg.Admin,
+		Ingest:             cfg.Ingest,
+		DNS:                cfg.DNS,
+		WUServer:           cfg.WUServer,
+		Alerts:             cfg.Alerts,
+		WeeWXAddress:       *weewxAddress,
+		WUForward:          cfg.WUForward,
+		Upstreams:          cfg.Upstreams,
+		OpenSenseMap:       cfg.OpenSenseMap,
+		Federation:         cfg.Federation,
+		GrafanaAnnotations: cfg.GrafanaAnnotations,
+		RTL433:             cfg.RTL433,
+		MQTTInput:          cfg.MQTTInput,
+		WUAPI:              cfg.WUAPI,
+		WeatherLinkIP:      cfg.WeatherLinkIP,
+		ConfigPath:         *configFile,
+		HistorySize:        *historySize,
+		RateWindow:         *rateWindow,
+		StorePath:          *storePath,
+		StoreRetention:     *storeRetention,
+		StoreDownsample:    cfg.Store.Downsample,
+		StatePath:          *statePath,
+		JournalPath:        *journalPath,
+	}
+}
+
+// loadConfig loads the configuration file, if one is set.
+func loadConfig() (config.Config, error) {
+	if *configFile == "" {
+		return config.Config{}, nil
 	}
+	c, err := config.Load(*configFile)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return *c, nil
+}
+
+// runPrintConfig prints the effective configuration as YAML, including the
+// detected exporter IP address, with secrets redacted.
+func runPrintConfig() int {
+	cfg, err := loadConfig()
+	if err != nil {
+		slog.Error("Failed to load configuration", slog.Any("err", err))
+		return 1
+	}
+	ec, err := exporter.ResolveConfig(exporterConfig(cfg))
+	if err != nil {
+		slog.Error("Failed to resolve configuration", slog.Any("err", err))
+		return 1
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(ec); err != nil {
+		slog.Error("Failed to encode configuration", slog.Any("err", err))
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,242 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// probeMetric is the metric checked by the probe subcommand after each test
+// submission.
+const probeMetric = "weather_station_temperature_celsius"
+
+// probeInterval is how often the probe subcommand scrapes the metrics while
+// waiting for a test submission to appear.
+const probeInterval = 250 * time.Millisecond
+
+// runProbe implements the "probe" subcommand, which verifies an installation
+// end-to-end: the DNS server answers the intercepted domains, test submissions
+// are accepted over HTTP and HTTPS, and the submitted values are exported.
+func runProbe(args []string) int {
+	fs := flag.NewFlagSet("probe", flag.ExitOnError)
+	var (
+		target      = fs.String("target", "", "Exporter host to probe")
+		dnsPort     = fs.Int("dns-port", 53, "Exporter DNS server port (0 skips the DNS check)")
+		wuPort      = fs.Int("wu-port", 80, "Exporter WU HTTP server port (0 skips the HTTP check)")
+		wuTLSPort   = fs.Int("wu-tls-port", 443, "Exporter WU HTTPS server port (0 skips the HTTPS check)")
+		metricsPort = fs.Int("metrics-port", 9452, "Exporter metrics server port")
+		station     = fs.String("station", "PROBE", "Station ID used for test submissions")
+		timeout     = fs.Duration("timeout", 10*time.Second, "Timeout for each check")
+	)
+	_ = fs.Parse(args)
+
+	if *target == "" {
+		fmt.Fprintln(os.Stderr, "probe: -target is required")
+		return 2
+	}
+
+	p := &prober{
+		target:     *target,
+		station:    *station,
+		timeout:    *timeout,
+		metricsURL: "http://" + net.JoinHostPort(*target, strconv.Itoa(*metricsPort)) + "/metrics",
+	}
+	ok := true
+	if *dnsPort != 0 {
+		ok = p.checkDNS(net.JoinHostPort(*target, strconv.Itoa(*dnsPort))) && ok
+	}
+	// Each submission uses a different temperature, so the metric check
+	// confirms that the value of that submission was exported.
+	if *wuPort != 0 {
+		ok = p.checkSubmission("http", *wuPort, 50) && ok
+	}
+	if *wuTLSPort != 0 {
+		ok = p.checkSubmission("https", *wuTLSPort, 59) && ok
+	}
+	if !ok {
+		return 1
+	}
+	fmt.Printf("Probe succeeded. The %q station can be deleted using the admin API.\n", *station)
+	return 0
+}
+
+// prober runs the probe subcommand checks.
+type prober struct {
+	target     string
+	station    string
+	timeout    time.Duration
+	metricsURL string
+}
+
+// checkDNS checks that the DNS server answers A or AAAA queries for the
+// intercepted domains.
+func (p *prober) checkDNS(addr string) bool {
+	ok := true
+	c := &dns.Client{Timeout: p.timeout}
+	for _, domain := range exporter.InterceptedDomains() {
+		ips, detail := query(c, addr, domain, dns.TypeA)
+		ips6, detail6 := query(c, addr, domain, dns.TypeAAAA)
+		answered := len(ips)+len(ips6) > 0
+		ok = answered && ok
+		report(answered, "dns", domain, detail+", "+detail6)
+	}
+	return ok
+}
+
+// checkSubmission sends a test submission with the given temperature to the
+// WU server using the scheme, and waits for the temperature to be exported.
+func (p *prober) checkSubmission(scheme string, port int, tempF float64) bool {
+	host := exporter.WUDomains()[0]
+	hc := &http.Client{
+		Timeout: p.timeout,
+		Transport: &http.Transport{
+			// The WU server uses a self-signed certificate.
+			TLSClientConfig: &tls.Config{
+				ServerName:         host,
+				InsecureSkipVerify: true, //nolint:gosec
+			},
+		},
+	}
+	defer hc.CloseIdleConnections()
+
+	q := url.Values{}
+	q.Set("ID", p.station)
+	q.Set("PASSWORD", "probe")
+	q.Set("action", "updateraww")
+	q.Set("dateutc", "now")
+	q.Set("tempf", strconv.FormatFloat(tempF, 'f', -1, 64))
+	u := scheme + "://" + net.JoinHostPort(p.target, strconv.Itoa(port)) + wu.SubmissionPath + "?" + q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		report(false, scheme, "submission", err.Error())
+		return false
+	}
+	req.Host = host
+	resp, err := hc.Do(req)
+	if err != nil {
+		report(false, scheme, "submission", err.Error())
+		return false
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		report(false, scheme, "submission", "unexpected status "+resp.Status)
+		return false
+	}
+	report(true, scheme, "submission", resp.Status)
+
+	want := (tempF - 32) * 5 / 9
+	if err := p.waitForMetric(want); err != nil {
+		report(false, scheme, "metrics", err.Error())
+		return false
+	}
+	report(true, scheme, "metrics", fmt.Sprintf("%s{station_id=%q} %v", probeMetric, p.station, want))
+	return true
+}
+
+// waitForMetric scrapes the metrics until the probe station's temperature has
+// the wanted value, or the timeout expires. Submissions are processed
+// asynchronously, so the value may not be exported immediately.
+func (p *prober) waitForMetric(want float64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	var lastErr error
+	for {
+		got, err := p.scrapeTemperature(ctx)
+		switch {
+		case err != nil:
+			lastErr = err
+		case math.Abs(got-want) < 1e-9:
+			return nil
+		default:
+			lastErr = fmt.Errorf("got %v, want %v", got, want)
+		}
+
+		select {
+		case <-ctx.Done():
+			return lastErr
+		case <-time.After(probeInterval):
+		}
+	}
+}
+
+// scrapeTemperature scrapes the metrics and returns the probe station's
+// temperature.
+func (p *prober) scrapeTemperature(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.metricsURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("scrape metrics: unexpected status %s", resp.Status)
+	}
+
+	var tp expfmt.TextParser
+	mfs, err := tp.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("parse metrics: %w", err)
+	}
+	if mf, ok := mfs[probeMetric]; ok {
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if lp.GetName() == "station_id" && lp.GetValue() == p.station {
+					return m.GetGauge().GetValue(), nil
+				}
+			}
+		}
+	}
+	return 0, errors.New("station not found in metrics")
+}
+
+// report prints the result of a probe check.
+func report(ok bool, check, subject, detail string) {
+	status := "OK  "
+	if !ok {
+		status = "FAIL"
+	}
+	fmt.Printf("%s %-9s %-32s %s\n", status, check, subject, detail)
+}
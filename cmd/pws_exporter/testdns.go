@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter"
+)
+
+// runTestDNS implements the "test-dns" subcommand, which queries a running DNS
+// server for each intercepted and forwarded domain, and prints the answer a
+// weather station would receive.
+func runTestDNS(args []string) int {
+	fs := flag.NewFlagSet("test-dns", flag.ExitOnError)
+	var (
+		server  = fs.String("server", "127.0.0.1:53", "DNS server address")
+		timeout = fs.Duration("timeout", 5*time.Second, "Timeout for each query")
+	)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pws_exporter test-dns [flags] [domain...]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	c := &dns.Client{Timeout: *timeout}
+	ok := true
+	for _, domain := range exporter.InterceptedDomains() {
+		// Dual-stack stations may query both A and AAAA records.
+		ips, detail := query(c, *server, domain, dns.TypeA)
+		ips6, detail6 := query(c, *server, domain, dns.TypeAAAA)
+		answered := len(ips)+len(ips6) > 0
+		ok = answered && ok
+		report(answered, "intercept", domain, detail+", "+detail6)
+	}
+	for _, domain := range exporter.ForwardDomains() {
+		ips, detail := query(c, *server, domain, dns.TypeA)
+		ok = len(ips) > 0 && ok
+		report(len(ips) > 0, "forward", strings.TrimSuffix(domain, "."), detail)
+	}
+	// Other domains are expected to be black holed.
+	for _, domain := range fs.Args() {
+		_, detail := query(c, *server, domain, dns.TypeA)
+		report(true, "other", domain, detail)
+	}
+	if !ok {
+		return 1
+	}
+	return 0
+}
+
+// query queries the DNS server for the A or AAAA records of the domain, and
+// returns the addresses along with a description of the answer.
+func query(c *dns.Client, server, domain string, qtype uint16) ([]string, string) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(domain), qtype)
+	res, _, err := c.Exchange(m, server)
+	if err != nil {
+		// The DNS server does not answer forwarded queries if the upstream
+		// resolver fails.
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, "no answer (upstream resolver failure?)"
+		}
+		return nil, err.Error()
+	}
+	if res.Rcode != dns.RcodeSuccess {
+		return nil, dns.RcodeToString[res.Rcode]
+	}
+
+	var ips, answers []string
+	for _, rr := range res.Answer {
+		switch rr := rr.(type) {
+		case *dns.A:
+			ips = append(ips, rr.A.String())
+			answers = append(answers, "A "+rr.A.String())
+		case *dns.AAAA:
+			ips = append(ips, rr.AAAA.String())
+			answers = append(answers, "AAAA "+rr.AAAA.String())
+		case *dns.CNAME:
+			answers = append(answers, "CNAME "+rr.Target)
+		}
+	}
+	if len(answers) == 0 {
+		return nil, "no " + dns.TypeToString[qtype] + " records"
+	}
+	return ips, strings.Join(answers, ", ")
+}
@@ -0,0 +1,595 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package alert implements a lightweight alert engine, which periodically
+// evaluates threshold rules against the latest observations and watches for
+// stations that stop submitting, and sends notifications when an alert starts
+// firing and when it recovers, unless the alert is silenced.
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// notifyTimeout is the maximum time to wait for a notification to be sent.
+const notifyTimeout = 30 * time.Second
+
+// queueSize is the maximum number of notifications waiting to be sent.
+const queueSize = 256
+
+// DefaultInterval is the default interval at which rules are evaluated.
+const DefaultInterval = 15 * time.Second
+
+// Operator is a comparison operator.
+type Operator string
+
+// Supported operators.
+const (
+	OperatorGreater      Operator = ">"
+	OperatorGreaterEqual Operator = ">="
+	OperatorLess         Operator = "<"
+	OperatorLessEqual    Operator = "<="
+)
+
+// ParseOperator parses a comparison operator.
+func ParseOperator(s string) (Operator, error) {
+	switch op := Operator(s); op {
+	case OperatorGreater, OperatorGreaterEqual, OperatorLess, OperatorLessEqual:
+		return op, nil
+	default:
+		return "", fmt.Errorf("invalid operator %q", s)
+	}
+}
+
+// breached returns whether the value breaches the threshold.
+func (op Operator) breached(v, threshold float64) bool {
+	switch op {
+	case OperatorGreater:
+		return v > threshold
+	case OperatorGreaterEqual:
+		return v >= threshold
+	case OperatorLess:
+		return v < threshold
+	case OperatorLessEqual:
+		return v <= threshold
+	default:
+		return false
+	}
+}
+
+// recovered returns whether the value has recovered from breaching the
+// threshold by more than the hysteresis.
+func (op Operator) recovered(v, threshold, hysteresis float64) bool {
+	switch op {
+	case OperatorGreater, OperatorGreaterEqual:
+		return !op.breached(v, threshold-hysteresis)
+	default:
+		return !op.breached(v, threshold+hysteresis)
+	}
+}
+
+// Rule is a threshold alert rule.
+type Rule struct {
+	// Name is the name of the rule.
+	Name string
+
+	// Field is the observation field compared to the threshold.
+	Field string
+
+	// Operator and Threshold are the condition that breaches the rule, e.g.
+	// temperature < 0.
+	Operator  Operator
+	Threshold float64
+
+	// For is the duration the condition must be breached for before the
+	// rule fires.
+	For time.Duration
+
+	// Hysteresis is how far the value must recover past the threshold before
+	// a firing rule is resolved, to avoid flapping around the threshold.
+	Hysteresis float64
+
+	// Stations are the stations the rule applies to. If empty, the rule
+	// applies to all stations.
+	Stations []string
+
+	// Labels are added to the rule's events, and can be matched by
+	// silences.
+	Labels map[string]string
+
+	// Notifier is used to send notifications for the rule.
+	Notifier Notifier
+}
+
+// OfflineRule is a rule that fires when a station has not submitted for
+// longer than a duration.
+type OfflineRule struct {
+	// Name is the name of the rule.
+	Name string
+
+	// After is the duration since the last submission after which the
+	// station is considered offline. If zero, the duration returned by
+	// Config.StaleAfter for the station is used.
+	After time.Duration
+
+	// Stations are the stations the rule applies to. If empty, the rule
+	// applies to all stations.
+	Stations []string
+
+	// Labels are added to the rule's events, and can be matched by
+	// silences.
+	Labels map[string]string
+
+	// Notifier is used to send notifications for the rule.
+	Notifier Notifier
+}
+
+// Config is the alert engine configuration. Rule names must be unique.
+type Config struct {
+	// Rules are the threshold rules.
+	Rules []Rule
+
+	// OfflineRules are the station offline rules.
+	OfflineRules []OfflineRule
+
+	// Silences are windows during which notifications are not sent.
+	Silences []Silence
+
+	// Interval is the interval at which rules are evaluated. If zero,
+	// DefaultInterval is used.
+	Interval time.Duration
+
+	// StaleAfter returns the duration since a station's last submission
+	// after which it is considered offline by offline rules without an
+	// After duration, e.g. derived from the station's expected submission
+	// interval. Stations for which it returns zero, or all stations if it
+	// is nil, are not watched by those rules.
+	StaleAfter func(stationID string) time.Duration
+}
+
+// Type is the type of an alert.
+type Type string
+
+// Alert types.
+const (
+	TypeThreshold Type = "threshold"
+	TypeOffline   Type = "offline"
+)
+
+// State is the state of an alert.
+type State string
+
+// Alert states.
+const (
+	StateFiring   State = "firing"
+	StateResolved State = "resolved"
+)
+
+// Event is a notification that an alert started firing or was resolved for a
+// station.
+type Event struct {
+	Type      Type              `json:"type"`
+	Rule      string            `json:"rule"`
+	StationID string            `json:"station_id"`
+	State     State             `json:"state"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+	Time      time.Time         `json:"time"`
+
+	// Threshold alert fields.
+	Field     string   `json:"field,omitempty"`
+	Value     *float64 `json:"value,omitempty"`
+	Operator  Operator `json:"operator,omitempty"`
+	Threshold *float64 `json:"threshold,omitempty"`
+
+	// Offline alert fields. LastSeen is the time of the station's last
+	// submission before it went offline, and RemoteAddr is the address it
+	// was last seen from.
+	LastSeen   *time.Time `json:"last_seen,omitempty"`
+	RemoteAddr string     `json:"remote_addr,omitempty"`
+}
+
+// Title returns a short summary of the event.
+func (e Event) Title() string {
+	switch {
+	case e.Type == TypeOffline && e.State == StateFiring:
+		return fmt.Sprintf("Station %s is offline", e.StationID)
+	case e.Type == TypeOffline:
+		return fmt.Sprintf("Station %s is back online", e.StationID)
+	default:
+		return fmt.Sprintf("[%s] %s: station %s", strings.ToUpper(string(e.State)), e.Rule, e.StationID)
+	}
+}
+
+// Message returns a human-readable description of the event.
+func (e Event) Message() string {
+	switch e.Type {
+	case TypeOffline:
+		var b strings.Builder
+		b.WriteString(e.Title())
+		if e.LastSeen != nil {
+			if e.State == StateFiring {
+				fmt.Fprintf(&b, ", last seen %s", e.LastSeen.UTC().Format(time.RFC3339))
+			} else {
+				fmt.Fprintf(&b, " after %s", e.Time.Sub(*e.LastSeen).Round(time.Second))
+			}
+		}
+		if e.RemoteAddr != "" {
+			fmt.Fprintf(&b, " from %s", e.RemoteAddr)
+		}
+		return b.String()
+	default:
+		var value, threshold string
+		if e.Value != nil {
+			value = strconv.FormatFloat(*e.Value, 'f', -1, 64)
+		}
+		if e.Threshold != nil {
+			threshold = strconv.FormatFloat(*e.Threshold, 'f', -1, 64)
+		}
+		if e.State == StateFiring {
+			return fmt.Sprintf("%s %s is %s (%s %s) since %s", e.Title(), e.Field, value,
+				e.Operator, threshold, e.StartedAt.UTC().Format(time.RFC3339))
+		}
+		return fmt.Sprintf("%s %s recovered to %s", e.Title(), e.Field, value)
+	}
+}
+
+// Notifier sends alert notifications.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// ruleKey identifies the state of a rule for a station.
+type ruleKey struct {
+	rule      string
+	stationID string
+}
+
+// ruleState is the state of a rule for a station.
+type ruleState struct {
+	// since is the time the rule was first breached, or zero if the rule is
+	// not breached. For offline rules, this is the station's last seen time.
+	since  time.Time
+	firing bool
+
+	// remoteAddr is the address the station was last seen from before it
+	// went offline.
+	remoteAddr string
+}
+
+// station is the latest state of a station.
+type station struct {
+	lastSeen   time.Time
+	remoteAddr string
+
+	// observedAt and values are the time and field values of the latest
+	// observation, and evaluated is whether they have been evaluated.
+	observedAt time.Time
+	values     map[string]float64
+	evaluated  bool
+}
+
+// delivery is a notification waiting to be sent.
+type delivery struct {
+	notifier Notifier
+	event    Event
+}
+
+// Engine periodically evaluates alert rules against the latest state of each
+// station. Notifications are sent in the background, in the order the events
+// occurred.
+type Engine struct {
+	rules        []Rule
+	offlineRules []OfflineRule
+	silences     []Silence
+	interval     time.Duration
+	staleAfter   func(stationID string) time.Duration
+
+	mu       sync.Mutex
+	states   map[ruleKey]*ruleState
+	stations map[string]*station
+
+	queue     chan delivery
+	done      chan struct{}
+	quit      chan struct{}
+	scheduler sync.WaitGroup
+}
+
+// NewEngine returns a new alert engine, and starts evaluating rules in the
+// background. The engine must be closed once it is no longer used.
+func NewEngine(c Config) *Engine {
+	e := &Engine{
+		rules:        c.Rules,
+		offlineRules: c.OfflineRules,
+		silences:     c.Silences,
+		interval:     c.Interval,
+		staleAfter:   c.StaleAfter,
+		states:       make(map[ruleKey]*ruleState),
+		stations:     make(map[string]*station),
+		queue:        make(chan delivery, queueSize),
+		done:         make(chan struct{}),
+		quit:         make(chan struct{}),
+	}
+	if e.interval <= 0 {
+		e.interval = DefaultInterval
+	}
+	go e.send()
+	e.scheduler.Add(1)
+	go e.schedule()
+	return e
+}
+
+// Observe records an observation from a station, taken at time t. values
+// contains the observation's field values. The observation is evaluated
+// against the rules on the next evaluation, unless a newer observation is
+// recorded first.
+func (e *Engine) Observe(stationID string, t time.Time, values map[string]float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	st := e.station(stationID)
+	st.observedAt = t
+	st.values = values
+	st.evaluated = false
+}
+
+// Seen records that a submission was received from a station at time t.
+func (e *Engine) Seen(stationID, remoteAddr string, t time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	st := e.station(stationID)
+	st.lastSeen = t
+	st.remoteAddr = remoteAddr
+}
+
+// station returns the state of a station, creating it if needed. e.mu must be
+// held.
+func (e *Engine) station(stationID string) *station {
+	st, ok := e.stations[stationID]
+	if !ok {
+		st = &station{}
+		e.stations[stationID] = st
+	}
+	return st
+}
+
+// schedule evaluates the rules at the engine's interval, until the engine is
+// closed.
+func (e *Engine) schedule() {
+	defer e.scheduler.Done()
+
+	t := time.NewTicker(e.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-e.quit:
+			return
+		case now := <-t.C:
+			e.evaluate(now)
+		}
+	}
+}
+
+// evaluate evaluates the rules against the latest state of each station.
+func (e *Engine) evaluate(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for stationID, st := range e.stations {
+		if !st.evaluated && st.values != nil {
+			for i := range e.rules {
+				e.evaluateThreshold(&e.rules[i], stationID, st)
+			}
+			st.evaluated = true
+		}
+		if !st.lastSeen.IsZero() {
+			for i := range e.offlineRules {
+				e.evaluateOffline(&e.offlineRules[i], stationID, st, now)
+			}
+		}
+	}
+}
+
+// evaluateThreshold evaluates a threshold rule against the latest observation
+// from a station.
+func (e *Engine) evaluateThreshold(r *Rule, stationID string, st *station) {
+	v, ok := st.values[r.Field]
+	if !ok || !appliesTo(r.Stations, stationID) {
+		return
+	}
+
+	rs := e.state(r.Name, stationID)
+	t := st.observedAt
+	if rs.firing {
+		if r.Operator.recovered(v, r.Threshold, r.Hysteresis) {
+			e.notifyThreshold(r, stationID, StateResolved, v, rs.since, t)
+			rs.firing = false
+			rs.since = time.Time{}
+		}
+		return
+	}
+	if !r.Operator.breached(v, r.Threshold) {
+		rs.since = time.Time{}
+		return
+	}
+	if rs.since.IsZero() {
+		rs.since = t
+	}
+	if t.Sub(rs.since) >= r.For {
+		rs.firing = true
+		e.notifyThreshold(r, stationID, StateFiring, v, rs.since, t)
+	}
+}
+
+// evaluateOffline evaluates an offline rule against the last time a station
+// was seen.
+func (e *Engine) evaluateOffline(r *OfflineRule, stationID string, st *station, now time.Time) {
+	if !appliesTo(r.Stations, stationID) {
+		return
+	}
+
+	after := r.After
+	if after == 0 && e.staleAfter != nil {
+		after = e.staleAfter(stationID)
+	}
+	if after <= 0 {
+		return
+	}
+
+	rs := e.state(r.Name, stationID)
+	offline := now.Sub(st.lastSeen) > after
+	switch {
+	case offline && !rs.firing:
+		rs.firing = true
+		rs.since = st.lastSeen
+		rs.remoteAddr = st.remoteAddr
+		e.notifyOffline(r, stationID, StateFiring, rs.since, after, now, st.remoteAddr)
+	case !offline && rs.firing:
+		rs.firing = false
+		e.notifyOffline(r, stationID, StateResolved, rs.since, after, st.lastSeen, st.remoteAddr)
+	}
+}
+
+// state returns the state of a rule for a station, creating it if needed.
+// e.mu must be held.
+func (e *Engine) state(rule, stationID string) *ruleState {
+	key := ruleKey{rule: rule, stationID: stationID}
+	rs, ok := e.states[key]
+	if !ok {
+		rs = &ruleState{}
+		e.states[key] = rs
+	}
+	return rs
+}
+
+// appliesTo returns whether a rule for the stations applies to the station.
+func appliesTo(stations []string, stationID string) bool {
+	return len(stations) == 0 || slices.Contains(stations, stationID)
+}
+
+// notifyThreshold queues a notification for a threshold rule.
+func (e *Engine) notifyThreshold(r *Rule, stationID string, state State, v float64, since, t time.Time) {
+	slog.Info("Alert "+string(state),
+		slog.String("rule", r.Name), slog.String("station_id", stationID),
+		slog.String("field", r.Field), slog.Float64("value", v))
+	e.enqueue(r.Notifier, Event{
+		Type:      TypeThreshold,
+		Rule:      r.Name,
+		StationID: stationID,
+		State:     state,
+		Labels:    r.Labels,
+		StartedAt: since,
+		Time:      t,
+		Field:     r.Field,
+		Value:     &v,
+		Operator:  r.Operator,
+		Threshold: &r.Threshold,
+	})
+}
+
+// notifyOffline queues a notification for an offline rule, for a station
+// that is considered offline after the given duration.
+func (e *Engine) notifyOffline(r *OfflineRule, stationID string, state State, lastSeen time.Time, after time.Duration, t time.Time, remoteAddr string) {
+	slog.Info("Alert "+string(state),
+		slog.String("rule", r.Name), slog.String("station_id", stationID),
+		slog.Time("last_seen", lastSeen), slog.String("remote_addr", remoteAddr))
+	e.enqueue(r.Notifier, Event{
+		Type:       TypeOffline,
+		Rule:       r.Name,
+		StationID:  stationID,
+		State:      state,
+		Labels:     r.Labels,
+		StartedAt:  lastSeen.Add(after),
+		Time:       t,
+		LastSeen:   &lastSeen,
+		RemoteAddr: remoteAddr,
+	})
+}
+
+// enqueue queues a notification. If the event is silenced or the queue is
+// full, the notification is dropped.
+func (e *Engine) enqueue(n Notifier, ev Event) {
+	if n == nil {
+		return
+	}
+	for i := range e.silences {
+		if e.silences[i].silences(ev) {
+			slog.Debug("Alert notification silenced",
+				slog.String("rule", ev.Rule), slog.String("station_id", ev.StationID),
+				slog.String("silence", e.silences[i].Name))
+			return
+		}
+	}
+	select {
+	case e.queue <- delivery{notifier: n, event: ev}:
+	default:
+		slog.Warn("Alert notification queue is full, dropping notification",
+			slog.String("rule", ev.Rule), slog.String("station_id", ev.StationID))
+	}
+}
+
+// send sends queued notifications until the engine is closed.
+func (e *Engine) send() {
+	defer close(e.done)
+	for d := range e.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := d.notifier.Notify(ctx, d.event); err != nil {
+			slog.Error("Failed to send alert notification",
+				slog.String("rule", d.event.Rule),
+				slog.String("station_id", d.event.StationID),
+				slog.Any("err", err))
+		}
+		cancel()
+	}
+}
+
+// DeleteStation removes the state of a station.
+func (e *Engine) DeleteStation(stationID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key := range e.states {
+		if key.stationID == stationID {
+			delete(e.states, key)
+		}
+	}
+	delete(e.stations, stationID)
+}
+
+// Close stops evaluating rules, and waits for queued notifications to be sent,
+// until ctx is done.
+func (e *Engine) Close(ctx context.Context) error {
+	close(e.quit)
+	e.scheduler.Wait()
+	close(e.queue)
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
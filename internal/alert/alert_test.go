@@ -0,0 +1,333 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a notifier that records events.
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) Notify(_ context.Context, e Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestEngine(t *testing.T) {
+	rec := &recorder{}
+	e := NewEngine(Config{Rules: []Rule{{
+		Name:       "freezing",
+		Field:      "temperature",
+		Operator:   OperatorLess,
+		Threshold:  0,
+		For:        10 * time.Minute,
+		Hysteresis: 1,
+		Notifier:   rec,
+	}}})
+
+	start := time.Date(2025, 1, 23, 0, 0, 0, 0, time.UTC)
+	for i, temp := range []float64{
+		1,    // not breached
+		-1,   // breached, pending
+		2,    // not breached, pending reset
+		-1,   // breached, pending
+		-2,   // breached for 5 minutes
+		-1,   // breached for 10 minutes, firing
+		0.5,  // within hysteresis, still firing
+		-3,   // still firing
+		1.5,  // recovered
+		-0.5, // breached, pending
+	} {
+		ts := start.Add(time.Duration(i) * 5 * time.Minute)
+		e.Observe("a", ts, map[string]float64{"temperature": temp})
+		e.evaluate(ts)
+		e.evaluate(ts) // observations are only evaluated once
+	}
+	e.Observe("b", start, map[string]float64{"humidity": 50})
+	e.evaluate(start)
+
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if len(rec.events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(rec.events), rec.events)
+	}
+	firing, resolved := rec.events[0], rec.events[1]
+	if firing.State != StateFiring || *firing.Value != -1 ||
+		!firing.StartedAt.Equal(start.Add(15*time.Minute)) {
+		t.Errorf("unexpected firing event: %+v", firing)
+	}
+	if resolved.State != StateResolved || *resolved.Value != 1.5 ||
+		!resolved.Time.Equal(start.Add(40*time.Minute)) {
+		t.Errorf("unexpected resolved event: %+v", resolved)
+	}
+}
+
+func TestEngineOffline(t *testing.T) {
+	rec := &recorder{}
+	e := NewEngine(Config{OfflineRules: []OfflineRule{{
+		Name:     "offline",
+		After:    10 * time.Minute,
+		Stations: []string{"a", "b"},
+		Notifier: rec,
+	}}})
+
+	start := time.Date(2025, 1, 23, 0, 0, 0, 0, time.UTC)
+	e.Seen("a", "192.0.2.1", start)
+	e.Seen("b", "192.0.2.2", start)
+	e.Seen("c", "192.0.2.3", start) // not watched
+	e.evaluate(start.Add(5 * time.Minute))
+	e.Seen("b", "192.0.2.2", start.Add(5*time.Minute))
+	e.evaluate(start.Add(11 * time.Minute)) // a offline
+	e.evaluate(start.Add(12 * time.Minute)) // a still offline
+	e.Seen("a", "192.0.2.4", start.Add(30*time.Minute))
+	e.Seen("b", "192.0.2.2", start.Add(30*time.Minute))
+	e.evaluate(start.Add(30*time.Minute + 10*time.Second))
+
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if len(rec.events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(rec.events), rec.events)
+	}
+	offline, online := rec.events[0], rec.events[1]
+	if offline.Type != TypeOffline || offline.State != StateFiring ||
+		offline.StationID != "a" || offline.RemoteAddr != "192.0.2.1" ||
+		!offline.LastSeen.Equal(start) {
+		t.Errorf("unexpected offline event: %+v", offline)
+	}
+	if online.State != StateResolved || online.StationID != "a" ||
+		online.RemoteAddr != "192.0.2.4" || !online.LastSeen.Equal(start) ||
+		!online.Time.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("unexpected online event: %+v", online)
+	}
+	if got, want := online.Message(), "Station a is back online after 30m0s from 192.0.2.4"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+}
+
+func TestEngineStaleAfter(t *testing.T) {
+	rec := &recorder{}
+	e := NewEngine(Config{
+		OfflineRules: []OfflineRule{{Name: "stale", Notifier: rec}},
+		StaleAfter: func(stationID string) time.Duration {
+			if stationID == "a" {
+				return 2 * time.Minute
+			}
+			return 0 // not watched
+		},
+	})
+
+	start := time.Date(2025, 1, 23, 0, 0, 0, 0, time.UTC)
+	e.Seen("a", "192.0.2.1", start)
+	e.Seen("b", "192.0.2.2", start)
+	e.evaluate(start.Add(time.Minute))
+	e.evaluate(start.Add(3 * time.Minute)) // a offline
+
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if len(rec.events) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(rec.events), rec.events)
+	}
+	if ev := rec.events[0]; ev.StationID != "a" || ev.State != StateFiring ||
+		!ev.StartedAt.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("unexpected offline event: %+v", ev)
+	}
+}
+
+func TestEngineSilence(t *testing.T) {
+	rec := &recorder{}
+	e := NewEngine(Config{
+		Rules: []Rule{{
+			Name:      "hot",
+			Field:     "temperature",
+			Operator:  OperatorGreater,
+			Threshold: 30,
+			Labels:    map[string]string{"severity": "warning"},
+			Notifier:  rec,
+		}},
+		Silences: []Silence{{
+			Name:     "night",
+			From:     22 * time.Hour,
+			To:       6 * time.Hour,
+			Location: time.UTC,
+			Labels:   map[string]string{"severity": "warning"},
+		}},
+	})
+
+	night := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	e.Observe("a", night, map[string]float64{"temperature": 35})
+	e.evaluate(night) // silenced
+	e.Observe("a", night.Add(8*time.Hour), map[string]float64{"temperature": 20})
+	e.evaluate(night.Add(8 * time.Hour)) // resolved
+
+	if err := e.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if len(rec.events) != 1 || rec.events[0].State != StateResolved ||
+		rec.events[0].Labels["severity"] != "warning" {
+		t.Fatalf("unexpected events: %+v", rec.events)
+	}
+}
+
+func TestSilenceActive(t *testing.T) {
+	// 2025-01-23 is a Thursday.
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 1, day, hour, 0, 0, 0, time.UTC)
+	}
+	tts := []struct {
+		name    string
+		silence Silence
+		t       time.Time
+		want    bool
+	}{
+		{
+			name:    "absolute",
+			silence: Silence{Start: at(23, 0), End: at(24, 0)},
+			t:       at(23, 12),
+			want:    true,
+		},
+		{
+			name:    "after end",
+			silence: Silence{Start: at(23, 0), End: at(24, 0)},
+			t:       at(24, 0),
+		},
+		{
+			name:    "daily window",
+			silence: Silence{From: 9 * time.Hour, To: 17 * time.Hour},
+			t:       at(23, 12),
+			want:    true,
+		},
+		{
+			name:    "outside daily window",
+			silence: Silence{From: 9 * time.Hour, To: 17 * time.Hour},
+			t:       at(23, 17),
+		},
+		{
+			name:    "overnight window after midnight",
+			silence: Silence{Days: []time.Weekday{time.Thursday}, From: 22 * time.Hour, To: 6 * time.Hour},
+			t:       at(24, 3),
+			want:    true,
+		},
+		{
+			name:    "overnight window started on other day",
+			silence: Silence{Days: []time.Weekday{time.Thursday}, From: 22 * time.Hour, To: 6 * time.Hour},
+			t:       at(23, 3),
+		},
+		{
+			name:    "whole day",
+			silence: Silence{Days: []time.Weekday{time.Saturday, time.Sunday}},
+			t:       at(25, 12),
+			want:    true,
+		},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.silence.Location = time.UTC
+			if got := tt.silence.active(tt.t); got != tt.want {
+				t.Errorf("active() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChat(t *testing.T) {
+	var gotPath string
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	lastSeen := time.Date(2025, 1, 23, 0, 0, 0, 0, time.UTC)
+	ev := Event{
+		Type:       TypeOffline,
+		StationID:  "a",
+		State:      StateFiring,
+		LastSeen:   &lastSeen,
+		RemoteAddr: "192.0.2.1",
+	}
+	const msg = "Station a is offline, last seen 2025-01-23T00:00:00Z from 192.0.2.1"
+
+	d := &Discord{URL: srv.URL + "/api/webhooks/1/token"}
+	if err := d.Notify(context.Background(), ev); err != nil {
+		t.Fatalf("discord: %v", err)
+	}
+	if gotPath != "/api/webhooks/1/token" || got["content"] != msg {
+		t.Errorf("discord: got %s %v", gotPath, got)
+	}
+
+	tg := &Telegram{Token: "123:abc", ChatID: "-100", APIURL: srv.URL}
+	if err := tg.Notify(context.Background(), ev); err != nil {
+		t.Fatalf("telegram: %v", err)
+	}
+	if gotPath != "/bot123:abc/sendMessage" || got["chat_id"] != "-100" || got["text"] != msg {
+		t.Errorf("telegram: got %s %v", gotPath, got)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var got Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	w := &Webhook{URL: srv.URL}
+	want := Event{Rule: "freezing", StationID: "a", State: StateFiring}
+	if err := w.Notify(context.Background(), want); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if got.Rule != want.Rule || got.StationID != want.StationID || got.State != want.State {
+		t.Errorf("got event %+v, want %+v", got, want)
+	}
+
+	w.URL = srv.URL + "/missing"
+	if err := w.Notify(context.Background(), want); err == nil {
+		t.Error("expected error for unsuccessful response")
+	}
+}
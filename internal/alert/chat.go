@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultTelegramAPIURL is the default Telegram Bot API URL.
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// Discord is a notifier that sends events as messages to a Discord webhook.
+type Discord struct {
+	// URL is the Discord webhook URL.
+	URL string
+
+	// HTTPClient is the HTTP client used to send requests. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// Notify sends the event to the Discord webhook.
+func (d *Discord) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(struct {
+		Content string `json:"content"`
+	}{
+		Content: e.Message(),
+	})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, d.HTTPClient, d.URL, body)
+}
+
+// Telegram is a notifier that sends events as messages to a Telegram chat
+// using a bot.
+type Telegram struct {
+	// Token is the bot token.
+	Token string
+
+	// ChatID is the ID of the chat to send messages to.
+	ChatID string
+
+	// APIURL is the Telegram Bot API URL. If empty, the public Telegram Bot
+	// API is used.
+	APIURL string
+
+	// HTTPClient is the HTTP client used to send requests. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// Notify sends the event to the Telegram chat.
+func (t *Telegram) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}{
+		ChatID: t.ChatID,
+		Text:   e.Message(),
+	})
+	if err != nil {
+		return err
+	}
+
+	apiURL := t.APIURL
+	if apiURL == "" {
+		apiURL = defaultTelegramAPIURL
+	}
+	endpoint := strings.TrimSuffix(apiURL, "/") + "/bot" + t.Token + "/sendMessage"
+	if err = postJSON(ctx, t.HTTPClient, endpoint, body); err != nil {
+		// Avoid logging the bot token, which is part of the URL.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = strings.Replace(urlErr.URL, t.Token, "<token>", 1)
+		}
+		return err
+	}
+	return nil
+}
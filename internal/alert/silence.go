@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package alert
+
+import (
+	"slices"
+	"time"
+)
+
+// Silence is a window during which notifications are not sent for matching
+// alerts. Alert states are still evaluated while silenced, so an alert that
+// starts firing during a silence is not notified once the silence ends.
+type Silence struct {
+	// Name is the name of the silence.
+	Name string
+
+	// Start and End bound the silence. A zero Start or End leaves the
+	// silence unbounded in that direction.
+	Start time.Time
+	End   time.Time
+
+	// Days, From and To are a recurring window, from the offset From to the
+	// offset To since midnight on each of the days. If To is before From, the
+	// window ends on the next day. If From equals To, the window is the whole
+	// day. If Days is empty and From equals To, there is no recurring window.
+	Days     []time.Weekday
+	From, To time.Duration
+
+	// Location is the time zone of the recurring window. If nil, the local
+	// time zone is used.
+	Location *time.Location
+
+	// Rules, Stations and Labels restrict the silence to alerts for the
+	// rules, the stations and with the labels. If empty, the silence matches
+	// all alerts.
+	Rules    []string
+	Stations []string
+	Labels   map[string]string
+}
+
+// silences returns whether the silence matches the event and is active at the
+// time of the event.
+func (s *Silence) silences(e Event) bool {
+	return s.matches(e) && s.active(e.Time)
+}
+
+// matches returns whether the silence matches the event.
+func (s *Silence) matches(e Event) bool {
+	if len(s.Rules) > 0 && !slices.Contains(s.Rules, e.Rule) {
+		return false
+	}
+	if len(s.Stations) > 0 && !slices.Contains(s.Stations, e.StationID) {
+		return false
+	}
+	for k, v := range s.Labels {
+		if lv, ok := e.Labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// active returns whether the silence is active at t.
+func (s *Silence) active(t time.Time) bool {
+	if !s.Start.IsZero() && t.Before(s.Start) {
+		return false
+	}
+	if !s.End.IsZero() && !t.Before(s.End) {
+		return false
+	}
+	if len(s.Days) == 0 && s.From == s.To {
+		return true
+	}
+
+	loc := s.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	offset := t.Sub(midnight)
+	today, yesterday := t.Weekday(), midnight.AddDate(0, 0, -1).Weekday()
+
+	switch {
+	case s.From == s.To:
+		return s.onDay(today)
+	case s.From < s.To:
+		return offset >= s.From && offset < s.To && s.onDay(today)
+	default:
+		return (offset >= s.From && s.onDay(today)) ||
+			(offset < s.To && s.onDay(yesterday))
+	}
+}
+
+// onDay returns whether the recurring window starts on the day.
+func (s *Silence) onDay(d time.Weekday) bool {
+	return len(s.Days) == 0 || slices.Contains(s.Days, d)
+}
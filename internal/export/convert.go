@@ -0,0 +1,220 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// Encoder writes observations to a stream in a format.
+type Encoder interface {
+	// Encode writes an observation.
+	Encode(o weather.Observation) error
+
+	// Close flushes any buffered observations. It does not close the
+	// underlying writer.
+	Close() error
+}
+
+// NewEncoder returns an encoder that writes observations to w in the given
+// format:
+//   - csv: a header row followed by a row for each observation, using the same
+//     columns as the Parquet export.
+//   - json: an observation JSON object per line.
+//   - openmetrics: OpenMetrics text with a timestamped sample for each
+//     observation, using the same metric names as the exporter. Observations
+//     are buffered until Close, as the samples of each metric family must be
+//     written together.
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case "csv":
+		return newCSVEncoder(w), nil
+	case "json":
+		return &jsonEncoder{enc: json.NewEncoder(w)}, nil
+	case "openmetrics":
+		return &openMetricsEncoder{w: w, series: make(map[string][]sample)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// csvEncoder writes observations as CSV.
+type csvEncoder struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// csvHeader is the header row of the CSV format.
+var csvHeader = []string{
+	"station_id", "time", "received_at", "realtime", "realtime_frequency_seconds",
+	"wind_direction_degrees", "wind_speed_kph", "wind_gust_speed_kph", "humidity_percent",
+	"dew_point_celsius", "temperature_celsius", "rain_past_hour_mm", "rain_today_mm",
+	"barometric_pressure_hpa", "indoor_temperature_celsius", "indoor_humidity_percent",
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Encode(o weather.Observation) error {
+	if !e.wroteHeader {
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+		e.wroteHeader = true
+	}
+
+	dm := o.Measurement
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	// Fields the station did not measure are empty.
+	m := func(param string, v float64) string {
+		if !dm.Has(param) {
+			return ""
+		}
+		return f(v)
+	}
+	return e.w.Write([]string{
+		o.StationID,
+		dm.DateUTC.Format(time.RFC3339),
+		o.ReceivedAt.UTC().Format(time.RFC3339),
+		strconv.FormatBool(dm.RealTime),
+		f(dm.RealTimeFreq),
+		m("winddir", dm.WindDirection),
+		m("windspeedmph", dm.WindSpeed),
+		m("windgustmph", dm.WindGust),
+		m("humidity", dm.Humidity),
+		m("dewptf", dm.DewPoint),
+		m("tempf", dm.Temperature),
+		m("rainin", dm.RainPastHour),
+		m("dailyrainin", dm.RainToday),
+		m("baromin", dm.Barometric),
+		m("indoortempf", dm.IndoorTemp),
+		m("indoorhumidity", dm.IndoorHumidity),
+	})
+}
+
+func (e *csvEncoder) Close() error {
+	if !e.wroteHeader {
+		// Write the header of an empty export.
+		_ = e.w.Write(csvHeader)
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonEncoder writes observations as line-delimited JSON.
+type jsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonEncoder) Encode(o weather.Observation) error {
+	return e.enc.Encode(o)
+}
+
+func (e *jsonEncoder) Close() error {
+	return nil
+}
+
+// sample is a timestamped value of a series.
+type sample struct {
+	value float64
+	ts    time.Time
+}
+
+// openMetricsEncoder writes observations as OpenMetrics text.
+type openMetricsEncoder struct {
+	w io.Writer
+
+	// series are the samples of each metric name and station ID, keyed by
+	// "name\xffstation_id".
+	series map[string][]sample
+}
+
+func (e *openMetricsEncoder) Encode(o weather.Observation) error {
+	for _, rs := range remoteWriteSeries {
+		v, ok := rs.value(o.Measurement)
+		if !ok {
+			continue
+		}
+		k := rs.name + "\xff" + o.StationID
+		e.series[k] = append(e.series[k], sample{
+			value: v,
+			ts:    o.Measurement.DateUTC,
+		})
+	}
+	return nil
+}
+
+// labelValueEscaper escapes OpenMetrics label values.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (e *openMetricsEncoder) Close() error {
+	stations := make(map[string][]string)
+	for k := range e.series {
+		name, stationID, _ := strings.Cut(k, "\xff")
+		stations[name] = append(stations[name], stationID)
+	}
+
+	var b strings.Builder
+	for _, rs := range remoteWriteSeries {
+		// Counter samples have a _total suffix, which is not part of the
+		// metric family name.
+		family, typ := rs.name, "gauge"
+		if name, ok := strings.CutSuffix(rs.name, "_total"); ok {
+			family, typ = name, "counter"
+		}
+		fmt.Fprintf(&b, "# TYPE %s %s\n", family, typ)
+
+		slices.Sort(stations[rs.name])
+		for _, stationID := range stations[rs.name] {
+			samples := e.series[rs.name+"\xff"+stationID]
+			slices.SortStableFunc(samples, func(a, b sample) int {
+				return a.ts.Compare(b.ts)
+			})
+			// Timestamps must be increasing within a series, so only the
+			// first sample of each timestamp is written.
+			samples = slices.CompactFunc(samples, func(a, b sample) bool {
+				return a.ts.Equal(b.ts)
+			})
+			for _, s := range samples {
+				fmt.Fprintf(&b, "%s{station_id=\"%s\"} %s %s\n",
+					rs.name, labelValueEscaper.Replace(stationID),
+					strconv.FormatFloat(s.value, 'g', -1, 64),
+					strconv.FormatFloat(float64(s.ts.UnixMilli())/1000, 'f', -1, 64))
+			}
+		}
+		if _, err := io.WriteString(e.w, b.String()); err != nil {
+			return err
+		}
+		b.Reset()
+	}
+	_, err := io.WriteString(e.w, "# EOF\n")
+	return err
+}
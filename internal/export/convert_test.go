@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestEncoder(t *testing.T) {
+	ts := time.Date(2025, 1, 23, 12, 0, 0, 0, time.UTC)
+	observations := []weather.Observation{
+		{
+			StationID:   "b",
+			ReceivedAt:  ts.Add(time.Minute),
+			Measurement: wu.DeviceMeasurement{DateUTC: ts.Add(time.Minute), Temperature: 21, Humidity: 50},
+		},
+		{
+			StationID:   "a",
+			ReceivedAt:  ts,
+			Measurement: wu.DeviceMeasurement{DateUTC: ts, Temperature: 20.5, RainToday: 1.2, Missing: []string{"humidity"}},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{
+			format: "csv",
+			want: []string{
+				"station_id,time,received_at,realtime,",
+				"b,2025-01-23T12:01:00Z,2025-01-23T12:01:00Z,false,0,0,0,0,50,0,21,0,0,0,0,0\n",
+				"a,2025-01-23T12:00:00Z,2025-01-23T12:00:00Z,false,0,0,0,0,,0,20.5,0,1.2,0,0,0\n",
+			},
+		},
+		{
+			format: "json",
+			want: []string{
+				`{"station_id":"b","received_at":"2025-01-23T12:01:00Z",`,
+				`"temperature_celsius":20.5,`,
+			},
+		},
+		{
+			format: "openmetrics",
+			want: []string{
+				"# TYPE weather_station_temperature_celsius gauge\n" +
+					"weather_station_temperature_celsius{station_id=\"a\"} 20.5 1737633600\n" +
+					"weather_station_temperature_celsius{station_id=\"b\"} 21 1737633660\n",
+				"weather_station_humidity_percent{station_id=\"b\"} 0.5 1737633660\n",
+				"# TYPE weather_station_rain_mm counter\n" +
+					"weather_station_rain_mm_total{station_id=\"a\"} 1.2 1737633600\n",
+				"# EOF\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b strings.Builder
+			enc, err := NewEncoder(tt.format, &b)
+			if err != nil {
+				t.Fatalf("new encoder: %v", err)
+			}
+			for _, o := range observations {
+				if err := enc.Encode(o); err != nil {
+					t.Fatalf("encode: %v", err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, b.String())
+				}
+			}
+		})
+	}
+
+	if _, err := NewEncoder("xml", nil); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
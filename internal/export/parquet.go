@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package export implements exporting stored observations to files.
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/joshuasing/pws_exporter/internal/store"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// parquetRow is the Parquet schema of an exported observation. Values that
+// the station did not measure are null.
+type parquetRow struct {
+	StationID         string    `parquet:"station_id,dict"`
+	Time              time.Time `parquet:"time,timestamp(millisecond)"`
+	ReceivedAt        time.Time `parquet:"received_at,timestamp(millisecond)"`
+	RealTime          bool      `parquet:"realtime"`
+	RealTimeFreq      float64   `parquet:"realtime_frequency_seconds"`
+	WindDirection     *float64  `parquet:"wind_direction_degrees,optional"`
+	WindSpeed         *float64  `parquet:"wind_speed_kph,optional"`
+	WindGust          *float64  `parquet:"wind_gust_speed_kph,optional"`
+	Humidity          *float64  `parquet:"humidity_percent,optional"`
+	DewPoint          *float64  `parquet:"dew_point_celsius,optional"`
+	Temperature       *float64  `parquet:"temperature_celsius,optional"`
+	RainPastHour      *float64  `parquet:"rain_past_hour_mm,optional"`
+	RainToday         *float64  `parquet:"rain_today_mm,optional"`
+	Barometric        *float64  `parquet:"barometric_pressure_hpa,optional"`
+	IndoorTemperature *float64  `parquet:"indoor_temperature_celsius,optional"`
+	IndoorHumidity    *float64  `parquet:"indoor_humidity_percent,optional"`
+	SolarRadiation    *float64  `parquet:"solar_radiation_wm2,optional"`
+	AbsBarometric     *float64  `parquet:"absolute_barometric_pressure_hpa,optional"`
+	SnowDepth         *float64  `parquet:"snow_depth_mm,optional"`
+	SunshineToday     *float64  `parquet:"sunshine_today_seconds,optional"`
+}
+
+func newParquetRow(o weather.Observation) parquetRow {
+	dm := o.Measurement
+	return parquetRow{
+		StationID:         o.StationID,
+		Time:              dm.DateUTC,
+		ReceivedAt:        o.ReceivedAt,
+		RealTime:          dm.RealTime,
+		RealTimeFreq:      dm.RealTimeFreq,
+		WindDirection:     measuredValue(dm, "winddir", dm.WindDirection),
+		WindSpeed:         measuredValue(dm, "windspeedmph", dm.WindSpeed),
+		WindGust:          measuredValue(dm, "windgustmph", dm.WindGust),
+		Humidity:          measuredValue(dm, "humidity", dm.Humidity),
+		DewPoint:          measuredValue(dm, "dewptf", dm.DewPoint),
+		Temperature:       measuredValue(dm, "tempf", dm.Temperature),
+		RainPastHour:      measuredValue(dm, "rainin", dm.RainPastHour),
+		RainToday:         measuredValue(dm, "dailyrainin", dm.RainToday),
+		Barometric:        measuredValue(dm, "baromin", dm.Barometric),
+		IndoorTemperature: measuredValue(dm, "indoortempf", dm.IndoorTemp),
+		IndoorHumidity:    measuredValue(dm, "indoorhumidity", dm.IndoorHumidity),
+		SolarRadiation:    measuredValue(dm, "solarradiation", dm.SolarRadiation),
+		AbsBarometric:     dm.AbsBarometric,
+		SnowDepth:         dm.SnowDepth,
+		SunshineToday:     dm.SunshineToday,
+	}
+}
+
+// Parquet writes the observations matching the query to Parquet files in dir,
+// partitioned by (UTC) day using Hive-style directory names, e.g.
+// dir/date=2025-01-23/observations.parquet. It returns the number of files
+// written.
+func Parquet(ctx context.Context, s *store.Store, q store.Query, dir string) (int, error) {
+	var (
+		files int
+		day   string
+		f     *os.File
+		w     *parquet.GenericWriter[parquetRow]
+	)
+	closeFile := func() error {
+		if w == nil {
+			return nil
+		}
+		if err := w.Close(); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("write %s: %w", f.Name(), err)
+		}
+		w = nil
+		return f.Close()
+	}
+
+	err := s.Observations(ctx, q, func(o weather.Observation) error {
+		if d := o.Measurement.DateUTC.UTC().Format(time.DateOnly); d != day || w == nil {
+			if err := closeFile(); err != nil {
+				return err
+			}
+			day = d
+
+			partition := filepath.Join(dir, "date="+day)
+			if err := os.MkdirAll(partition, 0o755); err != nil {
+				return err
+			}
+			var err error
+			f, err = os.Create(filepath.Join(partition, "observations.parquet"))
+			if err != nil {
+				return err
+			}
+			w = parquet.NewGenericWriter[parquetRow](f, parquet.Compression(&parquet.Zstd))
+			files++
+		}
+		_, err := w.Write([]parquetRow{newParquetRow(o)})
+		return err
+	})
+	if err != nil {
+		if w != nil {
+			_ = f.Close()
+		}
+		return files, err
+	}
+	return files, closeFile()
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package export
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/joshuasing/pws_exporter/internal/store"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestParquet(t *testing.T) {
+	dir := t.TempDir()
+	s, err := store.Open(filepath.Join(dir, "test.db"), store.Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	day := time.Date(2025, 1, 23, 12, 0, 0, 0, time.UTC)
+	for _, ts := range []time.Time{day, day.Add(time.Hour), day.Add(24 * time.Hour)} {
+		err := s.Insert(ctx, weather.Observation{
+			StationID:   "test",
+			ReceivedAt:  ts,
+			Measurement: wu.DeviceMeasurement{DateUTC: ts, Temperature: 20, Missing: []string{"humidity"}},
+		})
+		if err != nil {
+			t.Fatalf("insert observation: %v", err)
+		}
+	}
+
+	out := filepath.Join(dir, "out")
+	n, err := Parquet(ctx, s, store.Query{}, out)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("wrote %d files, want 2", n)
+	}
+
+	rows, err := parquet.ReadFile[parquetRow](filepath.Join(out, "date=2025-01-23", "observations.parquet"))
+	if err != nil {
+		t.Fatalf("read parquet: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if rows[0].StationID != "test" || rows[0].Temperature == nil || *rows[0].Temperature != 20 || !rows[0].Time.Equal(day) {
+		t.Errorf("unexpected row: %+v", rows[0])
+	}
+	if rows[0].Humidity != nil {
+		t.Errorf("missing humidity got %v, want null", *rows[0].Humidity)
+	}
+}
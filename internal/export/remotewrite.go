@@ -0,0 +1,151 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joshuasing/pws_exporter/internal/remotewrite"
+	"github.com/joshuasing/pws_exporter/internal/store"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// remoteWriteBatchSize is the number of observations sent in each remote
+// write request.
+const remoteWriteBatchSize = 500
+
+// remoteWriteSeries maps exporter metric names to observation values. Values
+// of fields that are missing from an observation are not sent.
+var remoteWriteSeries = []struct {
+	name  string
+	value func(dm wu.DeviceMeasurement) (float64, bool)
+}{
+	{"weather_station_absolute_barometric_pressure_hpa", optional(func(dm wu.DeviceMeasurement) *float64 { return dm.AbsBarometric })},
+	{"weather_station_barometric_pressure_hpa", measured("baromin", func(dm wu.DeviceMeasurement) float64 { return dm.Barometric })},
+	{"weather_station_dew_point_celsius", measured("dewptf", func(dm wu.DeviceMeasurement) float64 { return dm.DewPoint })},
+	{"weather_station_humidity_percent", measured("humidity", func(dm wu.DeviceMeasurement) float64 { return dm.Humidity / 100 })},
+	{"weather_station_indoor_humidity_percent", measured("indoorhumidity", func(dm wu.DeviceMeasurement) float64 { return dm.IndoorHumidity / 100 })},
+	{"weather_station_indoor_temperature_celsius", measured("indoortempf", func(dm wu.DeviceMeasurement) float64 { return dm.IndoorTemp })},
+	{"weather_station_rain_past_hour_mm", measured("rainin", func(dm wu.DeviceMeasurement) float64 { return dm.RainPastHour })},
+	{"weather_station_rain_mm_total", measured("dailyrainin", func(dm wu.DeviceMeasurement) float64 { return dm.RainToday })},
+	{"weather_station_snow_depth_mm", optional(func(dm wu.DeviceMeasurement) *float64 { return dm.SnowDepth })},
+	{"weather_station_solar_radiation_watts_per_square_meter", measured("solarradiation", func(dm wu.DeviceMeasurement) float64 { return dm.SolarRadiation })},
+	{"weather_station_temperature_celsius", measured("tempf", func(dm wu.DeviceMeasurement) float64 { return dm.Temperature })},
+	{"weather_station_wind_direction_degrees", measured("winddir", func(dm wu.DeviceMeasurement) float64 { return dm.WindDirection })},
+	{"weather_station_wind_gust_speed_kph", measured("windgustmph", func(dm wu.DeviceMeasurement) float64 { return dm.WindGust })},
+	{"weather_station_wind_speed_kph", measured("windspeedmph", func(dm wu.DeviceMeasurement) float64 { return dm.WindSpeed })},
+}
+
+// measured returns the value of a weather data field, identified by its query
+// parameter, if it is not missing.
+func measured(param string, v func(dm wu.DeviceMeasurement) float64) func(dm wu.DeviceMeasurement) (float64, bool) {
+	return func(dm wu.DeviceMeasurement) (float64, bool) {
+		return v(dm), dm.Has(param)
+	}
+}
+
+// measuredValue returns the value of a weather data field, identified by its
+// query parameter, or nil if it is missing.
+func measuredValue(dm wu.DeviceMeasurement, param string, v float64) *float64 {
+	if !dm.Has(param) {
+		return nil
+	}
+	return &v
+}
+
+// optional returns the value of a field that is nil if not submitted.
+func optional(v func(dm wu.DeviceMeasurement) *float64) func(dm wu.DeviceMeasurement) (float64, bool) {
+	return func(dm wu.DeviceMeasurement) (float64, bool) {
+		if p := v(dm); p != nil {
+			return *p, true
+		}
+		return 0, false
+	}
+}
+
+// RemoteWrite sends the observations matching the query to a Prometheus
+// remote write endpoint as timestamped samples, using the same metric names
+// as the exporter. The labels are added to every time series. It returns the
+// number of observations sent.
+func RemoteWrite(ctx context.Context, s *store.Store, q store.Query, c *remotewrite.Client, labels []remotewrite.Label) (int, error) {
+	var (
+		sent  int
+		batch []weather.Observation
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := c.Write(ctx, toTimeSeries(batch, labels)); err != nil {
+			return fmt.Errorf("write %d observations: %w", len(batch), err)
+		}
+		sent += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := s.Observations(ctx, q, func(o weather.Observation) error {
+		batch = append(batch, o)
+		if len(batch) >= remoteWriteBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return sent, err
+	}
+	return sent, flush()
+}
+
+// toTimeSeries converts observations into time series. The observations must
+// be ordered by time.
+func toTimeSeries(observations []weather.Observation, labels []remotewrite.Label) []remotewrite.TimeSeries {
+	type key struct{ name, stationID string }
+	index := make(map[key]int)
+
+	var series []remotewrite.TimeSeries
+	for _, o := range observations {
+		for _, rs := range remoteWriteSeries {
+			v, ok := rs.value(o.Measurement)
+			if !ok {
+				continue
+			}
+			k := key{name: rs.name, stationID: o.StationID}
+			i, ok := index[k]
+			if !ok {
+				i = len(series)
+				index[k] = i
+				ls := append([]remotewrite.Label{
+					{Name: "__name__", Value: rs.name},
+					{Name: "station_id", Value: o.StationID},
+				}, labels...)
+				series = append(series, remotewrite.TimeSeries{Labels: ls})
+			}
+			series[i].Samples = append(series[i].Samples, remotewrite.Sample{
+				Value:     v,
+				Timestamp: o.Measurement.DateUTC,
+			})
+		}
+	}
+	return series
+}
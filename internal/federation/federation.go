@@ -0,0 +1,255 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package federation implements pulling station metrics from remote
+// pws_exporter instances, so that stations from several field sites can be
+// exported by a single exporter.
+package federation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultInterval is the default interval at which sites are scraped.
+const DefaultInterval = 30 * time.Second
+
+// SiteLabel is the name of the label added to the metrics of each site.
+const SiteLabel = "site"
+
+// metricPrefix is the prefix of the station metric families that are
+// federated. The remote exporters' own metrics are not federated.
+const metricPrefix = "weather_"
+
+// Site is a remote pws_exporter instance.
+type Site struct {
+	// Name is the value of the site label added to the site's metrics.
+	Name string
+
+	// URL is the URL of the remote exporter's metrics endpoint, e.g.
+	// http://192.0.2.1:9452/metrics.
+	URL string
+
+	// Username and Password are the HTTP basic authentication credentials
+	// used to scrape the remote exporter, if set, e.g. tenant credentials.
+	Username string
+	Password string
+}
+
+// Federator periodically scrapes the station metrics of remote exporters,
+// and serves them with a site label added as a prometheus.Gatherer. The
+// metrics of a site are not served while it cannot be scraped.
+type Federator struct {
+	sites    []Site
+	interval time.Duration
+	hc       *http.Client
+
+	up          *prometheus.GaugeVec
+	lastSuccess *prometheus.GaugeVec
+
+	mu       sync.RWMutex
+	families map[string][]*dto.MetricFamily
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewFederator returns a new federator for the sites, and starts scraping
+// them in the background every interval. If interval is zero,
+// DefaultInterval is used. The scrape state of each site is exported as
+// metrics on reg. The federator must be closed once it is no longer used.
+func NewFederator(sites []Site, interval time.Duration, reg prometheus.Registerer) *Federator {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	f := &Federator{
+		sites:    sites,
+		interval: interval,
+		hc:       &http.Client{Timeout: interval},
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "federation",
+			Name:      "up",
+			Help:      "Whether the last scrape of the site succeeded (1) or not (0)",
+		}, []string{SiteLabel}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "federation",
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Time of the last successful scrape of the site",
+		}, []string{SiteLabel}),
+		families: make(map[string][]*dto.MetricFamily),
+		quit:     make(chan struct{}),
+	}
+	reg.MustRegister(f.up, f.lastSuccess)
+	for _, s := range sites {
+		f.up.WithLabelValues(s.Name).Set(0)
+		f.wg.Add(1)
+		go f.run(s)
+	}
+	return f
+}
+
+// Close stops scraping the sites.
+func (f *Federator) Close() {
+	close(f.quit)
+	f.wg.Wait()
+}
+
+// run scrapes a site every interval until the federator is closed.
+func (f *Federator) run(s Site) {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		f.update(s)
+		select {
+		case <-f.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update scrapes a site and replaces its metrics.
+func (f *Federator) update(s Site) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-f.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	mfs, err := f.scrape(ctx, s)
+	if err != nil {
+		slog.Warn("Failed to scrape federated site",
+			slog.String("site", s.Name), slog.Any("err", err))
+		f.up.WithLabelValues(s.Name).Set(0)
+	} else {
+		f.up.WithLabelValues(s.Name).Set(1)
+		f.lastSuccess.WithLabelValues(s.Name).SetToCurrentTime()
+	}
+
+	f.mu.Lock()
+	f.families[s.Name] = mfs
+	f.mu.Unlock()
+}
+
+// scrape returns the station metric families of a site, with the site label
+// added.
+func (f *Federator) scrape(ctx context.Context, s Site) ([]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+	if s.Username != "" || s.Password != "" {
+		req.SetBasicAuth(s.Username, s.Password)
+	}
+	resp, err := f.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var p expfmt.TextParser
+	parsed, err := p.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parse metrics: %w", err)
+	}
+
+	mfs := make([]*dto.MetricFamily, 0, len(parsed))
+	for name, mf := range parsed {
+		if !strings.HasPrefix(name, metricPrefix) {
+			continue
+		}
+		for _, m := range mf.Metric {
+			setSiteLabel(m, s.Name)
+		}
+		mfs = append(mfs, mf)
+	}
+	return mfs, nil
+}
+
+// setSiteLabel sets the site label of a metric, replacing an existing site
+// label. Labels are kept sorted by name.
+func setSiteLabel(m *dto.Metric, site string) {
+	for _, lp := range m.Label {
+		if lp.GetName() == SiteLabel {
+			lp.Value = proto.String(site)
+			return
+		}
+	}
+	m.Label = append(m.Label, &dto.LabelPair{
+		Name:  proto.String(SiteLabel),
+		Value: proto.String(site),
+	})
+	sort.Slice(m.Label, func(i, j int) bool {
+		return m.Label[i].GetName() < m.Label[j].GetName()
+	})
+}
+
+// Gather implements prometheus.Gatherer. It returns copies of the latest
+// metric families of all sites, merged by name.
+func (f *Federator) Gather() ([]*dto.MetricFamily, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	byName := make(map[string]*dto.MetricFamily)
+	for _, s := range f.sites {
+		for _, mf := range f.families[s.Name] {
+			out, ok := byName[mf.GetName()]
+			if !ok {
+				out = &dto.MetricFamily{Name: mf.Name, Help: mf.Help, Type: mf.Type}
+				byName[mf.GetName()] = out
+			}
+			for _, m := range mf.Metric {
+				out.Metric = append(out.Metric, proto.Clone(m).(*dto.Metric))
+			}
+		}
+	}
+
+	mfs := make([]*dto.MetricFamily, 0, len(byName))
+	for _, mf := range byName {
+		mfs = append(mfs, mf)
+	}
+	sort.Slice(mfs, func(i, j int) bool {
+		return mfs[i].GetName() < mfs[j].GetName()
+	})
+	return mfs, nil
+}
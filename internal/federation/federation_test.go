@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package federation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const testMetrics = `# HELP weather_station_temperature_celsius Temperature in Celsius
+# TYPE weather_station_temperature_celsius gauge
+weather_station_temperature_celsius{station_id="KTEST1"} 21.5
+# HELP pws_exporter_listener_up Whether the listener is running
+# TYPE pws_exporter_listener_up gauge
+pws_exporter_listener_up{listener="wu"} 1
+`
+
+func TestFederator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(testMetrics))
+	}))
+	defer srv.Close()
+
+	reg := prometheus.NewRegistry()
+	f := NewFederator([]Site{
+		{Name: "north", URL: srv.URL, Username: "user", Password: "pass"},
+		{Name: "south", URL: srv.URL}, // unauthorized
+	}, time.Hour, reg)
+	defer f.Close()
+
+	var mfs []*dto.MetricFamily
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		var err error
+		if mfs, err = f.Gather(); err != nil {
+			t.Fatalf("gather: %v", err)
+		}
+		if len(mfs) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(mfs) != 1 || mfs[0].GetName() != "weather_station_temperature_celsius" {
+		t.Fatalf("got %v, want only the temperature metric family", mfs)
+	}
+	if len(mfs[0].Metric) != 1 {
+		t.Fatalf("got %d metrics, want 1", len(mfs[0].Metric))
+	}
+	m := mfs[0].Metric[0]
+	labels := make(map[string]string)
+	for _, lp := range m.Label {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	if labels["site"] != "north" || labels["station_id"] != "KTEST1" || m.GetGauge().GetValue() != 21.5 {
+		t.Errorf("unexpected metric: %v", m)
+	}
+}
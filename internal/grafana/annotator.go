@@ -0,0 +1,370 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package grafana writes Grafana annotations for weather events detected from
+// the observations of stations, such as the start and end of rain, daily gust
+// records, and stations going offline, so that dashboards show event markers
+// without any annotation queries against the observation data.
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Defaults.
+const (
+	DefaultInterval      = 15 * time.Second
+	DefaultRainStopAfter = 30 * time.Minute
+)
+
+// queueSize is the maximum number of annotations waiting to be created.
+const queueSize = 256
+
+// createTimeout is the maximum time to wait for an annotation to be created.
+const createTimeout = 10 * time.Second
+
+// Event is a type of weather event.
+type Event string
+
+// Supported events.
+const (
+	EventRain    Event = "rain"    // Rain started or stopped
+	EventGust    Event = "gust"    // New daily wind gust record
+	EventOffline Event = "offline" // Station went offline or came back online
+)
+
+// Config is the annotator configuration.
+type Config struct {
+	// Client is the Grafana client used to create annotations.
+	Client *Client
+
+	// DashboardUID is the UID of the dashboard annotations are added to. If
+	// empty, organization annotations are created.
+	DashboardUID string
+
+	// Tags are added to every annotation, in addition to the station ID and
+	// the event.
+	Tags []string
+
+	// Events are the events that are annotated. If empty, all events are
+	// annotated.
+	Events []Event
+
+	// Stations are the stations whose events are annotated. If empty, the
+	// events of all stations are annotated.
+	Stations []string
+
+	// GustThreshold is the minimum wind gust speed, in km/h, for a daily
+	// gust record to be annotated.
+	GustThreshold float64
+
+	// RainStopAfter is the duration without the daily rain total increasing
+	// after which rain is considered to have stopped. If zero,
+	// DefaultRainStopAfter is used.
+	RainStopAfter time.Duration
+
+	// Interval is the interval at which stations are checked for going
+	// offline. If zero, DefaultInterval is used.
+	Interval time.Duration
+
+	// StaleAfter returns the duration since a station's last submission
+	// after which it is considered offline. Stations for which it returns
+	// zero, or all stations if it is nil, are not watched.
+	StaleAfter func(stationID string) time.Duration
+
+	// Location returns the time zone of a station's day, used to reset the
+	// daily gust record. If nil, or if it returns nil, UTC is used.
+	Location func(stationID string) *time.Location
+}
+
+// station is the event state of a station.
+type station struct {
+	lastSeen time.Time
+	offline  bool
+
+	// observed is whether an observation has been received from the
+	// station, and observedAt is the time of the latest one.
+	observed   bool
+	observedAt time.Time
+
+	rainToday  float64
+	raining    bool
+	rainStart  float64 // Daily rain total when the rain started
+	lastRainAt time.Time
+
+	gustDay time.Time
+	gustMax float64
+}
+
+// Annotator detects weather events from the observations of stations, and
+// creates a Grafana annotation for each event. Annotations are created in the
+// background, in the order the events occurred.
+type Annotator struct {
+	client        *Client
+	dashboardUID  string
+	tags          []string
+	events        []Event
+	stations      []string
+	gustThreshold float64
+	rainStopAfter time.Duration
+	interval      time.Duration
+	staleAfter    func(stationID string) time.Duration
+	location      func(stationID string) *time.Location
+
+	mu    sync.Mutex
+	state map[string]*station
+
+	queue     chan Annotation
+	done      chan struct{}
+	quit      chan struct{}
+	scheduler sync.WaitGroup
+}
+
+// NewAnnotator returns a new annotator, and starts watching for stations going
+// offline in the background. The annotator must be closed once it is no
+// longer used.
+func NewAnnotator(c Config) *Annotator {
+	a := &Annotator{
+		client:        c.Client,
+		dashboardUID:  c.DashboardUID,
+		tags:          c.Tags,
+		events:        c.Events,
+		stations:      c.Stations,
+		gustThreshold: c.GustThreshold,
+		rainStopAfter: c.RainStopAfter,
+		interval:      c.Interval,
+		staleAfter:    c.StaleAfter,
+		location:      c.Location,
+		state:         make(map[string]*station),
+		queue:         make(chan Annotation, queueSize),
+		done:          make(chan struct{}),
+		quit:          make(chan struct{}),
+	}
+	if a.rainStopAfter <= 0 {
+		a.rainStopAfter = DefaultRainStopAfter
+	}
+	if a.interval <= 0 {
+		a.interval = DefaultInterval
+	}
+	go a.send()
+	a.scheduler.Add(1)
+	go a.schedule()
+	return a
+}
+
+// Observe records an observation from a station, taken at time t. values
+// contains the observation's field values, of which "rain_today" (mm) and
+// "wind_gust_speed" (km/h) are used. Observations older than the station's
+// latest observation are ignored.
+func (a *Annotator) Observe(stationID string, t time.Time, values map[string]float64) {
+	if !a.watched(stationID) {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st := a.station(stationID)
+	if st.observed && t.Before(st.observedAt) {
+		return
+	}
+	first := !st.observed
+	st.observed, st.observedAt = true, t
+
+	if rain, ok := values["rain_today"]; ok {
+		a.observeRain(stationID, st, t, rain, first)
+	}
+	if gust, ok := values["wind_gust_speed"]; ok {
+		a.observeGust(stationID, st, t, gust)
+	}
+}
+
+// observeRain detects rain starting and stopping from the station's daily rain
+// total. a.mu must be held.
+func (a *Annotator) observeRain(stationID string, st *station, t time.Time, rain float64, first bool) {
+	last := st.rainToday
+	st.rainToday = rain
+	switch {
+	case first || rain < last:
+		// No previous total to compare against, or the daily total was
+		// reset.
+		if st.raining {
+			st.rainStart = 0
+		}
+	case rain > last:
+		st.lastRainAt = t
+		if !st.raining {
+			st.raining = true
+			st.rainStart = last
+			a.annotate(stationID, EventRain, t, "Rain started")
+		}
+	case st.raining && t.Sub(st.lastRainAt) >= a.rainStopAfter:
+		st.raining = false
+		a.annotate(stationID, EventRain, st.lastRainAt,
+			fmt.Sprintf("Rain stopped (%.1f mm)", rain-st.rainStart))
+	}
+}
+
+// observeGust detects new daily wind gust records. a.mu must be held.
+func (a *Annotator) observeGust(stationID string, st *station, t time.Time, gust float64) {
+	var loc *time.Location
+	if a.location != nil {
+		loc = a.location(stationID)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	lt := t.In(loc)
+	day := time.Date(lt.Year(), lt.Month(), lt.Day(), 0, 0, 0, 0, loc)
+	if !day.Equal(st.gustDay) {
+		st.gustDay, st.gustMax = day, 0
+	}
+	if gust <= st.gustMax {
+		return
+	}
+	st.gustMax = gust
+	if gust >= a.gustThreshold {
+		a.annotate(stationID, EventGust, t, fmt.Sprintf("Gust record of %.1f km/h", gust))
+	}
+}
+
+// Seen records that a submission was received from a station at time t.
+func (a *Annotator) Seen(stationID string, t time.Time) {
+	if !a.watched(stationID) {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st := a.station(stationID)
+	st.lastSeen = t
+	if st.offline {
+		st.offline = false
+		a.annotate(stationID, EventOffline, t, "Station back online")
+	}
+}
+
+// watched returns whether the events of a station are annotated.
+func (a *Annotator) watched(stationID string) bool {
+	return len(a.stations) == 0 || slices.Contains(a.stations, stationID)
+}
+
+// station returns the state of a station, creating it if needed. a.mu must be
+// held.
+func (a *Annotator) station(stationID string) *station {
+	st, ok := a.state[stationID]
+	if !ok {
+		st = &station{}
+		a.state[stationID] = st
+	}
+	return st
+}
+
+// schedule checks for stations going offline at the annotator's interval,
+// until the annotator is closed.
+func (a *Annotator) schedule() {
+	defer a.scheduler.Done()
+
+	t := time.NewTicker(a.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-a.quit:
+			return
+		case now := <-t.C:
+			a.evaluate(now)
+		}
+	}
+}
+
+// evaluate checks whether stations have gone offline.
+func (a *Annotator) evaluate(now time.Time) {
+	if a.staleAfter == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for stationID, st := range a.state {
+		if st.offline || st.lastSeen.IsZero() {
+			continue
+		}
+		after := a.staleAfter(stationID)
+		if after > 0 && now.Sub(st.lastSeen) > after {
+			st.offline = true
+			a.annotate(stationID, EventOffline, st.lastSeen, "Station offline")
+		}
+	}
+}
+
+// annotate queues an annotation for an event, if the event is annotated.
+func (a *Annotator) annotate(stationID string, event Event, t time.Time, text string) {
+	if len(a.events) > 0 && !slices.Contains(a.events, event) {
+		return
+	}
+	tags := make([]string, 0, len(a.tags)+2)
+	tags = append(tags, a.tags...)
+	tags = append(tags, stationID, string(event))
+	an := Annotation{
+		Time:         t,
+		Text:         stationID + ": " + text,
+		Tags:         tags,
+		DashboardUID: a.dashboardUID,
+	}
+	select {
+	case a.queue <- an:
+	default:
+		slog.Warn("Grafana annotation queue is full, dropping annotation",
+			slog.String("station_id", stationID), slog.String("event", string(event)))
+	}
+}
+
+// send creates queued annotations until the annotator is closed.
+func (a *Annotator) send() {
+	defer close(a.done)
+	for an := range a.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), createTimeout)
+		if err := a.client.Create(ctx, an); err != nil {
+			slog.Error("Failed to create Grafana annotation",
+				slog.String("text", an.Text), slog.Any("err", err))
+		}
+		cancel()
+	}
+}
+
+// Close stops watching stations, and waits for queued annotations to be
+// created, until ctx is done.
+func (a *Annotator) Close(ctx context.Context) error {
+	close(a.quit)
+	a.scheduler.Wait()
+	a.mu.Lock()
+	close(a.queue)
+	a.mu.Unlock()
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAnnotator(t *testing.T) {
+	type annotation struct {
+		Time int64    `json:"time"`
+		Tags []string `json:"tags"`
+		Text string   `json:"text"`
+	}
+	var (
+		mu  sync.Mutex
+		got []annotation
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/annotations" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var a annotation
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		got = append(got, a)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"id":1,"message":"Annotation added"}`))
+	}))
+	defer srv.Close()
+
+	a := NewAnnotator(Config{
+		Client:        &Client{URL: srv.URL, Token: "token"},
+		Tags:          []string{"weather"},
+		Stations:      []string{"KTEST1"},
+		GustThreshold: 30,
+		Interval:      time.Hour,
+		StaleAfter:    func(string) time.Duration { return 10 * time.Minute },
+	})
+
+	start := time.Date(2025, 1, 23, 12, 0, 0, 0, time.UTC)
+	observe := func(minutes int, rain, gust float64) {
+		ts := start.Add(time.Duration(minutes) * time.Minute)
+		a.Seen("KTEST1", ts)
+		a.Observe("KTEST1", ts, map[string]float64{"rain_today": rain, "wind_gust_speed": gust})
+	}
+	observe(0, 1, 10)
+	observe(5, 1, 20)
+	observe(10, 1.5, 35) // Rain started, gust record
+	observe(15, 3, 32)
+	observe(20, 3, 40) // Gust record
+	observe(50, 3, 12) // Rain stopped
+	a.Observe("KOTHER1", start, map[string]float64{"rain_today": 5, "wind_gust_speed": 90})
+	a.evaluate(start.Add(61 * time.Minute)) // Offline
+	observe(90, 3, 10)                      // Back online
+
+	if err := a.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	want := []annotation{
+		{Time: start.Add(10 * time.Minute).UnixMilli(), Tags: []string{"weather", "KTEST1", "rain"}, Text: "KTEST1: Rain started"},
+		{Time: start.Add(10 * time.Minute).UnixMilli(), Tags: []string{"weather", "KTEST1", "gust"}, Text: "KTEST1: Gust record of 35.0 km/h"},
+		{Time: start.Add(20 * time.Minute).UnixMilli(), Tags: []string{"weather", "KTEST1", "gust"}, Text: "KTEST1: Gust record of 40.0 km/h"},
+		{Time: start.Add(15 * time.Minute).UnixMilli(), Tags: []string{"weather", "KTEST1", "rain"}, Text: "KTEST1: Rain stopped (2.0 mm)"},
+		{Time: start.Add(50 * time.Minute).UnixMilli(), Tags: []string{"weather", "KTEST1", "offline"}, Text: "KTEST1: Station offline"},
+		{Time: start.Add(90 * time.Minute).UnixMilli(), Tags: []string{"weather", "KTEST1", "offline"}, Text: "KTEST1: Station back online"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d annotations, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].Time != want[i].Time || got[i].Text != want[i].Text || !slices.Equal(got[i].Tags, want[i].Tags) {
+			t.Errorf("annotation %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAnnotatorEvents(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		count int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	// Only gust records are annotated, and they are reset on a new day.
+	a := NewAnnotator(Config{
+		Client:        &Client{URL: srv.URL},
+		Events:        []Event{EventGust},
+		GustThreshold: 10,
+	})
+	start := time.Date(2025, 1, 23, 23, 50, 0, 0, time.UTC)
+	a.Observe("KTEST1", start, map[string]float64{"rain_today": 0, "wind_gust_speed": 50})
+	a.Observe("KTEST1", start.Add(20*time.Minute), map[string]float64{"rain_today": 0.2, "wind_gust_speed": 20})
+	if err := a.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("got %d annotations, want 2", count)
+	}
+}
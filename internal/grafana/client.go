@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Annotation is a Grafana annotation.
+type Annotation struct {
+	// Time is the time of the annotation.
+	Time time.Time
+
+	// Text is the annotation text.
+	Text string
+
+	// Tags are the annotation tags.
+	Tags []string
+
+	// DashboardUID is the UID of the dashboard the annotation is added to.
+	// If empty, an organization annotation is created, which can be shown
+	// on any dashboard using an annotation query that matches its tags.
+	DashboardUID string
+}
+
+// Client creates annotations using the Grafana HTTP API.
+type Client struct {
+	// URL is the Grafana URL, e.g. http://localhost:3000.
+	URL string
+
+	// Token is a Grafana service account token. If empty, requests are not
+	// authenticated.
+	Token string
+
+	// HTTPClient is the HTTP client used to send requests. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// Create creates an annotation.
+func (c *Client) Create(ctx context.Context, a Annotation) error {
+	body, err := json.Marshal(struct {
+		DashboardUID string   `json:"dashboardUID,omitempty"`
+		Time         int64    `json:"time"`
+		Tags         []string `json:"tags"`
+		Text         string   `json:"text"`
+	}{
+		DashboardUID: a.DashboardUID,
+		Time:         a.Time.UnixMilli(),
+		Tags:         a.Tags,
+		Text:         a.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	u := strings.TrimSuffix(c.URL, "/") + "/api/annotations"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pws_exporter")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("grafana: %s: %s", res.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
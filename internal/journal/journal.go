@@ -0,0 +1,315 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package journal implements an append-only write-ahead journal of raw
+// submissions, allowing submissions that were accepted but not processed to be
+// replayed after a crash.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// compactSize is the journal file size after which the journal is compacted,
+// once at least half of the file is acknowledged records.
+const compactSize = 1 << 20 // 1 MiB
+
+// Entry is a raw submission recorded in the journal.
+type Entry struct {
+	Seq        uint64    `json:"seq"`
+	ReceivedAt time.Time `json:"received_at"`
+	RawQuery   string    `json:"query"`
+}
+
+// record is a line in the journal file, which is either an entry or an
+// acknowledgement of a processed entry.
+type record struct {
+	*Entry
+	Ack uint64 `json:"ack,omitempty"`
+}
+
+// pendingEntry is an entry that has not been acknowledged, and the size of its
+// record in the journal file.
+type pendingEntry struct {
+	Entry
+	size int64
+}
+
+// Journal is an append-only journal of raw submissions.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	size    int64 // journal file size
+	live    int64 // size of the records of pending entries
+	seq     uint64
+	corrupt int
+	dirty   bool // acknowledgements written but not synced
+	pending map[uint64]pendingEntry
+}
+
+// Open opens the journal file at the given path, creating it if it does not
+// exist. It returns the journal and the entries that were not acknowledged,
+// which should be replayed and then acknowledged. Corrupt records are skipped
+// and counted (see Corrupt).
+func Open(path string) (*Journal, []Entry, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	j := &Journal{
+		path:    path,
+		f:       f,
+		pending: make(map[uint64]pendingEntry),
+	}
+	entries, err := j.read()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("read journal: %w", err)
+	}
+	return j, entries, nil
+}
+
+// read reads the journal file and returns the unacknowledged entries.
+func (j *Journal) read() ([]Entry, error) {
+	var (
+		entries []pendingEntry
+		acked   = make(map[uint64]struct{})
+	)
+	r := bufio.NewReader(j.f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A trailing partial line is a write that was interrupted by a
+			// crash, and is discarded.
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		j.size += int64(len(line))
+
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			// A corrupt record (e.g. from a disk error) does not prevent the
+			// remaining entries from being replayed.
+			j.corrupt++
+			continue
+		}
+		switch {
+		case rec.Ack != 0:
+			acked[rec.Ack] = struct{}{}
+		case rec.Entry != nil:
+			entries = append(entries, pendingEntry{Entry: *rec.Entry, size: int64(len(line))})
+			j.seq = max(j.seq, rec.Seq)
+		}
+	}
+
+	// Discard any partial trailing line.
+	if err := j.f.Truncate(j.size); err != nil {
+		return nil, err
+	}
+	if _, err := j.f.Seek(j.size, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var out []Entry
+	for _, e := range entries {
+		if _, ok := acked[e.Seq]; !ok {
+			out = append(out, e.Entry)
+			j.pending[e.Seq] = e
+			j.live += e.size
+		}
+	}
+	return out, nil
+}
+
+// Corrupt returns the number of corrupt records that were skipped when the
+// journal was opened.
+func (j *Journal) Corrupt() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.corrupt
+}
+
+// ReadFile calls fn for every entry recorded in the journal file at the given
+// path, including entries that have been acknowledged, in the order they were
+// appended. The file is opened read-only, so it can be read while the journal
+// is in use. Corrupt records are skipped. Acknowledged entries are removed when
+// the journal is compacted, so it only contains the most recent submissions.
+func ReadFile(path string, fn func(Entry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Ignore a trailing partial line.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			continue
+		}
+		if rec.Ack != 0 || rec.Entry == nil {
+			continue
+		}
+		if err := fn(*rec.Entry); err != nil {
+			return err
+		}
+	}
+}
+
+// Append writes a raw submission to the journal and syncs it to disk. It
+// returns the sequence number of the entry, which must be acknowledged once
+// the submission has been processed.
+func (j *Journal) Append(receivedAt time.Time, rawQuery string) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	e := Entry{Seq: j.seq, ReceivedAt: receivedAt, RawQuery: rawQuery}
+	n, err := j.write(record{Entry: &e})
+	if err != nil {
+		return 0, err
+	}
+	// The sync also persists any acknowledgements written since the last sync.
+	if err := j.f.Sync(); err != nil {
+		return 0, err
+	}
+	j.dirty = false
+	j.pending[e.Seq] = pendingEntry{Entry: e, size: n}
+	j.live += n
+	return e.Seq, nil
+}
+
+// Ack acknowledges that the entry with the given sequence number has been
+// processed. Acknowledgements are not synced individually, and are persisted
+// by the next Append, compaction or Close. An acknowledgement lost in a crash
+// causes the entry to be replayed again. Once the journal has grown large
+// enough and at least half of it is acknowledged records, it is compacted.
+func (j *Journal) Ack(seq uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	e, ok := j.pending[seq]
+	if !ok {
+		return nil
+	}
+	delete(j.pending, seq)
+	j.live -= e.size
+
+	if j.size >= compactSize && j.size >= 2*j.live {
+		return j.compact()
+	}
+	if _, err := j.write(record{Ack: seq}); err != nil {
+		return err
+	}
+	j.dirty = true
+	return nil
+}
+
+// write writes a record to the journal, and returns the size of the record.
+func (j *Journal) write(rec record) (int64, error) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return 0, err
+	}
+	b = append(b, '\n')
+	n, err := j.f.Write(b)
+	j.size += int64(n)
+	return int64(n), err
+}
+
+// compact rewrites the journal with only the pending entries. The entries are
+// written to a temporary file, which then atomically replaces the journal.
+func (j *Journal) compact() error {
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	seqs := slices.Sorted(maps.Keys(j.pending))
+	w := bufio.NewWriter(f)
+	var size int64
+	for _, seq := range seqs {
+		e := j.pending[seq]
+		b, err := json.Marshal(record{Entry: &e.Entry})
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		b = append(b, '\n')
+		n, _ := w.Write(b)
+		size += int64(n)
+		e.size = int64(n)
+		j.pending[seq] = e
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	_ = j.f.Close()
+	j.f = f
+	j.size = size
+	j.live = size
+	j.dirty = false
+	return nil
+}
+
+// Close syncs any unsynced acknowledgements and closes the journal.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var err error
+	if j.dirty {
+		err = j.f.Sync()
+	}
+	return errors.Join(err, j.f.Close())
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	j, pending, err := Open(path)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("new journal has %d pending entries", len(pending))
+	}
+
+	now := time.Now().UTC()
+	seq1, err := j.Append(now, "ID=a")
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	seq2, err := j.Append(now, "ID=b")
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := j.Ack(seq1); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// Simulate a crash during a write.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open journal file: %v", err)
+	}
+	_, _ = f.WriteString(`{"seq":3,"rece`)
+	_ = f.Close()
+
+	j, pending, err = Open(path)
+	if err != nil {
+		t.Fatalf("re-open journal: %v", err)
+	}
+	defer j.Close()
+	if len(pending) != 1 {
+		t.Fatalf("got %d pending entries, want 1", len(pending))
+	}
+	if pending[0].Seq != seq2 || pending[0].RawQuery != "ID=b" || !pending[0].ReceivedAt.Equal(now) {
+		t.Errorf("unexpected pending entry: %+v", pending[0])
+	}
+
+	// New entries must not reuse sequence numbers.
+	seq3, err := j.Append(now, "ID=c")
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if seq3 <= seq2 {
+		t.Errorf("sequence number got %d, want > %d", seq3, seq2)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	j, _, err := Open(path)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	defer j.Close()
+
+	now := time.Now().UTC()
+	for _, q := range []string{"ID=a", "ID=b"} {
+		seq, err := j.Append(now, q)
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if err := j.Ack(seq); err != nil {
+			t.Fatalf("ack: %v", err)
+		}
+	}
+
+	var queries []string
+	err = ReadFile(path, func(e Entry) error {
+		queries = append(queries, e.RawQuery)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	if len(queries) != 2 || queries[0] != "ID=a" || queries[1] != "ID=b" {
+		t.Errorf("got entries %v, want [ID=a ID=b]", queries)
+	}
+}
+
+func TestJournalCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	data := `{"seq":1,"received_at":"2024-01-01T00:00:00Z","query":"ID=a"}
+{"seq":2,"rece
+{"seq":3,"received_at":"2024-01-01T00:00:00Z","query":"ID=c"}
+{"ack":1}
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write journal: %v", err)
+	}
+
+	j, pending, err := Open(path)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	defer j.Close()
+	if got := j.Corrupt(); got != 1 {
+		t.Errorf("corrupt records got %d, want 1", got)
+	}
+	if len(pending) != 1 || pending[0].Seq != 3 {
+		t.Errorf("got pending entries %+v, want seq 3", pending)
+	}
+}
+
+func TestJournalCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	j, _, err := Open(path)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+
+	// An entry that is never acknowledged must not prevent compaction.
+	now := time.Now().UTC()
+	stuck, err := j.Append(now, "ID=stuck")
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	query := "ID=a&" + strings.Repeat("x", 1000)
+	for range 2 * compactSize / len(query) {
+		seq, err := j.Append(now, query)
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if err := j.Ack(seq); err != nil {
+			t.Fatalf("ack: %v", err)
+		}
+	}
+	last, err := j.Append(now, "ID=last")
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat journal: %v", err)
+	}
+	if fi.Size() >= compactSize {
+		t.Errorf("journal size got %d, want < %d", fi.Size(), compactSize)
+	}
+
+	j, pending, err := Open(path)
+	if err != nil {
+		t.Fatalf("re-open journal: %v", err)
+	}
+	defer j.Close()
+	if len(pending) != 2 || pending[0].Seq != stuck || pending[1].Seq != last {
+		t.Errorf("got pending entries %+v, want seq %d and %d", pending, stuck, last)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package logfile implements a log file writer with size and age based
+// rotation.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the time format used in the names of rotated files.
+const backupTimeFormat = "20060102T150405.000000"
+
+// Options are the log file rotation options. Zero values disable the
+// corresponding rotation or retention.
+type Options struct {
+	// MaxSize is the size in bytes after which the file is rotated.
+	MaxSize int64
+
+	// MaxAge is the duration after which the file is rotated.
+	MaxAge time.Duration
+
+	// MaxBackups is the maximum number of rotated files to keep.
+	MaxBackups int
+}
+
+// Writer is a log file writer that rotates the file when it grows too large or
+// too old. Rotated files are renamed to the file path with the rotation time
+// appended, e.g. "pws_exporter.log.20250123T230918.000000".
+type Writer struct {
+	path string
+	opts Options
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	opened time.Time
+}
+
+// Open opens the log file at the given path for appending, creating it if it
+// does not exist.
+func Open(path string, opts Options) (*Writer, error) {
+	w := &Writer{path: path, opts: opts}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open opens the log file.
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.f = f
+	w.size = fi.Size()
+	w.opened = time.Now()
+	return nil
+}
+
+// Write writes p to the log file, rotating it first if necessary.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, fmt.Errorf("rotate log file: %w", err)
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// shouldRotate returns whether the file should be rotated before writing n
+// bytes. Empty files are never rotated.
+func (w *Writer) shouldRotate(n int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.opts.MaxSize > 0 && w.size+n > w.opts.MaxSize {
+		return true
+	}
+	return w.opts.MaxAge > 0 && time.Since(w.opened) > w.opts.MaxAge
+}
+
+// rotate renames the current file, opens a new file and removes old backups.
+func (w *Writer) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	w.f = nil
+	backup := w.path + "." + time.Now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(w.path, backup); err != nil {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.removeBackups()
+}
+
+// removeBackups removes the oldest rotated files when there are more than
+// MaxBackups.
+func (w *Writer) removeBackups() error {
+	if w.opts.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > w.opts.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// backups returns the paths of the rotated files, oldest first.
+func (w *Writer) backups() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(w.path))
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(w.path) + "."
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, strings.TrimPrefix(name, prefix)); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(filepath.Dir(w.path), name))
+	}
+	// The time format sorts lexically in chronological order.
+	slices.Sort(backups)
+	return backups, nil
+}
+
+// Close closes the log file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterRotateSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	w, err := Open(path, Options{MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if string(b) != "fourth\n" {
+		t.Errorf("log file contains %q, want %q", b, "fourth\n")
+	}
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("backups: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("got %d backups, want 2", len(backups))
+	}
+	for i, want := range []string{"second\n", "third\n"} {
+		b, err := os.ReadFile(backups[i])
+		if err != nil {
+			t.Fatalf("read backup: %v", err)
+		}
+		if string(b) != want {
+			t.Errorf("backup %d contains %q, want %q", i, b, want)
+		}
+	}
+}
+
+func TestWriterRotateAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	w, err := Open(path, Options{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("old\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	w.opened = w.opened.Add(-2 * time.Hour)
+	if _, err := w.Write([]byte("new\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if string(b) != "new\n" {
+		t.Errorf("log file contains %q, want %q", b, "new\n")
+	}
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("backups: %v", err)
+	}
+	if len(backups) != 1 || !strings.HasPrefix(filepath.Base(backups[0]), "test.log.") {
+		t.Errorf("got backups %v, want one backup", backups)
+	}
+}
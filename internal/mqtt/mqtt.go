@@ -0,0 +1,440 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package mqtt implements a minimal MQTT 3.1.1 client, which subscribes to
+// topics and receives the messages published to them.
+//
+// Only the parts of the protocol needed to receive messages are implemented:
+// messages are received with at most QoS 1, and the client does not publish
+// messages. Sessions are not persisted, so messages published while the
+// client is disconnected are not received.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Packet types.
+const (
+	typeConnect    = 1
+	typeConnAck    = 2
+	typePublish    = 3
+	typePubAck     = 4
+	typeSubscribe  = 8
+	typeSubAck     = 9
+	typePingReq    = 12
+	typePingResp   = 13
+	typeDisconnect = 14
+)
+
+const (
+	// defaultKeepAlive is the keep alive interval used if none is set.
+	defaultKeepAlive = time.Minute
+
+	// dialTimeout is the maximum time to wait for the connection to the
+	// broker, and for the broker to acknowledge the connection and the
+	// subscription.
+	dialTimeout = 10 * time.Second
+
+	// maxPacketSize is the maximum size of a received packet. Larger packets
+	// are rejected, and close the connection.
+	maxPacketSize = 1 << 20
+)
+
+// Options are the options used to connect to the broker.
+type Options struct {
+	// Broker is the URL of the broker, e.g. mqtt://localhost:1883. The
+	// mqtt:// and tcp:// schemes connect without TLS, and the mqtts://,
+	// ssl:// and tls:// schemes connect with TLS. The port defaults to 1883
+	// without TLS, and 8883 with TLS.
+	Broker string
+
+	// ClientID is the client identifier. If empty, a random identifier is
+	// used.
+	ClientID string
+
+	// Username and Password are the credentials used to connect to the
+	// broker, if set.
+	Username string
+	Password string
+
+	// KeepAlive is the interval the client pings the broker at. The
+	// connection is closed if nothing is received from the broker for 1.5
+	// times the interval. Defaults to 1 minute.
+	KeepAlive time.Duration
+
+	// TLSConfig is the TLS configuration used for TLS connections. If nil,
+	// the default configuration is used.
+	TLSConfig *tls.Config
+}
+
+// Message is a message received from the broker.
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// ParseBroker parses the broker URL, and returns the broker address and
+// whether TLS is used.
+func ParseBroker(broker string) (addr string, useTLS bool, err error) {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return "", false, err
+	}
+	port := "1883"
+	switch u.Scheme {
+	case "mqtt", "tcp":
+	case "mqtts", "ssl", "tls":
+		useTLS, port = true, "8883"
+	default:
+		return "", false, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", false, errors.New("missing host")
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// Match returns whether a topic matches a topic filter, which may contain the
+// single-level (+) and multi-level (#) wildcards. Topics starting with $ are
+// not matched by filters starting with a wildcard.
+func Match(filter, topic string) bool {
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+	fs, ts := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, f := range fs {
+		switch {
+		case f == "#":
+			return true
+		case i >= len(ts):
+			return false
+		case f != "+" && f != ts[i]:
+			return false
+		}
+	}
+	return len(fs) == len(ts)
+}
+
+// Subscribe connects to the broker and subscribes to the topic filters, and
+// calls handler with each message received until ctx is done, in which case
+// it disconnects and returns nil, or the connection fails. ready is called
+// with the broker address once the broker has acknowledged the subscription.
+//
+// The handler is called synchronously, and the next message is not received
+// until it returns.
+func Subscribe(ctx context.Context, o Options, filters []string, ready func(addr string), handler func(Message)) error {
+	addr, useTLS, err := ParseBroker(o.Broker)
+	if err != nil {
+		return fmt.Errorf("broker %q: %w", o.Broker, err)
+	}
+	if len(filters) == 0 {
+		return errors.New("no topic filters")
+	}
+	keepAlive := o.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = defaultKeepAlive
+	}
+	clientID := o.ClientID
+	if clientID == "" {
+		b := make([]byte, 8)
+		_, _ = rand.Read(b)
+		clientID = "pws_exporter-" + hex.EncodeToString(b)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	var conn net.Conn
+	if useTLS {
+		d := tls.Dialer{Config: o.TLSConfig}
+		conn, err = d.DialContext(dialCtx, "tcp", addr)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(dialCtx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	c := &client{conn: conn, r: bufio.NewReader(conn)}
+	defer conn.Close()
+
+	// Close the connection once ctx is done, which unblocks reads.
+	stop := context.AfterFunc(ctx, func() {
+		c.write(typeDisconnect<<4, nil)
+		_ = conn.Close()
+	})
+	defer stop()
+
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+	if err := c.connect(clientID, o.Username, o.Password, keepAlive); err != nil {
+		return c.err(ctx, err)
+	}
+	if err := c.subscribe(filters); err != nil {
+		return c.err(ctx, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	ready(addr)
+
+	// Ping the broker while the connection is open.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	pingCtx, stopPing := context.WithCancel(ctx)
+	defer stopPing()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t := time.NewTicker(keepAlive)
+		defer t.Stop()
+		for {
+			select {
+			case <-pingCtx.Done():
+				return
+			case <-t.C:
+				if err := c.write(typePingReq<<4, nil); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(keepAlive * 3 / 2))
+		header, body, err := c.read()
+		if err != nil {
+			return c.err(ctx, err)
+		}
+		switch header >> 4 {
+		case typePublish:
+			msg, id, err := parsePublish(header, body)
+			if err != nil {
+				return c.err(ctx, err)
+			}
+			handler(msg)
+			if id != 0 {
+				if err := c.write(typePubAck<<4, binary.BigEndian.AppendUint16(nil, id)); err != nil {
+					return c.err(ctx, err)
+				}
+			}
+		case typePingResp:
+		default:
+			return c.err(ctx, fmt.Errorf("unexpected packet type %d", header>>4))
+		}
+	}
+}
+
+// client is a connection to the broker.
+type client struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	mu sync.Mutex // Serializes writes
+}
+
+// err returns nil if ctx is done, as the connection was closed because the
+// client disconnected, and otherwise returns err.
+func (c *client) err(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// connect sends the CONNECT packet, and waits for the broker to accept the
+// connection.
+func (c *client) connect(clientID, username, password string, keepAlive time.Duration) error {
+	flags := byte(0x02) // Clean session
+	body := appendString(nil, "MQTT")
+	body = append(body, 4) // Protocol level 3.1.1
+	flagsAt := len(body)
+	body = append(body, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(min(keepAlive/time.Second, 0xFFFF)))
+	body = appendString(body, clientID)
+	if username != "" {
+		flags |= 0x80
+		body = appendString(body, username)
+	}
+	if password != "" {
+		flags |= 0x40
+		body = appendString(body, password)
+	}
+	body[flagsAt] = flags
+	if err := c.write(typeConnect<<4, body); err != nil {
+		return err
+	}
+
+	header, ack, err := c.read()
+	if err != nil {
+		return err
+	}
+	if header>>4 != typeConnAck || len(ack) != 2 {
+		return errors.New("invalid connection acknowledgement")
+	}
+	switch ack[1] {
+	case 0:
+		return nil
+	case 4, 5:
+		return errors.New("connection refused: not authorized")
+	default:
+		return fmt.Errorf("connection refused: return code %d", ack[1])
+	}
+}
+
+// subscribe sends the SUBSCRIBE packet, and waits for the broker to accept
+// the subscriptions.
+func (c *client) subscribe(filters []string) error {
+	const packetID = 1
+	body := binary.BigEndian.AppendUint16(nil, packetID)
+	for _, f := range filters {
+		body = appendString(body, f)
+		body = append(body, 1) // Maximum QoS 1
+	}
+	if err := c.write(typeSubscribe<<4|0x02, body); err != nil {
+		return err
+	}
+
+	// The broker may publish retained messages before acknowledging the
+	// subscription, which are dropped.
+	for {
+		header, ack, err := c.read()
+		if err != nil {
+			return err
+		}
+		switch header >> 4 {
+		case typePublish:
+			continue
+		case typeSubAck:
+		default:
+			return fmt.Errorf("unexpected packet type %d", header>>4)
+		}
+		if len(ack) != 2+len(filters) || binary.BigEndian.Uint16(ack) != packetID {
+			return errors.New("invalid subscription acknowledgement")
+		}
+		for i, code := range ack[2:] {
+			if code == 0x80 {
+				return fmt.Errorf("subscription to %q refused", filters[i])
+			}
+		}
+		return nil
+	}
+}
+
+// write writes a packet.
+func (c *client) write(header byte, body []byte) error {
+	b := append([]byte{header}, appendLength(nil, len(body))...)
+	b = append(b, body...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.conn.Write(b)
+	return err
+}
+
+// read reads a packet, and returns its first header byte and its body.
+func (c *client) read() (byte, []byte, error) {
+	header, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var length, shift int
+	for i := 0; ; i++ {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length |= int(b&0x7F) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, nil, errors.New("invalid remaining length")
+		}
+		shift += 7
+	}
+	if length > maxPacketSize {
+		return 0, nil, fmt.Errorf("packet size %d exceeds maximum of %d bytes", length, maxPacketSize)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// parsePublish parses a PUBLISH packet, and returns the message and its packet
+// identifier, which is 0 for QoS 0 messages that are not acknowledged.
+func parsePublish(header byte, body []byte) (Message, uint16, error) {
+	qos := header >> 1 & 0x03
+	if len(body) < 2 {
+		return Message{}, 0, errors.New("invalid publish packet")
+	}
+	n := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+n {
+		return Message{}, 0, errors.New("invalid publish topic")
+	}
+	msg := Message{Topic: string(body[2 : 2+n])}
+	body = body[2+n:]
+	var id uint16
+	if qos > 0 {
+		if len(body) < 2 {
+			return Message{}, 0, errors.New("invalid publish packet identifier")
+		}
+		id = binary.BigEndian.Uint16(body)
+		body = body[2:]
+	}
+	msg.Payload = body
+	return msg, id, nil
+}
+
+// appendString appends a length-prefixed UTF-8 string.
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// appendLength appends the remaining length of a packet.
+func appendLength(b []byte, n int) []byte {
+	for {
+		d := byte(n & 0x7F)
+		n >>= 7
+		if n > 0 {
+			d |= 0x80
+		}
+		b = append(b, d)
+		if n == 0 {
+			return b
+		}
+	}
+}
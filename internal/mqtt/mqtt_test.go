@@ -0,0 +1,283 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// broker is a fake broker, which accepts a single connection.
+type broker struct {
+	t *testing.T
+	l net.Listener
+}
+
+func newBroker(t *testing.T) *broker {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	return &broker{t: t, l: l}
+}
+
+func (b *broker) url() string {
+	return "mqtt://" + b.l.Addr().String()
+}
+
+// accept accepts a connection, and returns a client used to read and write
+// packets from the broker side.
+func (b *broker) accept() *client {
+	conn, err := b.l.Accept()
+	if err != nil {
+		b.t.Error(err)
+		return nil
+	}
+	b.t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return &client{conn: conn, r: bufio.NewReader(conn)}
+}
+
+func TestParseBroker(t *testing.T) {
+	tests := []struct {
+		broker string
+		addr   string
+		tls    bool
+		err    bool
+	}{
+		{broker: "mqtt://localhost", addr: "localhost:1883"},
+		{broker: "tcp://10.0.0.1:1884", addr: "10.0.0.1:1884"},
+		{broker: "mqtts://broker.example", addr: "broker.example:8883", tls: true},
+		{broker: "ssl://[::1]:8884", addr: "[::1]:8884", tls: true},
+		{broker: "http://localhost", err: true},
+		{broker: "mqtt://", err: true},
+	}
+	for _, tt := range tests {
+		addr, useTLS, err := ParseBroker(tt.broker)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseBroker(%q) error = %v, want error %v", tt.broker, err, tt.err)
+			continue
+		}
+		if addr != tt.addr || useTLS != tt.tls {
+			t.Errorf("ParseBroker(%q) = %q, %v, want %q, %v", tt.broker, addr, useTLS, tt.addr, tt.tls)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"weather/station", "weather/station", true},
+		{"weather/station", "weather/other", false},
+		{"weather/+/state", "weather/backyard/state", true},
+		{"weather/+/state", "weather/backyard/other", false},
+		{"weather/+", "weather/backyard/state", false},
+		{"weather/#", "weather/backyard/state", true},
+		{"weather/#", "weather", true},
+		{"#", "weather/backyard", true},
+		{"#", "$SYS/broker/uptime", false},
+		{"+/broker/uptime", "$SYS/broker/uptime", false},
+		{"$SYS/#", "$SYS/broker/uptime", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	b := newBroker(t)
+
+	brokerDone := make(chan struct{})
+	go func() {
+		defer close(brokerDone)
+		c := b.accept()
+		if c == nil {
+			return
+		}
+
+		header, body, err := c.read()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if header>>4 != typeConnect {
+			t.Errorf("packet type = %d, want CONNECT", header>>4)
+			return
+		}
+		want := appendString(nil, "MQTT")
+		want = append(want, 4, 0xC2, 0, 30)
+		want = appendString(want, "test")
+		want = appendString(want, "user")
+		want = appendString(want, "pass")
+		if string(body) != string(want) {
+			t.Errorf("CONNECT = %x, want %x", body, want)
+		}
+		_ = c.write(typeConnAck<<4, []byte{0, 0})
+
+		header, body, err = c.read()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if header != typeSubscribe<<4|0x02 {
+			t.Errorf("header = %x, want SUBSCRIBE", header)
+		}
+		want = binary.BigEndian.AppendUint16(nil, 1)
+		want = append(appendString(want, "rtl_433/+/events"), 1)
+		if string(body) != string(want) {
+			t.Errorf("SUBSCRIBE = %x, want %x", body, want)
+		}
+		_ = c.write(typeSubAck<<4, []byte{0, 1, 1})
+
+		// QoS 0 message.
+		_ = c.write(typePublish<<4, append(appendString(nil, "rtl_433/a/events"), "one"...))
+
+		// QoS 1 message, which must be acknowledged.
+		msg := binary.BigEndian.AppendUint16(appendString(nil, "rtl_433/b/events"), 7)
+		_ = c.write(typePublish<<4|0x02, append(msg, "two"...))
+		header, body, err = c.read()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if header>>4 != typePubAck || binary.BigEndian.Uint16(body) != 7 {
+			t.Errorf("PUBACK = %x %x, want packet 7", header, body)
+		}
+
+		// The client disconnects once the context is cancelled.
+		header, _, err = c.read()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if header>>4 != typeDisconnect {
+			t.Errorf("packet type = %d, want DISCONNECT", header>>4)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		readyAddr string
+		got       []Message
+	)
+	o := Options{
+		Broker:    b.url(),
+		ClientID:  "test",
+		Username:  "user",
+		Password:  "pass",
+		KeepAlive: 30 * time.Second,
+	}
+	err := Subscribe(ctx, o, []string{"rtl_433/+/events"}, func(addr string) {
+		readyAddr = addr
+	}, func(msg Message) {
+		got = append(got, msg)
+		if len(got) == 2 {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	<-brokerDone
+
+	if readyAddr != b.l.Addr().String() {
+		t.Errorf("ready address = %q, want %q", readyAddr, b.l.Addr())
+	}
+	want := []Message{
+		{Topic: "rtl_433/a/events", Payload: []byte("one")},
+		{Topic: "rtl_433/b/events", Payload: []byte("two")},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Topic != want[i].Topic || string(got[i].Payload) != string(want[i].Payload) {
+			t.Errorf("message %d = %q %q, want %q %q", i, got[i].Topic, got[i].Payload, want[i].Topic, want[i].Payload)
+		}
+	}
+}
+
+func TestSubscribeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ack  func(c *client)
+	}{
+		{
+			name: "not authorized",
+			ack: func(c *client) {
+				_ = c.write(typeConnAck<<4, []byte{0, 5})
+			},
+		},
+		{
+			name: "subscription refused",
+			ack: func(c *client) {
+				_ = c.write(typeConnAck<<4, []byte{0, 0})
+				if _, _, err := c.read(); err != nil {
+					return
+				}
+				_ = c.write(typeSubAck<<4, []byte{0, 1, 0x80})
+			},
+		},
+		{
+			name: "connection lost",
+			ack: func(c *client) {
+				_ = c.write(typeConnAck<<4, []byte{0, 0})
+				if _, _, err := c.read(); err != nil {
+					return
+				}
+				_ = c.write(typeSubAck<<4, []byte{0, 1, 0})
+				_ = c.conn.Close()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBroker(t)
+			go func() {
+				c := b.accept()
+				if c == nil {
+					return
+				}
+				if _, _, err := c.read(); err != nil {
+					return
+				}
+				tt.ack(c)
+			}()
+
+			err := Subscribe(context.Background(), Options{Broker: b.url()},
+				[]string{"#"}, func(string) {}, func(Message) {})
+			if err == nil {
+				t.Error("Subscribe succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package opensensemap implements a client for uploading measurements to
+// openSenseMap (https://opensensemap.org) senseBoxes.
+package opensensemap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the openSenseMap API URL.
+const DefaultURL = "https://api.opensensemap.org"
+
+// Measurement is a measurement of a senseBox sensor.
+type Measurement struct {
+	SensorID string
+	Value    float64
+	Time     time.Time
+}
+
+// Client uploads measurements to openSenseMap.
+type Client struct {
+	// URL is the openSenseMap API URL. If empty, DefaultURL is used.
+	URL string
+
+	// HTTPClient is the HTTP client used to send requests. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// Post uploads measurements to a senseBox. The access token is required for
+// senseBoxes that have authentication enabled.
+func (c *Client) Post(ctx context.Context, boxID, accessToken string, measurements []Measurement) error {
+	type measurement struct {
+		Sensor    string `json:"sensor"`
+		Value     string `json:"value"`
+		CreatedAt string `json:"createdAt"`
+	}
+	data := make([]measurement, 0, len(measurements))
+	for _, m := range measurements {
+		data = append(data, measurement{
+			Sensor:    m.SensorID,
+			Value:     strconv.FormatFloat(m.Value, 'f', -1, 64),
+			CreatedAt: m.Time.UTC().Format(time.RFC3339Nano),
+		})
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	apiURL := c.URL
+	if apiURL == "" {
+		apiURL = DefaultURL
+	}
+	u := strings.TrimSuffix(apiURL, "/") + "/boxes/" + url.PathEscape(boxID) + "/data"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pws_exporter")
+	if accessToken != "" {
+		req.Header.Set("Authorization", accessToken)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("opensensemap: %s: %s", res.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
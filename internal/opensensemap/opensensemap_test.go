@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package opensensemap
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientPost(t *testing.T) {
+	var got []map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/boxes/box1/data" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := &Client{URL: srv.URL}
+	ts := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	err := c.Post(context.Background(), "box1", "token", []Measurement{
+		{SensorID: "temp", Value: 17.5, Time: ts},
+		{SensorID: "hum", Value: 62, Time: ts},
+	})
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if len(got) != 2 || got[0]["sensor"] != "temp" || got[0]["value"] != "17.5" ||
+		got[0]["createdAt"] != "2025-01-23T23:00:00Z" || got[1]["value"] != "62" {
+		t.Errorf("unexpected measurements: %v", got)
+	}
+
+	if err := c.Post(context.Background(), "box1", "wrong", nil); err == nil {
+		t.Error("expected error for unauthorized request")
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package remotewrite implements a minimal Prometheus remote write (1.0)
+// client, as specified by https://prometheus.io/docs/specs/remote_write_spec/.
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// maxRetries is the maximum number of times a failed request is retried.
+const maxRetries = 5
+
+// Label is a time series label.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a time series sample.
+type Sample struct {
+	Value     float64
+	Timestamp time.Time
+}
+
+// TimeSeries is a time series with a set of labels, including the __name__
+// label, and its samples in time order.
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// Client is a remote write client.
+type Client struct {
+	// URL is the remote write endpoint URL.
+	URL string
+
+	// Username and Password are optional HTTP basic authentication
+	// credentials.
+	Username string
+	Password string
+
+	// HTTPClient is the HTTP client used to send requests. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// Write sends the time series to the remote write endpoint. Requests that
+// fail with a server error or are rate limited are retried with exponential
+// backoff.
+func (c *Client) Write(ctx context.Context, series []TimeSeries) error {
+	body := s2.EncodeSnappy(nil, encodeWriteRequest(series))
+
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		retry, err := c.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send sends a single remote write request, and returns whether the request
+// should be retried if it failed.
+func (c *Client) send(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "pws_exporter")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	err = fmt.Errorf("remote write: %s: %s", res.Status, bytes.TrimSpace(msg))
+	return res.StatusCode/100 == 5 || res.StatusCode == http.StatusTooManyRequests, err
+}
+
+// encodeWriteRequest encodes a prometheus.WriteRequest protobuf message.
+func encodeWriteRequest(series []TimeSeries) []byte {
+	var b []byte
+	for _, ts := range series {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeTimeSeries(ts))
+	}
+	return b
+}
+
+// encodeTimeSeries encodes a prometheus.TimeSeries protobuf message. Labels
+// are sorted by name, as required by the specification.
+func encodeTimeSeries(ts TimeSeries) []byte {
+	labels := make([]Label, len(ts.Labels))
+	copy(labels, ts.Labels)
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+
+	var b []byte
+	for _, l := range labels {
+		var lb []byte
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.Name)
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.Value)
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, lb)
+	}
+	for _, s := range ts.Samples {
+		var sb []byte
+		sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(s.Value))
+		sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+		// int64 fields are encoded as two's complement varints.
+		sb = protowire.AppendVarint(sb, uint64(s.Timestamp.UnixMilli())) //nolint:gosec
+
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+	return b
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestWrite(t *testing.T) {
+	var (
+		body    []byte
+		headers http.Header
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		b, _ := io.ReadAll(r.Body)
+		body, _ = s2.Decode(nil, b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c := &Client{URL: ts.URL}
+	err := c.Write(context.Background(), []TimeSeries{{
+		Labels: []Label{
+			{Name: "station_id", Value: "test"},
+			{Name: "__name__", Value: "weather_station_temperature_celsius"},
+		},
+		Samples: []Sample{{Value: 20.5, Timestamp: time.UnixMilli(1737590400000)}},
+	}})
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := headers.Get("Content-Encoding"); got != "snappy" {
+		t.Errorf("Content-Encoding got %q, want %q", got, "snappy")
+	}
+
+	// WriteRequest.timeseries
+	num, typ, n := protowire.ConsumeTag(body)
+	if num != 1 || typ != protowire.BytesType {
+		t.Fatalf("unexpected WriteRequest field %d (type %d)", num, typ)
+	}
+	series, _ := protowire.ConsumeBytes(body[n:])
+
+	// TimeSeries.labels[0], which must be sorted.
+	_, _, n = protowire.ConsumeTag(series)
+	label, _ := protowire.ConsumeBytes(series[n:])
+	_, _, n = protowire.ConsumeTag(label)
+	name, _ := protowire.ConsumeString(label[n:])
+	if name != "__name__" {
+		t.Errorf("first label got %q, want %q", name, "__name__")
+	}
+}
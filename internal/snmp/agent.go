@@ -0,0 +1,326 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package snmp implements a minimal read-only SNMP agent, supporting the Get,
+// GetNext and GetBulk requests of SNMPv1 and SNMPv2c.
+package snmp
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net"
+	"slices"
+)
+
+// SNMP versions, as encoded in messages.
+const (
+	versionV1  = 0
+	versionV2c = 1
+)
+
+// PDU types.
+const (
+	pduGetRequest     = 0xa0
+	pduGetNextRequest = 0xa1
+	pduResponse       = 0xa2
+	pduSetRequest     = 0xa3
+	pduGetBulkRequest = 0xa5
+)
+
+// Error statuses.
+const (
+	errNoError     = 0
+	errTooBig      = 1
+	errNoSuchName  = 2
+	errReadOnly    = 4
+	errGenErr      = 5
+	errNotWritable = 17
+)
+
+// maxMessageSize is the maximum size of messages received and sent.
+const maxMessageSize = 1472
+
+// maxRepetitions limits the number of repetitions of a GetBulk request.
+const maxRepetitions = 64
+
+// Variable is a variable in the MIB view of an agent.
+type Variable struct {
+	OID OID
+
+	// Value is the value of the variable. Supported types are int, int64,
+	// string, []byte, OID, Gauge32, TimeTicks and Counter64.
+	Value any
+}
+
+// Agent is a read-only SNMP agent.
+type Agent struct {
+	// Community is the community string requests must use. Requests with a
+	// different community are ignored.
+	Community string
+
+	// Variables returns the MIB view of the agent, sorted by OID. It is
+	// called once for each request.
+	Variables func() []Variable
+}
+
+// Serve serves SNMP requests received on pc, until pc is closed.
+func (a *Agent) Serve(pc net.PacketConn) error {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		res, err := a.handle(buf[:n])
+		if err != nil {
+			slog.Debug("Failed to handle SNMP request",
+				slog.String("remote_addr", addr.String()), slog.Any("err", err))
+			continue
+		}
+		if res == nil {
+			continue
+		}
+		if _, err := pc.WriteTo(res, addr); err != nil {
+			slog.Debug("Failed to send SNMP response",
+				slog.String("remote_addr", addr.String()), slog.Any("err", err))
+		}
+	}
+}
+
+// request is a decoded SNMP request.
+type request struct {
+	version   int64
+	community []byte
+	pduType   byte
+	requestID int64
+
+	// nonRepeaters and maxRepetitions are only used by GetBulk requests,
+	// and are encoded in place of the error status and index.
+	nonRepeaters   int64
+	maxRepetitions int64
+
+	oids []OID
+}
+
+// handle handles a request message, and returns the response message. A nil
+// response is returned if the request must be ignored.
+func (a *Agent) handle(msg []byte) ([]byte, error) {
+	req, err := decodeRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(req.community, []byte(a.Community)) != 1 {
+		return nil, nil
+	}
+
+	vars := a.Variables()
+	var (
+		results     []Variable
+		errStatus   int64
+		errIndex    int64
+		v1          = req.version == versionV1
+		exceptionAt = func(i int, e exception, oid OID) {
+			if v1 {
+				if errStatus == errNoError {
+					errStatus, errIndex = errNoSuchName, int64(i+1)
+				}
+				results = append(results, Variable{OID: oid})
+				return
+			}
+			results = append(results, Variable{OID: oid, Value: e})
+		}
+	)
+	switch req.pduType {
+	case pduGetRequest:
+		for i, oid := range req.oids {
+			if v, ok := get(vars, oid); ok {
+				results = append(results, v)
+			} else {
+				exceptionAt(i, noSuchObject, oid)
+			}
+		}
+	case pduGetNextRequest:
+		for i, oid := range req.oids {
+			if v, ok := next(vars, oid); ok {
+				results = append(results, v)
+			} else {
+				exceptionAt(i, endOfMibView, oid)
+			}
+		}
+	case pduGetBulkRequest:
+		if v1 {
+			return nil, errors.New("GetBulk is not supported by SNMPv1")
+		}
+		nonRepeaters := int(min(max(req.nonRepeaters, 0), int64(len(req.oids))))
+		for _, oid := range req.oids[:nonRepeaters] {
+			if v, ok := next(vars, oid); ok {
+				results = append(results, v)
+			} else {
+				results = append(results, Variable{OID: oid, Value: endOfMibView})
+			}
+		}
+		repeaters := slices.Clone(req.oids[nonRepeaters:])
+		for range min(max(req.maxRepetitions, 0), maxRepetitions) {
+			if len(repeaters) == 0 {
+				break
+			}
+			for j, oid := range repeaters {
+				if v, ok := next(vars, oid); ok {
+					results = append(results, v)
+					repeaters[j] = v.OID
+				} else {
+					results = append(results, Variable{OID: oid, Value: endOfMibView})
+				}
+			}
+		}
+	case pduSetRequest:
+		errStatus, errIndex = errNotWritable, 1
+		if v1 {
+			errStatus = errReadOnly
+		}
+		for _, oid := range req.oids {
+			results = append(results, Variable{OID: oid})
+		}
+	default:
+		return nil, errors.New("unsupported PDU type")
+	}
+
+	res, err := encodeResponse(req, errStatus, errIndex, results)
+	if err != nil {
+		return encodeResponse(req, errGenErr, 0, nil)
+	}
+	for len(res) > maxMessageSize {
+		if req.pduType != pduGetBulkRequest || len(results) <= 1 {
+			return encodeResponse(req, errTooBig, 0, nil)
+		}
+		// GetBulk responses are truncated to fit.
+		results = results[:len(results)/2]
+		if res, err = encodeResponse(req, errStatus, errIndex, results); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// get returns the variable with the OID.
+func get(vars []Variable, oid OID) (Variable, bool) {
+	i, ok := slices.BinarySearchFunc(vars, oid, func(v Variable, oid OID) int {
+		return v.OID.Compare(oid)
+	})
+	if !ok {
+		return Variable{}, false
+	}
+	return vars[i], true
+}
+
+// next returns the first variable with an OID after oid.
+func next(vars []Variable, oid OID) (Variable, bool) {
+	i, ok := slices.BinarySearchFunc(vars, oid, func(v Variable, oid OID) int {
+		return v.OID.Compare(oid)
+	})
+	if ok {
+		i++
+	}
+	if i >= len(vars) {
+		return Variable{}, false
+	}
+	return vars[i], true
+}
+
+// decodeRequest decodes a request message.
+func decodeRequest(msg []byte) (*request, error) {
+	body, _, err := readExpected(msg, tagSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	var req request
+	if req.version, body, err = readInteger(body); err != nil {
+		return nil, err
+	}
+	if req.version != versionV1 && req.version != versionV2c {
+		return nil, errors.New("unsupported SNMP version")
+	}
+	if req.community, body, err = readExpected(body, tagOctetString); err != nil {
+		return nil, err
+	}
+
+	var pdu []byte
+	if req.pduType, pdu, _, err = readTLV(body); err != nil {
+		return nil, err
+	}
+	if req.requestID, pdu, err = readInteger(pdu); err != nil {
+		return nil, err
+	}
+	if req.nonRepeaters, pdu, err = readInteger(pdu); err != nil {
+		return nil, err
+	}
+	if req.maxRepetitions, pdu, err = readInteger(pdu); err != nil {
+		return nil, err
+	}
+
+	varbinds, _, err := readExpected(pdu, tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	for len(varbinds) > 0 {
+		var vb []byte
+		if vb, varbinds, err = readExpected(varbinds, tagSequence); err != nil {
+			return nil, err
+		}
+		value, _, err := readExpected(vb, tagOID)
+		if err != nil {
+			return nil, err
+		}
+		oid, err := parseOID(value)
+		if err != nil {
+			return nil, err
+		}
+		req.oids = append(req.oids, oid)
+	}
+	return &req, nil
+}
+
+// encodeResponse encodes a response message to a request.
+func encodeResponse(req *request, errStatus, errIndex int64, vars []Variable) ([]byte, error) {
+	var varbinds []byte
+	for _, v := range vars {
+		vb := appendOID(nil, v.OID)
+		vb, err := appendValue(vb, v.Value)
+		if err != nil {
+			return nil, err
+		}
+		varbinds = appendTLV(varbinds, tagSequence, vb)
+	}
+
+	pdu := appendInteger(nil, tagInteger, req.requestID)
+	pdu = appendInteger(pdu, tagInteger, errStatus)
+	pdu = appendInteger(pdu, tagInteger, errIndex)
+	pdu = appendTLV(pdu, tagSequence, varbinds)
+
+	msg := appendInteger(nil, tagInteger, req.version)
+	msg = appendTLV(msg, tagOctetString, req.community)
+	msg = appendTLV(msg, pduResponse, pdu)
+	return appendTLV(nil, tagSequence, msg), nil
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package snmp
+
+import (
+	"bytes"
+	"testing"
+)
+
+// encodeRequest encodes a request message.
+func encodeRequest(version int64, community string, pduType byte, a, b int64, oids ...OID) []byte {
+	var varbinds []byte
+	for _, oid := range oids {
+		vb := appendOID(nil, oid)
+		vb = appendTLV(vb, tagNull, nil)
+		varbinds = appendTLV(varbinds, tagSequence, vb)
+	}
+	pdu := appendInteger(nil, tagInteger, 42)
+	pdu = appendInteger(pdu, tagInteger, a)
+	pdu = appendInteger(pdu, tagInteger, b)
+	pdu = appendTLV(pdu, tagSequence, varbinds)
+
+	msg := appendInteger(nil, tagInteger, version)
+	msg = appendTLV(msg, tagOctetString, []byte(community))
+	msg = appendTLV(msg, pduType, pdu)
+	return appendTLV(nil, tagSequence, msg)
+}
+
+// response is a decoded response message.
+type response struct {
+	errStatus int64
+	errIndex  int64
+	varbinds  []responseVarbind
+}
+
+// responseVarbind is a decoded response varbind.
+type responseVarbind struct {
+	oid   OID
+	tag   byte
+	value []byte
+}
+
+func decodeResponse(t *testing.T, msg []byte) response {
+	t.Helper()
+	req, err := decodeRequest(msg)
+	if err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if req.pduType != pduResponse || req.requestID != 42 {
+		t.Fatalf("unexpected response PDU %#x, request ID %d", req.pduType, req.requestID)
+	}
+	res := response{errStatus: req.nonRepeaters, errIndex: req.maxRepetitions}
+
+	// Decode the varbind values, which decodeRequest ignores.
+	body, _, _ := readExpected(msg, tagSequence)
+	_, body, _ = readInteger(body)
+	_, body, _ = readExpected(body, tagOctetString)
+	_, pdu, _, _ := readTLV(body)
+	for range 3 {
+		_, pdu, _ = readInteger(pdu)
+	}
+	varbinds, _, _ := readExpected(pdu, tagSequence)
+	for i := 0; len(varbinds) > 0; i++ {
+		var vb []byte
+		vb, varbinds, _ = readExpected(varbinds, tagSequence)
+		_, vb, _ = readExpected(vb, tagOID)
+		tag, value, _, err := readTLV(vb)
+		if err != nil {
+			t.Fatalf("decode varbind: %v", err)
+		}
+		res.varbinds = append(res.varbinds, responseVarbind{oid: req.oids[i], tag: tag, value: value})
+	}
+	return res
+}
+
+func TestAgent(t *testing.T) {
+	root := OID{1, 3, 6, 1, 3, 9452}
+	a := &Agent{
+		Community: "public",
+		Variables: func() []Variable {
+			return []Variable{
+				{OID: root.Append(1, 0), Value: 2},
+				{OID: root.Append(2, 1, 2, 1), Value: "a"},
+				{OID: root.Append(2, 1, 2, 2), Value: "b"},
+				{OID: root.Append(2, 1, 3, 1), Value: Gauge32(1737673200)},
+			}
+		},
+	}
+
+	tts := []struct {
+		name      string
+		req       []byte
+		errStatus int64
+		want      []responseVarbind
+	}{
+		{
+			name: "get",
+			req:  encodeRequest(versionV2c, "public", pduGetRequest, 0, 0, root.Append(1, 0), root.Append(9, 0)),
+			want: []responseVarbind{
+				{oid: root.Append(1, 0), tag: tagInteger, value: []byte{2}},
+				{oid: root.Append(9, 0), tag: tagNoSuchObject},
+			},
+		},
+		{
+			name: "get next",
+			req:  encodeRequest(versionV2c, "public", pduGetNextRequest, 0, 0, root, root.Append(2, 1, 3, 1)),
+			want: []responseVarbind{
+				{oid: root.Append(1, 0), tag: tagInteger, value: []byte{2}},
+				{oid: root.Append(2, 1, 3, 1), tag: tagEndOfMibView},
+			},
+		},
+		{
+			name: "get bulk",
+			req:  encodeRequest(versionV2c, "public", pduGetBulkRequest, 0, 3, root.Append(1)),
+			want: []responseVarbind{
+				{oid: root.Append(1, 0), tag: tagInteger, value: []byte{2}},
+				{oid: root.Append(2, 1, 2, 1), tag: tagOctetString, value: []byte("a")},
+				{oid: root.Append(2, 1, 2, 2), tag: tagOctetString, value: []byte("b")},
+			},
+		},
+		{
+			name:      "v1 get missing",
+			req:       encodeRequest(versionV1, "public", pduGetRequest, 0, 0, root.Append(9, 0)),
+			errStatus: errNoSuchName,
+			want:      []responseVarbind{{oid: root.Append(9, 0), tag: tagNull}},
+		},
+		{
+			name:      "set",
+			req:       encodeRequest(versionV2c, "public", pduSetRequest, 0, 0, root.Append(1, 0)),
+			errStatus: errNotWritable,
+			want:      []responseVarbind{{oid: root.Append(1, 0), tag: tagNull}},
+		},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := a.handle(tt.req)
+			if err != nil {
+				t.Fatalf("handle: %v", err)
+			}
+			res := decodeResponse(t, msg)
+			if res.errStatus != tt.errStatus {
+				t.Errorf("error status = %d, want %d", res.errStatus, tt.errStatus)
+			}
+			if len(res.varbinds) != len(tt.want) {
+				t.Fatalf("got %d varbinds, want %d: %+v", len(res.varbinds), len(tt.want), res.varbinds)
+			}
+			for i, want := range tt.want {
+				got := res.varbinds[i]
+				if got.oid.Compare(want.oid) != 0 || got.tag != want.tag || !bytes.Equal(got.value, want.value) {
+					t.Errorf("varbind %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("wrong community", func(t *testing.T) {
+		msg, err := a.handle(encodeRequest(versionV2c, "private", pduGetRequest, 0, 0, root.Append(1, 0)))
+		if err != nil || msg != nil {
+			t.Errorf("got %x, %v, want no response", msg, err)
+		}
+	})
+}
+
+func TestEncoding(t *testing.T) {
+	for _, tt := range []struct {
+		v    int64
+		want []byte
+	}{
+		{0, []byte{0x02, 0x01, 0x00}},
+		{127, []byte{0x02, 0x01, 0x7f}},
+		{128, []byte{0x02, 0x02, 0x00, 0x80}},
+		{-1, []byte{0x02, 0x01, 0xff}},
+		{-129, []byte{0x02, 0x02, 0xff, 0x7f}},
+	} {
+		if got := appendInteger(nil, tagInteger, tt.v); !bytes.Equal(got, tt.want) {
+			t.Errorf("appendInteger(%d) = %x, want %x", tt.v, got, tt.want)
+		}
+		if got, _, err := readInteger(tt.want); err != nil || got != tt.v {
+			t.Errorf("readInteger(%x) = %d, %v, want %d", tt.want, got, err, tt.v)
+		}
+	}
+
+	if got, want := appendUnsigned(nil, tagGauge32, 0xffffffff), []byte{0x42, 0x05, 0x00, 0xff, 0xff, 0xff, 0xff}; !bytes.Equal(got, want) {
+		t.Errorf("appendUnsigned = %x, want %x", got, want)
+	}
+
+	oid, err := ParseOID("1.3.6.1.4.1.2680.1.2.7.3.2.0")
+	if err != nil {
+		t.Fatalf("parse OID: %v", err)
+	}
+	value, _, err := readExpected(appendOID(nil, oid), tagOID)
+	if err != nil {
+		t.Fatalf("read OID: %v", err)
+	}
+	if !bytes.Equal(value, []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x94, 0x78, 0x01, 0x02, 0x07, 0x03, 0x02, 0x00}) {
+		t.Errorf("encoded OID = %x", value)
+	}
+	if got, err := parseOID(value); err != nil || got.Compare(oid) != 0 {
+		t.Errorf("parseOID = %v, %v, want %v", got, err, oid)
+	}
+	for _, s := range []string{"", "1", "1.x", "3.1"} {
+		if _, err := ParseOID(s); err == nil {
+			t.Errorf("ParseOID(%q): expected error", s)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BER tags used by SNMP.
+const (
+	tagInteger        = 0x02
+	tagOctetString    = 0x04
+	tagNull           = 0x05
+	tagOID            = 0x06
+	tagSequence       = 0x30
+	tagGauge32        = 0x42
+	tagTimeTicks      = 0x43
+	tagCounter64      = 0x46
+	tagNoSuchObject   = 0x80
+	tagNoSuchInstance = 0x81
+	tagEndOfMibView   = 0x82
+)
+
+// OID is an object identifier.
+type OID []uint32
+
+// ParseOID parses a dotted object identifier, e.g. "1.3.6.1".
+func ParseOID(s string) (OID, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "."), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID %q", s)
+	}
+	oid := make(OID, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		oid = append(oid, uint32(n))
+	}
+	if oid[0] > 2 || (oid[0] < 2 && oid[1] >= 40) {
+		return nil, fmt.Errorf("invalid OID %q", s)
+	}
+	return oid, nil
+}
+
+// String returns the dotted form of the OID.
+func (o OID) String() string {
+	parts := make([]string, len(o))
+	for i, n := range o {
+		parts[i] = strconv.FormatUint(uint64(n), 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Append returns a new OID with the sub-identifiers appended.
+func (o OID) Append(sub ...uint32) OID {
+	oid := make(OID, 0, len(o)+len(sub))
+	return append(append(oid, o...), sub...)
+}
+
+// Compare compares two OIDs lexicographically, returning -1, 0 or 1.
+func (o OID) Compare(other OID) int {
+	for i := 0; i < len(o) && i < len(other); i++ {
+		switch {
+		case o[i] < other[i]:
+			return -1
+		case o[i] > other[i]:
+			return 1
+		}
+	}
+	switch {
+	case len(o) < len(other):
+		return -1
+	case len(o) > len(other):
+		return 1
+	}
+	return 0
+}
+
+// Gauge32 is an unsigned 32-bit gauge value.
+type Gauge32 uint32
+
+// TimeTicks is a time in hundredths of a second.
+type TimeTicks uint32
+
+// Counter64 is an unsigned 64-bit counter value.
+type Counter64 uint64
+
+// exception is a SNMPv2 varbind exception value.
+type exception byte
+
+// Varbind exceptions.
+const (
+	noSuchObject   exception = tagNoSuchObject
+	noSuchInstance exception = tagNoSuchInstance
+	endOfMibView   exception = tagEndOfMibView
+)
+
+// appendTLV appends a BER tag, length and value.
+func appendTLV(b []byte, tag byte, value []byte) []byte {
+	b = append(b, tag)
+	n := len(value)
+	switch {
+	case n < 0x80:
+		b = append(b, byte(n))
+	case n <= 0xff:
+		b = append(b, 0x81, byte(n))
+	case n <= 0xffff:
+		b = append(b, 0x82, byte(n>>8), byte(n))
+	default:
+		b = append(b, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(b, value...)
+}
+
+// appendInteger appends a BER integer.
+func appendInteger(b []byte, tag byte, v int64) []byte {
+	var buf [8]byte
+	n := 8
+	for i := 7; i >= 0; i-- {
+		buf[i] = byte(v)
+		v >>= 8
+	}
+	// Strip redundant leading bytes, keeping the sign bit.
+	start := 0
+	for start < n-1 {
+		if (buf[start] == 0x00 && buf[start+1]&0x80 == 0) ||
+			(buf[start] == 0xff && buf[start+1]&0x80 != 0) {
+			start++
+			continue
+		}
+		break
+	}
+	return appendTLV(b, tag, buf[start:])
+}
+
+// appendUnsigned appends a BER unsigned integer, such as a Gauge32.
+func appendUnsigned(b []byte, tag byte, v uint64) []byte {
+	var buf [9]byte
+	for i := 8; i >= 1; i-- {
+		buf[i] = byte(v)
+		v >>= 8
+	}
+	start := 0
+	for start < 8 && buf[start] == 0 && buf[start+1]&0x80 == 0 {
+		start++
+	}
+	return appendTLV(b, tag, buf[start:])
+}
+
+// appendOID appends a BER object identifier.
+func appendOID(b []byte, oid OID) []byte {
+	var v []byte
+	if len(oid) >= 2 {
+		v = appendBase128(v, oid[0]*40+oid[1])
+		for _, n := range oid[2:] {
+			v = appendBase128(v, n)
+		}
+	}
+	return appendTLV(b, tagOID, v)
+}
+
+// appendBase128 appends a base-128 encoded OID sub-identifier.
+func appendBase128(b []byte, n uint32) []byte {
+	var buf [5]byte
+	i := len(buf) - 1
+	buf[i] = byte(n & 0x7f)
+	for n >>= 7; n > 0; n >>= 7 {
+		i--
+		buf[i] = byte(n&0x7f) | 0x80
+	}
+	return append(b, buf[i:]...)
+}
+
+// appendValue appends a BER encoded varbind value.
+func appendValue(b []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return appendTLV(b, tagNull, nil), nil
+	case int:
+		return appendInteger(b, tagInteger, int64(v)), nil
+	case int64:
+		return appendInteger(b, tagInteger, v), nil
+	case string:
+		return appendTLV(b, tagOctetString, []byte(v)), nil
+	case []byte:
+		return appendTLV(b, tagOctetString, v), nil
+	case OID:
+		return appendOID(b, v), nil
+	case Gauge32:
+		return appendUnsigned(b, tagGauge32, uint64(v)), nil
+	case TimeTicks:
+		return appendUnsigned(b, tagTimeTicks, uint64(v)), nil
+	case Counter64:
+		return appendUnsigned(b, tagCounter64, uint64(v)), nil
+	case exception:
+		return appendTLV(b, byte(v), nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+// errMalformed is returned when a message cannot be decoded.
+var errMalformed = errors.New("malformed message")
+
+// readTLV reads a BER tag, length and value, and returns the value and the
+// remaining data.
+func readTLV(b []byte) (tag byte, value, rest []byte, err error) {
+	if len(b) < 2 {
+		return 0, nil, nil, errMalformed
+	}
+	tag = b[0]
+	n := int(b[1])
+	b = b[2:]
+	if n&0x80 != 0 {
+		size := n & 0x7f
+		if size == 0 || size > 3 || len(b) < size {
+			return 0, nil, nil, errMalformed
+		}
+		n = 0
+		for _, c := range b[:size] {
+			n = n<<8 | int(c)
+		}
+		b = b[size:]
+	}
+	if len(b) < n {
+		return 0, nil, nil, errMalformed
+	}
+	return tag, b[:n], b[n:], nil
+}
+
+// readExpected reads a TLV with the expected tag.
+func readExpected(b []byte, want byte) (value, rest []byte, err error) {
+	tag, value, rest, err := readTLV(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tag != want {
+		return nil, nil, errMalformed
+	}
+	return value, rest, nil
+}
+
+// readInteger reads a BER integer.
+func readInteger(b []byte) (int64, []byte, error) {
+	value, rest, err := readExpected(b, tagInteger)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(value) == 0 || len(value) > 8 {
+		return 0, nil, errMalformed
+	}
+	v := int64(int8(value[0]))
+	for _, c := range value[1:] {
+		v = v<<8 | int64(c)
+	}
+	return v, rest, nil
+}
+
+// parseOID decodes the value of a BER object identifier.
+func parseOID(b []byte) (OID, error) {
+	if len(b) == 0 {
+		return nil, errMalformed
+	}
+	var (
+		oid OID
+		n   uint32
+	)
+	for i, c := range b {
+		if n > 1<<25 {
+			return nil, errMalformed
+		}
+		n = n<<7 | uint32(c&0x7f)
+		if c&0x80 != 0 {
+			if i == len(b)-1 {
+				return nil, errMalformed
+			}
+			continue
+		}
+		if len(oid) == 0 {
+			first := min(n/40, 2)
+			oid = append(oid, first, n-first*40)
+		} else {
+			oid = append(oid, n)
+		}
+		n = 0
+	}
+	return oid, nil
+}
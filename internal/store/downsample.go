@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DownsampleRule replaces observations older than After with aggregated
+// observations at the given Resolution.
+type DownsampleRule struct {
+	// After is the age after which observations are downsampled.
+	After time.Duration
+
+	// Resolution is the time interval that observations are aggregated
+	// into.
+	Resolution time.Duration
+}
+
+// Downsample aggregates observations older than the rule's age into one
+// observation per station for each resolution interval. Observations that
+// already have the same or a coarser resolution are not modified. It returns
+// the number of observations that were replaced.
+//
+// Aggregated observations use the average of most values, the maximum wind
+// gust, rain totals and sunshine duration, and the circular mean of the wind
+// direction. Missing (NULL) values are skipped, so a field is only missing
+// from an aggregated observation if it is missing from all of the aggregated
+// observations. Fields that are not averaged (e.g. the sensor channels) are
+// not kept.
+func (s *Store) Downsample(ctx context.Context, now time.Time, rule DownsampleRule) (int64, error) {
+	res := rule.Resolution.Milliseconds()
+	if res <= 0 {
+		return 0, fmt.Errorf("invalid resolution %s", rule.Resolution)
+	}
+	// Align the cutoff to the resolution so that intervals are never split.
+	cutoff := now.Add(-rule.After).UnixMilli() / res * res
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO observations (
+		station_id, time, received_at, realtime, realtime_frequency,
+		wind_direction, wind_speed, wind_gust, humidity, dew_point,
+		temperature, rain_past_hour, rain_today, barometric,
+		indoor_temperature, indoor_humidity, solar_radiation,
+		supply_voltage, battery_voltage, capacitor_voltage, solar_voltage,
+		storm_rain, absolute_barometric, snow_depth, sunshine_today, resolution
+	) SELECT
+		station_id, time / ?1 * ?1 AS bucket, MAX(received_at), FALSE, 0,
+		mod(degrees(atan2(AVG(sin(radians(wind_direction))), AVG(cos(radians(wind_direction))))) + 360, 360),
+		AVG(wind_speed), MAX(wind_gust), AVG(humidity), AVG(dew_point),
+		AVG(temperature), MAX(rain_past_hour), MAX(rain_today), AVG(barometric),
+		AVG(indoor_temperature), AVG(indoor_humidity), AVG(solar_radiation),
+		AVG(supply_voltage), AVG(battery_voltage), AVG(capacitor_voltage), AVG(solar_voltage),
+		MAX(storm_rain), AVG(absolute_barometric), AVG(snow_depth), MAX(sunshine_today), ?1
+	FROM observations
+	WHERE time < ?2 AND resolution < ?1
+	GROUP BY station_id, bucket`, res, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("aggregate observations: %w", err)
+	}
+
+	r, err := tx.ExecContext(ctx,
+		`DELETE FROM observations WHERE time < ?2 AND resolution < ?1`, res, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("delete downsampled observations: %w", err)
+	}
+	n, err := r.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrations are the schema migrations applied to the database, in order.
+// The index of the last applied migration is stored in the SQLite user_version
+// pragma. Migrations must never be modified once released, only appended.
+var migrations = []string{
+	// 1: Initial observations table.
+	`CREATE TABLE observations (
+		id                 INTEGER PRIMARY KEY,
+		station_id         TEXT    NOT NULL,
+		time               INTEGER NOT NULL,
+		received_at        INTEGER NOT NULL,
+		realtime           BOOLEAN NOT NULL,
+		realtime_frequency REAL    NOT NULL,
+		wind_direction     REAL    NOT NULL,
+		wind_speed         REAL    NOT NULL,
+		wind_gust          REAL    NOT NULL,
+		humidity           REAL    NOT NULL,
+		dew_point          REAL    NOT NULL,
+		temperature        REAL    NOT NULL,
+		rain_past_hour     REAL    NOT NULL,
+		rain_today         REAL    NOT NULL,
+		barometric         REAL    NOT NULL,
+		indoor_temperature REAL    NOT NULL,
+		indoor_humidity    REAL    NOT NULL
+	);
+	CREATE INDEX observations_station_time ON observations (station_id, time);`,
+
+	// 2: Resolution of downsampled observations, in milliseconds (0 is raw).
+	`ALTER TABLE observations ADD COLUMN resolution INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX observations_time_resolution ON observations (time, resolution);`,
+
+	// 3: Fields added to measurements after the initial table. Fields that
+	// are nil if not submitted are nullable, the channels are a JSON array,
+	// and missing is a JSON array of the query parameters of missing fields.
+	`ALTER TABLE observations ADD COLUMN solar_radiation REAL NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN missing TEXT;
+	ALTER TABLE observations ADD COLUMN heater_on BOOLEAN;
+	ALTER TABLE observations ADD COLUMN supply_voltage REAL;
+	ALTER TABLE observations ADD COLUMN battery_voltage REAL;
+	ALTER TABLE observations ADD COLUMN capacitor_voltage REAL;
+	ALTER TABLE observations ADD COLUMN solar_voltage REAL;
+	ALTER TABLE observations ADD COLUMN storm_rain REAL;
+	ALTER TABLE observations ADD COLUMN storm_start INTEGER;
+	ALTER TABLE observations ADD COLUMN absolute_barometric REAL;
+	ALTER TABLE observations ADD COLUMN channels TEXT;
+	ALTER TABLE observations ADD COLUMN snow_depth REAL;
+	ALTER TABLE observations ADD COLUMN sunshine_today REAL;`,
+
+	// 4: Nullable measurement columns, which are NULL for fields that the
+	// station did not measure, replacing the missing column so that
+	// aggregates skip missing values. SQLite cannot drop NOT NULL
+	// constraints, so the table is rebuilt.
+	`CREATE TABLE observations_new (
+		id                  INTEGER PRIMARY KEY,
+		station_id          TEXT    NOT NULL,
+		time                INTEGER NOT NULL,
+		received_at         INTEGER NOT NULL,
+		realtime            BOOLEAN NOT NULL,
+		realtime_frequency  REAL    NOT NULL,
+		wind_direction      REAL,
+		wind_speed          REAL,
+		wind_gust           REAL,
+		humidity            REAL,
+		dew_point           REAL,
+		temperature         REAL,
+		rain_past_hour      REAL,
+		rain_today          REAL,
+		barometric          REAL,
+		indoor_temperature  REAL,
+		indoor_humidity     REAL,
+		solar_radiation     REAL,
+		resolution          INTEGER NOT NULL DEFAULT 0,
+		heater_on           BOOLEAN,
+		supply_voltage      REAL,
+		battery_voltage     REAL,
+		capacitor_voltage   REAL,
+		solar_voltage       REAL,
+		storm_rain          REAL,
+		storm_start         INTEGER,
+		absolute_barometric REAL,
+		channels            TEXT,
+		snow_depth          REAL,
+		sunshine_today      REAL
+	);
+	INSERT INTO observations_new SELECT
+		id, station_id, time, received_at, realtime, realtime_frequency,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'winddir') THEN NULL ELSE wind_direction END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'windspeedmph') THEN NULL ELSE wind_speed END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'windgustmph') THEN NULL ELSE wind_gust END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'humidity') THEN NULL ELSE humidity END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'dewptf') THEN NULL ELSE dew_point END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'tempf') THEN NULL ELSE temperature END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'rainin') THEN NULL ELSE rain_past_hour END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'dailyrainin') THEN NULL ELSE rain_today END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'baromin') THEN NULL ELSE barometric END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'indoortempf') THEN NULL ELSE indoor_temperature END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'indoorhumidity') THEN NULL ELSE indoor_humidity END,
+		CASE WHEN EXISTS (SELECT 1 FROM json_each(missing) WHERE value = 'solarradiation') THEN NULL ELSE solar_radiation END,
+		resolution, heater_on, supply_voltage, battery_voltage,
+		capacitor_voltage, solar_voltage, storm_rain, storm_start,
+		absolute_barometric, channels, snow_depth, sunshine_today
+	FROM observations;
+	DROP TABLE observations;
+	ALTER TABLE observations_new RENAME TO observations;
+	CREATE INDEX observations_station_time ON observations (station_id, time);
+	CREATE INDEX observations_time_resolution ON observations (time, resolution);`,
+}
+
+// migrate applies any pending schema migrations to the database.
+func migrate(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d",
+			version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		// PRAGMA statements do not support placeholders.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migration %d: update schema version: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// fields maps observation field names to database columns.
+var fields = map[string]string{
+	"absolute_barometric_pressure": "absolute_barometric",
+	"barometric_pressure":          "barometric",
+	"dew_point":                    "dew_point",
+	"humidity":                     "humidity",
+	"indoor_humidity":              "indoor_humidity",
+	"indoor_temperature":           "indoor_temperature",
+	"rain_past_hour":               "rain_past_hour",
+	"rain_today":                   "rain_today",
+	"snow_depth":                   "snow_depth",
+	"solar_radiation":              "solar_radiation",
+	"temperature":                  "temperature",
+	"wind_direction":               "wind_direction",
+	"wind_gust_speed":              "wind_gust",
+	"wind_speed":                   "wind_speed",
+}
+
+// Fields returns the names of the observation fields that can be aggregated.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Stations returns the IDs of all stations with stored observations, in
+// order.
+func (s *Store) Stations(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT DISTINCT station_id FROM observations ORDER BY station_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Point is an aggregated value of an observation field over a time interval.
+type Point struct {
+	Time  time.Time `json:"time"`
+	Avg   float64   `json:"avg"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Count int       `json:"count"`
+}
+
+// Aggregate returns the values of an observation field for a station,
+// aggregated into intervals of the given step, in the time range [from, to).
+// The wind direction average is the circular mean. Observations that did not
+// measure the field are not counted.
+//
+// Steps that are a whole number of days are aligned to midnight in loc, so
+// that daily values follow the station's local day. If loc is nil, intervals
+// are aligned to the Unix epoch (UTC midnight for daily steps).
+func (s *Store) Aggregate(ctx context.Context, stationID, field string, from, to time.Time, step time.Duration, loc *time.Location) ([]Point, error) {
+	column, ok := fields[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	stepMS := step.Milliseconds()
+	if stepMS <= 0 {
+		return nil, fmt.Errorf("invalid step %s", step)
+	}
+	if loc != nil && loc != time.UTC && step%day == 0 {
+		return s.aggregateDays(ctx, stationID, column, from, to, int64(step/day), loc)
+	}
+
+	avg := "AVG(" + column + ")"
+	if column == "wind_direction" {
+		avg = "mod(degrees(atan2(AVG(sin(radians(wind_direction))), AVG(cos(radians(wind_direction))))) + 360, 360)"
+	}
+
+	// The column name is from the fields map and safe to use in the query.
+	rows, err := s.db.QueryContext(ctx, `SELECT time / ?1 * ?1 AS bucket, `+avg+`,
+		MIN(`+column+`), MAX(`+column+`), COUNT(*)
+	FROM observations
+	WHERE station_id = ?2 AND time >= ?3 AND time < ?4 AND `+column+` IS NOT NULL
+	GROUP BY bucket ORDER BY bucket`, //nolint:gosec
+		stepMS, stationID, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []Point
+	for rows.Next() {
+		var (
+			p      Point
+			bucket int64
+		)
+		if err := rows.Scan(&bucket, &p.Avg, &p.Min, &p.Max, &p.Count); err != nil {
+			return nil, err
+		}
+		p.Time = unixMilli(bucket)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+const (
+	// day is the length of a day, ignoring daylight saving time changes.
+	day = 24 * time.Hour
+
+	// zoneStep is the interval observations are first aggregated into when
+	// aligning to local days. All time zone offsets are a multiple of it.
+	zoneStep = 15 * time.Minute
+)
+
+// aggregateDays aggregates the values of a column into intervals of the
+// given number of local days in loc. Values are first aggregated into
+// 15-minute intervals in the database, which are then merged into the local
+// days they start in, so that daylight saving time changes are respected.
+func (s *Store) aggregateDays(ctx context.Context, stationID, column string, from, to time.Time, days int64, loc *time.Location) ([]Point, error) {
+	sum := "SUM(" + column + "), 0"
+	if column == "wind_direction" {
+		sum = "SUM(sin(radians(wind_direction))), SUM(cos(radians(wind_direction)))"
+	}
+
+	// The column name is from the fields map and safe to use in the query.
+	rows, err := s.db.QueryContext(ctx, `SELECT time / ?1 * ?1 AS bucket, `+sum+`,
+		MIN(`+column+`), MAX(`+column+`), COUNT(*)
+	FROM observations
+	WHERE station_id = ?2 AND time >= ?3 AND time < ?4 AND `+column+` IS NOT NULL
+	GROUP BY bucket ORDER BY bucket`, //nolint:gosec
+		zoneStep.Milliseconds(), stationID, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// sums are the sums of the values of each point, or of the sines and
+	// cosines of wind directions.
+	var (
+		points []Point
+		sums   [][2]float64
+	)
+	for rows.Next() {
+		var (
+			bucket     int64
+			sum        [2]float64
+			minV, maxV float64
+			count      int
+		)
+		if err := rows.Scan(&bucket, &sum[0], &sum[1], &minV, &maxV, &count); err != nil {
+			return nil, err
+		}
+
+		t := dayBucket(unixMilli(bucket), days, loc)
+		if len(points) == 0 || !points[len(points)-1].Time.Equal(t) {
+			points = append(points, Point{Time: t, Min: minV, Max: maxV})
+			sums = append(sums, [2]float64{})
+		}
+		i := len(points) - 1
+		points[i].Min = min(points[i].Min, minV)
+		points[i].Max = max(points[i].Max, maxV)
+		points[i].Count += count
+		sums[i][0] += sum[0]
+		sums[i][1] += sum[1]
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range points {
+		if column == "wind_direction" {
+			points[i].Avg = math.Mod(math.Atan2(sums[i][0], sums[i][1])*180/math.Pi+360, 360)
+		} else {
+			points[i].Avg = sums[i][0] / float64(points[i].Count)
+		}
+	}
+	return points, nil
+}
+
+// dayBucket returns local midnight in loc of the first day of the interval of
+// the given number of days that t belongs to. Intervals are aligned to the
+// Unix epoch date.
+func dayBucket(t time.Time, days int64, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	n := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / int64(day/time.Second)
+	n -= ((n % days) + days) % days
+	y, m, d = time.Unix(n*int64(day/time.Second), 0).UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// Gap is a period without observations from a station.
+type Gap struct {
+	// Start is the time of the last observation before the gap, and End is
+	// the time of the first observation after the gap.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Gaps returns the gaps between consecutive observations from a station that
+// are longer than maxInterval, in the time range [from, to).
+func (s *Store) Gaps(ctx context.Context, stationID string, from, to time.Time, maxInterval time.Duration) ([]Gap, error) {
+	if maxInterval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s", maxInterval)
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT prev, time FROM (
+		SELECT time, LAG(time) OVER (ORDER BY time) AS prev
+		FROM observations
+		WHERE station_id = ?1 AND time >= ?2 AND time < ?3
+	)
+	WHERE time - prev > ?4
+	ORDER BY time`,
+		stationID, from.UnixMilli(), to.UnixMilli(), maxInterval.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gaps []Gap
+	for rows.Next() {
+		var start, end int64
+		if err := rows.Scan(&start, &end); err != nil {
+			return nil, err
+		}
+		gaps = append(gaps, Gap{Start: unixMilli(start), End: unixMilli(end)})
+	}
+	return gaps, rows.Err()
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package store implements persistent storage of weather station observations
+// using an embedded SQLite database.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // SQLite driver
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// compactInterval is the interval at which observations are downsampled and
+// observations older than the retention period are deleted.
+const compactInterval = time.Hour
+
+// Store is a SQLite observation store.
+type Store struct {
+	db         *sql.DB
+	retention  time.Duration
+	downsample []DownsampleRule
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Options are the store options.
+type Options struct {
+	// Retention is the duration that observations are kept for. Observations
+	// older than this are periodically deleted. If zero, observations are
+	// kept forever.
+	Retention time.Duration
+
+	// Downsample are the rules used to downsample old observations.
+	Downsample []DownsampleRule
+}
+
+// Open opens the SQLite database at the given path, creating it if it does not
+// exist, and applies any pending schema migrations.
+func Open(path string, opts Options) (*Store, error) {
+	// The connection pool is not limited to a single connection, so that
+	// long reads (e.g. a CSV export to a slow client) do not block inserts,
+	// which WAL mode allows. SQLite still only supports a single writer, so
+	// concurrent writers wait for the busy timeout. Transactions begin
+	// immediately, taking the write lock up front, as a deferred transaction
+	// that is upgraded to a write fails without waiting.
+	db, err := sql.Open("sqlite", "file:"+path+
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	if err := migrate(context.Background(), db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("migrate database: %w", err)
+	}
+
+	s := &Store{
+		db:         db,
+		retention:  opts.Retention,
+		downsample: opts.Downsample,
+		quit:       make(chan struct{}),
+	}
+	if s.retention > 0 || len(s.downsample) > 0 {
+		s.wg.Add(1)
+		go s.compactLoop()
+	}
+	return s, nil
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	return s.db.Close()
+}
+
+// measuredColumns are the nullable columns of the weather data fields, in
+// the order they are inserted and selected, and the WU query parameters of
+// the fields. A column is NULL if the field is missing from the measurement.
+var measuredColumns = []struct {
+	param string
+	field func(dm *wu.DeviceMeasurement) *float64
+}{
+	{"winddir", func(dm *wu.DeviceMeasurement) *float64 { return &dm.WindDirection }},
+	{"windspeedmph", func(dm *wu.DeviceMeasurement) *float64 { return &dm.WindSpeed }},
+	{"windgustmph", func(dm *wu.DeviceMeasurement) *float64 { return &dm.WindGust }},
+	{"humidity", func(dm *wu.DeviceMeasurement) *float64 { return &dm.Humidity }},
+	{"dewptf", func(dm *wu.DeviceMeasurement) *float64 { return &dm.DewPoint }},
+	{"tempf", func(dm *wu.DeviceMeasurement) *float64 { return &dm.Temperature }},
+	{"rainin", func(dm *wu.DeviceMeasurement) *float64 { return &dm.RainPastHour }},
+	{"dailyrainin", func(dm *wu.DeviceMeasurement) *float64 { return &dm.RainToday }},
+	{"baromin", func(dm *wu.DeviceMeasurement) *float64 { return &dm.Barometric }},
+	{"indoortempf", func(dm *wu.DeviceMeasurement) *float64 { return &dm.IndoorTemp }},
+	{"indoorhumidity", func(dm *wu.DeviceMeasurement) *float64 { return &dm.IndoorHumidity }},
+	{"solarradiation", func(dm *wu.DeviceMeasurement) *float64 { return &dm.SolarRadiation }},
+}
+
+// Insert stores an observation.
+func (s *Store) Insert(ctx context.Context, o weather.Observation) error {
+	dm := o.Measurement
+	channels, err := jsonArray(dm.Channels)
+	if err != nil {
+		return err
+	}
+	var stormStart *int64
+	if dm.StormStart != nil {
+		ms := dm.StormStart.UnixMilli()
+		stormStart = &ms
+	}
+
+	args := []any{
+		o.StationID, dm.DateUTC.UnixMilli(), o.ReceivedAt.UnixMilli(),
+		dm.RealTime, dm.RealTimeFreq,
+	}
+	for _, c := range measuredColumns {
+		var v *float64
+		if dm.Has(c.param) {
+			v = c.field(&dm)
+		}
+		args = append(args, v)
+	}
+	args = append(args, dm.HeaterOn, dm.SupplyVoltage, dm.BatteryVoltage,
+		dm.CapacitorVoltage, dm.SolarVoltage, dm.StormRain, stormStart,
+		dm.AbsBarometric, channels, dm.SnowDepth, dm.SunshineToday)
+
+	_, err = s.db.ExecContext(ctx, `INSERT INTO observations (
+		station_id, time, received_at, realtime, realtime_frequency,
+		wind_direction, wind_speed, wind_gust, humidity, dew_point,
+		temperature, rain_past_hour, rain_today, barometric,
+		indoor_temperature, indoor_humidity, solar_radiation,
+		heater_on, supply_voltage, battery_voltage, capacitor_voltage,
+		solar_voltage, storm_rain, storm_start, absolute_barometric,
+		channels, snow_depth, sunshine_today
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		args...)
+	return err
+}
+
+// jsonArray returns the JSON encoding of a slice, or nil (NULL) if it is
+// empty.
+func jsonArray[T any](v []T) (*string, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := string(b)
+	return &s, nil
+}
+
+// Query is a query for stored observations.
+type Query struct {
+	// StationID is the ID of the station to return observations for. If
+	// empty, observations for all stations are returned.
+	StationID string
+
+	// From and To limit the returned observations to those with an
+	// observation time in the range [From, To). Zero values are unbounded.
+	From time.Time
+	To   time.Time
+}
+
+// ParseTime parses an optional RFC 3339 time, used for the From and To bounds
+// of a query. An empty string is the zero time, which is unbounded.
+func ParseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// Match reports whether the observation matches the query.
+func (q Query) Match(o weather.Observation) bool {
+	t := o.Measurement.DateUTC
+	return (q.StationID == "" || o.StationID == q.StationID) &&
+		(q.From.IsZero() || !t.Before(q.From)) &&
+		(q.To.IsZero() || t.Before(q.To))
+}
+
+// Observations calls fn for each stored observation matching the query, in
+// order of observation time. If fn returns an error, iteration stops and the
+// error is returned.
+func (s *Store) Observations(ctx context.Context, q Query, fn func(weather.Observation) error) error {
+	query := `SELECT station_id, time, received_at, realtime, realtime_frequency,
+		wind_direction, wind_speed, wind_gust, humidity, dew_point,
+		temperature, rain_past_hour, rain_today, barometric,
+		indoor_temperature, indoor_humidity, solar_radiation,
+		heater_on, supply_voltage, battery_voltage, capacitor_voltage,
+		solar_voltage, storm_rain, storm_start, absolute_barometric,
+		channels, snow_depth, sunshine_today
+	FROM observations WHERE 1 = 1`
+	var args []any
+	if q.StationID != "" {
+		query += " AND station_id = ?"
+		args = append(args, q.StationID)
+	}
+	if !q.From.IsZero() {
+		query += " AND time >= ?"
+		args = append(args, q.From.UnixMilli())
+	}
+	if !q.To.IsZero() {
+		query += " AND time < ?"
+		args = append(args, q.To.UnixMilli())
+	}
+	query += " ORDER BY time, id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			o                   weather.Observation
+			dm                  = &o.Measurement
+			obsTime, receivedAt int64
+			measured            = make([]sql.NullFloat64, len(measuredColumns))
+			channels            sql.NullString
+			stormStart          sql.NullInt64
+		)
+		dest := []any{&o.StationID, &obsTime, &receivedAt, &dm.RealTime, &dm.RealTimeFreq}
+		for i := range measured {
+			dest = append(dest, &measured[i])
+		}
+		dest = append(dest, &dm.HeaterOn, &dm.SupplyVoltage, &dm.BatteryVoltage,
+			&dm.CapacitorVoltage, &dm.SolarVoltage, &dm.StormRain, &stormStart,
+			&dm.AbsBarometric, &channels, &dm.SnowDepth, &dm.SunshineToday)
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, c := range measuredColumns {
+			if measured[i].Valid {
+				*c.field(dm) = measured[i].Float64
+			} else {
+				dm.Missing = append(dm.Missing, c.param)
+			}
+		}
+		dm.DateUTC = unixMilli(obsTime)
+		o.ReceivedAt = unixMilli(receivedAt)
+		if stormStart.Valid {
+			t := unixMilli(stormStart.Int64)
+			dm.StormStart = &t
+		}
+		if channels.Valid {
+			if err := json.Unmarshal([]byte(channels.String), &dm.Channels); err != nil {
+				return fmt.Errorf("decode channels: %w", err)
+			}
+		}
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Prune deletes observations older than the given time, and returns the
+// number of deleted observations.
+func (s *Store) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM observations WHERE time < ?`, before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteStation deletes all observations of a station, and returns the
+// number of deleted observations.
+func (s *Store) DeleteStation(ctx context.Context, stationID string) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM observations WHERE station_id = ?`, stationID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// compactLoop periodically downsamples observations and deletes observations
+// older than the retention period.
+func (s *Store) compactLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(compactInterval)
+	defer ticker.Stop()
+	for {
+		s.compact(context.Background(), time.Now())
+
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// compact applies the downsampling rules and retention period.
+func (s *Store) compact(ctx context.Context, now time.Time) {
+	for _, rule := range s.downsample {
+		n, err := s.Downsample(ctx, now, rule)
+		if err != nil {
+			slog.Error("Failed to downsample observations",
+				slog.Duration("after", rule.After),
+				slog.Duration("resolution", rule.Resolution),
+				slog.Any("err", err))
+			continue
+		}
+		if n > 0 {
+			slog.Debug("Downsampled observations",
+				slog.Duration("resolution", rule.Resolution),
+				slog.Int64("count", n))
+		}
+	}
+
+	if s.retention > 0 {
+		n, err := s.Prune(ctx, now.Add(-s.retention))
+		if err != nil {
+			slog.Error("Failed to prune observations", slog.Any("err", err))
+		} else if n > 0 {
+			slog.Debug("Pruned observations", slog.Int64("count", n))
+		}
+	}
+}
+
+// unixMilli returns the UTC time for the given Unix time in milliseconds.
+func unixMilli(ms int64) time.Time {
+	return time.UnixMilli(ms).UTC()
+}
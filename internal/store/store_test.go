@@ -0,0 +1,504 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	for i := range 3 {
+		err := s.Insert(ctx, weather.Observation{
+			StationID:  "test",
+			ReceivedAt: now,
+			Measurement: wu.DeviceMeasurement{
+				DateUTC:     now.Add(-time.Duration(i) * time.Hour),
+				Temperature: 20,
+			},
+		})
+		if err != nil {
+			t.Fatalf("insert observation: %v", err)
+		}
+	}
+
+	var got []weather.Observation
+	err = s.Observations(ctx, Query{
+		StationID: "test",
+		From:      now.Add(-90 * time.Minute),
+	}, func(o weather.Observation) error {
+		got = append(got, o)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("query observations: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d observations, want 2", len(got))
+	}
+	if !got[0].Measurement.DateUTC.Before(got[1].Measurement.DateUTC) {
+		t.Errorf("observations are not ordered by time")
+	}
+	if got[0].Measurement.Temperature != 20 {
+		t.Errorf("temperature got %f, want %f", got[0].Measurement.Temperature, 20.0)
+	}
+
+	n, err := s.Prune(ctx, now.Add(-90*time.Minute))
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("pruned %d observations, want 1", n)
+	}
+
+	n, err = s.DeleteStation(ctx, "test")
+	if err != nil {
+		t.Fatalf("delete station: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("deleted %d observations, want 2", n)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close store: %v", err)
+	}
+
+	// Re-opening an existing database must not re-apply migrations.
+	s, err = Open(path, Options{})
+	if err != nil {
+		t.Fatalf("re-open store: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close store: %v", err)
+	}
+}
+
+func TestStoreFields(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"), Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	heaterOn, snowDepth, pressure := true, 120.0, 1005.5
+	want := weather.Observation{
+		StationID:  "test",
+		ReceivedAt: now,
+		Measurement: wu.DeviceMeasurement{
+			DateUTC:        now,
+			Temperature:    20,
+			SolarRadiation: 450,
+			Missing:        []string{"humidity", "dewptf"},
+			HeaterOn:       &heaterOn,
+			StormStart:     &now,
+			AbsBarometric:  &pressure,
+			Channels:       []wu.ChannelMeasurement{{Channel: 2, Humidity: &pressure}},
+			SnowDepth:      &snowDepth,
+		},
+	}
+	if err := s.Insert(ctx, want); err != nil {
+		t.Fatalf("insert observation: %v", err)
+	}
+
+	var got []weather.Observation
+	err = s.Observations(ctx, Query{}, func(o weather.Observation) error {
+		got = append(got, o)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("query observations: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d observations, want 1", len(got))
+	}
+	if !reflect.DeepEqual(got[0], want) {
+		t.Errorf("got observation %+v, want %+v", got[0], want)
+	}
+
+	points, err := s.Aggregate(ctx, "test", "snow_depth", now.Add(-time.Hour), now.Add(time.Hour), time.Hour, nil)
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	if len(points) != 1 || points[0].Avg != snowDepth {
+		t.Errorf("got snow depth points %+v, want %v", points, snowDepth)
+	}
+}
+
+func TestStoreConcurrentInsert(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"), Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	insert := func(i int) error {
+		return s.Insert(ctx, weather.Observation{
+			StationID:   "test",
+			ReceivedAt:  now,
+			Measurement: wu.DeviceMeasurement{DateUTC: now.Add(time.Duration(i) * time.Second)},
+		})
+	}
+	if err := insert(0); err != nil {
+		t.Fatalf("insert observation: %v", err)
+	}
+
+	// Inserts must not fail or wait for a read in progress, and concurrent
+	// writers must wait for each other instead of failing.
+	const writers, inserts = 8, 50
+	err = s.Observations(ctx, Query{}, func(weather.Observation) error {
+		var (
+			wg   sync.WaitGroup
+			errc = make(chan error, writers)
+		)
+		for w := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range inserts {
+					if err := insert(1 + w*inserts + i); err != nil {
+						errc <- err
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errc)
+		return <-errc
+	})
+	if err != nil {
+		t.Fatalf("concurrent insert: %v", err)
+	}
+
+	var n int
+	err = s.Observations(ctx, Query{}, func(weather.Observation) error {
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("query observations: %v", err)
+	}
+	if want := 1 + writers*inserts; n != want {
+		t.Errorf("got %d observations, want %d", n, want)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"), Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	now := time.Date(2025, 1, 23, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-48 * time.Hour)
+	// A field that is missing from some observations is aggregated from the
+	// others, and a field that is missing from all stays missing.
+	for i, v := range []struct {
+		temp, gust, dir float64
+		missing         []string
+	}{
+		{temp: 10, gust: 5, dir: 350, missing: []string{"tempf", "solarradiation"}},
+		{temp: 20, gust: 15, dir: 10, missing: []string{"solarradiation"}},
+	} {
+		err := s.Insert(ctx, weather.Observation{
+			StationID:  "test",
+			ReceivedAt: old,
+			Measurement: wu.DeviceMeasurement{
+				DateUTC:       old.Add(time.Duration(i) * time.Minute),
+				Temperature:   v.temp,
+				WindGust:      v.gust,
+				WindDirection: v.dir,
+				Missing:       v.missing,
+			},
+		})
+		if err != nil {
+			t.Fatalf("insert observation: %v", err)
+		}
+	}
+	// Recent observations must not be downsampled.
+	err = s.Insert(ctx, weather.Observation{
+		StationID:   "test",
+		ReceivedAt:  now,
+		Measurement: wu.DeviceMeasurement{DateUTC: now, Temperature: 30},
+	})
+	if err != nil {
+		t.Fatalf("insert observation: %v", err)
+	}
+
+	rule := DownsampleRule{After: 24 * time.Hour, Resolution: 5 * time.Minute}
+	n, err := s.Downsample(ctx, now, rule)
+	if err != nil {
+		t.Fatalf("downsample: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("downsampled %d observations, want 2", n)
+	}
+
+	var got []weather.Observation
+	err = s.Observations(ctx, Query{}, func(o weather.Observation) error {
+		got = append(got, o)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("query observations: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d observations, want 2", len(got))
+	}
+	dm := got[0].Measurement
+	if dm.Temperature != 20 {
+		t.Errorf("temperature got %f, want %f", dm.Temperature, 20.0)
+	}
+	if !slices.Equal(dm.Missing, []string{"solarradiation"}) {
+		t.Errorf("missing fields got %v, want [solarradiation]", dm.Missing)
+	}
+	if dm.WindGust != 15 {
+		t.Errorf("wind gust got %f, want %f", dm.WindGust, 15.0)
+	}
+	if dm.WindDirection > 0.01 && dm.WindDirection < 359.99 {
+		t.Errorf("wind direction got %f, want 0", dm.WindDirection)
+	}
+
+	// Downsampling again must not modify the aggregated observations.
+	if n, err = s.Downsample(ctx, now, rule); err != nil || n != 0 {
+		t.Errorf("second downsample got (%d, %v), want (0, nil)", n, err)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"), Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	start := time.Date(2025, 1, 23, 12, 0, 0, 0, time.UTC)
+	for i, temp := range []float64{10, 20, 30} {
+		ts := start.Add(time.Duration(i) * 5 * time.Minute)
+		err := s.Insert(ctx, weather.Observation{
+			StationID:   "test",
+			ReceivedAt:  ts,
+			Measurement: wu.DeviceMeasurement{DateUTC: ts, Temperature: temp},
+		})
+		if err != nil {
+			t.Fatalf("insert observation: %v", err)
+		}
+	}
+	// An observation without a temperature is not aggregated as 0.
+	err = s.Insert(ctx, weather.Observation{
+		StationID:   "test",
+		ReceivedAt:  start,
+		Measurement: wu.DeviceMeasurement{DateUTC: start.Add(time.Minute), Missing: []string{"tempf"}},
+	})
+	if err != nil {
+		t.Fatalf("insert observation: %v", err)
+	}
+
+	stations, err := s.Stations(ctx)
+	if err != nil {
+		t.Fatalf("stations: %v", err)
+	}
+	if len(stations) != 1 || stations[0] != "test" {
+		t.Errorf("got stations %v, want [test]", stations)
+	}
+
+	points, err := s.Aggregate(ctx, "test", "temperature", start, start.Add(time.Hour), 10*time.Minute, nil)
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("got %d points, want 2", len(points))
+	}
+	if p := points[0]; !p.Time.Equal(start) || p.Avg != 15 || p.Min != 10 || p.Max != 20 || p.Count != 2 {
+		t.Errorf("unexpected first point: %+v", p)
+	}
+
+	if _, err := s.Aggregate(ctx, "test", "unknown", start, start.Add(time.Hour), time.Minute, nil); err == nil {
+		t.Error("aggregate of unknown field should fail")
+	}
+}
+
+func TestAggregateDays(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"), Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer s.Close()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("load location: %v", err)
+	}
+
+	// 2025-03-09 is the start of daylight saving time in New York, and is
+	// only 23 hours long.
+	ctx := context.Background()
+	for _, o := range []struct {
+		time time.Time
+		temp float64
+	}{
+		{time.Date(2025, 3, 8, 23, 30, 0, 0, loc), 10},
+		{time.Date(2025, 3, 9, 0, 30, 0, 0, loc), 20},
+		{time.Date(2025, 3, 9, 23, 30, 0, 0, loc), 30},
+		{time.Date(2025, 3, 10, 0, 30, 0, 0, loc), 40},
+	} {
+		err := s.Insert(ctx, weather.Observation{
+			StationID:   "test",
+			ReceivedAt:  o.time,
+			Measurement: wu.DeviceMeasurement{DateUTC: o.time.UTC(), Temperature: o.temp},
+		})
+		if err != nil {
+			t.Fatalf("insert observation: %v", err)
+		}
+	}
+
+	from := time.Date(2025, 3, 8, 0, 0, 0, 0, loc)
+	points, err := s.Aggregate(ctx, "test", "temperature", from, from.AddDate(0, 0, 3), 24*time.Hour, loc)
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	want := []Point{
+		{Time: time.Date(2025, 3, 8, 0, 0, 0, 0, loc), Avg: 10, Min: 10, Max: 10, Count: 1},
+		{Time: time.Date(2025, 3, 9, 0, 0, 0, 0, loc), Avg: 25, Min: 20, Max: 30, Count: 2},
+		{Time: time.Date(2025, 3, 10, 0, 0, 0, 0, loc), Avg: 40, Min: 40, Max: 40, Count: 1},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d: %+v", len(points), len(want), points)
+	}
+	for i, p := range points {
+		w := want[i]
+		if !p.Time.Equal(w.Time) || p.Avg != w.Avg || p.Min != w.Min || p.Max != w.Max || p.Count != w.Count {
+			t.Errorf("point %d: got %+v, want %+v", i, p, w)
+		}
+	}
+}
+
+func TestGaps(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"), Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	start := time.Date(2025, 1, 23, 12, 0, 0, 0, time.UTC)
+	for _, m := range []int{0, 1, 2, 10, 11, 20} {
+		ts := start.Add(time.Duration(m) * time.Minute)
+		err := s.Insert(ctx, weather.Observation{
+			StationID:   "test",
+			ReceivedAt:  ts,
+			Measurement: wu.DeviceMeasurement{DateUTC: ts},
+		})
+		if err != nil {
+			t.Fatalf("insert observation: %v", err)
+		}
+	}
+
+	gaps, err := s.Gaps(ctx, "test", start, start.Add(time.Hour), 2*time.Minute)
+	if err != nil {
+		t.Fatalf("gaps: %v", err)
+	}
+	want := []Gap{
+		{Start: start.Add(2 * time.Minute), End: start.Add(10 * time.Minute)},
+		{Start: start.Add(11 * time.Minute), End: start.Add(20 * time.Minute)},
+	}
+	if len(gaps) != len(want) {
+		t.Fatalf("got %d gaps, want %d: %+v", len(gaps), len(want), gaps)
+	}
+	for i, g := range gaps {
+		if !g.Start.Equal(want[i].Start) || !g.End.Equal(want[i].End) {
+			t.Errorf("gap %d: got %+v, want %+v", i, g, want[i])
+		}
+	}
+}
+
+func TestMigrateMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	ctx := context.Background()
+	for _, m := range migrations[:3] {
+		if _, err := db.ExecContext(ctx, m); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO observations (
+		station_id, time, received_at, realtime, realtime_frequency,
+		wind_direction, wind_speed, wind_gust, humidity, dew_point,
+		temperature, rain_past_hour, rain_today, barometric,
+		indoor_temperature, indoor_humidity, missing
+	) VALUES ('test', 0, 0, FALSE, 0, 0, 0, 0, 0, 0, 21, 0, 0, 0, 0, 0, '["humidity","dewptf"]');
+	PRAGMA user_version = 3`)
+	if err != nil {
+		t.Fatalf("insert observation: %v", err)
+	}
+	_ = db.Close()
+
+	s, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer s.Close()
+
+	var got []weather.Observation
+	err = s.Observations(ctx, Query{}, func(o weather.Observation) error {
+		got = append(got, o)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("query observations: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d observations, want 1", len(got))
+	}
+	dm := got[0].Measurement
+	if !slices.Equal(dm.Missing, []string{"humidity", "dewptf"}) {
+		t.Errorf("got missing fields %v, want [humidity dewptf]", dm.Missing)
+	}
+	if dm.Temperature != 21 {
+		t.Errorf("temperature got %v, want 21", dm.Temperature)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package syslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// Handler is a slog.Handler that sends records formatted by another handler
+// to syslog, with a severity based on the record level.
+type Handler struct {
+	slog.Handler
+	out *output
+}
+
+// output is the writer used by the handler that formats records. Records are
+// written while mu is held, with severity set to the record severity.
+type output struct {
+	mu       sync.Mutex
+	w        *Writer
+	severity Severity
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	if err := o.w.Write(o.severity, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// NewHandler returns a handler that sends records to w. Records are formatted
+// by the handler returned by newHandler, which must write each record to the
+// given writer with a single call to Write.
+func NewHandler(w *Writer, newHandler func(w io.Writer) slog.Handler) *Handler {
+	out := &output{w: w}
+	return &Handler{Handler: newHandler(out), out: out}
+}
+
+// Handle sends the record to syslog.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	h.out.mu.Lock()
+	defer h.out.mu.Unlock()
+
+	h.out.severity = severity(r.Level)
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a handler whose records include the given attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs), out: h.out}
+}
+
+// WithGroup returns a handler whose record attributes are in the given group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name), out: h.out}
+}
+
+// severity returns the syslog severity for a log level.
+func severity(level slog.Level) Severity {
+	switch {
+	case level >= slog.LevelError:
+		return SeverityError
+	case level >= slog.LevelWarn:
+		return SeverityWarning
+	case level >= slog.LevelInfo:
+		return SeverityInfo
+	default:
+		return SeverityDebug
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package syslog implements sending log messages to a local or remote syslog
+// server, using the RFC 5424 message format.
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Severity is a syslog message severity.
+type Severity int
+
+// Syslog severities.
+const (
+	SeverityError   Severity = 3
+	SeverityWarning Severity = 4
+	SeverityInfo    Severity = 6
+	SeverityDebug   Severity = 7
+)
+
+// facilityDaemon is the syslog facility used for messages.
+const facilityDaemon = 3
+
+// dialTimeout is the maximum time to wait when connecting to the server.
+const dialTimeout = 5 * time.Second
+
+// localSockets are the paths of the local syslog socket on various systems.
+var localSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Writer sends messages to a syslog server. Messages sent over stream
+// connections (TCP and Unix stream sockets) use octet-counting framing, as
+// specified by RFC 6587.
+type Writer struct {
+	network  string
+	address  string
+	hostname string
+	appName  string
+	pid      string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// Dial connects to the syslog server at the given address. The address is
+// either "local", to use the local syslog socket, or a URL with the scheme
+// "udp", "tcp" or "unix", e.g. "udp://192.168.1.2:514" or "unix:///dev/log".
+// Messages are sent with the given application name.
+func Dial(address, appName string) (*Writer, error) {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	w := &Writer{
+		hostname: hostname,
+		appName:  appName,
+		pid:      strconv.Itoa(os.Getpid()),
+	}
+
+	if address == "local" {
+		for _, path := range localSockets {
+			for _, network := range []string{"unixgram", "unix"} {
+				w.network, w.address = network, path
+				if err := w.connect(); err == nil {
+					return w, nil
+				}
+			}
+		}
+		return nil, errors.New("no local syslog socket found")
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parse syslog address: %w", err)
+	}
+	switch u.Scheme {
+	case "udp", "tcp":
+		w.network, w.address = u.Scheme, u.Host
+	case "unix":
+		w.network, w.address = "unixgram", u.Path
+	default:
+		return nil, fmt.Errorf("unsupported syslog address scheme: %q", u.Scheme)
+	}
+	if err := w.connect(); err != nil {
+		if w.network != "unixgram" {
+			return nil, err
+		}
+		// The socket may be a stream socket.
+		w.network = "unix"
+		if err := w.connect(); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// connect connects to the syslog server.
+func (w *Writer) connect() error {
+	conn, err := net.DialTimeout(w.network, w.address, dialTimeout)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// Write sends a message with the given severity. If sending fails, the
+// connection is re-established and the message is sent again.
+func (w *Writer) Write(severity Severity, msg []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	b := w.format(severity, time.Now(), msg)
+	if w.conn != nil {
+		if _, err := w.conn.Write(b); err == nil {
+			return nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if err := w.connect(); err != nil {
+		return err
+	}
+	_, err := w.conn.Write(b)
+	return err
+}
+
+// format formats a message using the RFC 5424 format.
+func (w *Writer) format(severity Severity, t time.Time, msg []byte) []byte {
+	m := fmt.Appendf(nil, "<%d>1 %s %s %s %s - - %s", facilityDaemon*8+int(severity),
+		t.Format(time.RFC3339Nano), w.hostname, w.appName, w.pid, bytes.TrimRight(msg, "\n"))
+	switch w.network {
+	case "tcp", "unix":
+		return append(strconv.AppendInt(nil, int64(len(m)), 10), append([]byte{' '}, m...)...)
+	default:
+		return m
+	}
+}
+
+// Close closes the connection to the syslog server.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package syslog
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := Dial("udp://"+pc.LocalAddr().String(), "test")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer w.Close()
+
+	logger := slog.New(NewHandler(w, func(w io.Writer) slog.Handler {
+		return slog.NewTextHandler(w, nil)
+	}))
+	logger.Warn("hello", slog.String("station_id", "a"))
+
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(b)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	re := regexp.MustCompile(`^<28>1 \S+ \S+ test \d+ - - time=\S+ level=WARN msg=hello station_id=a$`)
+	if !re.Match(b[:n]) {
+		t.Errorf("unexpected message %q", b[:n])
+	}
+}
+
+func TestFormatStream(t *testing.T) {
+	w := &Writer{network: "tcp", hostname: "host", appName: "test", pid: "1"}
+	got := string(w.format(SeverityInfo, time.Date(2025, 1, 23, 23, 9, 18, 0, time.UTC), []byte("hello\n")))
+	want := "48 <30>1 2025-01-23T23:09:18Z host test 1 - - hello"
+	if got != want {
+		t.Errorf("format() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package upstream
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// DefaultBufferSize is the default maximum number of submissions kept in an
+// upstream's buffer.
+const DefaultBufferSize = 10000
+
+// bufferEntry is a submission kept in a buffer, which is a line of the buffer
+// file.
+type bufferEntry struct {
+	StationID  string    `json:"station_id"`
+	ReceivedAt time.Time `json:"received_at"`
+	RawQuery   string    `json:"query"`
+}
+
+// buffer is a bounded on-disk buffer of submissions that could not be sent to
+// an upstream. The entries are kept in memory, and mirrored to a file of
+// JSON lines so they survive restarts. Only used by the worker's goroutine.
+//
+// Entries are appended to the file. Entries evicted from a full buffer are
+// only dropped from memory, and remain at the start of the file until it is
+// compacted, once it holds twice as many lines as the buffer's size. As the
+// file is read from its end, they are not restored on restart.
+type buffer struct {
+	path    string
+	size    int
+	entries []bufferEntry
+	lines   int // number of lines in the file
+	f       *os.File
+}
+
+// openBuffer opens the buffer file at path, creating it if it does not exist,
+// and reads the buffered submissions. If the file holds more than size
+// submissions, the oldest are dropped.
+func openBuffer(path string, size int) (*buffer, error) {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	b := &buffer{path: path, size: size}
+	f, err := os.Open(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for sc.Scan() {
+			var e bufferEntry
+			// A partially written line is ignored.
+			if err := json.Unmarshal(sc.Bytes(), &e); err == nil {
+				b.entries = append(b.entries, e)
+			}
+		}
+		err := sc.Err()
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read buffer: %w", err)
+		}
+	}
+	if len(b.entries) > size {
+		b.entries = b.entries[len(b.entries)-size:]
+	}
+	if err := b.rewrite(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// len returns the number of buffered submissions.
+func (b *buffer) len() int {
+	return len(b.entries)
+}
+
+// has returns whether submissions from the station are buffered.
+func (b *buffer) has(stationID string) bool {
+	return slices.ContainsFunc(b.entries, func(e bufferEntry) bool {
+		return e.StationID == stationID
+	})
+}
+
+// push appends a submission to the buffer. If the buffer is full, the oldest
+// submission is dropped, and true is returned.
+func (b *buffer) push(e bufferEntry) (bool, error) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return false, err
+	}
+	evicted := len(b.entries) >= b.size
+	if evicted {
+		b.entries = slices.Delete(b.entries, 0, 1)
+	}
+	b.entries = append(b.entries, e)
+	if b.lines >= 2*b.size {
+		return evicted, b.rewrite()
+	}
+	if _, err := b.f.Write(append(line, '\n')); err != nil {
+		return evicted, err
+	}
+	b.lines++
+	return evicted, b.f.Sync()
+}
+
+// remove removes the submissions for which drop returns true, given their
+// index in the buffer.
+func (b *buffer) remove(drop func(i int) bool) error {
+	entries := b.entries[:0]
+	for i, e := range b.entries {
+		if !drop(i) {
+			entries = append(entries, e)
+		}
+	}
+	clear(b.entries[len(entries):])
+	b.entries = entries
+	return b.rewrite()
+}
+
+// rewrite atomically replaces the buffer file with the buffered submissions,
+// and reopens it for appending. The file contains the raw submissions,
+// including station passwords, so it is only readable by the owner.
+func (b *buffer) rewrite() error {
+	tmp, err := os.CreateTemp(filepath.Dir(b.path), filepath.Base(b.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, e := range b.entries {
+		if err := enc.Encode(e); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), b.path); err != nil {
+		return err
+	}
+
+	b.lines = len(b.entries)
+	if b.f != nil {
+		_ = b.f.Close()
+	}
+	b.f, err = os.OpenFile(b.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	return err
+}
+
+// close closes the buffer file.
+func (b *buffer) close() error {
+	return b.f.Close()
+}
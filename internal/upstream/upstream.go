@@ -0,0 +1,798 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package upstream re-submits the submissions received by the exporter to
+// upstream weather services, such as Weather Underground, PWSWeather, Windy
+// and Weathercloud, or to custom URLs, retrying failed submissions with an
+// exponential backoff. Submissions that still fail can be kept in an on-disk
+// buffer, and replayed once the upstream recovers.
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/joshuasing/pws_exporter/internal/wuforward"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// Service is the upstream weather service that submissions are sent to.
+type Service string
+
+const (
+	// ServiceWU is Weather Underground. ID and Password are the WU station
+	// ID and key.
+	ServiceWU Service = "wu"
+
+	// ServicePWSWeather is PWSWeather (AerisWeather). ID and Password are
+	// the PWSWeather station ID and API key.
+	ServicePWSWeather Service = "pwsweather"
+
+	// ServiceWindy is Windy.com. Password is the Windy API key, and ID is
+	// the optional index of the station on the API key.
+	ServiceWindy Service = "windy"
+
+	// ServiceWeathercloud is Weathercloud. ID and Password are the
+	// Weathercloud device ID and key.
+	ServiceWeathercloud Service = "weathercloud"
+
+	// ServiceCustom is a custom service, that submissions are sent to with a
+	// GET request to a URL template.
+	ServiceCustom Service = "custom"
+)
+
+// Submission URLs of the services.
+const (
+	PWSWeatherURL   = "https://pwsupdate.pwsweather.com/api/v1/submitwx"
+	WindyURL        = "https://stations.windy.com/pws/update/"
+	WeathercloudURL = "http://api.weathercloud.net/v01/set"
+)
+
+// Defaults of an upstream's retry policy.
+const (
+	DefaultMaxAttempts = 3
+	DefaultBackoff     = 5 * time.Second
+)
+
+// defaultIntervals are the minimum intervals between the submissions of a
+// station to the services that limit how often stations may submit.
+var defaultIntervals = map[Service]time.Duration{
+	ServiceWindy:        5 * time.Minute,
+	ServiceWeathercloud: 10 * time.Minute,
+}
+
+// queueSize is the maximum number of submissions waiting to be sent to an
+// upstream.
+const queueSize = 256
+
+// requestTimeout is the maximum duration of a request to an upstream.
+const requestTimeout = 10 * time.Second
+
+// replayInterval is the interval at which buffered submissions are replayed,
+// if no submission has been sent to the upstream in the meantime, or the
+// remaining submissions were rate limited.
+const replayInterval = time.Minute
+
+// Upstream is an upstream service that submissions are sent to.
+type Upstream struct {
+	// Name is the name of the upstream, used in metrics and logs.
+	Name string
+
+	// Service is the service of the upstream.
+	Service Service
+
+	// Stations are the IDs of the stations whose submissions are sent to
+	// the upstream. If empty, the submissions of all stations are sent.
+	Stations []string
+
+	// ID and Password are the credentials of the station at the service.
+	ID       string
+	Password string
+
+	// URL is the submission URL, which overrides the service's URL. For
+	// ServiceCustom, it is a text/template that is executed with a
+	// TemplateData.
+	URL string
+
+	// Interval is the minimum interval between the submissions of a
+	// station. Submissions received sooner are skipped. If zero, the
+	// service's limit is used, if any.
+	Interval time.Duration
+
+	// MaxAttempts is the maximum number of attempts to send a submission.
+	// Defaults to DefaultMaxAttempts.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, which doubles with each
+	// retry. Defaults to DefaultBackoff.
+	Backoff time.Duration
+
+	// BufferFile is the path of the file that submissions are buffered in
+	// when the upstream cannot be reached, so they can be replayed once it
+	// recovers. If empty, submissions that fail are dropped.
+	BufferFile string
+
+	// BufferSize is the maximum number of buffered submissions, after which
+	// the oldest are dropped. Defaults to DefaultBufferSize.
+	BufferSize int
+}
+
+// TemplateData is the data that the URL template of a custom upstream is
+// executed with.
+type TemplateData struct {
+	// StationID is the ID of the station that sent the submission.
+	StationID string
+
+	// ID and Password are the upstream's credentials.
+	ID       string
+	Password string
+
+	// Time is the time of the observation.
+	Time time.Time
+
+	// Params are the WU query parameters of the submission (in imperial
+	// units), e.g. {{index .Params "tempf"}}.
+	Params map[string]string
+
+	// Query is the encoded WU query string of the submission, with the
+	// station ID and password removed.
+	Query string
+}
+
+// submission is a submission waiting to be sent to an upstream.
+type submission struct {
+	stationID  string
+	query      url.Values
+	receivedAt time.Time
+}
+
+// Forwarder sends the submissions of stations to upstreams. Each upstream
+// sends its submissions in the background, in the order they were received,
+// independently of the other upstreams.
+type Forwarder struct {
+	workers []*worker
+	hc      *http.Client
+
+	submissions *prometheus.CounterVec
+	retries     *prometheus.CounterVec
+	buffered    *prometheus.GaugeVec
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// worker sends the submissions queued for an upstream.
+type worker struct {
+	f        *Forwarder
+	upstream Upstream
+	tmpl     *template.Template
+	stations map[string]struct{} // nil for all stations
+	queue    chan submission
+	buffer   *buffer // nil if buffering is disabled
+
+	// last is the observation time of the last submission sent for each
+	// station, and sent and replayed are the times the last request and the
+	// last replayed request were sent for each station. Only used by the
+	// worker's goroutine.
+	last     map[string]time.Time
+	sent     map[string]time.Time
+	replayed map[string]time.Time
+}
+
+// NewForwarder returns a new forwarder, which sends submissions to the
+// upstreams. The outcomes of submissions are exported as metrics on reg. The
+// forwarder must be closed once it is no longer used.
+func NewForwarder(upstreams []Upstream, reg prometheus.Registerer) (*Forwarder, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Forwarder{
+		hc: &http.Client{Timeout: requestTimeout},
+		submissions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "upstream",
+			Name:      "submissions_total",
+			Help:      "Total number of submissions sent to the upstream, by outcome (success, failure, buffered, skipped or dropped)",
+		}, []string{"upstream", "outcome"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "upstream",
+			Name:      "retries_total",
+			Help:      "Total number of retried attempts to send submissions to the upstream",
+		}, []string{"upstream"}),
+		buffered: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "upstream",
+			Name:      "buffered_submissions",
+			Help:      "Number of submissions buffered to be replayed once the upstream recovers",
+		}, []string{"upstream"}),
+		cancel: cancel,
+	}
+	for _, u := range upstreams {
+		w := &worker{
+			f:        f,
+			upstream: u,
+			queue:    make(chan submission, queueSize),
+			last:     make(map[string]time.Time),
+			sent:     make(map[string]time.Time),
+			replayed: make(map[string]time.Time),
+		}
+		if u.Service == ServiceCustom {
+			tmpl, err := template.New(u.Name).Parse(u.URL)
+			if err != nil {
+				f.closeBuffers()
+				cancel()
+				return nil, fmt.Errorf("upstream %q: parse URL template: %w", u.Name, err)
+			}
+			w.tmpl = tmpl
+		}
+		if u.BufferFile != "" {
+			b, err := openBuffer(u.BufferFile, u.BufferSize)
+			if err != nil {
+				f.closeBuffers()
+				cancel()
+				return nil, fmt.Errorf("upstream %q: open buffer: %w", u.Name, err)
+			}
+			w.buffer = b
+			f.buffered.WithLabelValues(u.Name).Set(float64(b.len()))
+		}
+		if len(u.Stations) > 0 {
+			w.stations = make(map[string]struct{}, len(u.Stations))
+			for _, s := range u.Stations {
+				w.stations[s] = struct{}{}
+			}
+		}
+		for _, outcome := range []string{"success", "failure", "buffered", "skipped", "dropped"} {
+			f.submissions.WithLabelValues(u.Name, outcome)
+		}
+		f.retries.WithLabelValues(u.Name)
+		f.workers = append(f.workers, w)
+	}
+	reg.MustRegister(f.submissions, f.retries, f.buffered)
+
+	for _, w := range f.workers {
+		f.wg.Add(1)
+		go w.run(ctx)
+	}
+	return f, nil
+}
+
+// Forward queues a raw submission query string from a station to be sent to
+// the upstreams the station's submissions are sent to. receivedAt is used as
+// the time of submissions without a date. It returns the number of upstreams
+// the submission was dropped for because their queue is full.
+func (f *Forwarder) Forward(stationID, rawQuery string, receivedAt time.Time) int {
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return 0
+	}
+
+	var dropped int
+	for _, w := range f.workers {
+		if w.stations != nil {
+			if _, ok := w.stations[stationID]; !ok {
+				continue
+			}
+		}
+		select {
+		case w.queue <- submission{stationID: stationID, query: q, receivedAt: receivedAt}:
+		default:
+			f.submissions.WithLabelValues(w.upstream.Name, "dropped").Inc()
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// Close waits for queued submissions to be sent, until ctx is done. Retries
+// that are still pending when ctx is done are abandoned, and their
+// submissions are buffered if buffering is enabled.
+func (f *Forwarder) Close(ctx context.Context) error {
+	for _, w := range f.workers {
+		close(w.queue)
+	}
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		f.cancel()
+		<-done
+		err = ctx.Err()
+	}
+	f.cancel()
+	return errors.Join(err, f.closeBuffers())
+}
+
+// closeBuffers closes the buffer files of the upstreams.
+func (f *Forwarder) closeBuffers() error {
+	var errs []error
+	for _, w := range f.workers {
+		if w.buffer != nil {
+			errs = append(errs, w.buffer.close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run sends queued submissions until the queue is closed. If buffering is
+// enabled, buffered submissions are replayed on start, once a submission has
+// been sent after a failure, and every replayInterval. Replaying is done in
+// batches, between queued submissions, so the queue does not fill up.
+func (w *worker) run(ctx context.Context) {
+	defer w.f.wg.Done()
+
+	var (
+		tick      <-chan time.Time
+		replaying bool
+	)
+	if w.buffer != nil {
+		ticker := time.NewTicker(replayInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+		replaying = w.replay(ctx)
+	}
+	for {
+		if replaying {
+			select {
+			case s, ok := <-w.queue:
+				if !ok {
+					return
+				}
+				w.handle(ctx, s)
+			default:
+				replaying = w.replay(ctx)
+			}
+			continue
+		}
+		select {
+		case s, ok := <-w.queue:
+			if !ok {
+				return
+			}
+			if w.handle(ctx, s) && w.buffer != nil && w.buffer.len() > 0 {
+				replaying = w.replay(ctx)
+			}
+		case <-tick:
+			replaying = w.replay(ctx)
+		}
+	}
+}
+
+// interval returns the minimum interval between the submissions of a
+// station to the upstream.
+func (w *worker) interval() time.Duration {
+	if w.upstream.Interval > 0 {
+		return w.upstream.Interval
+	}
+	return defaultIntervals[w.upstream.Service]
+}
+
+// handle sends a submission to the upstream, unless the station's previous
+// submission was sent less than the upstream's interval ago, and returns
+// whether it was sent. While older submissions of the station are buffered,
+// the submission is buffered behind them, so the upstream receives the
+// station's submissions in order.
+func (w *worker) handle(ctx context.Context, s submission) bool {
+	u := w.upstream
+	interval := w.interval()
+	if last, ok := w.last[s.stationID]; ok && s.receivedAt.Sub(last) < interval {
+		w.f.submissions.WithLabelValues(u.Name, "skipped").Inc()
+		return false
+	}
+	// Replayed submissions count towards the upstream's rate limit.
+	if replayed, ok := w.replayed[s.stationID]; ok && time.Since(replayed) < interval {
+		w.f.submissions.WithLabelValues(u.Name, "skipped").Inc()
+		return false
+	}
+	if w.buffer != nil && w.buffer.has(s.stationID) {
+		w.bufferSubmission(s)
+		return false
+	}
+
+	target, err := w.submissionURL(s)
+	if err == nil {
+		w.sent[s.stationID] = time.Now()
+		err = w.sendWithRetries(ctx, s.stationID, target)
+	}
+	switch {
+	case err == nil:
+		w.last[s.stationID] = s.receivedAt
+		w.f.submissions.WithLabelValues(u.Name, "success").Inc()
+		return true
+	case w.buffer != nil && (retryable(err) || ctx.Err() != nil):
+		slog.Warn("Failed to send submission to upstream, buffering it",
+			slog.String("upstream", u.Name),
+			slog.String("station_id", s.stationID),
+			slog.Any("err", err))
+		w.bufferSubmission(s)
+	default:
+		w.f.submissions.WithLabelValues(u.Name, "failure").Inc()
+		slog.Error("Failed to send submission to upstream",
+			slog.String("upstream", u.Name),
+			slog.String("station_id", s.stationID),
+			slog.Any("err", err))
+	}
+	return false
+}
+
+// bufferSubmission adds a submission to the buffer, to be replayed once the
+// upstream recovers.
+func (w *worker) bufferSubmission(s submission) {
+	name := w.upstream.Name
+	evicted, err := w.buffer.push(bufferEntry{
+		StationID:  s.stationID,
+		ReceivedAt: s.receivedAt,
+		RawQuery:   s.query.Encode(),
+	})
+	w.f.buffered.WithLabelValues(name).Set(float64(w.buffer.len()))
+	if err != nil {
+		w.f.submissions.WithLabelValues(name, "failure").Inc()
+		slog.Error("Failed to write upstream buffer",
+			slog.String("upstream", name),
+			slog.Any("err", err))
+		return
+	}
+	w.f.submissions.WithLabelValues(name, "buffered").Inc()
+	if evicted {
+		slog.Warn("Upstream buffer is full, dropping the oldest submission",
+			slog.String("upstream", name))
+		w.f.submissions.WithLabelValues(name, "dropped").Inc()
+	}
+}
+
+// replayBatchSize is the maximum number of buffered submissions sent by a
+// single replay, before queued submissions are sent.
+const replayBatchSize = 20
+
+// replay sends a batch of buffered submissions, oldest first, with the time
+// of their observation, and returns whether more can be sent right away.
+//
+// The submissions of a station are sent in order, at most once per the
+// upstream's interval, including the station's queued submissions, so that
+// rate limited services do not reject them. Replaying stops at the first
+// submission that fails with a retryable error, as the upstream has not
+// recovered yet, while submissions that fail otherwise are dropped.
+func (w *worker) replay(ctx context.Context) bool {
+	if w.buffer == nil || w.buffer.len() == 0 {
+		return false
+	}
+	name := w.upstream.Name
+	interval := w.interval()
+
+	var (
+		done    = make(map[int]bool)
+		blocked = make(map[string]bool) // stations whose next entry cannot be sent yet
+		sent    int
+		more    bool
+	)
+	for i, e := range w.buffer.entries {
+		if ctx.Err() != nil {
+			break
+		}
+		if blocked[e.StationID] {
+			continue
+		}
+		if t, ok := w.sent[e.StationID]; ok && time.Since(t) < interval {
+			blocked[e.StationID] = true
+			continue
+		}
+		if len(done) == replayBatchSize {
+			more = true
+			break
+		}
+
+		q, err := url.ParseQuery(e.RawQuery)
+		var target string
+		if err == nil {
+			target, err = w.submissionURL(submission{stationID: e.StationID, query: q, receivedAt: e.ReceivedAt})
+		}
+		if err == nil {
+			now := time.Now()
+			w.sent[e.StationID], w.replayed[e.StationID] = now, now
+			err = w.send(ctx, target)
+		}
+		if err != nil && (retryable(err) || ctx.Err() != nil) {
+			slog.Debug("Upstream has not recovered, stopping replay",
+				slog.String("upstream", name),
+				slog.Any("err", err))
+			more = false
+			break
+		}
+		done[i] = true
+		if interval > 0 {
+			blocked[e.StationID] = true
+		}
+		if err != nil {
+			w.f.submissions.WithLabelValues(name, "failure").Inc()
+			slog.Error("Failed to replay buffered submission to upstream",
+				slog.String("upstream", name),
+				slog.String("station_id", e.StationID),
+				slog.Any("err", err))
+			continue
+		}
+		sent++
+		w.f.submissions.WithLabelValues(name, "success").Inc()
+	}
+	if len(done) == 0 {
+		return false
+	}
+	if err := w.buffer.remove(func(i int) bool { return done[i] }); err != nil {
+		slog.Error("Failed to write upstream buffer",
+			slog.String("upstream", name),
+			slog.Any("err", err))
+	}
+	w.f.buffered.WithLabelValues(name).Set(float64(w.buffer.len()))
+	slog.Info("Replayed buffered submissions to upstream",
+		slog.String("upstream", name),
+		slog.Int("sent", sent),
+		slog.Int("remaining", w.buffer.len()))
+	return more
+}
+
+// sendWithRetries sends a request to the submission URL, retrying retryable
+// failures with an exponential backoff until the upstream's maximum number of
+// attempts is reached, or ctx is done.
+func (w *worker) sendWithRetries(ctx context.Context, stationID, target string) error {
+	maxAttempts := w.upstream.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	backoff := w.upstream.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	for attempt := 1; ; attempt++ {
+		err := w.send(ctx, target)
+		if err == nil || !retryable(err) || attempt >= maxAttempts {
+			return err
+		}
+		slog.Debug("Retrying submission to upstream",
+			slog.String("upstream", w.upstream.Name),
+			slog.String("station_id", stationID),
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", backoff),
+			slog.Any("err", err))
+		w.f.retries.WithLabelValues(w.upstream.Name).Inc()
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// statusError is the error of a request that the upstream responded to with
+// an unsuccessful status.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return "unexpected response status: " + e.status
+}
+
+// retryable returns whether a failed request may succeed if it is retried.
+// Requests that failed to connect, were rate limited, or failed with a server
+// error are retried, while other responses are permanent failures.
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusTooManyRequests || se.code >= 500
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// send sends a GET request to the submission URL.
+func (w *worker) send(ctx context.Context, target string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "pws_exporter")
+
+	res, err := w.f.hc.Do(req)
+	if err != nil {
+		// Avoid logging credentials, which are part of the URL.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURL(target)
+		}
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return &statusError{code: res.StatusCode, status: res.Status}
+	}
+	return nil
+}
+
+// redactURL returns the scheme and host of a URL, without the path and query
+// that may contain credentials.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// submissionURL returns the URL that a submission is sent to.
+func (w *worker) submissionURL(s submission) (string, error) {
+	u := w.upstream
+	q := make(url.Values, len(s.query))
+	for k, v := range s.query {
+		q[k] = v
+	}
+
+	// Upstreams receive submissions some time after they were sent, so
+	// the date is set to the time of the observation.
+	t := s.receivedAt.UTC()
+	if v := q.Get("dateutc"); v != "" && v != "now" {
+		if dt, err := time.ParseInLocation("2006-01-02 15:04:05", v, time.UTC); err == nil {
+			t = dt
+		}
+	}
+	q.Set("dateutc", t.Format("2006-01-02 15:04:05"))
+
+	switch u.Service {
+	case ServiceWU:
+		base := u.URL
+		if base == "" {
+			base = wuforward.DefaultURL
+			if q.Get("realtime") == "1" {
+				base = wuforward.DefaultRapidFireURL
+			}
+		}
+		q.Set("ID", u.ID)
+		q.Set("PASSWORD", u.Password)
+		return base + "?" + q.Encode(), nil
+	case ServicePWSWeather:
+		q.Set("ID", u.ID)
+		q.Set("PASSWORD", u.Password)
+		q.Del("realtime")
+		q.Del("rtfreq")
+		return or(u.URL, PWSWeatherURL) + "?" + q.Encode(), nil
+	case ServiceWindy:
+		wq := translate(q, windyParams)
+		if u.ID != "" {
+			wq.Set("station", u.ID)
+		}
+		return or(u.URL, WindyURL) + url.PathEscape(u.Password) + "?" + wq.Encode(), nil
+	case ServiceWeathercloud:
+		wq := translate(q, weathercloudParams)
+		wq.Set("wid", u.ID)
+		wq.Set("key", u.Password)
+		wq.Set("date", t.Format("20060102"))
+		wq.Set("time", t.Format("1504"))
+		wq.Set("software", "pws_exporter")
+		return or(u.URL, WeathercloudURL) + "?" + wq.Encode(), nil
+	case ServiceCustom:
+		params := make(map[string]string, len(q))
+		for k := range q {
+			params[k] = q.Get(k)
+		}
+		q.Del("ID")
+		q.Del("PASSWORD")
+		var buf bytes.Buffer
+		err := w.tmpl.Execute(&buf, TemplateData{
+			StationID: s.stationID,
+			ID:        u.ID,
+			Password:  u.Password,
+			Time:      t,
+			Params:    params,
+			Query:     q.Encode(),
+		})
+		if err != nil {
+			return "", fmt.Errorf("execute URL template: %w", err)
+		}
+		return buf.String(), nil
+	}
+	return "", fmt.Errorf("unknown service %q", u.Service)
+}
+
+// param is a WU parameter that is sent to a service under another name.
+type param struct {
+	wu, name string
+
+	// convert converts the WU value to the unit of the service. Nil if the
+	// value is sent unchanged.
+	convert func(float64) float64
+}
+
+// windyParams are the WU parameters accepted by Windy.
+var windyParams = []param{
+	{"dateutc", "dateutc", nil},
+	{"tempf", "tempf", nil},
+	{"dewptf", "dewptf", nil},
+	{"humidity", "humidity", nil},
+	{"baromin", "baromin", nil},
+	{"windspeedmph", "windspeedmph", nil},
+	{"windgustmph", "windgustmph", nil},
+	{"winddir", "winddir", nil},
+	{"rainin", "rainin", nil},
+	{"UV", "uv", nil},
+}
+
+// weathercloudParams are the WU parameters accepted by Weathercloud, which
+// expects metric values multiplied by 10 and rounded.
+var weathercloudParams = []param{
+	{"tempf", "temp", func(f float64) float64 { return (f - 32) * 5 / 9 * 10 }},
+	{"dewptf", "dew", func(f float64) float64 { return (f - 32) * 5 / 9 * 10 }},
+	{"humidity", "hum", keep},
+	{"baromin", "bar", func(in float64) float64 { return in * 33.8639 * 10 }},
+	{"windspeedmph", "wspd", func(mph float64) float64 { return mph * 0.44704 * 10 }},
+	{"windgustmph", "wspdhi", func(mph float64) float64 { return mph * 0.44704 * 10 }},
+	{"winddir", "wdir", keep},
+	{"dailyrainin", "rain", func(in float64) float64 { return in * 25.4 * 10 }},
+	{"rainin", "rainrate", func(in float64) float64 { return in * 25.4 * 10 }},
+	{"solarradiation", "solarrad", func(v float64) float64 { return v * 10 }},
+	{"UV", "uvi", func(v float64) float64 { return v * 10 }},
+	{"indoortempf", "tempin", func(f float64) float64 { return (f - 32) * 5 / 9 * 10 }},
+	{"indoorhumidity", "humin", keep},
+}
+
+// keep returns the value unchanged, for values that are only rounded.
+func keep(v float64) float64 { return v }
+
+// translate returns the values of the WU parameters that are sent to a
+// service, renamed and converted. Missing and invalid values are not sent.
+func translate(q url.Values, params []param) url.Values {
+	out := make(url.Values, len(params))
+	for _, p := range params {
+		v := q.Get(p.wu)
+		if wu.IsMissing(v) {
+			continue
+		}
+		if p.convert != nil {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			v = strconv.FormatFloat(math.Round(p.convert(f)), 'f', 0, 64)
+		}
+		out.Set(p.name, v)
+	}
+	return out
+}
+
+// or returns s, or def if s is empty.
+func or(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,507 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const testQuery = "ID=KOLD1&PASSWORD=old&action=updateraww&dateutc=now&tempf=59.7&humidity=80&baromin=29.92" +
+	"&windspeedmph=10&winddir=-9999&dailyrainin=0.12&UV=3&realtime=1&rtfreq=5"
+
+func TestSubmissionURL(t *testing.T) {
+	q, err := url.ParseQuery(testQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := submission{stationID: "KOLD1", query: q, receivedAt: time.Date(2025, 10, 15, 12, 30, 5, 0, time.UTC)}
+
+	for _, tt := range []struct {
+		upstream Upstream
+		base     string
+		want     map[string]string
+		unset    []string
+	}{
+		{
+			upstream: Upstream{Service: ServiceWU, ID: "KNEW1", Password: "key"},
+			base:     "https://rtupdate.wunderground.com/weatherstation/updateweatherstation.php",
+			want:     map[string]string{"ID": "KNEW1", "PASSWORD": "key", "dateutc": "2025-10-15 12:30:05", "tempf": "59.7"},
+		},
+		{
+			upstream: Upstream{Service: ServicePWSWeather, ID: "PWS1", Password: "key"},
+			base:     PWSWeatherURL,
+			want:     map[string]string{"ID": "PWS1", "PASSWORD": "key", "tempf": "59.7"},
+			unset:    []string{"realtime", "rtfreq"},
+		},
+		{
+			upstream: Upstream{Service: ServiceWindy, ID: "1", Password: "api/key"},
+			base:     WindyURL + "api%2Fkey",
+			want:     map[string]string{"station": "1", "tempf": "59.7", "uv": "3", "dateutc": "2025-10-15 12:30:05"},
+			unset:    []string{"ID", "PASSWORD", "winddir", "dailyrainin"},
+		},
+		{
+			upstream: Upstream{Service: ServiceWeathercloud, ID: "wid", Password: "key"},
+			base:     WeathercloudURL,
+			want: map[string]string{
+				"wid": "wid", "key": "key", "temp": "154", "hum": "80", "bar": "10132", "wspd": "45",
+				"rain": "30", "uvi": "30", "date": "20251015", "time": "1230",
+			},
+			unset: []string{"tempf", "wdir"},
+		},
+	} {
+		t.Run(string(tt.upstream.Service), func(t *testing.T) {
+			w := &worker{upstream: tt.upstream}
+			got, err := w.submissionURL(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			base, rawQuery, _ := strings.Cut(got, "?")
+			if base != tt.base {
+				t.Errorf("URL = %q, want %q", base, tt.base)
+			}
+			gq, err := url.ParseQuery(rawQuery)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for param, want := range tt.want {
+				if got := gq.Get(param); got != want {
+					t.Errorf("%s = %q, want %q", param, got, want)
+				}
+			}
+			for _, param := range tt.unset {
+				if gq.Has(param) {
+					t.Errorf("%s = %q, want unset", param, gq.Get(param))
+				}
+			}
+		})
+	}
+}
+
+func TestCustomURL(t *testing.T) {
+	f, err := NewForwarder([]Upstream{{
+		Name:    "custom",
+		Service: ServiceCustom,
+		URL:     `https://example.com/wx/{{.StationID}}?t={{index .Params "tempf" | urlquery}}&at={{.Time.Unix}}&{{.Query}}`,
+	}}, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close(context.Background())
+
+	q, err := url.ParseQuery("ID=KOLD1&PASSWORD=old&tempf=59.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.workers[0].submissionURL(submission{stationID: "KOLD1", query: q, receivedAt: time.Unix(1760531405, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "https://example.com/wx/KOLD1?t=59.7&at=1760531405&dateutc=2025-10-15+12%3A30%3A05&tempf=59.7"
+	if got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+
+	if _, err := NewForwarder([]Upstream{{Name: "bad", Service: ServiceCustom, URL: "{{.StationID"}}, prometheus.NewRegistry()); err == nil {
+		t.Error("NewForwarder() with an invalid template succeeded, want error")
+	}
+}
+
+func TestForwarder(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests = make(map[string]int)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		n := requests[r.URL.Path]
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/flaky" && n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/rejected":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte("success\n"))
+		}
+	}))
+	defer srv.Close()
+
+	upstream := func(name, path string) Upstream {
+		return Upstream{
+			Name: name, Service: ServiceWU, ID: "KNEW1", Password: "key",
+			URL: srv.URL + path, Backoff: time.Millisecond,
+		}
+	}
+	flaky := upstream("flaky", "/flaky")
+	rejected := upstream("rejected", "/rejected")
+	limited := upstream("limited", "/limited")
+	limited.Interval = time.Minute
+	filtered := upstream("filtered", "/filtered")
+	filtered.Stations = []string{"KOTHER1"}
+
+	f, err := NewForwarder([]Upstream{flaky, rejected, limited, filtered}, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := range 2 {
+		if dropped := f.Forward("KOLD1", testQuery, now.Add(time.Duration(i)*time.Second)); dropped != 0 {
+			t.Errorf("dropped %d submissions", dropped)
+		}
+	}
+	if err := f.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	for _, tt := range []struct {
+		upstream, outcome string
+		want              float64
+	}{
+		{"flaky", "success", 2},
+		{"rejected", "failure", 2},
+		{"limited", "success", 1},
+		{"limited", "skipped", 1},
+		{"filtered", "success", 0},
+	} {
+		if got := testutil.ToFloat64(f.submissions.WithLabelValues(tt.upstream, tt.outcome)); got != tt.want {
+			t.Errorf("%s %s submissions = %v, want %v", tt.upstream, tt.outcome, got, tt.want)
+		}
+	}
+	if got := testutil.ToFloat64(f.retries.WithLabelValues("flaky")); got != 1 {
+		t.Errorf("flaky retries = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(f.retries.WithLabelValues("rejected")); got != 0 {
+		t.Errorf("rejected retries = %v, want 0 (client errors are not retried)", got)
+	}
+	if requests["/flaky"] != 3 || requests["/rejected"] != 2 || requests["/filtered"] != 0 {
+		t.Errorf("got requests %v", requests)
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buffer.jsonl")
+	b, err := openBuffer(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Evicted entries remain in the file until it holds twice as many lines
+	// as the buffer's size, when it is compacted.
+	now := time.Now().UTC().Truncate(time.Second)
+	wantLines := []int{1, 2, 3, 4, 2}
+	for i, station := range []string{"KOLD1", "KOLD2", "KOLD3", "KOLD4", "KOLD5"} {
+		evicted, err := b.push(bufferEntry{StationID: station, ReceivedAt: now, RawQuery: testQuery})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i >= 2; evicted != want {
+			t.Errorf("push %s evicted = %v, want %v", station, evicted, want)
+		}
+		if got := strings.Count(string(mustReadFile(t, path)), "\n"); got != wantLines[i] {
+			t.Errorf("push %s: buffer file has %d lines, want %d", station, got, wantLines[i])
+		}
+	}
+	for _, station := range []string{"KOLD6", "KOLD7"} {
+		if _, err := b.push(bufferEntry{StationID: station, ReceivedAt: now, RawQuery: testQuery}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.close(); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Errorf("buffer file permissions = %v, want 0600", perm)
+	}
+
+	// Evicted entries and a partially written line are ignored when the
+	// buffer is reopened.
+	if err := os.WriteFile(path, append(mustReadFile(t, path), `{"station_id":"KOLD4`...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	b, err = openBuffer(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.close()
+	if b.len() != 2 || b.entries[0].StationID != "KOLD6" || b.entries[1].StationID != "KOLD7" {
+		t.Fatalf("got entries %+v, want KOLD6 and KOLD7", b.entries)
+	}
+	if !b.entries[0].ReceivedAt.Equal(now) || b.entries[0].RawQuery != testQuery {
+		t.Errorf("got entry %+v", b.entries[0])
+	}
+	if err := b.remove(func(i int) bool { return i == 0 }); err != nil {
+		t.Fatal(err)
+	}
+	if b.len() != 1 || b.entries[0].StationID != "KOLD7" {
+		t.Errorf("got entries %+v after remove, want KOLD7", b.entries)
+	}
+	if got := strings.Count(string(mustReadFile(t, path)), "\n"); got != 1 {
+		t.Errorf("buffer file has %d lines after remove, want 1", got)
+	}
+}
+
+func TestForwarderBuffer(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		down  = true
+		dates []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		dates = append(dates, r.URL.Query().Get("dateutc"))
+		_, _ = w.Write([]byte("success\n"))
+	}))
+	defer srv.Close()
+
+	u := Upstream{
+		Name: "wu", Service: ServiceWU, ID: "KNEW1", Password: "key",
+		URL: srv.URL, MaxAttempts: 1, BufferFile: filepath.Join(t.TempDir(), "wu.jsonl"),
+	}
+	observed := time.Date(2024, 5, 1, 12, 30, 15, 0, time.UTC)
+
+	// The submission is buffered while the upstream is down.
+	f, err := NewForwarder([]Upstream{u}, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Forward("KOLD1", testQuery, observed)
+	if err := f.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := testutil.ToFloat64(f.submissions.WithLabelValues("wu", "buffered")); got != 1 {
+		t.Errorf("buffered submissions = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(f.buffered.WithLabelValues("wu")); got != 1 {
+		t.Errorf("buffered gauge = %v, want 1", got)
+	}
+
+	// Once the upstream recovers, the buffered submission is replayed with
+	// the time of its observation, before new submissions are sent.
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	f, err = NewForwarder([]Upstream{u}, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Forward("KOLD1", testQuery, observed.Add(time.Hour))
+	if err := f.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	want := []string{"2024-05-01 12:30:15", "2024-05-01 13:30:15"}
+	if strings.Join(dates, ",") != strings.Join(want, ",") {
+		t.Errorf("got dates %q, want %q", dates, want)
+	}
+	if got := testutil.ToFloat64(f.submissions.WithLabelValues("wu", "success")); got != 2 {
+		t.Errorf("successful submissions = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(f.buffered.WithLabelValues("wu")); got != 0 {
+		t.Errorf("buffered gauge = %v, want 0", got)
+	}
+	if b := mustReadFile(t, u.BufferFile); len(b) != 0 {
+		t.Errorf("buffer file is not empty: %s", b)
+	}
+}
+
+func TestReplayLimits(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Query().Get("ID"))
+		mu.Unlock()
+		_, _ = w.Write([]byte("success\n"))
+	}))
+	defer srv.Close()
+
+	f, err := NewForwarder(nil, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	newWorker := func(t *testing.T, interval time.Duration, stations ...string) *worker {
+		b, err := openBuffer(filepath.Join(t.TempDir(), "buffer.jsonl"), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = b.close() })
+		for i, station := range stations {
+			e := bufferEntry{StationID: station, ReceivedAt: time.Unix(int64(i), 0), RawQuery: testQuery}
+			if _, err := b.push(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+		return &worker{
+			f: f,
+			upstream: Upstream{
+				Name: "wu", Service: ServiceCustom, URL: srv.URL + "?ID={{.StationID}}", Interval: interval,
+			},
+			tmpl:     template.Must(template.New("wu").Parse(srv.URL + "?ID={{.StationID}}")),
+			buffer:   b,
+			last:     make(map[string]time.Time),
+			sent:     make(map[string]time.Time),
+			replayed: make(map[string]time.Time),
+		}
+	}
+
+	t.Run("batches", func(t *testing.T) {
+		w := newWorker(t, 0, slices.Repeat([]string{"KOLD1"}, replayBatchSize+5)...)
+		if !w.replay(context.Background()) {
+			t.Error("replay of a full batch should continue")
+		}
+		if w.buffer.len() != 5 || len(requests) != replayBatchSize {
+			t.Errorf("got %d requests and %d remaining entries, want %d and 5", len(requests), w.buffer.len(), replayBatchSize)
+		}
+		if w.replay(context.Background()) {
+			t.Error("replay of the last batch should not continue")
+		}
+		if w.buffer.len() != 0 {
+			t.Errorf("got %d remaining entries, want 0", w.buffer.len())
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		// Each station is sent at most once per interval, in order, and its
+		// queued submissions are skipped while it is rate limited.
+		w := newWorker(t, time.Hour, "KOLD1", "KOLD1", "KOLD2", "KOLD1")
+		if w.replay(context.Background()) {
+			t.Error("rate limited replay should not continue")
+		}
+		if got := strings.Join(requests, ","); got != "KOLD1,KOLD2" {
+			t.Errorf("got requests %s, want KOLD1,KOLD2", got)
+		}
+		if w.buffer.len() != 2 {
+			t.Errorf("got %d remaining entries, want 2", w.buffer.len())
+		}
+		q, _ := url.ParseQuery(testQuery)
+		if w.handle(context.Background(), submission{stationID: "KOLD1", query: q, receivedAt: time.Now()}) {
+			t.Error("submission of a rate limited station was sent")
+		}
+		if w.replay(context.Background()); len(requests) != 2 {
+			t.Errorf("got %d requests, want 2", len(requests))
+		}
+	})
+}
+
+func TestReplayOrder(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Query().Get("ID")+"@"+r.URL.Query().Get("t"))
+		mu.Unlock()
+		_, _ = w.Write([]byte("success\n"))
+	}))
+	defer srv.Close()
+
+	f, err := NewForwarder(nil, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := openBuffer(filepath.Join(t.TempDir(), "buffer.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.close()
+	for i := range replayBatchSize + 1 {
+		e := bufferEntry{StationID: "KOLD1", ReceivedAt: time.Unix(int64(i), 0), RawQuery: testQuery}
+		if _, err := b.push(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmpl := srv.URL + "?ID={{.StationID}}&t={{.Time.Unix}}"
+	w := &worker{
+		f:        f,
+		upstream: Upstream{Name: "custom", Service: ServiceCustom, URL: tmpl},
+		tmpl:     template.Must(template.New("custom").Parse(tmpl)),
+		buffer:   b,
+		last:     make(map[string]time.Time),
+		sent:     make(map[string]time.Time),
+		replayed: make(map[string]time.Time),
+	}
+
+	// The first batch is replayed, and submissions arrive before the rest of
+	// the buffer is replayed.
+	if !w.replay(context.Background()) {
+		t.Fatal("replay of a full batch should continue")
+	}
+	q, _ := url.ParseQuery(testQuery)
+	if w.handle(context.Background(), submission{stationID: "KOLD1", query: q, receivedAt: time.Unix(100, 0)}) {
+		t.Error("submission of a station with buffered submissions was sent before them")
+	}
+	if !w.handle(context.Background(), submission{stationID: "KNEW1", query: q, receivedAt: time.Unix(101, 0)}) {
+		t.Error("submission of a station without buffered submissions was not sent")
+	}
+	if w.replay(context.Background()) {
+		t.Error("replay of the last batch should not continue")
+	}
+
+	var want []string
+	for i := range replayBatchSize {
+		want = append(want, fmt.Sprintf("KOLD1@%d", i))
+	}
+	want = append(want, "KNEW1@101", fmt.Sprintf("KOLD1@%d", replayBatchSize), "KOLD1@100")
+	if !slices.Equal(requests, want) {
+		t.Errorf("got requests %v, want %v", requests, want)
+	}
+	if w.buffer.len() != 0 {
+		t.Errorf("got %d remaining entries, want 0", w.buffer.len())
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
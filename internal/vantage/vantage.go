@@ -0,0 +1,404 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package vantage implements polling Davis Vantage Pro2 and Vantage Vue
+// consoles through a WeatherLink IP data logger, using the documented Vantage
+// serial protocol that the data logger serves on TCP port 22222.
+//
+// The data logger's own uploads to weatherlink.com use an undocumented
+// protocol, so the console is polled for LOOP packets instead of intercepting
+// its uploads.
+package vantage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultPort is the TCP port of the WeatherLink IP data logger.
+const DefaultPort = "22222"
+
+// DefaultInterval is the default interval at which consoles are polled.
+const DefaultInterval = 30 * time.Second
+
+// DefaultRainClick is the default size of a rain collector tip, in inches.
+const DefaultRainClick = 0.01
+
+// LoopSize is the size of a LOOP packet, including its CRC.
+const LoopSize = 99
+
+const (
+	// ack is the response to a command that was accepted.
+	ack = 0x06
+
+	// wakeAttempts is the number of attempts to wake up the console.
+	wakeAttempts = 3
+
+	// wakeTimeout is the time to wait for the console to wake up.
+	wakeTimeout = 1200 * time.Millisecond
+
+	// requestTimeout is the maximum duration of a poll.
+	requestTimeout = 10 * time.Second
+)
+
+// Dashed values reported by the console for sensors that are not present.
+const (
+	dashedTemp     = 32767
+	dashedByte     = 255
+	dashedSolar    = 32767
+	dashedRainRate = 65535
+	dashedDate     = 0xFFFF
+)
+
+var (
+	errBadHeader = errors.New("invalid LOOP packet header")
+	errBadCRC    = errors.New("invalid LOOP packet CRC")
+)
+
+// Loop is the current conditions reported by a console in a LOOP packet, in
+// the console's units. Values of sensors that are not present are nil. Rain is
+// counted in rain collector tips ("clicks").
+type Loop struct {
+	Barometer       *float64 // inHg
+	InsideTemp      *float64 // °F
+	InsideHumidity  *float64 // %
+	OutsideTemp     *float64 // °F
+	OutsideHumidity *float64 // %
+	WindSpeed       *float64 // mph
+	AvgWindSpeed    *float64 // mph, 10 minute average
+	WindDir         *float64 // degrees
+	ExtraTemps      [7]*float64
+	ExtraHumidities [7]*float64
+	RainRate        *float64 // clicks per hour
+	UV              *float64
+	SolarRadiation  *float64 // W/m²
+	StormRain       float64  // clicks
+	StormStart      time.Time
+	DayRain         float64 // clicks
+}
+
+// ParseLoop parses a LOOP packet.
+func ParseLoop(b []byte) (Loop, error) {
+	if len(b) != LoopSize || !bytes.HasPrefix(b, []byte("LOO")) || b[4] != 0 {
+		return Loop{}, errBadHeader
+	}
+	if crc16(b) != 0 {
+		return Loop{}, errBadCRC
+	}
+
+	u16 := func(off int) uint16 { return uint16(b[off]) | uint16(b[off+1])<<8 }
+	i16 := func(off int) int16 { return int16(u16(off)) }
+	val := func(v float64) *float64 { return &v }
+	temp := func(off int) *float64 {
+		if v := i16(off); v != dashedTemp && v != -dashedTemp-1 {
+			return val(float64(v) / 10)
+		}
+		return nil
+	}
+	byteVal := func(off int, offset, scale float64) *float64 {
+		if b[off] != dashedByte {
+			return val((float64(b[off]) + offset) / scale)
+		}
+		return nil
+	}
+
+	var l Loop
+	if v := u16(7); v != 0 {
+		l.Barometer = val(float64(v) / 1000)
+	}
+	l.InsideTemp = temp(9)
+	l.InsideHumidity = byteVal(11, 0, 1)
+	l.OutsideTemp = temp(12)
+	l.WindSpeed = byteVal(14, 0, 1)
+	l.AvgWindSpeed = byteVal(15, 0, 1)
+	if v := u16(16); v > 0 && v <= 360 {
+		l.WindDir = val(float64(v % 360))
+	}
+	for i := range l.ExtraTemps {
+		l.ExtraTemps[i] = byteVal(18+i, -90, 1)
+		l.ExtraHumidities[i] = byteVal(34+i, 0, 1)
+	}
+	l.OutsideHumidity = byteVal(33, 0, 1)
+	if v := u16(41); v != dashedRainRate {
+		l.RainRate = val(float64(v))
+	}
+	l.UV = byteVal(43, 0, 10)
+	if v := u16(44); v != dashedSolar {
+		l.SolarRadiation = val(float64(v))
+	}
+	l.StormRain = float64(u16(46))
+	if v := u16(48); v != dashedDate && v != 0 {
+		month, day, year := int(v>>12), int(v>>7&0x1F), int(v&0x7F)+2000
+		l.StormStart = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+	l.DayRain = float64(u16(50))
+	return l, nil
+}
+
+// Query returns the conditions as the query parameters of a WU submission.
+// rainClick is the size of a rain collector tip in inches.
+func (l Loop) Query(rainClick float64) url.Values {
+	q := make(url.Values)
+	set := func(param string, v *float64) {
+		if v != nil {
+			q.Set(param, strconv.FormatFloat(*v, 'f', -1, 64))
+		}
+	}
+	rain := func(clicks float64) string {
+		return strconv.FormatFloat(clicks*rainClick, 'f', 4, 64)
+	}
+	q.Set("action", "updateraww")
+	q.Set("dateutc", "now")
+	set("baromin", l.Barometer)
+	set("indoortempf", l.InsideTemp)
+	set("indoorhumidity", l.InsideHumidity)
+	set("tempf", l.OutsideTemp)
+	set("humidity", l.OutsideHumidity)
+	set("windspeedmph", l.WindSpeed)
+	set("windspdmph_avg10m", l.AvgWindSpeed)
+	set("winddir", l.WindDir)
+	for i := range l.ExtraTemps {
+		set("temp"+strconv.Itoa(i+1)+"f", l.ExtraTemps[i])
+		set("humidity"+strconv.Itoa(i+1), l.ExtraHumidities[i])
+	}
+	// The rain rate is the rain over the last hour at the current rate.
+	if l.RainRate != nil {
+		q.Set("rainin", rain(*l.RainRate))
+	}
+	q.Set("dailyrainin", rain(l.DayRain))
+	set("UV", l.UV)
+	set("solarradiation", l.SolarRadiation)
+	if !l.StormStart.IsZero() {
+		q.Set("stormrainin", rain(l.StormRain))
+		q.Set("stormstart", l.StormStart.Format("2006-01-02"))
+	}
+	return q
+}
+
+// crc16 returns the CRC-CCITT of b, as used by the Vantage serial protocol.
+// The CRC of a packet including its CRC is zero.
+func crc16(b []byte) uint16 {
+	var crc uint16
+	for _, c := range b {
+		crc ^= uint16(c) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// Client is a client of a WeatherLink IP data logger.
+type Client struct {
+	// Address is the host and port of the data logger. If the port is
+	// omitted, DefaultPort is used.
+	Address string
+}
+
+// Loop returns the current conditions reported by the console.
+//
+// The data logger only accepts a single connection at a time, so a new
+// connection is made for each poll, and closed once the LOOP packet has been
+// received.
+func (c *Client) Loop(ctx context.Context) (Loop, error) {
+	addr := c.Address
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, DefaultPort)
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return Loop{}, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := wake(conn); err != nil {
+		return Loop{}, err
+	}
+	if _, err := io.WriteString(conn, "LOOP 1\n"); err != nil {
+		return Loop{}, fmt.Errorf("send LOOP: %w", err)
+	}
+	b := make([]byte, 1+LoopSize)
+	if _, err := io.ReadFull(conn, b[:1]); err != nil {
+		return Loop{}, fmt.Errorf("read LOOP response: %w", err)
+	}
+	if b[0] != ack {
+		return Loop{}, fmt.Errorf("LOOP not acknowledged (0x%02x)", b[0])
+	}
+	if _, err := io.ReadFull(conn, b[1:]); err != nil {
+		return Loop{}, fmt.Errorf("read LOOP packet: %w", err)
+	}
+	return ParseLoop(b[1:])
+}
+
+// wake wakes up the console, which responds to a line feed with a line feed
+// and carriage return once it is awake.
+func wake(conn net.Conn) error {
+	resp := make([]byte, 2)
+	for range wakeAttempts {
+		if _, err := io.WriteString(conn, "\n"); err != nil {
+			return fmt.Errorf("wake console: %w", err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(wakeTimeout))
+		_, err := io.ReadFull(conn, resp)
+		if err == nil && string(resp) == "\n\r" {
+			return conn.SetReadDeadline(time.Time{})
+		}
+		var ne net.Error
+		if err != nil && !(errors.As(err, &ne) && ne.Timeout()) {
+			return fmt.Errorf("wake console: %w", err)
+		}
+	}
+	return errors.New("console did not wake up")
+}
+
+// Logger is a data logger that is polled.
+type Logger struct {
+	// StationID is the station ID of the console's observations.
+	StationID string
+
+	// Client is the client of the data logger.
+	Client *Client
+
+	// RainClick is the size of a rain collector tip in inches. Defaults to
+	// DefaultRainClick.
+	RainClick float64
+}
+
+// Poller periodically polls the current conditions of consoles, and calls a
+// handler with each observation.
+type Poller struct {
+	loggers  []Logger
+	interval time.Duration
+	handler  func(ctx context.Context, l Logger, q url.Values)
+
+	up          *prometheus.GaugeVec
+	lastSuccess *prometheus.GaugeVec
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPoller returns a new poller for the data loggers, and starts polling them
+// in the background every interval. If interval is zero, DefaultInterval is
+// used. The handler is called with the data logger and conditions of each
+// console, as the query parameters of a WU submission. The poll state of each
+// station is exported as metrics on reg. The poller must be closed once it is
+// no longer used.
+func NewPoller(loggers []Logger, interval time.Duration, handler func(ctx context.Context, l Logger, q url.Values), reg prometheus.Registerer) *Poller {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Poller{
+		loggers:  loggers,
+		interval: interval,
+		handler:  handler,
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "weatherlink_ip",
+			Name:      "up",
+			Help:      "Whether the last poll of the station's WeatherLink IP data logger succeeded (1) or not (0)",
+		}, []string{"station_id"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "weatherlink_ip",
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Time of the last successful poll of the station's WeatherLink IP data logger",
+		}, []string{"station_id"}),
+		cancel: cancel,
+	}
+	reg.MustRegister(p.up, p.lastSuccess)
+	for _, l := range loggers {
+		p.up.WithLabelValues(l.StationID).Set(0)
+	}
+	p.wg.Add(1)
+	go p.run(ctx)
+	return p
+}
+
+// Close stops polling the data loggers.
+func (p *Poller) Close() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// run polls the data loggers every interval until ctx is done.
+func (p *Poller) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		for _, l := range p.loggers {
+			if ctx.Err() != nil {
+				return
+			}
+			p.poll(ctx, l)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll polls a data logger, and handles the console's conditions.
+func (p *Poller) poll(ctx context.Context, l Logger) {
+	loop, err := l.Client.Loop(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("Failed to poll WeatherLink IP data logger",
+			slog.String("station_id", l.StationID),
+			slog.String("address", l.Client.Address),
+			slog.Any("err", err))
+		p.up.WithLabelValues(l.StationID).Set(0)
+		return
+	}
+	p.up.WithLabelValues(l.StationID).Set(1)
+	p.lastSuccess.WithLabelValues(l.StationID).SetToCurrentTime()
+	rainClick := l.RainClick
+	if rainClick <= 0 {
+		rainClick = DefaultRainClick
+	}
+	p.handler(ctx, l, loop.Query(rainClick))
+}
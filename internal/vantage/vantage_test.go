@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package vantage
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// testLoop returns a LOOP packet with a valid CRC.
+func testLoop() []byte {
+	b := make([]byte, LoopSize)
+	copy(b, "LOO")
+	put := func(off int, v uint16) { b[off], b[off+1] = byte(v), byte(v>>8) }
+	put(7, 30012)   // Barometer
+	put(9, 712)     // Inside temperature
+	b[11] = 45      // Inside humidity
+	put(12, 0xFFEC) // Outside temperature (-2.0°F)
+	b[14] = 7       // Wind speed
+	b[15] = 5       // 10 minute average wind speed
+	put(16, 360)    // Wind direction
+	// Extra temperatures and humidities
+	for i := range 7 {
+		b[18+i], b[34+i] = dashedByte, dashedByte
+	}
+	b[18] = 150              // Extra temperature 1 (60°F)
+	b[34] = 80               // Extra humidity 1
+	b[33] = 93               // Outside humidity
+	put(41, 24)              // Rain rate
+	b[43] = 35               // UV
+	put(44, dashedSolar)     // Solar radiation
+	put(46, 57)              // Storm rain
+	put(48, 10<<12|15<<7|25) // Storm start (2025-10-15)
+	put(50, 42)              // Day rain
+	b[95], b[96] = '\n', '\r'
+	crc := crc16(b[:LoopSize-2])
+	b[97], b[98] = byte(crc>>8), byte(crc)
+	return b
+}
+
+func TestParseLoop(t *testing.T) {
+	l, err := ParseLoop(testLoop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := l.Query(DefaultRainClick)
+	for param, want := range map[string]string{
+		"baromin":        "30.012",
+		"indoortempf":    "71.2",
+		"indoorhumidity": "45",
+		"tempf":          "-2",
+		"humidity":       "93",
+		"windspeedmph":   "7",
+		"winddir":        "0",
+		"temp1f":         "60",
+		"humidity1":      "80",
+		"rainin":         "0.2400",
+		"dailyrainin":    "0.4200",
+		"stormrainin":    "0.5700",
+		"stormstart":     "2025-10-15",
+		"UV":             "3.5",
+	} {
+		if got := q.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+	for _, param := range []string{"solarradiation", "temp2f", "humidity2"} {
+		if q.Has(param) {
+			t.Errorf("%s = %q, want unset", param, q.Get(param))
+		}
+	}
+
+	if got := l.Query(0.2 / 25.4).Get("dailyrainin"); got != "0.3307" {
+		t.Errorf("metric dailyrainin = %q, want %q", got, "0.3307")
+	}
+
+	b := testLoop()
+	b[12]++
+	if _, err := ParseLoop(b); !errors.Is(err, errBadCRC) {
+		t.Errorf("corrupted packet error = %v, want %v", err, errBadCRC)
+	}
+	if _, err := ParseLoop(b[:50]); !errors.Is(err, errBadHeader) {
+		t.Errorf("short packet error = %v, want %v", err, errBadHeader)
+	}
+}
+
+// serveLogger serves a fake data logger on a random port, and returns its
+// address.
+func serveLogger(t *testing.T, loop []byte) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch line {
+					case "\n":
+						_, _ = conn.Write([]byte("\n\r"))
+					case "LOOP 1\n":
+						_, _ = conn.Write(append([]byte{ack}, loop...))
+					default:
+						_, _ = conn.Write([]byte{0x21})
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestLoop(t *testing.T) {
+	c := &Client{Address: serveLogger(t, testLoop())}
+	l, err := c.Loop(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.OutsideTemp == nil || *l.OutsideTemp != -2 {
+		t.Errorf("outside temperature = %v, want -2", l.OutsideTemp)
+	}
+
+	b := testLoop()
+	b[97]++
+	c = &Client{Address: serveLogger(t, b)}
+	if _, err := c.Loop(context.Background()); !errors.Is(err, errBadCRC) {
+		t.Errorf("corrupted packet error = %v, want %v", err, errBadCRC)
+	}
+}
+
+func TestPoller(t *testing.T) {
+	// Reserve a port that nothing listens on.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := ln.Addr().String()
+	_ = ln.Close()
+
+	type observation struct {
+		stationID string
+		q         url.Values
+	}
+	observations := make(chan observation, 10)
+	reg := prometheus.NewRegistry()
+	p := NewPoller([]Logger{
+		{StationID: "vantage", Client: &Client{Address: serveLogger(t, testLoop())}},
+		{StationID: "broken", Client: &Client{Address: closed}},
+	}, 50*time.Millisecond, func(_ context.Context, l Logger, q url.Values) {
+		observations <- observation{l.StationID, q}
+	}, reg)
+	defer p.Close()
+
+	o := <-observations
+	if o.stationID != "vantage" || o.q.Get("tempf") != "-2" {
+		t.Errorf("observation = %+v, want vantage with tempf=-2", o)
+	}
+	<-observations
+
+	if got := testutil.ToFloat64(p.up.WithLabelValues("vantage")); got != 1 {
+		t.Errorf("up = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(p.up.WithLabelValues("broken")); got != 0 {
+		t.Errorf("broken logger up = %v, want 0", got)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package weewx implements a bridge that forwards Weather Underground (WU)
+// submissions to the WeeWX interceptor driver, allowing WeeWX to consume the
+// data intercepted by the exporter.
+package weewx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// submissionPath is the WU submission path, which the interceptor driver
+// expects submissions to be sent to.
+const submissionPath = "/weatherstation/updateweatherstation.php"
+
+// queueSize is the maximum number of submissions waiting to be forwarded.
+const queueSize = 256
+
+// forwardTimeout is the maximum time to wait for a submission to be
+// forwarded.
+const forwardTimeout = 10 * time.Second
+
+// Bridge forwards raw WU submissions to a WeeWX interceptor driver listening
+// on a TCP port or a unix socket. Submissions are forwarded in the
+// background, in the order they were received.
+type Bridge struct {
+	url   string
+	hc    *http.Client
+	queue chan string
+	done  chan struct{}
+}
+
+// NewBridge returns a new bridge to the interceptor driver listening on
+// address, which is either a host:port or a unix socket path prefixed with
+// "unix:". The bridge must be closed once it is no longer used.
+func NewBridge(address string) (*Bridge, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	host := address
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		if path == "" {
+			return nil, fmt.Errorf("invalid weewx address %q", address)
+		}
+		var d net.Dialer
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", path)
+		}
+		host = "localhost"
+	} else if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid weewx address %q: %w", address, err)
+	}
+
+	b := &Bridge{
+		url:   "http://" + host + submissionPath,
+		hc:    &http.Client{Transport: transport, Timeout: forwardTimeout},
+		queue: make(chan string, queueSize),
+		done:  make(chan struct{}),
+	}
+	go b.run()
+	return b, nil
+}
+
+// Forward queues a raw submission query string to be forwarded. If the queue
+// is full, the submission is dropped and false is returned.
+func (b *Bridge) Forward(rawQuery string) bool {
+	select {
+	case b.queue <- rawQuery:
+		return true
+	default:
+		return false
+	}
+}
+
+// run forwards queued submissions until the bridge is closed.
+func (b *Bridge) run() {
+	defer close(b.done)
+	for rawQuery := range b.queue {
+		if err := b.send(rawQuery); err != nil {
+			slog.Error("Failed to forward submission to WeeWX", slog.Any("err", err))
+		}
+	}
+}
+
+// send sends a submission to the interceptor driver.
+func (b *Bridge) send(rawQuery string) error {
+	req, err := http.NewRequest(http.MethodGet, b.url+"?"+rawQuery, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "pws_exporter")
+
+	res, err := b.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+	return nil
+}
+
+// Close waits for queued submissions to be forwarded, until ctx is done.
+func (b *Bridge) Close(ctx context.Context) error {
+	close(b.queue)
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
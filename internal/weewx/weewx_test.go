@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package weewx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestBridge(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != submissionPath {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		got = append(got, r.URL.RawQuery)
+		mu.Unlock()
+		_, _ = w.Write([]byte("success\n"))
+	})
+
+	tcp := httptest.NewServer(handler)
+	defer tcp.Close()
+
+	sock := filepath.Join(t.TempDir(), "weewx.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unix := httptest.NewUnstartedServer(handler)
+	unix.Listener = l
+	unix.Start()
+	defer unix.Close()
+
+	for _, address := range []string{tcp.Listener.Addr().String(), "unix:" + sock} {
+		t.Run(address, func(t *testing.T) {
+			got = nil
+			b, err := NewBridge(address)
+			if err != nil {
+				t.Fatalf("new bridge: %v", err)
+			}
+			queries := []string{"ID=a&tempf=63.5", "ID=a&tempf=64.1"}
+			for _, q := range queries {
+				if !b.Forward(q) {
+					t.Fatalf("forward %q: queue full", q)
+				}
+			}
+			if err := b.Close(context.Background()); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(got) != len(queries) || got[0] != queries[0] || got[1] != queries[1] {
+				t.Errorf("got %v, want %v", got, queries)
+			}
+		})
+	}
+
+	for _, address := range []string{"localhost", "unix:"} {
+		if _, err := NewBridge(address); err == nil {
+			t.Errorf("NewBridge(%q): expected error", address)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package wuapi implements polling the current observations of personal
+// weather stations from the Weather Underground (weather.com) PWS API, so that
+// stations that upload directly to WU can be exported without intercepting
+// their submissions.
+package wuapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultURL is the URL of the current observations endpoint of the PWS API.
+const DefaultURL = "https://api.weather.com/v2/pws/observations/current"
+
+// DefaultInterval is the default interval at which stations are polled. The
+// PWS API is limited to 1500 requests per day and 30 requests per minute for
+// each API key, which allows polling 5 stations every 5 minutes.
+const DefaultInterval = 5 * time.Minute
+
+// requestTimeout is the maximum duration of a request to the API.
+const requestTimeout = 30 * time.Second
+
+// errNoObservation is returned when the station has not reported an
+// observation recently, e.g. because it is offline.
+var errNoObservation = errors.New("no recent observation")
+
+// Observation is the current observation of a station, in imperial units.
+// Fields that the station does not report are nil.
+type Observation struct {
+	StationID      string   `json:"stationID"`
+	ObsTimeUTC     string   `json:"obsTimeUtc"`
+	Epoch          int64    `json:"epoch"`
+	SoftwareType   string   `json:"softwareType"`
+	SolarRadiation *float64 `json:"solarRadiation"`
+	UV             *float64 `json:"uv"`
+	WindDir        *float64 `json:"winddir"`
+	Humidity       *float64 `json:"humidity"`
+	Imperial       struct {
+		Temp        *float64 `json:"temp"`
+		DewPoint    *float64 `json:"dewpt"`
+		WindSpeed   *float64 `json:"windSpeed"`
+		WindGust    *float64 `json:"windGust"`
+		Pressure    *float64 `json:"pressure"`
+		PrecipRate  *float64 `json:"precipRate"`
+		PrecipTotal *float64 `json:"precipTotal"`
+	} `json:"imperial"`
+}
+
+// Query returns the observation as the query parameters of a WU submission.
+func (o Observation) Query() url.Values {
+	q := make(url.Values)
+	set := func(param string, v *float64) {
+		if v != nil {
+			q.Set(param, strconv.FormatFloat(*v, 'f', -1, 64))
+		}
+	}
+	q.Set("ID", o.StationID)
+	q.Set("action", "updateraww")
+	q.Set("dateutc", time.Unix(o.Epoch, 0).UTC().Format("2006-01-02 15:04:05"))
+	if o.SoftwareType != "" {
+		q.Set("softwaretype", o.SoftwareType)
+	}
+	set("tempf", o.Imperial.Temp)
+	set("dewptf", o.Imperial.DewPoint)
+	set("humidity", o.Humidity)
+	set("winddir", o.WindDir)
+	set("windspeedmph", o.Imperial.WindSpeed)
+	set("windgustmph", o.Imperial.WindGust)
+	set("baromin", o.Imperial.Pressure)
+	// The precipitation rate is the rain rate over the last hour.
+	set("rainin", o.Imperial.PrecipRate)
+	set("dailyrainin", o.Imperial.PrecipTotal)
+	set("solarradiation", o.SolarRadiation)
+	set("UV", o.UV)
+	return q
+}
+
+// Client is a client of the PWS API.
+type Client struct {
+	// APIKey is the weather.com API key, which is available to WU users that
+	// upload data from a station.
+	APIKey string
+
+	// URL is the URL of the current observations endpoint. Defaults to
+	// DefaultURL.
+	URL string
+
+	// HTTPClient is the HTTP client used to make requests. Defaults to a
+	// client with a 30 second timeout.
+	HTTPClient *http.Client
+}
+
+// Current returns the current observation of a station.
+func (c *Client) Current(ctx context.Context, stationID string) (Observation, error) {
+	u := c.URL
+	if u == "" {
+		u = DefaultURL
+	}
+	q := url.Values{
+		"stationId":        {stationID},
+		"format":           {"json"},
+		"units":            {"e"},
+		"numericPrecision": {"decimal"},
+		"apiKey":           {c.APIKey},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"?"+q.Encode(), nil)
+	if err != nil {
+		return Observation{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: requestTimeout}
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		// The error contains the URL, which contains the API key.
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err
+		}
+		return Observation{}, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return Observation{}, errNoObservation
+	default:
+		return Observation{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		Observations []Observation `json:"observations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Observation{}, fmt.Errorf("decode response: %w", err)
+	}
+	if len(body.Observations) == 0 {
+		return Observation{}, errNoObservation
+	}
+	return body.Observations[0], nil
+}
+
+// Poller periodically polls the current observations of stations, and calls
+// a handler with each new observation.
+type Poller struct {
+	client   *Client
+	stations []string
+	interval time.Duration
+	handler  func(ctx context.Context, o Observation)
+
+	up          *prometheus.GaugeVec
+	lastSuccess *prometheus.GaugeVec
+
+	// last is the time of the last observation of each station, which is
+	// not handled again. Only used by the polling goroutine.
+	last map[string]int64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPoller returns a new poller for the stations, and starts polling them in
+// the background every interval. If interval is zero, DefaultInterval is
+// used. The handler is called with each observation that is newer than the
+// station's previous observation. The poll state of each station is exported
+// as metrics on reg. The poller must be closed once it is no longer used.
+func NewPoller(client *Client, stations []string, interval time.Duration, handler func(ctx context.Context, o Observation), reg prometheus.Registerer) *Poller {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Poller{
+		client:   client,
+		stations: stations,
+		interval: interval,
+		handler:  handler,
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "wu_api",
+			Name:      "up",
+			Help:      "Whether the last poll of the station from the WU API succeeded (1) or not (0)",
+		}, []string{"station_id"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "wu_api",
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Time of the last successful poll of the station from the WU API",
+		}, []string{"station_id"}),
+		last:   make(map[string]int64),
+		cancel: cancel,
+	}
+	reg.MustRegister(p.up, p.lastSuccess)
+	for _, s := range stations {
+		p.up.WithLabelValues(s).Set(0)
+	}
+	p.wg.Add(1)
+	go p.run(ctx)
+	return p
+}
+
+// Close stops polling the stations.
+func (p *Poller) Close() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// run polls the stations every interval until ctx is done. Stations are
+// polled one at a time, to stay within the API's rate limit.
+func (p *Poller) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		for _, s := range p.stations {
+			if ctx.Err() != nil {
+				return
+			}
+			p.poll(ctx, s)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll polls a station, and handles its observation if it is new.
+func (p *Poller) poll(ctx context.Context, stationID string) {
+	o, err := p.client.Current(ctx, stationID)
+	switch {
+	case errors.Is(err, errNoObservation):
+		slog.Debug("No recent observation from station in WU API",
+			slog.String("station_id", stationID))
+	case err != nil:
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("Failed to poll station from WU API",
+			slog.String("station_id", stationID), slog.Any("err", err))
+		p.up.WithLabelValues(stationID).Set(0)
+		return
+	}
+	p.up.WithLabelValues(stationID).Set(1)
+	p.lastSuccess.WithLabelValues(stationID).SetToCurrentTime()
+	if err != nil || o.Epoch <= p.last[stationID] {
+		return
+	}
+	p.last[stationID] = o.Epoch
+	if o.StationID == "" {
+		o.StationID = stationID
+	}
+	p.handler(ctx, o)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package wuapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const testResponse = `{"observations":[{"stationID":"KNCCARY89","obsTimeUtc":"2019-02-04T14:53:03Z",
+"obsTimeLocal":"2019-02-04 09:53:03","neighborhood":"Highcroft Village","softwareType":"GoWunder 1337.9041ac1",
+"country":"US","solarRadiation":436.0,"lon":-78.8759613,"realtimeFrequency":null,"epoch":1549291983,
+"lat":35.80221176,"uv":1.2,"winddir":329,"humidity":71,"qcStatus":1,"imperial":{"temp":53,"heatIndex":53,
+"dewpt":44,"windChill":53,"windSpeed":2,"windGust":null,"pressure":30.09,"precipRate":0.0,"precipTotal":0.12,
+"elev":413}}]}`
+
+func TestCurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("apiKey") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if q.Get("units") != "e" || q.Get("format") != "json" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		switch q.Get("stationId") {
+		case "KNCCARY89":
+			_, _ = w.Write([]byte(testResponse))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{APIKey: "key", URL: srv.URL}
+	o, err := c.Current(context.Background(), "KNCCARY89")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := o.Query()
+	for param, want := range map[string]string{
+		"ID":             "KNCCARY89",
+		"dateutc":        "2019-02-04 14:53:03",
+		"softwaretype":   "GoWunder 1337.9041ac1",
+		"tempf":          "53",
+		"dewptf":         "44",
+		"humidity":       "71",
+		"winddir":        "329",
+		"windspeedmph":   "2",
+		"baromin":        "30.09",
+		"rainin":         "0",
+		"dailyrainin":    "0.12",
+		"solarradiation": "436",
+		"UV":             "1.2",
+	} {
+		if got := q.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+	if q.Has("windgustmph") {
+		t.Errorf("windgustmph = %q, want unset", q.Get("windgustmph"))
+	}
+
+	if _, err := c.Current(context.Background(), "KOFFLINE1"); !errors.Is(err, errNoObservation) {
+		t.Errorf("offline station error = %v, want %v", err, errNoObservation)
+	}
+	c.APIKey = "invalid"
+	_, err = c.Current(context.Background(), "KNCCARY89")
+	if err == nil || strings.Contains(err.Error(), "invalid") {
+		t.Errorf("invalid API key error = %v, want error without API key", err)
+	}
+}
+
+func TestPoller(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		epoch = 1549291983
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("stationId") != "KNCCARY89" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		resp := strings.Replace(testResponse, `"epoch":1549291983`, `"epoch":`+strconv.Itoa(epoch), 1)
+		mu.Unlock()
+		_, _ = w.Write([]byte(resp))
+	}))
+	defer srv.Close()
+
+	observations := make(chan Observation, 10)
+	reg := prometheus.NewRegistry()
+	p := NewPoller(&Client{APIKey: "key", URL: srv.URL}, []string{"KNCCARY89", "KBROKEN1"}, 50*time.Millisecond,
+		func(_ context.Context, o Observation) {
+			observations <- o
+		}, reg)
+	defer p.Close()
+
+	o := <-observations
+	if o.Epoch != 1549291983 {
+		t.Errorf("epoch = %d, want 1549291983", o.Epoch)
+	}
+
+	// The same observation is not handled again.
+	select {
+	case o := <-observations:
+		t.Fatalf("unexpected repeated observation %+v", o)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	mu.Lock()
+	epoch += 60
+	mu.Unlock()
+	if o := <-observations; o.Epoch != 1549292043 {
+		t.Errorf("epoch = %d, want 1549292043", o.Epoch)
+	}
+
+	if got := testutil.ToFloat64(p.up.WithLabelValues("KNCCARY89")); got != 1 {
+		t.Errorf("up = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(p.up.WithLabelValues("KBROKEN1")); got != 0 {
+		t.Errorf("broken station up = %v, want 0", got)
+	}
+}
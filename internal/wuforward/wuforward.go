@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package wuforward forwards Weather Underground (WU) submissions received by
+// the exporter to one or more WU station IDs, rewriting the station ID and
+// key of each submission.
+package wuforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WU submission URLs. RapidFire submissions are sent to the real-time URL.
+const (
+	DefaultURL          = "https://weatherstation.wunderground.com/weatherstation/updateweatherstation.php"
+	DefaultRapidFireURL = "https://rtupdate.wunderground.com/weatherstation/updateweatherstation.php"
+)
+
+// queueSize is the maximum number of submissions waiting to be forwarded.
+const queueSize = 256
+
+// forwardTimeout is the maximum time to wait for a submission to be
+// forwarded.
+const forwardTimeout = 10 * time.Second
+
+// Target is a WU station that submissions are forwarded to.
+type Target struct {
+	// ID and Password are the WU station ID and key.
+	ID       string
+	Password string
+
+	// URL is the submission URL. If empty, DefaultURL is used, or
+	// DefaultRapidFireURL for RapidFire submissions.
+	URL string
+}
+
+// submission is a submission waiting to be forwarded to a target.
+type submission struct {
+	stationID string
+	target    Target
+	query     url.Values
+}
+
+// Forwarder forwards WU submissions from stations to their targets.
+// Submissions are forwarded in the background, in the order they were
+// received.
+type Forwarder struct {
+	targets map[string][]Target
+	hc      *http.Client
+	queue   chan submission
+	done    chan struct{}
+}
+
+// NewForwarder returns a new forwarder, which forwards the submissions of
+// each station ID in targets to the station's targets. The forwarder must be
+// closed once it is no longer used.
+func NewForwarder(targets map[string][]Target) *Forwarder {
+	f := &Forwarder{
+		targets: targets,
+		hc:      &http.Client{Timeout: forwardTimeout},
+		queue:   make(chan submission, queueSize),
+		done:    make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// Forward queues a raw submission query string from a station to be
+// forwarded to the station's targets. It returns the number of targets the
+// submission was dropped for because the queue is full.
+func (f *Forwarder) Forward(stationID, rawQuery string) int {
+	targets := f.targets[stationID]
+	if len(targets) == 0 {
+		return 0
+	}
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return 0
+	}
+
+	var dropped int
+	for _, t := range targets {
+		select {
+		case f.queue <- submission{stationID: stationID, target: t, query: q}:
+		default:
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// run forwards queued submissions until the forwarder is closed.
+func (f *Forwarder) run() {
+	defer close(f.done)
+	for s := range f.queue {
+		if err := f.send(s); err != nil {
+			slog.Error("Failed to forward submission to WU",
+				slog.String("station_id", s.stationID),
+				slog.String("target_id", s.target.ID),
+				slog.Any("err", err))
+		}
+	}
+}
+
+// send sends a submission to its target, with the station ID and key
+// rewritten.
+func (f *Forwarder) send(s submission) error {
+	q := make(url.Values, len(s.query))
+	for k, v := range s.query {
+		q[k] = v
+	}
+	q.Set("ID", s.target.ID)
+	q.Set("PASSWORD", s.target.Password)
+
+	u := s.target.URL
+	if u == "" {
+		u = DefaultURL
+		if q.Get("realtime") == "1" {
+			u = DefaultRapidFireURL
+		}
+	}
+	req, err := http.NewRequest(http.MethodGet, u+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "pws_exporter")
+
+	res, err := f.hc.Do(req)
+	if err != nil {
+		// Avoid logging the station key, which is part of the URL.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = u
+		}
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+	return nil
+}
+
+// Close waits for queued submissions to be forwarded, until ctx is done.
+func (f *Forwarder) Close(ctx context.Context) error {
+	close(f.queue)
+	select {
+	case <-f.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package wuforward
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestForwarder(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		got []url.Values
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = append(got, r.URL.Query())
+		mu.Unlock()
+		_, _ = w.Write([]byte("success\n"))
+	}))
+	defer srv.Close()
+
+	f := NewForwarder(map[string][]Target{
+		"KOLD1": {
+			{ID: "KNEW1", Password: "key1", URL: srv.URL},
+			{ID: "KMIRROR1", Password: "key2", URL: srv.URL},
+		},
+	})
+	if dropped := f.Forward("KOLD1", "ID=KOLD1&PASSWORD=old&tempf=63.5"); dropped != 0 {
+		t.Errorf("dropped %d submissions", dropped)
+	}
+	f.Forward("KOTHER1", "ID=KOTHER1&PASSWORD=other&tempf=70")
+	if err := f.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d submissions, want 2: %v", len(got), got)
+	}
+	var ids []string
+	for _, q := range got {
+		ids = append(ids, q.Get("ID")+":"+q.Get("PASSWORD"))
+		if q.Get("tempf") != "63.5" {
+			t.Errorf("tempf = %q, want 63.5", q.Get("tempf"))
+		}
+	}
+	slices.Sort(ids)
+	if want := []string{"KMIRROR1:key2", "KNEW1:key1"}; !slices.Equal(ids, want) {
+		t.Errorf("got IDs %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,1462 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package config implements loading of the pws_exporter configuration file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the pws_exporter configuration file.
+type Config struct {
+	// Tenants isolates stations into separate registries, each with their own
+	// scrape credentials. Stations that do not belong to a tenant are exported
+	// on the default registry.
+	Tenants []Tenant `yaml:"tenants"`
+
+	// Stations are per-station settings, keyed by station ID.
+	Stations map[string]Station `yaml:"stations"`
+
+	// Sites group stations on one property, whose observations are
+	// aggregated into site-level metrics.
+	Sites []Site `yaml:"sites"`
+
+	// NormalizeStationIDs normalizes the IDs of submitting stations when
+	// submissions are received, to keep the station_id label cardinality
+	// bounded.
+	NormalizeStationIDs StationIDNormalization `yaml:"normalize_station_ids"`
+
+	// Relabel renames or drops exported metrics and rewrites station IDs when
+	// metrics are collected.
+	Relabel Relabel `yaml:"relabel"`
+
+	// Store is the observation store configuration.
+	Store Store `yaml:"store"`
+
+	// Admin is the admin API configuration.
+	Admin Admin `yaml:"admin"`
+
+	// Ingest is the ingestion API configuration.
+	Ingest Ingest `yaml:"ingest"`
+
+	// WUServer is the configuration of the WU HTTP and HTTPS servers.
+	WUServer HTTPServer `yaml:"wu_server"`
+
+	// Alerts is the alerting configuration.
+	Alerts Alerts `yaml:"alerts"`
+
+	// WUForward forwards the submissions of stations to Weather Underground,
+	// under one or more WU station IDs.
+	WUForward []WUForward `yaml:"wu_forward"`
+
+	// Upstreams re-submit the submissions of stations to upstream weather
+	// services, such as Weather Underground, PWSWeather, Windy and
+	// Weathercloud, or custom URLs.
+	Upstreams []Upstream `yaml:"upstreams"`
+
+	// OpenSenseMap uploads the observations of stations to openSenseMap
+	// senseBoxes.
+	OpenSenseMap []OpenSenseMap `yaml:"opensensemap"`
+
+	// Federation pulls the station metrics of remote exporters, which are
+	// exported with a site label.
+	Federation Federation `yaml:"federation"`
+
+	// GrafanaAnnotations writes Grafana annotations for weather events
+	// detected from the observations of stations.
+	GrafanaAnnotations GrafanaAnnotations `yaml:"grafana_annotations"`
+
+	// DNS adds records and forward domains to the DNS server.
+	DNS DNS `yaml:"dns"`
+
+	// RTL433 receives the events of rtl_433 sensors from an MQTT broker,
+	// which are submitted as observations of stations.
+	RTL433 RTL433 `yaml:"rtl_433"`
+
+	// MQTTInput receives weather data published to an MQTT broker as JSON,
+	// which is submitted as observations of stations.
+	MQTTInput MQTTInput `yaml:"mqtt_input"`
+
+	// WUAPI polls the current observations of stations that upload to
+	// Weather Underground from the WU PWS API.
+	WUAPI WUAPI `yaml:"wu_api"`
+
+	// WeatherLinkIP polls the current conditions of Davis Vantage consoles
+	// from WeatherLink IP data loggers.
+	WeatherLinkIP WeatherLinkIP `yaml:"weatherlink_ip"`
+}
+
+// DNSExporterAddress is the DNS record value that is replaced with the
+// exporter's address, as answered for the intercepted domains.
+const DNSExporterAddress = "exporter"
+
+// DNS is the additional configuration of the DNS server.
+type DNS struct {
+	// Records are additional domains answered by the DNS server, mapped to an
+	// IPv4 or IPv6 address, or to "exporter" for the exporter's addresses,
+	// e.g. to redirect the submissions of other weather services.
+	Records map[string]string `yaml:"records"`
+
+	// ForwardDomains are additional domains forwarded to the upstream
+	// resolver, e.g. time servers used by the weather station.
+	ForwardDomains []string `yaml:"forward_domains"`
+}
+
+// domainName matches a domain name, with an optional trailing dot.
+var domainName = regexp.MustCompile(`^([A-Za-z0-9_]([A-Za-z0-9_-]{0,61}[A-Za-z0-9_])?\.)*[A-Za-z0-9_]([A-Za-z0-9_-]{0,61}[A-Za-z0-9_])?\.?$`)
+
+// Validate checks the DNS configuration for errors.
+func (d *DNS) Validate() error {
+	for domain, value := range d.Records {
+		if domain == "" {
+			return errors.New("records: empty domain")
+		}
+		if !domainName.MatchString(domain) || len(domain) > 254 {
+			return fmt.Errorf("record %q: invalid domain name", domain)
+		}
+		if value != DNSExporterAddress && net.ParseIP(value) == nil {
+			return fmt.Errorf("record %q: invalid address %q", domain, value)
+		}
+	}
+	for _, domain := range d.ForwardDomains {
+		if domain == "" {
+			return errors.New("forward_domains: empty domain")
+		}
+		if !domainName.MatchString(domain) || len(domain) > 254 {
+			return fmt.Errorf("forward domain %q: invalid domain name", domain)
+		}
+		if _, ok := d.Records[domain]; ok {
+			return fmt.Errorf("domain %q is both a record and forwarded", domain)
+		}
+	}
+	return nil
+}
+
+// GrafanaAnnotations is the configuration of the Grafana annotation writer.
+type GrafanaAnnotations struct {
+	// URL is the Grafana URL, e.g. http://localhost:3000. Annotations are
+	// disabled if empty.
+	URL string `yaml:"url"`
+
+	// Token is a Grafana service account token with permission to create
+	// annotations.
+	Token string `yaml:"token"`
+
+	// DashboardUID is the UID of the dashboard annotations are added to. If
+	// empty, organization annotations are created.
+	DashboardUID string `yaml:"dashboard_uid"`
+
+	// Tags are added to every annotation, in addition to the station ID and
+	// the event.
+	Tags []string `yaml:"tags"`
+
+	// Events are the events that are annotated: rain, gust and offline. If
+	// empty, all events are annotated.
+	Events []string `yaml:"events"`
+
+	// Stations are the stations whose events are annotated. If empty, the
+	// events of all stations are annotated.
+	Stations []string `yaml:"stations"`
+
+	// GustThreshold is the minimum wind gust speed, in km/h, for a daily
+	// gust record to be annotated.
+	GustThreshold float64 `yaml:"gust_threshold"`
+
+	// RainStopAfter is the duration without rain after which rain is
+	// considered to have stopped. If zero, 30 minutes is used.
+	RainStopAfter time.Duration `yaml:"rain_stop_after"`
+}
+
+// Federation is the configuration of the remote exporters whose station
+// metrics are federated.
+type Federation struct {
+	// Interval is the interval at which sites are scraped. If zero, sites
+	// are scraped every 30 seconds.
+	Interval time.Duration `yaml:"interval"`
+
+	// Sites are the remote exporters.
+	Sites []FederationSite `yaml:"sites"`
+}
+
+// FederationSite is a remote exporter whose station metrics are federated.
+type FederationSite struct {
+	// Name is the value of the site label added to the site's metrics.
+	Name string `yaml:"name"`
+
+	// URL is the URL of the remote exporter's metrics endpoint, e.g.
+	// http://192.0.2.1:9452/metrics.
+	URL string `yaml:"url"`
+
+	// Username and Password are the HTTP basic authentication credentials
+	// used to scrape the remote exporter, e.g. tenant credentials.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// RTL433 is the configuration of the rtl_433 input, which subscribes to the
+// events published to MQTT by rtl_433.
+type RTL433 struct {
+	// MQTT is the MQTT broker that rtl_433 publishes events to. The input is
+	// disabled if no broker is set.
+	MQTT MQTT `yaml:"mqtt"`
+
+	// Topic is the topic filter subscribed to. Defaults to
+	// "rtl_433/+/events".
+	Topic string `yaml:"topic"`
+
+	// Devices map the sensors received by rtl_433 to stations. Events from
+	// other sensors are ignored.
+	Devices []RTL433Device `yaml:"devices"`
+}
+
+// RTL433Device maps an rtl_433 sensor to a station. Events are matched by the
+// model, id and channel reported by rtl_433, and the first matching device is
+// used.
+type RTL433Device struct {
+	// Model is the model of the sensor, e.g. "Fineoffset-WH65B".
+	Model string `yaml:"model"`
+
+	// ID and Channel are the ID and channel of the sensor. If empty, any ID
+	// or channel is matched.
+	ID      string `yaml:"id"`
+	Channel string `yaml:"channel"`
+
+	// Station is the ID of the station that the sensor belongs to.
+	Station string `yaml:"station"`
+
+	// Sensor is the role of the sensor in the station: "outdoor" (default),
+	// "indoor", or "channel1" to "channel8" for additional temperature and
+	// humidity sensors.
+	Sensor string `yaml:"sensor"`
+}
+
+// MQTTInput is the configuration of the MQTT input, which subscribes to
+// topics that weather data is published to as JSON.
+type MQTTInput struct {
+	// MQTT is the MQTT broker that weather data is published to. The input
+	// is disabled if no broker is set.
+	MQTT MQTT `yaml:"mqtt"`
+
+	// Subscriptions map the payloads of the messages published to topics to
+	// measurement fields. Messages are handled by the first subscription
+	// whose topic filter matches.
+	Subscriptions []MQTTSubscription `yaml:"subscriptions"`
+}
+
+// MQTTSubscription maps the JSON payloads of the messages published to a
+// topic to measurement fields.
+type MQTTSubscription struct {
+	// Topic is the topic filter subscribed to, which may contain the + and #
+	// wildcards.
+	Topic string `yaml:"topic"`
+
+	// The station that messages are submitted for is set by exactly one of:
+	// Station, the station ID; StationField, the path of the station ID in
+	// the payload; or StationTopicLevel, the 0-based level of the topic that
+	// is the station ID.
+	Station           string `yaml:"station"`
+	StationField      string `yaml:"station_field"`
+	StationTopicLevel *int   `yaml:"station_topic_level"`
+
+	// Units are the units of the published values, "metric" (default) or
+	// "imperial".
+	Units string `yaml:"units"`
+
+	// Fields maps measurement fields (e.g. "temperature") to the path of
+	// their value in the payload, a dot-separated list of object keys and
+	// array indexes, or "." for the whole payload.
+	Fields map[string]string `yaml:"fields"`
+}
+
+// WUAPI is the configuration of the WU PWS API poller.
+type WUAPI struct {
+	// APIKey is the weather.com API key, which is available to WU users
+	// that upload data from a station.
+	APIKey string `yaml:"api_key"`
+
+	// Stations are the WU station IDs that are polled.
+	Stations []string `yaml:"stations"`
+
+	// Interval is the interval at which stations are polled. If zero,
+	// stations are polled every 5 minutes.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// WeatherLinkIP is the configuration of the WeatherLink IP data logger
+// poller.
+type WeatherLinkIP struct {
+	// Loggers are the data loggers that are polled.
+	Loggers []WeatherLinkIPLogger `yaml:"loggers"`
+
+	// Interval is the interval at which data loggers are polled. If zero,
+	// data loggers are polled every 30 seconds.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// WeatherLinkIPLogger is the configuration of a WeatherLink IP data logger.
+type WeatherLinkIPLogger struct {
+	// Station is the station ID of the console's observations.
+	Station string `yaml:"station"`
+
+	// Address is the host and port of the data logger. If the port is
+	// omitted, port 22222 is used.
+	Address string `yaml:"address"`
+
+	// RainCollector is the size of the rain collector tips, one of "0.01in"
+	// (default), "0.2mm" or "0.1mm".
+	RainCollector string `yaml:"rain_collector"`
+}
+
+// RainClick returns the size of the rain collector tips in inches, or zero if
+// the rain collector is unknown.
+func (l WeatherLinkIPLogger) RainClick() float64 {
+	switch l.RainCollector {
+	case "", "0.01in":
+		return 0.01
+	case "0.2mm":
+		return 0.2 / 25.4
+	case "0.1mm":
+		return 0.1 / 25.4
+	}
+	return 0
+}
+
+// MQTT is the configuration of an MQTT broker connection.
+type MQTT struct {
+	// Broker is the URL of the broker, e.g. mqtt://localhost:1883. The
+	// mqtts:// scheme connects using TLS.
+	Broker string `yaml:"broker"`
+
+	// ClientID is the MQTT client identifier. If empty, a random identifier
+	// is used.
+	ClientID string `yaml:"client_id"`
+
+	// Username and Password are the credentials used to connect to the
+	// broker.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// Station is the configuration of a single station.
+type Station struct {
+	// Timezone is the IANA time zone of the station, e.g.
+	// "America/New_York". It is used for daily resets, such as the daily
+	// rain rollover and daily aggregates, and should match the time zone
+	// the station resets its daily totals in. If empty, UTC is used.
+	Timezone string `yaml:"timezone"`
+
+	// ReceiveTime ignores the observation time reported by the station and
+	// uses the time the exporter received the submission instead, for
+	// stations with firmware that reports a bogus time.
+	ReceiveTime bool `yaml:"receive_time"`
+
+	// ExpectedInterval is the interval the station is expected to submit
+	// at. A station is considered down once it has not submitted for twice
+	// its expected interval, which is exported as weather_station_up, used
+	// by offline alert rules without an after duration, and used to detect
+	// gaps in the observation store. If zero, the station is not watched.
+	ExpectedInterval time.Duration `yaml:"expected_interval"`
+
+	// Response overrides the response sent to the station for accepted
+	// submissions, for consoles that lock up unless they receive a specific
+	// response. If nil, the WU API response is sent.
+	Response *StationResponse `yaml:"response"`
+
+	// RapidFireResponse overrides the response sent to the station for
+	// accepted RapidFire (real-time) submissions. If nil, Response is used.
+	RapidFireResponse *StationResponse `yaml:"rapidfire_response"`
+
+	// RapidFireWindow downsamples the station's RapidFire (real-time)
+	// submissions, by aggregating the submissions received within each
+	// window into a single observation: the mean wind speed and direction,
+	// the highest wind gust, and the latest value of all other fields. If
+	// zero, each submission is processed.
+	RapidFireWindow time.Duration `yaml:"rapidfire_window"`
+
+	// DisabledFields are the names of the observation fields whose metrics
+	// are not exported for the station, e.g. "indoor_temperature", for
+	// readings that are private or from sensors that have been removed.
+	DisabledFields []string `yaml:"disabled_fields"`
+}
+
+// StationResponse is the HTTP response sent to a station for accepted
+// submissions.
+type StationResponse struct {
+	// Status is the HTTP status code. If zero, 200 is used.
+	Status int `yaml:"status"`
+
+	// Body is the response body.
+	Body string `yaml:"body"`
+}
+
+// Site is a named group of stations on one property.
+type Site struct {
+	// Name is the name of the site, used as the site label of the site-level
+	// metrics.
+	Name string `yaml:"name"`
+
+	// Stations are the IDs of the stations at the site. All stations must
+	// belong to the same tenant, or to no tenant.
+	Stations []string `yaml:"stations"`
+}
+
+// Location returns the time zone of the station, or nil if it is not set.
+func (s Station) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// OpenSenseMap uploads the observations of a station to an openSenseMap
+// senseBox.
+type OpenSenseMap struct {
+	// Station is the ID of the station whose observations are uploaded.
+	Station string `yaml:"station"`
+
+	// BoxID is the senseBox ID.
+	BoxID string `yaml:"box_id"`
+
+	// AccessToken is the senseBox access token, required if the senseBox
+	// has authentication enabled.
+	AccessToken string `yaml:"access_token"`
+
+	// Sensors maps observation field names, as used by the JSON API, to the
+	// senseBox sensor IDs they are uploaded as. Values are uploaded in
+	// metric units.
+	Sensors map[string]string `yaml:"sensors"`
+}
+
+// WUForward forwards the submissions of a station to Weather Underground.
+type WUForward struct {
+	// Station is the ID of the station whose submissions are forwarded.
+	Station string `yaml:"station"`
+
+	// Targets are the WU stations the submissions are forwarded to.
+	Targets []WUTarget `yaml:"targets"`
+}
+
+// WUTarget is a WU station that submissions are forwarded to.
+type WUTarget struct {
+	// ID and Password are the WU station ID and key. The station ID and
+	// password of forwarded submissions are replaced with them.
+	ID       string `yaml:"id"`
+	Password string `yaml:"password"`
+
+	// URL is the submission URL. If empty, the WU submission URL is used.
+	URL string `yaml:"url"`
+}
+
+// Upstream is an upstream weather service that submissions are re-submitted
+// to.
+type Upstream struct {
+	// Name is the name of the upstream, used in metrics and logs.
+	Name string `yaml:"name"`
+
+	// Service is the upstream service, one of "wu", "pwsweather", "windy",
+	// "weathercloud" or "custom".
+	Service string `yaml:"service"`
+
+	// Stations are the IDs of the stations whose submissions are sent to
+	// the upstream. If empty, the submissions of all stations are sent.
+	Stations []string `yaml:"stations"`
+
+	// ID and Password are the credentials of the station at the service:
+	// the station ID and key for WU and PWSWeather, the device ID and key
+	// for Weathercloud, and the station index and API key for Windy.
+	ID       string `yaml:"id"`
+	Password string `yaml:"password"`
+
+	// URL is the submission URL, which overrides the service's URL. For
+	// custom upstreams, it is a Go template of the URL.
+	URL string `yaml:"url"`
+
+	// Interval is the minimum interval between the submissions of a
+	// station. If zero, the service's limit is used, if any.
+	Interval time.Duration `yaml:"interval"`
+
+	// MaxAttempts is the maximum number of attempts to send a submission.
+	// If zero, submissions are attempted 3 times.
+	MaxAttempts int `yaml:"max_attempts"`
+
+	// Backoff is the delay before the first retry, which doubles with each
+	// retry. If zero, the first retry is after 5 seconds.
+	Backoff time.Duration `yaml:"backoff"`
+
+	// BufferFile is the path of the file that submissions are buffered in
+	// while the upstream cannot be reached, to be replayed once it recovers.
+	// If empty, submissions that fail are dropped.
+	BufferFile string `yaml:"buffer_file"`
+
+	// BufferSize is the maximum number of buffered submissions, after which
+	// the oldest are dropped. If zero, up to 10000 submissions are buffered.
+	BufferSize int `yaml:"buffer_size"`
+}
+
+// Alerts is the alerting configuration.
+type Alerts struct {
+	// Interval is the interval at which alert rules are evaluated. If zero,
+	// rules are evaluated every 15 seconds.
+	Interval time.Duration `yaml:"interval"`
+
+	// Channels are the named notification channels that rules send
+	// notifications to.
+	Channels map[string]Channel `yaml:"channels"`
+
+	// Rules are the alert rules.
+	Rules []AlertRule `yaml:"rules"`
+
+	// Silences are windows during which notifications are not sent for
+	// matching alerts.
+	Silences []Silence `yaml:"silences"`
+
+	// Offline is shorthand for an offline rule named "station_offline" with
+	// inline channels, which sends notifications when a station stops
+	// submitting and when it comes back online.
+	Offline *OfflineAlert `yaml:"offline"`
+}
+
+// OfflineAlert is the station offline alert shorthand configuration.
+type OfflineAlert struct {
+	// After is the duration since a station's last submission after which
+	// it is considered offline. If zero, stations are considered offline
+	// after twice their expected interval, and stations without an expected
+	// interval are not watched.
+	After time.Duration `yaml:"after"`
+
+	// Stations are the station IDs to watch. If empty, all stations are
+	// watched once they have submitted.
+	Stations []string `yaml:"stations"`
+
+	// Channels are the channels notifications are sent to.
+	Channels []Channel `yaml:"channels"`
+}
+
+// Channel is a notification channel.
+type Channel struct {
+	// Type is the channel type, one of "webhook", "discord", "telegram" or
+	// "smtp".
+	Type string `yaml:"type"`
+
+	// URL is the webhook URL, for the webhook and discord channels.
+	URL string `yaml:"url"`
+
+	// BotToken and ChatID are the bot token and the chat ID to send messages
+	// to, for the telegram channel.
+	BotToken string `yaml:"bot_token"`
+	ChatID   string `yaml:"chat_id"`
+
+	// Address is the SMTP server address in host:port form, Username and
+	// Password are the optional credentials, and From and To are the sender
+	// and recipient addresses, for the smtp channel.
+	Address  string   `yaml:"address"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// Alert rule types.
+const (
+	AlertTypeThreshold = "threshold"
+	AlertTypeOffline   = "offline"
+)
+
+// AlertRule is an alert rule.
+type AlertRule struct {
+	// Name is the name of the rule.
+	Name string `yaml:"name"`
+
+	// Type is the rule type. Threshold rules fire when an observation field
+	// breaches a threshold, and offline rules fire when a station has not
+	// submitted for longer than After. Defaults to "threshold".
+	Type string `yaml:"type"`
+
+	// Field is the name of the observation field, as used by the JSON API,
+	// e.g. "temperature". Values are compared in metric units.
+	Field string `yaml:"field"`
+
+	// Operator is the comparison operator, one of ">", ">=", "<" or "<=".
+	Operator string `yaml:"operator"`
+
+	// Threshold is the value the field is compared to.
+	Threshold float64 `yaml:"threshold"`
+
+	// For is the duration the threshold must be breached for before the
+	// alert fires.
+	For time.Duration `yaml:"for"`
+
+	// Hysteresis is how far the value must recover past the threshold before
+	// the alert is resolved.
+	Hysteresis float64 `yaml:"hysteresis"`
+
+	// After is the duration since a station's last submission after which
+	// an offline rule fires. If zero, the rule fires after twice the
+	// station's expected interval, and stations without an expected
+	// interval are not watched.
+	After time.Duration `yaml:"after"`
+
+	// Stations are the station IDs the rule applies to. If empty, the rule
+	// applies to all stations.
+	Stations []string `yaml:"stations"`
+
+	// Labels are added to the rule's notifications, and can be matched by
+	// silences.
+	Labels map[string]string `yaml:"labels"`
+
+	// Channels are the names of the channels notifications are sent to.
+	Channels []string `yaml:"channels"`
+
+	// Webhook is a URL that notifications are sent to as JSON POST
+	// requests, in addition to Channels.
+	Webhook string `yaml:"webhook"`
+}
+
+// Silence is a window during which notifications are not sent for matching
+// alerts. A silence is either a fixed window between Start and End, a
+// recurring window between the From and To times of day on Days, or both.
+type Silence struct {
+	// Name is the name of the silence.
+	Name string `yaml:"name"`
+
+	// Start and End bound the silence. Either may be omitted.
+	Start time.Time `yaml:"start"`
+	End   time.Time `yaml:"end"`
+
+	// Days are the days of the week of the recurring window, e.g. "sat". If
+	// empty, the window recurs every day.
+	Days []string `yaml:"days"`
+
+	// From and To are the start and end times of day of the recurring
+	// window in 24-hour "15:04" form, in the time zone of the exporter. If To
+	// is before From, the window ends on the next day. If omitted, the window
+	// is the whole day.
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+
+	// Rules, Stations and Labels restrict the silence to alerts for the
+	// rules, the stations and with the labels. If empty, the silence matches
+	// all alerts.
+	Rules    []string          `yaml:"rules"`
+	Stations []string          `yaml:"stations"`
+	Labels   map[string]string `yaml:"labels"`
+}
+
+// weekdays maps day names to weekdays.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Weekdays returns the days of the recurring window.
+func (s *Silence) Weekdays() ([]time.Weekday, error) {
+	days := make([]time.Weekday, 0, len(s.Days))
+	for _, d := range s.Days {
+		wd, ok := weekdays[strings.ToLower(d)]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", d)
+		}
+		days = append(days, wd)
+	}
+	return days, nil
+}
+
+// Window returns the start and end times of day of the recurring window, as
+// offsets since midnight.
+func (s *Silence) Window() (from, to time.Duration, err error) {
+	if (s.From == "") != (s.To == "") {
+		return 0, 0, errors.New("from and to must both be set")
+	}
+	if s.From == "" {
+		return 0, 0, nil
+	}
+	if from, err = parseTimeOfDay(s.From); err != nil {
+		return 0, 0, fmt.Errorf("from: %w", err)
+	}
+	if to, err = parseTimeOfDay(s.To); err != nil {
+		return 0, 0, fmt.Errorf("to: %w", err)
+	}
+	return from, to, nil
+}
+
+// parseTimeOfDay parses a "15:04" time of day as an offset since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// HTTPServer are the timeouts and limits of an HTTP server. Zero values use
+// the server defaults.
+type HTTPServer struct {
+	// ReadTimeout is the maximum duration for reading an entire request,
+	// including the TLS handshake and body.
+	ReadTimeout time.Duration `yaml:"read_timeout"`
+
+	// WriteTimeout is the maximum duration before timing out writes of the
+	// response.
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+
+	// IdleTimeout is the maximum duration to wait for the next request on a
+	// keep-alive connection.
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+
+	// MaxHeaderBytes is the maximum size of request headers.
+	MaxHeaderBytes int `yaml:"max_header_bytes"`
+
+	// MaxBodyBytes is the maximum size of request bodies.
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+
+	// MaxConnections is the maximum number of concurrent connections. New
+	// connections are not accepted until existing connections are closed.
+	MaxConnections int `yaml:"max_connections"`
+}
+
+// Admin is the admin API configuration. The admin API is disabled unless
+// credentials are configured.
+type Admin struct {
+	// Username and Password are the HTTP basic authentication credentials
+	// required to use the admin API.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// Enabled returns whether the admin API is enabled.
+func (a Admin) Enabled() bool {
+	return a.Username != "" && a.Password != ""
+}
+
+// Ingest is the ingestion API configuration. Measurements are only accepted
+// from clients that authenticate with a configured token, or, for the
+// stations of a tenant, with the tenant's credentials.
+type Ingest struct {
+	// Token is the bearer token that allows measurements to be submitted
+	// for any station.
+	Token string `yaml:"token"`
+
+	// StationTokens maps station IDs to bearer tokens that only allow
+	// measurements to be submitted for that station.
+	StationTokens map[string]string `yaml:"station_tokens"`
+}
+
+// Store is the observation store configuration.
+type Store struct {
+	// Downsample are rules used to replace old observations with aggregated
+	// observations at a lower resolution, e.g. keep raw observations for 30
+	// days, and 5-minute averages after that.
+	Downsample []DownsampleRule `yaml:"downsample"`
+}
+
+// DownsampleRule replaces observations older than After with aggregated
+// observations at the given Resolution.
+type DownsampleRule struct {
+	After      time.Duration `yaml:"after"`
+	Resolution time.Duration `yaml:"resolution"`
+}
+
+// Tenant is a group of stations whose metrics are only available to scrapes
+// authenticated with the tenant credentials.
+type Tenant struct {
+	// Name is the name of the tenant.
+	Name string `yaml:"name"`
+
+	// Username and Password are the HTTP basic authentication credentials
+	// required to scrape the tenant's metrics.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+
+	// Stations is a list of station IDs that belong to the tenant.
+	Stations []string `yaml:"stations"`
+}
+
+// Relabel is a mapping applied to exported metrics at collection time.
+type Relabel struct {
+	// Rename maps metric names to the name they should be exported as.
+	Rename map[string]string `yaml:"rename"`
+
+	// Drop is a list of metric names that should not be exported.
+	Drop []string `yaml:"drop"`
+
+	// StationIDs maps station IDs to the station_id label value they should be
+	// exported as, e.g. to replace cryptic IDs with friendly names.
+	StationIDs map[string]string `yaml:"station_ids"`
+}
+
+// StationIDNormalization normalizes the IDs of submitting stations. The
+// normalized ID is used everywhere the station ID is used, including the
+// stations, tenants and sites configuration.
+type StationIDNormalization struct {
+	// Lowercase converts station IDs to lowercase.
+	Lowercase bool `yaml:"lowercase"`
+
+	// Rewrite replaces station IDs matching a regular expression, e.g. to map
+	// IDs that the firmware generates randomly to a stable name. Rules are
+	// applied in order after lowercasing, and only the first matching rule
+	// is applied.
+	Rewrite []StationIDRewrite `yaml:"rewrite"`
+
+	// RejectUnknown rejects submissions from stations that are not configured
+	// in stations or a tenant, after normalization.
+	RejectUnknown bool `yaml:"reject_unknown"`
+}
+
+// StationIDRewrite replaces station IDs matching a regular expression.
+type StationIDRewrite struct {
+	// Match is the regular expression, which must match the whole station ID.
+	Match string `yaml:"match"`
+
+	// Replacement is the station ID that matching IDs are replaced with,
+	// which may refer to capture groups of the expression, e.g. "$1".
+	Replacement string `yaml:"replacement"`
+}
+
+// Validate checks the station ID normalization configuration for errors.
+func (n *StationIDNormalization) Validate() error {
+	for i, r := range n.Rewrite {
+		if _, err := regexp.Compile(r.Match); err != nil {
+			return fmt.Errorf("rewrite[%d]: match: %w", i, err)
+		}
+		if r.Replacement == "" {
+			return fmt.Errorf("rewrite[%d]: replacement is required", i)
+		}
+	}
+	return nil
+}
+
+// Load reads and validates the configuration file at the given path.
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var c Config
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks the configuration for errors.
+func (c *Config) Validate() error {
+	names := make(map[string]struct{}, len(c.Tenants))
+	stations := make(map[string]string)
+	for i, t := range c.Tenants {
+		if t.Name == "" {
+			return fmt.Errorf("tenants[%d]: name is required", i)
+		}
+		if _, ok := names[t.Name]; ok {
+			return fmt.Errorf("tenant %q: duplicate tenant name", t.Name)
+		}
+		names[t.Name] = struct{}{}
+
+		if t.Username == "" || t.Password == "" {
+			return fmt.Errorf("tenant %q: username and password are required", t.Name)
+		}
+		for _, s := range t.Stations {
+			if other, ok := stations[s]; ok {
+				return fmt.Errorf("tenant %q: station %q already belongs to tenant %q",
+					t.Name, s, other)
+			}
+			stations[s] = t.Name
+		}
+	}
+
+	for id, s := range c.Stations {
+		if _, err := s.Location(); err != nil {
+			return fmt.Errorf("station %q: timezone: %w", id, err)
+		}
+		if s.ExpectedInterval < 0 {
+			return fmt.Errorf("station %q: expected_interval must not be negative", id)
+		}
+		if r := s.Response; r != nil && r.Status != 0 && (r.Status < 100 || r.Status > 599) {
+			return fmt.Errorf("station %q: response: invalid status %d", id, r.Status)
+		}
+		if r := s.RapidFireResponse; r != nil && r.Status != 0 && (r.Status < 100 || r.Status > 599) {
+			return fmt.Errorf("station %q: rapidfire_response: invalid status %d", id, r.Status)
+		}
+		if s.RapidFireWindow < 0 {
+			return fmt.Errorf("station %q: rapidfire_window must not be negative", id)
+		}
+	}
+	sites := make(map[string]struct{}, len(c.Sites))
+	for i, s := range c.Sites {
+		if s.Name == "" {
+			return fmt.Errorf("sites[%d]: name is required", i)
+		}
+		if _, ok := sites[s.Name]; ok {
+			return fmt.Errorf("site %q: duplicate site name", s.Name)
+		}
+		sites[s.Name] = struct{}{}
+		if len(s.Stations) == 0 {
+			return fmt.Errorf("site %q: at least one station is required", s.Name)
+		}
+		for _, id := range s.Stations[1:] {
+			if stations[id] != stations[s.Stations[0]] {
+				return fmt.Errorf("site %q: stations %q and %q belong to different tenants",
+					s.Name, s.Stations[0], id)
+			}
+		}
+	}
+	if err := c.NormalizeStationIDs.Validate(); err != nil {
+		return fmt.Errorf("normalize_station_ids: %w", err)
+	}
+	if err := c.Relabel.Validate(); err != nil {
+		return fmt.Errorf("relabel: %w", err)
+	}
+	if err := c.Store.Validate(); err != nil {
+		return fmt.Errorf("store: %w", err)
+	}
+	if (c.Admin.Username == "") != (c.Admin.Password == "") {
+		return errors.New("admin: username and password are required")
+	}
+	for id, token := range c.Ingest.StationTokens {
+		if token == "" {
+			return fmt.Errorf("ingest: station %q: token is required", id)
+		}
+	}
+	if err := c.WUServer.Validate(); err != nil {
+		return fmt.Errorf("wu_server: %w", err)
+	}
+	if err := c.Alerts.Validate(); err != nil {
+		return fmt.Errorf("alerts: %w", err)
+	}
+	boxes := make(map[string]struct{}, len(c.OpenSenseMap))
+	for i, b := range c.OpenSenseMap {
+		if b.Station == "" {
+			return fmt.Errorf("opensensemap[%d]: station is required", i)
+		}
+		if _, ok := boxes[b.Station]; ok {
+			return fmt.Errorf("opensensemap: duplicate station %q", b.Station)
+		}
+		boxes[b.Station] = struct{}{}
+		if b.BoxID == "" {
+			return fmt.Errorf("opensensemap %q: box_id is required", b.Station)
+		}
+		if len(b.Sensors) == 0 {
+			return fmt.Errorf("opensensemap %q: at least one sensor is required", b.Station)
+		}
+	}
+	if err := c.Federation.Validate(); err != nil {
+		return fmt.Errorf("federation: %w", err)
+	}
+	if err := c.GrafanaAnnotations.Validate(); err != nil {
+		return fmt.Errorf("grafana_annotations: %w", err)
+	}
+	if err := c.DNS.Validate(); err != nil {
+		return fmt.Errorf("dns: %w", err)
+	}
+	if err := c.RTL433.Validate(); err != nil {
+		return fmt.Errorf("rtl_433: %w", err)
+	}
+	if err := c.MQTTInput.Validate(); err != nil {
+		return fmt.Errorf("mqtt_input: %w", err)
+	}
+	if err := c.WUAPI.Validate(); err != nil {
+		return fmt.Errorf("wu_api: %w", err)
+	}
+	if err := c.WeatherLinkIP.Validate(); err != nil {
+		return fmt.Errorf("weatherlink_ip: %w", err)
+	}
+	upstreams := make(map[string]struct{}, len(c.Upstreams))
+	buffers := make(map[string]string, len(c.Upstreams))
+	for i, u := range c.Upstreams {
+		if u.Name == "" {
+			return fmt.Errorf("upstreams[%d]: name is required", i)
+		}
+		if _, ok := upstreams[u.Name]; ok {
+			return fmt.Errorf("upstreams: duplicate name %q", u.Name)
+		}
+		upstreams[u.Name] = struct{}{}
+		if err := u.Validate(); err != nil {
+			return fmt.Errorf("upstream %q: %w", u.Name, err)
+		}
+		if u.BufferFile != "" {
+			if other, ok := buffers[u.BufferFile]; ok {
+				return fmt.Errorf("upstream %q: buffer_file is also used by upstream %q", u.Name, other)
+			}
+			buffers[u.BufferFile] = u.Name
+		}
+	}
+	forwarded := make(map[string]struct{}, len(c.WUForward))
+	for i, f := range c.WUForward {
+		if f.Station == "" {
+			return fmt.Errorf("wu_forward[%d]: station is required", i)
+		}
+		if _, ok := forwarded[f.Station]; ok {
+			return fmt.Errorf("wu_forward: duplicate station %q", f.Station)
+		}
+		forwarded[f.Station] = struct{}{}
+		if err := f.Validate(); err != nil {
+			return fmt.Errorf("wu_forward %q: %w", f.Station, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the alerting configuration for errors.
+func (a *Alerts) Validate() error {
+	if a.Interval < 0 {
+		return errors.New("interval must not be negative")
+	}
+	for name, c := range a.Channels {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("channel %q: %w", name, err)
+		}
+	}
+
+	names := make(map[string]struct{}, len(a.Rules))
+	for i, r := range a.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("rules[%d]: name is required", i)
+		}
+		if _, ok := names[r.Name]; ok {
+			return fmt.Errorf("rule %q: duplicate rule name", r.Name)
+		}
+		names[r.Name] = struct{}{}
+		if err := a.validateRule(r); err != nil {
+			return fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+	}
+	for i, s := range a.Silences {
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("silences[%d]: %w", i, err)
+		}
+	}
+	if a.Offline != nil {
+		if err := a.Offline.Validate(); err != nil {
+			return fmt.Errorf("offline: %w", err)
+		}
+		if _, ok := names["station_offline"]; ok {
+			return errors.New(`offline: rule name "station_offline" is already used`)
+		}
+	}
+	return nil
+}
+
+// validateRule checks an alert rule for errors.
+func (a *Alerts) validateRule(r AlertRule) error {
+	switch r.Type {
+	case "", AlertTypeThreshold:
+		if r.Field == "" {
+			return errors.New("field is required")
+		}
+		switch r.Operator {
+		case ">", ">=", "<", "<=":
+		default:
+			return fmt.Errorf("invalid operator %q", r.Operator)
+		}
+		if r.For < 0 || r.Hysteresis < 0 {
+			return errors.New("for and hysteresis must not be negative")
+		}
+	case AlertTypeOffline:
+		if r.After < 0 {
+			return errors.New("after must not be negative")
+		}
+	default:
+		return fmt.Errorf("invalid type %q", r.Type)
+	}
+
+	if r.Webhook == "" && len(r.Channels) == 0 {
+		return errors.New("webhook or channels is required")
+	}
+	if r.Webhook != "" {
+		u, err := url.Parse(r.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.New("webhook must be a HTTP or HTTPS URL")
+		}
+	}
+	for _, name := range r.Channels {
+		if _, ok := a.Channels[name]; !ok {
+			return fmt.Errorf("unknown channel %q", name)
+		}
+	}
+	return nil
+}
+
+// Validate checks the federation configuration for errors.
+func (f *Federation) Validate() error {
+	if f.Interval < 0 {
+		return errors.New("interval must not be negative")
+	}
+	sites := make(map[string]struct{}, len(f.Sites))
+	for i, s := range f.Sites {
+		if s.Name == "" {
+			return fmt.Errorf("sites[%d]: name is required", i)
+		}
+		if _, ok := sites[s.Name]; ok {
+			return fmt.Errorf("site %q: duplicate site name", s.Name)
+		}
+		sites[s.Name] = struct{}{}
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("site %q: url must be a HTTP or HTTPS URL", s.Name)
+		}
+	}
+	return nil
+}
+
+// Validate checks the rtl_433 configuration for errors.
+func (r *RTL433) Validate() error {
+	if r.MQTT.Broker == "" {
+		if len(r.Devices) > 0 {
+			return errors.New("mqtt: broker is required")
+		}
+		return nil
+	}
+	if err := r.MQTT.Validate(); err != nil {
+		return fmt.Errorf("mqtt: %w", err)
+	}
+	if len(r.Devices) == 0 {
+		return errors.New("at least one device is required")
+	}
+	for i, d := range r.Devices {
+		if d.Model == "" || d.Station == "" {
+			return fmt.Errorf("devices[%d]: model and station are required", i)
+		}
+		switch d.Sensor {
+		case "", "outdoor", "indoor":
+		default:
+			// Matches the channels of wu.MaxChannels.
+			v, ok := strings.CutPrefix(d.Sensor, "channel")
+			if ch, err := strconv.Atoi(v); !ok || err != nil || ch < 1 || ch > 8 {
+				return fmt.Errorf("devices[%d]: unknown sensor %q", i, d.Sensor)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the MQTT input configuration for errors. The field names are
+// checked by the exporter.
+func (m *MQTTInput) Validate() error {
+	if m.MQTT.Broker == "" {
+		if len(m.Subscriptions) > 0 {
+			return errors.New("mqtt: broker is required")
+		}
+		return nil
+	}
+	if err := m.MQTT.Validate(); err != nil {
+		return fmt.Errorf("mqtt: %w", err)
+	}
+	if len(m.Subscriptions) == 0 {
+		return errors.New("at least one subscription is required")
+	}
+	for i, s := range m.Subscriptions {
+		if s.Topic == "" {
+			return fmt.Errorf("subscriptions[%d]: topic is required", i)
+		}
+		stations := 0
+		for _, set := range []bool{s.Station != "", s.StationField != "", s.StationTopicLevel != nil} {
+			if set {
+				stations++
+			}
+		}
+		if stations != 1 {
+			return fmt.Errorf("subscription %q: exactly one of station, station_field and station_topic_level is required", s.Topic)
+		}
+		if s.StationTopicLevel != nil && *s.StationTopicLevel < 0 {
+			return fmt.Errorf("subscription %q: station_topic_level must not be negative", s.Topic)
+		}
+		switch s.Units {
+		case "", "metric", "imperial":
+		default:
+			return fmt.Errorf("subscription %q: unknown units %q", s.Topic, s.Units)
+		}
+		if len(s.Fields) == 0 {
+			return fmt.Errorf("subscription %q: at least one field is required", s.Topic)
+		}
+	}
+	return nil
+}
+
+// Validate checks the WU PWS API configuration for errors.
+func (w *WUAPI) Validate() error {
+	if w.Interval < 0 {
+		return errors.New("interval must not be negative")
+	}
+	if len(w.Stations) > 0 && w.APIKey == "" {
+		return errors.New("api_key is required")
+	}
+	for i, s := range w.Stations {
+		if s == "" {
+			return fmt.Errorf("stations[%d]: station ID is required", i)
+		}
+	}
+	return nil
+}
+
+// Validate checks the WeatherLink IP configuration for errors.
+func (w *WeatherLinkIP) Validate() error {
+	if w.Interval < 0 {
+		return errors.New("interval must not be negative")
+	}
+	for i, l := range w.Loggers {
+		if l.Station == "" {
+			return fmt.Errorf("loggers[%d]: station is required", i)
+		}
+		if l.Address == "" {
+			return fmt.Errorf("loggers[%d]: address is required", i)
+		}
+		if l.RainClick() == 0 {
+			return fmt.Errorf("loggers[%d]: unknown rain collector %q", i, l.RainCollector)
+		}
+	}
+	return nil
+}
+
+// Validate checks the MQTT broker configuration for errors.
+func (m *MQTT) Validate() error {
+	u, err := url.Parse(m.Broker)
+	if err != nil || u.Host == "" {
+		return errors.New("broker must be a URL, e.g. mqtt://localhost:1883")
+	}
+	switch u.Scheme {
+	case "mqtt", "tcp", "mqtts", "ssl", "tls":
+	default:
+		return fmt.Errorf("unsupported broker scheme %q", u.Scheme)
+	}
+	return nil
+}
+
+// Validate checks the Grafana annotation configuration for errors.
+func (g *GrafanaAnnotations) Validate() error {
+	if g.URL == "" {
+		return nil
+	}
+	u, err := url.Parse(g.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("url must be a HTTP or HTTPS URL")
+	}
+	for _, e := range g.Events {
+		switch e {
+		case "rain", "gust", "offline":
+		default:
+			return fmt.Errorf("unknown event %q", e)
+		}
+	}
+	if g.GustThreshold < 0 {
+		return errors.New("gust_threshold must not be negative")
+	}
+	if g.RainStopAfter < 0 {
+		return errors.New("rain_stop_after must not be negative")
+	}
+	return nil
+}
+
+// Validate checks the WU forwarding configuration for errors.
+func (f *WUForward) Validate() error {
+	if len(f.Targets) == 0 {
+		return errors.New("at least one target is required")
+	}
+	for i, t := range f.Targets {
+		if t.ID == "" || t.Password == "" {
+			return fmt.Errorf("targets[%d]: id and password are required", i)
+		}
+		if t.URL != "" {
+			u, err := url.Parse(t.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return fmt.Errorf("targets[%d]: url must be a HTTP or HTTPS URL", i)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the upstream configuration for errors.
+func (u *Upstream) Validate() error {
+	switch u.Service {
+	case "wu", "pwsweather", "weathercloud":
+		if u.ID == "" || u.Password == "" {
+			return errors.New("id and password are required")
+		}
+	case "windy":
+		if u.Password == "" {
+			return errors.New("password (the Windy API key) is required")
+		}
+	case "custom":
+		if u.URL == "" {
+			return errors.New("url is required")
+		}
+	case "":
+		return errors.New("service is required")
+	default:
+		return fmt.Errorf("unknown service %q", u.Service)
+	}
+	if u.URL != "" && !strings.HasPrefix(u.URL, "http://") && !strings.HasPrefix(u.URL, "https://") {
+		return errors.New("url must be a HTTP or HTTPS URL")
+	}
+	if u.Interval < 0 || u.Backoff < 0 {
+		return errors.New("interval and backoff must not be negative")
+	}
+	if u.MaxAttempts < 0 {
+		return errors.New("max_attempts must not be negative")
+	}
+	if u.BufferSize < 0 {
+		return errors.New("buffer_size must not be negative")
+	}
+	return nil
+}
+
+// Validate checks the silence configuration for errors.
+func (s *Silence) Validate() error {
+	if !s.Start.IsZero() && !s.End.IsZero() && !s.End.After(s.Start) {
+		return errors.New("end must be after start")
+	}
+	if _, err := s.Weekdays(); err != nil {
+		return err
+	}
+	if _, _, err := s.Window(); err != nil {
+		return err
+	}
+	if s.Start.IsZero() && s.End.IsZero() && len(s.Days) == 0 && s.From == "" {
+		return errors.New("start, end, days or from and to is required")
+	}
+	return nil
+}
+
+// Validate checks the station offline alert configuration for errors.
+func (o *OfflineAlert) Validate() error {
+	if o.After < 0 {
+		return errors.New("after must not be negative")
+	}
+	if len(o.Channels) == 0 {
+		return errors.New("at least one channel is required")
+	}
+	for i, c := range o.Channels {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("channels[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the notification channel configuration for errors.
+func (c *Channel) Validate() error {
+	switch c.Type {
+	case "webhook", "discord":
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.New("url must be a HTTP or HTTPS URL")
+		}
+	case "telegram":
+		if c.BotToken == "" || c.ChatID == "" {
+			return errors.New("bot_token and chat_id are required")
+		}
+	case "smtp":
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("address: %w", err)
+		}
+		if c.From == "" || len(c.To) == 0 {
+			return errors.New("from and to are required")
+		}
+	default:
+		return fmt.Errorf("invalid type %q", c.Type)
+	}
+	return nil
+}
+
+// Validate checks the HTTP server configuration for errors.
+func (s *HTTPServer) Validate() error {
+	switch {
+	case s.ReadTimeout < 0, s.WriteTimeout < 0, s.IdleTimeout < 0:
+		return errors.New("timeouts must not be negative")
+	case s.MaxHeaderBytes < 0, s.MaxBodyBytes < 0:
+		return errors.New("size limits must not be negative")
+	case s.MaxConnections < 0:
+		return errors.New("max_connections must not be negative")
+	}
+	return nil
+}
+
+// Validate checks the store configuration for errors.
+func (s *Store) Validate() error {
+	var prev DownsampleRule
+	for i, r := range s.Downsample {
+		if r.After <= 0 || r.Resolution <= 0 {
+			return fmt.Errorf("downsample[%d]: after and resolution must be positive", i)
+		}
+		if i > 0 && (r.After <= prev.After || r.Resolution <= prev.Resolution) {
+			return fmt.Errorf("downsample[%d]: rules must be ordered by increasing after and resolution", i)
+		}
+		prev = r
+	}
+	return nil
+}
+
+// Validate checks the relabel configuration for errors.
+func (r *Relabel) Validate() error {
+	renamed := make(map[string]string, len(r.Rename))
+	for _, from := range slices.Sorted(maps.Keys(r.Rename)) {
+		to := r.Rename[from]
+		if !model.IsValidMetricName(model.LabelValue(to)) {
+			return fmt.Errorf("rename %q: invalid metric name %q", from, to)
+		}
+		if other, ok := renamed[to]; ok {
+			return fmt.Errorf("rename %q: %q is also renamed to %q", from, other, to)
+		}
+		renamed[to] = from
+	}
+	for _, name := range r.Drop {
+		if _, ok := r.Rename[name]; ok {
+			return fmt.Errorf("metric %q is both renamed and dropped", name)
+		}
+	}
+	mapped := make(map[string]string, len(r.StationIDs))
+	for _, from := range slices.Sorted(maps.Keys(r.StationIDs)) {
+		to := r.StationIDs[from]
+		if to == "" {
+			return fmt.Errorf("station ID %q: mapped to empty value", from)
+		}
+		if other, ok := mapped[to]; ok {
+			return fmt.Errorf("station ID %q: %q is also mapped to %q", from, other, to)
+		}
+		mapped[to] = from
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+)
+
+// Redacted is the value that secrets are replaced with by Config.Redacted.
+const Redacted = "REDACTED"
+
+// Redacted returns a copy of the configuration with passwords, tokens and
+// webhook URLs replaced with "REDACTED", so that it can be shown to users.
+// Secrets that are not set are left empty, so it can still be seen whether
+// they are configured.
+func (c Config) Redacted() Config {
+	c.Tenants = slices.Clone(c.Tenants)
+	for i := range c.Tenants {
+		c.Tenants[i].Password = redactSecret(c.Tenants[i].Password)
+	}
+	c.Admin.Password = redactSecret(c.Admin.Password)
+	c.Ingest.Token = redactSecret(c.Ingest.Token)
+	if c.Ingest.StationTokens != nil {
+		tokens := make(map[string]string, len(c.Ingest.StationTokens))
+		for id, token := range c.Ingest.StationTokens {
+			tokens[id] = redactSecret(token)
+		}
+		c.Ingest.StationTokens = tokens
+	}
+
+	c.Alerts.Channels = maps.Clone(c.Alerts.Channels)
+	for name, ch := range c.Alerts.Channels {
+		c.Alerts.Channels[name] = ch.redacted()
+	}
+	c.Alerts.Rules = slices.Clone(c.Alerts.Rules)
+	for i := range c.Alerts.Rules {
+		c.Alerts.Rules[i].Webhook = redactURL(c.Alerts.Rules[i].Webhook)
+	}
+	if o := c.Alerts.Offline; o != nil {
+		offline := *o
+		offline.Channels = slices.Clone(offline.Channels)
+		for i := range offline.Channels {
+			offline.Channels[i] = offline.Channels[i].redacted()
+		}
+		c.Alerts.Offline = &offline
+	}
+
+	c.WUForward = slices.Clone(c.WUForward)
+	for i := range c.WUForward {
+		targets := slices.Clone(c.WUForward[i].Targets)
+		for j := range targets {
+			targets[j].Password = redactSecret(targets[j].Password)
+			targets[j].URL = redactURLPassword(targets[j].URL)
+		}
+		c.WUForward[i].Targets = targets
+	}
+	c.Upstreams = slices.Clone(c.Upstreams)
+	for i := range c.Upstreams {
+		c.Upstreams[i].Password = redactSecret(c.Upstreams[i].Password)
+		c.Upstreams[i].URL = redactURLPassword(c.Upstreams[i].URL)
+	}
+	c.OpenSenseMap = slices.Clone(c.OpenSenseMap)
+	for i := range c.OpenSenseMap {
+		c.OpenSenseMap[i].AccessToken = redactSecret(c.OpenSenseMap[i].AccessToken)
+	}
+	c.Federation.Sites = slices.Clone(c.Federation.Sites)
+	for i := range c.Federation.Sites {
+		c.Federation.Sites[i].Password = redactSecret(c.Federation.Sites[i].Password)
+		c.Federation.Sites[i].URL = redactURLPassword(c.Federation.Sites[i].URL)
+	}
+	c.GrafanaAnnotations.Token = redactSecret(c.GrafanaAnnotations.Token)
+	c.GrafanaAnnotations.URL = redactURLPassword(c.GrafanaAnnotations.URL)
+	c.RTL433.MQTT.Password = redactSecret(c.RTL433.MQTT.Password)
+	c.RTL433.MQTT.Broker = redactURLPassword(c.RTL433.MQTT.Broker)
+	c.MQTTInput.MQTT.Password = redactSecret(c.MQTTInput.MQTT.Password)
+	c.MQTTInput.MQTT.Broker = redactURLPassword(c.MQTTInput.MQTT.Broker)
+	c.WUAPI.APIKey = redactSecret(c.WUAPI.APIKey)
+	return c
+}
+
+// redacted returns a copy of the channel with its secrets redacted.
+func (c Channel) redacted() Channel {
+	c.URL = redactURL(c.URL)
+	c.BotToken = redactSecret(c.BotToken)
+	c.Password = redactSecret(c.Password)
+	return c
+}
+
+// redactSecret returns "REDACTED" if s is set.
+func redactSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// redactURL redacts everything but the scheme and host of a URL, since
+// webhook URLs usually contain a token in their path or query.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return redactSecret(s)
+	}
+	if u.Path == "" && u.RawQuery == "" && u.User == nil {
+		return s
+	}
+	return u.Scheme + "://" + u.Host + "/" + Redacted
+}
+
+// redactURLPassword redacts the password in the user information of a URL.
+func redactURLPassword(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return redactSecret(s)
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	u.User = url.UserPassword(u.User.Username(), Redacted)
+	return u.String()
+}
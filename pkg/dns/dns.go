@@ -0,0 +1,237 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package dns implements the DNS server used to redirect weather station
+// submissions to the exporter.
+package dns
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/miekg/dns"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records a span for each handled query. Spans are only recorded if a
+// global OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/dns")
+
+// Server implements a simple proxying DNS server.
+type Server struct {
+	mux *dns.ServeMux
+
+	mu        sync.Mutex
+	dnsServer *dns.Server
+
+	zone atomic.Pointer[zone]
+
+	upstreamResolver string
+	dnsClient        *dns.Client
+
+	notifyStarted func()
+}
+
+// zone is the records answered locally and the domains forwarded to the
+// upstream resolver, which are replaced as a whole when the server is updated.
+type zone struct {
+	records        map[string]string
+	aaaaRecords    map[string]string
+	forwardDomains map[string]struct{}
+}
+
+// newZone returns the zone for the records and forward domains of c.
+func newZone(c Config) *zone {
+	z := &zone{
+		records:        c.Records,
+		aaaaRecords:    c.AAAARecords,
+		forwardDomains: make(map[string]struct{}, len(c.ForwardDomains)),
+	}
+	for _, domain := range c.ForwardDomains {
+		z.forwardDomains[domain] = struct{}{}
+	}
+	return z
+}
+
+// Config is the DNS server configuration.
+type Config struct {
+	// UpstreamResolver is the upstream DNS resolver to forward queries for
+	// domains in the ForwardDomains list.
+	UpstreamResolver string
+
+	// Records is a list of A records to answer locally. Queries for names that
+	// are not in this list or ForwardDomains will receive an answer of
+	// NXDOMAIN.
+	Records map[string]string
+
+	// AAAARecords is a list of AAAA records to answer locally. AAAA queries
+	// for names in Records but not AAAARecords, and A queries for names in
+	// AAAARecords but not Records, receive an empty answer, so that clients
+	// fall back to the other address family.
+	AAAARecords map[string]string
+
+	// ForwardDomains is a list of domains for which to forward queries to the
+	// UpstreamResolver. Domains that are not in this list or Records will
+	// receive an answer of NXDOMAIN.
+	ForwardDomains []string
+
+	// NotifyStarted is an optional function that is called once the server
+	// has started listening.
+	NotifyStarted func()
+}
+
+// NewServer returns a new DNS server.
+func NewServer(c Config) *Server {
+	s := &Server{
+		mux:              dns.NewServeMux(),
+		upstreamResolver: c.UpstreamResolver,
+		dnsClient:        &dns.Client{},
+		notifyStarted:    c.NotifyStarted,
+	}
+	s.zone.Store(newZone(c))
+	s.mux.Handle(".", s)
+	return s
+}
+
+// Update replaces the records and forward domains of the server with those of
+// c, without interrupting the server. Queries that are being handled are
+// answered using the previous records.
+func (s *Server) Update(c Config) {
+	s.zone.Store(newZone(c))
+}
+
+func (s *Server) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	if len(r.Question) != 1 {
+		return
+	}
+	q := r.Question[0]
+	domain := q.Name
+
+	_, span := tracer.Start(context.Background(), "dns.query",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("dns.question.name", domain),
+			attribute.String("dns.question.type", dns.TypeToString[q.Qtype]),
+		))
+	defer span.End()
+
+	l := slog.With(slog.String("name", domain),
+		slog.String("type", dns.TypeToString[q.Qtype]))
+	l.Debug("Handling DNS query")
+
+	z := s.zone.Load()
+	ip, isA := z.records[domain]
+	ip6, isAAAA := z.aaaaRecords[domain]
+	if isA || isAAAA {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		hdr := dns.RR_Header{
+			Name:   domain,
+			Rrtype: q.Qtype,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		}
+		switch {
+		case q.Qtype == dns.TypeA && isA:
+			m.Answer = append(m.Answer, &dns.A{Hdr: hdr, A: net.ParseIP(ip)})
+			l.Debug("Answering with local record", slog.String("a", ip))
+		case q.Qtype == dns.TypeAAAA && isAAAA:
+			m.Answer = append(m.Answer, &dns.AAAA{Hdr: hdr, AAAA: net.ParseIP(ip6)})
+			l.Debug("Answering with local record", slog.String("aaaa", ip6))
+		default:
+			// The name exists, but has no records of the queried type.
+			l.Debug("Answering with empty local record")
+		}
+		span.SetAttributes(attribute.String("dns.answer", "local"))
+		_ = w.WriteMsg(m)
+		return
+	}
+
+	// Forward queries for allowed/forwarded domains to the upstream resolver.
+	if _, ok := z.forwardDomains[domain]; ok {
+		span.SetAttributes(attribute.String("dns.answer", "forwarded"))
+		res, _, err := s.dnsClient.Exchange(r, s.upstreamResolver)
+		if err != nil {
+			l.Error("Error forwarding DNS query",
+				slog.Any("err", err))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return
+		}
+		l.Debug("Resolved forwarded query",
+			slog.Any("answers", res.Answer))
+		_ = w.WriteMsg(res)
+		return
+	}
+
+	// Blackhole other queries (NXDOMAIN)
+	m := new(dns.Msg)
+	m.SetRcode(r, dns.RcodeNameError)
+	l.Debug("Answering with NXDOMAIN")
+	span.SetAttributes(attribute.String("dns.answer", "nxdomain"))
+	_ = w.WriteMsg(m)
+}
+
+// ListenAndServe starts the DNS server on the given address.
+func (s *Server) ListenAndServe(addr string) error {
+	srv := &dns.Server{
+		Addr:              addr,
+		Net:               "udp",
+		Handler:           s,
+		NotifyStartedFunc: s.notifyStarted,
+	}
+	s.setServer(srv)
+	return srv.ListenAndServe()
+}
+
+// Serve serves DNS queries received on the given packet connection.
+func (s *Server) Serve(pc net.PacketConn) error {
+	srv := &dns.Server{
+		PacketConn:        pc,
+		Handler:           s,
+		NotifyStartedFunc: s.notifyStarted,
+	}
+	s.setServer(srv)
+	return srv.ActivateAndServe()
+}
+
+func (s *Server) setServer(srv *dns.Server) {
+	s.mu.Lock()
+	s.dnsServer = srv
+	s.mu.Unlock()
+}
+
+// Shutdown shuts down the DNS server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	srv := s.dnsServer
+	s.mu.Unlock()
+	if srv == nil {
+		// Not started.
+		return nil
+	}
+	return srv.ShutdownContext(ctx)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// startServer starts a DNS server on a random local port, and returns its
+// address.
+func startServer(t *testing.T, c Config) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	c.NotifyStarted = func() { close(started) }
+	s := NewServer(c)
+	go func() { _ = s.Serve(pc) }()
+	t.Cleanup(func() { _ = pc.Close() })
+	<-started
+	return pc.LocalAddr().String()
+}
+
+func TestServer(t *testing.T) {
+	upstream := startServer(t, Config{
+		Records: map[string]string{"time.example.com.": "192.0.2.123"},
+	})
+	addr := startServer(t, Config{
+		UpstreamResolver: upstream,
+		Records: map[string]string{
+			"dual.example.com.": "192.0.2.1",
+			"v4.example.com.":   "192.0.2.1",
+		},
+		AAAARecords: map[string]string{
+			"dual.example.com.": "2001:db8::1",
+			"v6.example.com.":   "2001:db8::1",
+		},
+		ForwardDomains: []string{"time.example.com."},
+	})
+
+	tests := []struct {
+		name       string
+		qtype      uint16
+		wantRcode  int
+		wantAnswer string
+	}{
+		{name: "dual.example.com.", qtype: dns.TypeA, wantAnswer: "192.0.2.1"},
+		{name: "dual.example.com.", qtype: dns.TypeAAAA, wantAnswer: "2001:db8::1"},
+		{name: "v4.example.com.", qtype: dns.TypeAAAA},
+		{name: "v6.example.com.", qtype: dns.TypeA},
+		{name: "v6.example.com.", qtype: dns.TypeAAAA, wantAnswer: "2001:db8::1"},
+		{name: "time.example.com.", qtype: dns.TypeA, wantAnswer: "192.0.2.123"},
+		{name: "other.example.com.", qtype: dns.TypeA, wantRcode: dns.RcodeNameError},
+	}
+	c := new(dns.Client)
+	for _, tt := range tests {
+		m := new(dns.Msg)
+		m.SetQuestion(tt.name, tt.qtype)
+		res, _, err := c.Exchange(m, addr)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.name, dns.TypeToString[tt.qtype], err)
+		}
+		if res.Rcode != tt.wantRcode {
+			t.Errorf("%s %s: rcode got %s, want %s", tt.name, dns.TypeToString[tt.qtype],
+				dns.RcodeToString[res.Rcode], dns.RcodeToString[tt.wantRcode])
+		}
+		var answer string
+		for _, rr := range res.Answer {
+			switch rr := rr.(type) {
+			case *dns.A:
+				answer = rr.A.String()
+			case *dns.AAAA:
+				answer = rr.AAAA.String()
+			}
+		}
+		if answer != tt.wantAnswer {
+			t.Errorf("%s %s: answer got %q, want %q", tt.name, dns.TypeToString[tt.qtype], answer, tt.wantAnswer)
+		}
+	}
+}
+
+func TestServerUpdate(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	s := NewServer(Config{
+		Records:       map[string]string{"old.example.com.": "192.0.2.1"},
+		NotifyStarted: func() { close(started) },
+	})
+	go func() { _ = s.Serve(pc) }()
+	t.Cleanup(func() { _ = pc.Close() })
+	<-started
+
+	query := func(name string) int {
+		m := new(dns.Msg)
+		m.SetQuestion(name, dns.TypeA)
+		res, _, err := new(dns.Client).Exchange(m, pc.LocalAddr().String())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return res.Rcode
+	}
+	if rcode := query("new.example.com."); rcode != dns.RcodeNameError {
+		t.Errorf("before update: rcode got %s, want NXDOMAIN", dns.RcodeToString[rcode])
+	}
+
+	s.Update(Config{Records: map[string]string{"new.example.com.": "192.0.2.2"}})
+	if rcode := query("new.example.com."); rcode != dns.RcodeSuccess {
+		t.Errorf("after update: rcode got %s, want NOERROR", dns.RcodeToString[rcode])
+	}
+	if rcode := query("old.example.com."); rcode != dns.RcodeNameError {
+		t.Errorf("after update: removed record rcode got %s, want NXDOMAIN", dns.RcodeToString[rcode])
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket
+// activation.
+const listenFDsStart = 3
+
+// activatedSocket is a socket passed by systemd socket activation.
+type activatedSocket struct {
+	file   *os.File
+	stream bool
+	addr   net.Addr
+
+	// service is the name of the service using the socket, if any.
+	service string
+}
+
+// activatedSockets are the sockets passed by systemd socket activation. A
+// socket is used by the service named by its FileDescriptorName, or otherwise
+// by the service with the same listen address. Services without a socket
+// create their listeners as usual.
+type activatedSockets struct {
+	mu      sync.Mutex
+	sockets []*activatedSocket
+}
+
+// systemdSockets returns the sockets passed by systemd socket activation, or
+// nil if there are none. The LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES
+// environment variables are unset, so that they are not inherited by child
+// processes.
+func systemdSockets() (*activatedSockets, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+	var names []string
+	if v := os.Getenv("LISTEN_FDNAMES"); v != "" {
+		names = strings.Split(v, ":")
+	}
+	for _, k := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		_ = os.Unsetenv(k)
+	}
+
+	files := make([]*os.File, 0, n)
+	for i := range n {
+		fd := listenFDsStart + i
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(names) {
+			name = names[i]
+		}
+		files = append(files, os.NewFile(uintptr(fd), name))
+	}
+	return newActivatedSockets(files)
+}
+
+// newActivatedSockets returns the activated sockets for the files, named by
+// their file names.
+func newActivatedSockets(files []*os.File) (*activatedSockets, error) {
+	s := &activatedSockets{sockets: make([]*activatedSocket, 0, len(files))}
+	for _, f := range files {
+		sock := &activatedSocket{file: f}
+		if l, err := net.FileListener(f); err == nil {
+			sock.stream, sock.addr = true, l.Addr()
+			_ = l.Close()
+		} else if pc, err := net.FilePacketConn(f); err == nil {
+			sock.addr = pc.LocalAddr()
+			_ = pc.Close()
+		} else {
+			return nil, errors.Join(fmt.Errorf("socket %s: unsupported socket", f.Name()), s.close())
+		}
+		s.sockets = append(s.sockets, sock)
+	}
+	return s, nil
+}
+
+// socket returns the socket used by the service, claiming it for the service
+// if it has not yet been claimed, or nil if there is none.
+func (s *activatedSockets) socket(service string, stream bool, address string) *activatedSocket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sock := range s.sockets {
+		if sock.service == service {
+			return sock
+		}
+	}
+	for _, sock := range s.sockets {
+		if sock.service == "" && sock.stream == stream && sock.file.Name() == service {
+			sock.service = service
+			return sock
+		}
+	}
+	for _, sock := range s.sockets {
+		if sock.service == "" && sock.stream == stream && addrMatches(sock.addr, address) {
+			sock.service = service
+			return sock
+		}
+	}
+	return nil
+}
+
+// close closes the sockets.
+func (s *activatedSockets) close() error {
+	var errs []error
+	for _, sock := range s.sockets {
+		errs = append(errs, sock.file.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// listenConfig returns the listen configuration of a service, which uses the
+// service's socket if there is one, and lc otherwise.
+func (s *activatedSockets) listenConfig(service string, lc listenConfig) listenConfig {
+	return &activatedListenConfig{sockets: s, service: service, lc: lc}
+}
+
+// activatedListenConfig creates the listeners of a service from its
+// activated socket.
+type activatedListenConfig struct {
+	sockets *activatedSockets
+	service string
+	lc      listenConfig
+}
+
+func (c *activatedListenConfig) Listen(ctx context.Context, network, address string) (net.Listener, error) {
+	if sock := c.sockets.socket(c.service, true, address); sock != nil {
+		return net.FileListener(sock.file)
+	}
+	return c.lc.Listen(ctx, network, address)
+}
+
+func (c *activatedListenConfig) ListenPacket(ctx context.Context, network, address string) (net.PacketConn, error) {
+	if sock := c.sockets.socket(c.service, false, address); sock != nil {
+		return net.FilePacketConn(sock.file)
+	}
+	return c.lc.ListenPacket(ctx, network, address)
+}
+
+// addrMatches returns whether the socket address matches the listen address.
+// A listen address without a host, or with an unspecified IP address, matches
+// any socket address with the same port.
+func addrMatches(addr net.Addr, address string) bool {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	addrHost, addrPort, err := net.SplitHostPort(addr.String())
+	if err != nil || addrPort != port {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if host == "" || ip != nil && ip.IsUnspecified() {
+		return true
+	}
+	return ip != nil && ip.Equal(net.ParseIP(addrHost))
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package exporter
+
+import (
+	"context"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestActivatedSockets(t *testing.T) {
+	ctx := context.Background()
+
+	// The metrics socket is matched by name, and the DNS socket by address.
+	tl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+	tf := namedSocketFile(t, tl.(*net.TCPListener), "metrics")
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	pf := namedSocketFile(t, pc.(*net.UDPConn), "pws_exporter.socket")
+	sockets, err := newActivatedSockets([]*os.File{tf, pf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sockets.close()
+
+	var lc net.ListenConfig
+	l, err := sockets.listenConfig("metrics", &lc).Listen(ctx, "tcp", ":9452")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Addr().String() != tl.Addr().String() {
+		t.Errorf("metrics listener address = %s, want %s", l.Addr(), tl.Addr())
+	}
+	_ = l.Close()
+
+	// The socket is used again when the service is restarted.
+	l, err = sockets.listenConfig("metrics", &lc).Listen(ctx, "tcp", ":9452")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l.Close()
+
+	dnsConn, err := sockets.listenConfig("dns", &lc).ListenPacket(ctx, "udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dnsConn.LocalAddr().String() != pc.LocalAddr().String() {
+		t.Errorf("DNS listener address = %s, want %s", dnsConn.LocalAddr(), pc.LocalAddr())
+	}
+	_ = dnsConn.Close()
+
+	// Services without a socket create their own listener.
+	l, err = sockets.listenConfig("wu", &lc).Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Addr().String() == tl.Addr().String() {
+		t.Error("wu listener uses the metrics socket")
+	}
+	_ = l.Close()
+}
+
+// namedSocketFile returns a duplicate of the socket's file descriptor, with
+// the given name, as passed by systemd socket activation.
+func namedSocketFile(t *testing.T, sock syscall.Conn, name string) *os.File {
+	t.Helper()
+	rc, err := sock.SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fd int
+	var dupErr error
+	if err := rc.Control(func(s uintptr) {
+		fd, dupErr = syscall.Dup(int(s))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if dupErr != nil {
+		t.Fatal(dupErr)
+	}
+	return os.NewFile(uintptr(fd), name)
+}
+
+func TestAddrMatches(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 80}
+	tests := map[string]bool{
+		":80":             true,
+		"0.0.0.0:80":      true,
+		"[::]:80":         true,
+		"192.0.2.1:80":    true,
+		"192.0.2.2:80":    false,
+		":443":            false,
+		"192.0.2.1":       false,
+		"localhost:80":    false,
+		"[2001:db8::]:80": false,
+	}
+	for address, want := range tests {
+		if got := addrMatches(addr, address); got != want {
+			t.Errorf("addrMatches(%s, %q) = %t, want %t", addr, address, got, want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package acurite implements the upload protocol of Acurite hubs, used by the
+// Acurite Access and the older smartHUB to send the readings of their
+// sensors to myacurite.com.
+//
+// Uploads use the parameters of the Weather Underground PWS Upload Protocol,
+// with the hub's MAC address as its ID, and the model type and ID of the
+// sensor that the readings are from. Each sensor's readings are sent in a
+// separate upload, and sensor arrays such as the 5-in-1 alternate between
+// uploads with different readings, so the latest readings of each station are
+// merged and translated to a WU submission, so that they are processed,
+// journaled and forwarded the same way as WU submissions.
+package acurite
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// tracer records spans for uploads. Spans are only recorded if a global
+// OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/exporter/acurite")
+
+// UpdatePath is the path that hubs send uploads to. Unlike the WU submission
+// path, it has no .php extension.
+const UpdatePath = "/weatherstation/updateweatherstation"
+
+// staleAfter is the duration after which the last reading of a parameter is
+// no longer included in the submissions of its station.
+const staleAfter = 15 * time.Minute
+
+// luxPerWattM2 is the approximate illuminance (lux) of sunlight per W/m² of
+// solar radiation, used to convert the light intensity reported by the Atlas
+// to solar radiation.
+const luxPerWattM2 = 126.7
+
+// arrayModels are the model types (mt) of the outdoor sensor arrays, whose
+// readings are submitted as the hub's station. The readings of other sensors,
+// such as tower sensors, are submitted as separate stations.
+var arrayModels = map[string]struct{}{
+	"5N1":   {},
+	"3N1":   {},
+	"Atlas": {},
+	"Iris":  {},
+}
+
+// renamedParams maps Acurite upload parameters to the equivalent parameters
+// of the WU PWS Upload Protocol.
+var renamedParams = map[string]string{
+	"uvindex": "UV",
+}
+
+// UpdateAPI implements the Acurite hub upload protocol.
+type UpdateAPI struct {
+	handleSubmission func(ctx context.Context, s wu.Submission)
+	stationID        func(stationID string) (string, bool)
+
+	mu       sync.Mutex
+	readings map[string]map[string]reading // By station ID, then WU parameter
+}
+
+// reading is the last reading of a WU parameter.
+type reading struct {
+	time  time.Time
+	value string
+}
+
+// NewUpdateAPI returns a new upload API. The handler is called synchronously
+// for each accepted upload, with the latest readings of its station merged
+// into a WU submission, before the response is sent to the hub.
+func NewUpdateAPI(handler func(ctx context.Context, s wu.Submission)) *UpdateAPI {
+	return &UpdateAPI{
+		handleSubmission: handler,
+		readings:         make(map[string]map[string]reading),
+	}
+}
+
+// SetStationIDFunc sets the function used to normalize the ID of the station
+// that an upload is for. If the function returns false, the upload is
+// rejected. It must be called before the API is served.
+func (api *UpdateAPI) SetStationIDFunc(fn func(stationID string) (string, bool)) {
+	api.stationID = fn
+}
+
+func (api *UpdateAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	remoteAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remoteAddr = req.RemoteAddr
+	}
+
+	ctx, span := tracer.Start(req.Context(), "acurite.upload",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("hub_id", q.Get("id")),
+			attribute.String("client.address", remoteAddr),
+		))
+	defer span.End()
+	if q.Get("id") == "" {
+		slog.Warn("Rejected Acurite upload with missing parameters",
+			slog.String("remote_addr", remoteAddr),
+			slog.String("outcome", "rejected"))
+		span.SetStatus(codes.Error, "missing parameters")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	stationID := StationID(q)
+	span.SetAttributes(attribute.String("station_id", stationID))
+	if api.stationID != nil {
+		id, ok := api.stationID(stationID)
+		if !ok {
+			slog.Warn("Rejected Acurite upload from unknown station",
+				slog.String("station_id", stationID),
+				slog.String("remote_addr", remoteAddr),
+				slog.String("outcome", "rejected"))
+			span.SetStatus(codes.Error, "unknown station")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		stationID = id
+	}
+
+	receivedAt := time.Now()
+	_, parseSpan := tracer.Start(ctx, "acurite.parse")
+	wq := api.merge(stationID, Translate(stationID, q), receivedAt)
+	dm, fieldErrs := wu.ParseMeasurement(wq, receivedAt)
+	parseSpan.End()
+	for _, fe := range fieldErrs {
+		span.RecordError(fe)
+		slog.Warn("Ignored invalid Acurite upload field",
+			slog.String("station_id", stationID),
+			slog.String("remote_addr", remoteAddr),
+			slog.String("param", fe.Param),
+			slog.String("value", fe.Value),
+			slog.Any("err", fe.Err))
+	}
+
+	slog.Info("Received Acurite weather data from station",
+		slog.String("station_id", stationID),
+		slog.String("remote_addr", remoteAddr),
+		slog.String("model", q.Get("mt")),
+		slog.String("sensor", q.Get("sensor")),
+		slog.String("outcome", "accepted"))
+
+	api.handleSubmission(ctx, wu.Submission{
+		StationID:   stationID,
+		ReceivedAt:  receivedAt,
+		RemoteAddr:  remoteAddr,
+		RawQuery:    wq.Encode(),
+		Measurement: dm,
+		Fields:      wu.SubmittedFields(wq, fieldErrs),
+		FieldErrors: fieldErrs,
+	})
+
+	// Hubs expect a JSON response, and set their clock from the local time.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "{\"localtime\":\"%s\"}\n", receivedAt.Format(time.TimeOnly))
+}
+
+// StationID returns the ID of the station that an upload is for. The readings
+// of outdoor sensor arrays are for the hub, identified by its MAC address
+// (id), and the readings of other sensors are for a station identified by the
+// hub's and the sensor's IDs, e.g. "24C86E012345-00002314".
+func StationID(q url.Values) string {
+	id := q.Get("id")
+	if _, ok := arrayModels[q.Get("mt")]; ok || q.Get("sensor") == "" {
+		return id
+	}
+	return id + "-" + q.Get("sensor")
+}
+
+// Translate translates an Acurite upload to the query parameters of an
+// equivalent WU submission. The light intensity reported by the Atlas, in
+// lux, is converted to solar radiation.
+func Translate(stationID string, q url.Values) url.Values {
+	wq := make(url.Values, len(q)+3)
+	for param, values := range q {
+		if wuParam, ok := renamedParams[param]; ok {
+			param = wuParam
+		}
+		wq[param] = values
+	}
+	if v := q.Get("lightintensity"); !wu.IsMissing(v) && !q.Has("solarradiation") {
+		if lux, err := strconv.ParseFloat(v, 64); err == nil {
+			v = strconv.FormatFloat(lux/luxPerWattM2, 'f', 1, 64)
+		}
+		wq.Set("solarradiation", v)
+	}
+	wq.Set("ID", stationID)
+	wq.Set("action", "updateraww")
+	wu.AddDewPoint(wq)
+	return wq
+}
+
+// merge stores the readings of a station received at t, and returns the
+// station's latest readings, excluding stale readings.
+func (api *UpdateAPI) merge(stationID string, q url.Values, t time.Time) url.Values {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	readings := api.readings[stationID]
+	if readings == nil {
+		readings = make(map[string]reading)
+		api.readings[stationID] = readings
+	}
+	for param := range q {
+		readings[param] = reading{time: t, value: q.Get(param)}
+	}
+	merged := make(url.Values, len(readings))
+	for param, r := range readings {
+		if t.Sub(r.time) > staleAfter {
+			delete(readings, param)
+			continue
+		}
+		merged.Set(param, r.value)
+	}
+	return merged
+}
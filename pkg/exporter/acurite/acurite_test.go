@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package acurite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+func TestUpload(t *testing.T) {
+	var got []wu.Submission
+	api := NewUpdateAPI(func(_ context.Context, s wu.Submission) {
+		got = append(got, s)
+	})
+	api.SetStationIDFunc(func(stationID string) (string, bool) {
+		return stationID, stationID != "unknown"
+	})
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	get := func(query string) *http.Response {
+		t.Helper()
+		res, err := ts.Client().Get(ts.URL + UpdatePath + "?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	if res := get("tempf=79.3"); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("missing id: got status %d, want %d", res.StatusCode, http.StatusBadRequest)
+	}
+	if res := get("id=unknown&mt=5N1&tempf=79.3"); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unknown station: got status %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+	if len(got) != 0 {
+		t.Fatalf("unexpected submissions from rejected uploads: %+v", got)
+	}
+
+	// The 5-in-1 alternates between temperature and rain readings.
+	res := get("dateutc=now&action=updateraw&realtime=1&id=24C86E012345&mt=5N1&sensor=00002314" +
+		"&windspeedmph=9&winddir=225&humidity=43&tempf=79.3&dewptf=55.3&baromin=29.91&battery=normal&rssi=3")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	var body struct {
+		LocalTime string `json:"localtime"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := time.Parse(time.TimeOnly, body.LocalTime); err != nil {
+		t.Errorf("localtime %q: %v", body.LocalTime, err)
+	}
+	get("dateutc=now&action=updateraw&realtime=1&id=24C86E012345&mt=5N1&sensor=00002314" +
+		"&windspeedmph=10&winddir=230&rainin=0.01&dailyrainin=0.25&baromin=29.91")
+	get("dateutc=now&action=updateraw&realtime=1&id=24C86E012345&mt=tower&sensor=00009876" +
+		"&humidity=60&tempf=68")
+
+	if len(got) != 3 {
+		t.Fatalf("got %d submissions, want 3", len(got))
+	}
+	s := got[1]
+	if s.StationID != "24C86E012345" {
+		t.Errorf("station ID = %q, want 24C86E012345", s.StationID)
+	}
+	q, err := url.ParseQuery(s.RawQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for param, want := range map[string]string{
+		"ID":           "24C86E012345",
+		"tempf":        "79.3",
+		"windspeedmph": "10",
+		"dailyrainin":  "0.25",
+	} {
+		if q.Get(param) != want {
+			t.Errorf("%s = %q, want %q", param, q.Get(param), want)
+		}
+	}
+	if s.Measurement.Temperature == 0 || s.Measurement.RainToday == 0 {
+		t.Errorf("got measurement %+v, want merged temperature and rain", s.Measurement)
+	}
+
+	if s := got[2]; s.StationID != "24C86E012345-00009876" {
+		t.Errorf("tower station ID = %q, want 24C86E012345-00009876", s.StationID)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	q := Translate("24C86E012345", url.Values{
+		"id":             {"24C86E012345"},
+		"mt":             {"Atlas"},
+		"tempf":          {"59"},
+		"humidity":       {"80"},
+		"uvindex":        {"3"},
+		"lightintensity": {"12670"},
+	})
+	for param, want := range map[string]string{
+		"ID":             "24C86E012345",
+		"action":         "updateraww",
+		"UV":             "3",
+		"solarradiation": "100.0",
+		"dewptf":         "52.8",
+	} {
+		if q.Get(param) != want {
+			t.Errorf("%s = %q, want %q", param, q.Get(param), want)
+		}
+	}
+}
+
+func TestStaleReadings(t *testing.T) {
+	api := NewUpdateAPI(nil)
+	now := time.Now()
+	api.merge("a", url.Values{"tempf": {"59"}}, now)
+
+	if q := api.merge("a", url.Values{"rainin": {"0"}}, now.Add(staleAfter/2)); !q.Has("tempf") {
+		t.Errorf("temperature missing from %v", q)
+	}
+	if q := api.merge("a", url.Values{"rainin": {"0"}}, now.Add(2*staleAfter)); q.Has("tempf") {
+		t.Errorf("stale temperature included in %v", q)
+	}
+}
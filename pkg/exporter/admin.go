@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// adminStation is a station in the admin API.
+type adminStation struct {
+	apiStation
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// maxSnapshotSize is the maximum size of a snapshot restored using the admin
+// API.
+const maxSnapshotSize = 64 << 20
+
+// adminToggle is a request to enable or disable a feature.
+type adminToggle struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// requireAdmin wraps the handler to require the admin credentials. If the
+// admin API is not enabled, all requests are responded to with 404 Not Found.
+func (e *Exporter) requireAdmin(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		e.cfgMu.RLock()
+		admin := e.admin
+		e.cfgMu.RUnlock()
+
+		if !admin.Enabled() {
+			http.NotFound(w, r)
+			return
+		}
+		username, password, ok := r.BasicAuth()
+		if !ok || !credentialsMatch(username, password, admin.Username, admin.Password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="pws_exporter admin"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// handleAdminStations serves all known stations, including the tenant each
+// station belongs to.
+func (e *Exporter) handleAdminStations(w http.ResponseWriter, _ *http.Request) {
+	e.cfgMu.RLock()
+	tenants := make(map[string]string, len(e.stationTenants))
+	for stationID, t := range e.stationTenants {
+		tenants[stationID] = t.name
+	}
+	e.cfgMu.RUnlock()
+
+	stations := make([]adminStation, 0)
+	for _, s := range e.status(func(string) bool { return true }).Stations {
+		stations = append(stations, adminStation{
+			apiStation: newAPIStation(s),
+			Tenant:     tenants[s.StationID],
+		})
+	}
+	writeJSON(w, http.StatusOK, stations)
+}
+
+// handleAdminDeleteStation deletes a station's series, state and stored
+// observations.
+func (e *Exporter) handleAdminDeleteStation(w http.ResponseWriter, r *http.Request) {
+	stationID := r.PathValue("id")
+	if err := e.deleteStation(r.Context(), stationID); err != nil {
+		slog.Error("Failed to delete station",
+			slog.String("station_id", stationID), slog.Any("err", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Deleted station", slog.String("station_id", stationID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminReload reloads the configuration file.
+func (e *Exporter) handleAdminReload(w http.ResponseWriter, _ *http.Request) {
+	if err := e.Reload(); err != nil {
+		slog.Error("Failed to reload configuration", slog.Any("err", err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminSnapshot serves a snapshot of the exporter state, in the same
+// format as the state file.
+func (e *Exporter) handleAdminSnapshot(w http.ResponseWriter, _ *http.Request) {
+	s := e.snapshotState()
+	w.Header().Set("Content-Disposition", `attachment; filename="pws_exporter-state.json"`)
+	writeJSON(w, http.StatusOK, s)
+}
+
+// handleAdminRestore restores the exporter state from a snapshot.
+func (e *Exporter) handleAdminRestore(w http.ResponseWriter, r *http.Request) {
+	var s state
+	r.Body = http.MaxBytesReader(w, r.Body, maxSnapshotSize)
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		http.Error(w, "invalid snapshot: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	e.restoreState(s)
+	e.stateDirty.Store(true)
+	slog.Info("Restored exporter state from snapshot", slog.Int("stations", len(s.Stations)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminSink enables or disables a sink.
+func (e *Exporter) handleAdminSink(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	h := e.sinkHealth(name)
+	if h == nil {
+		e.sinks.mu.RLock()
+		if rs := e.registeredSink(name); rs != nil {
+			h = &rs.health
+		}
+		e.sinks.mu.RUnlock()
+	}
+	if h == nil {
+		http.NotFound(w, r)
+		return
+	}
+	enabled, ok := decodeToggle(w, r)
+	if !ok {
+		return
+	}
+	h.disabled.Store(!enabled)
+	slog.Info("Updated sink", slog.String("sink", name), slog.Bool("enabled", enabled))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminMaintenance enables or disables maintenance mode, in which
+// received submissions are discarded.
+func (e *Exporter) handleAdminMaintenance(w http.ResponseWriter, r *http.Request) {
+	enabled, ok := decodeToggle(w, r)
+	if !ok {
+		return
+	}
+	e.maintenance.Store(enabled)
+	slog.Info("Updated maintenance mode", slog.Bool("enabled", enabled))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeToggle decodes an adminToggle request body. If the body is invalid, an
+// error response is written and ok is false.
+func decodeToggle(w http.ResponseWriter, r *http.Request) (enabled, ok bool) {
+	var t adminToggle
+	r.Body = http.MaxBytesReader(w, r.Body, 1024)
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil || t.Enabled == nil {
+		http.Error(w, `request body must be {"enabled": true|false}`, http.StatusBadRequest)
+		return false, false
+	}
+	return *t.Enabled, true
+}
+
+// sinkHealth returns the health of the named built-in sink, or nil if the
+// sink does not exist or is not configured.
+func (e *Exporter) sinkHealth(name string) *sinkHealth {
+	switch {
+	case name == "store" && e.store != nil:
+		return &e.storeHealth
+	case name == "journal" && e.journal != nil:
+		return &e.journalHealth
+	case name == "state" && e.statePath != "":
+		return &e.stateHealth
+	}
+	return nil
+}
+
+// deleteStation deletes a station's series, state and stored observations.
+func (e *Exporter) deleteStation(ctx context.Context, stationID string) error {
+	e.metricsFor(stationID).deleteStation(stationID)
+	e.history.delete(stationID)
+	e.rain.delete(stationID)
+	e.sunshine.delete(stationID)
+	e.rainIntensity.delete(stationID)
+	e.quality.delete(stationID)
+	e.rapidFire.discard(stationID)
+	e.lastSubmissions.delete(stationID)
+	if e.alerts != nil {
+		e.alerts.DeleteStation(stationID)
+	}
+	e.stateDirty.Store(true)
+	if e.store != nil {
+		if _, err := e.store.DeleteStation(ctx, stationID); err != nil {
+			return fmt.Errorf("delete stored observations: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/joshuasing/pws_exporter/internal/alert"
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+// alertConfig returns the alert engine configuration for the alerting
+// configuration.
+func alertConfig(c config.Alerts) (alert.Config, error) {
+	ac := alert.Config{Interval: c.Interval}
+	for _, r := range c.Rules {
+		var notifiers alert.Multi
+		if r.Webhook != "" {
+			notifiers = append(notifiers, &alert.Webhook{URL: r.Webhook})
+		}
+		for _, name := range r.Channels {
+			ch, ok := c.Channels[name]
+			if !ok {
+				return ac, fmt.Errorf("alert rule %q: unknown channel %q", r.Name, name)
+			}
+			notifiers = append(notifiers, channelNotifier(ch))
+		}
+
+		if r.Type == config.AlertTypeOffline {
+			ac.OfflineRules = append(ac.OfflineRules, alert.OfflineRule{
+				Name:     r.Name,
+				After:    r.After,
+				Stations: r.Stations,
+				Labels:   r.Labels,
+				Notifier: notifiers,
+			})
+			continue
+		}
+
+		if !slices.ContainsFunc(fields, func(f field) bool { return f.name == r.Field }) {
+			return ac, fmt.Errorf("alert rule %q: unknown field %q", r.Name, r.Field)
+		}
+		op, err := alert.ParseOperator(r.Operator)
+		if err != nil {
+			return ac, fmt.Errorf("alert rule %q: %w", r.Name, err)
+		}
+		ac.Rules = append(ac.Rules, alert.Rule{
+			Name:       r.Name,
+			Field:      r.Field,
+			Operator:   op,
+			Threshold:  r.Threshold,
+			For:        r.For,
+			Hysteresis: r.Hysteresis,
+			Stations:   r.Stations,
+			Labels:     r.Labels,
+			Notifier:   notifiers,
+		})
+	}
+
+	if o := c.Offline; o != nil {
+		notifiers := make(alert.Multi, 0, len(o.Channels))
+		for _, ch := range o.Channels {
+			notifiers = append(notifiers, channelNotifier(ch))
+		}
+		ac.OfflineRules = append(ac.OfflineRules, alert.OfflineRule{
+			Name:     "station_offline",
+			After:    o.After,
+			Stations: o.Stations,
+			Notifier: notifiers,
+		})
+	}
+
+	for _, s := range c.Silences {
+		days, err := s.Weekdays()
+		if err != nil {
+			return ac, fmt.Errorf("silence %q: %w", s.Name, err)
+		}
+		from, to, err := s.Window()
+		if err != nil {
+			return ac, fmt.Errorf("silence %q: %w", s.Name, err)
+		}
+		ac.Silences = append(ac.Silences, alert.Silence{
+			Name:     s.Name,
+			Start:    s.Start,
+			End:      s.End,
+			Days:     days,
+			From:     from,
+			To:       to,
+			Rules:    s.Rules,
+			Stations: s.Stations,
+			Labels:   s.Labels,
+		})
+	}
+	return ac, nil
+}
+
+// channelNotifier returns the notifier for a notification channel.
+func channelNotifier(c config.Channel) alert.Notifier {
+	switch c.Type {
+	case "discord":
+		return &alert.Discord{URL: c.URL}
+	case "telegram":
+		return &alert.Telegram{Token: c.BotToken, ChatID: c.ChatID}
+	case "smtp":
+		return &alert.SMTP{
+			Address:  c.Address,
+			Username: c.Username,
+			Password: c.Password,
+			From:     c.From,
+			To:       c.To,
+		}
+	default:
+		return &alert.Webhook{URL: c.URL}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package ambient implements the AmbientWeather.net upload protocol, used by
+// Ambient Weather stations (e.g. WS-2902 and WS-5000) that do not support the
+// Weather Underground protocol.
+//
+// Ambient Weather stations submit the same parameters as Ecowitt consoles, in
+// the query string of a GET request, so uploads are translated to the WU PWS
+// Upload Protocol the same way as Ecowitt reports.
+package ambient
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/ecowitt"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// tracer records spans for uploads. Spans are only recorded if a global
+// OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/exporter/ambient")
+
+// EndpointPath is the path that Ambient Weather stations send uploads to.
+const EndpointPath = "/endpoint"
+
+// EndpointAPI implements the AmbientWeather.net upload protocol. The station
+// is identified by its PASSKEY, which is the station's MAC address.
+type EndpointAPI struct {
+	handleSubmission func(ctx context.Context, s wu.Submission)
+	stationID        func(stationID string) (string, bool)
+}
+
+// NewEndpointAPI returns a new upload API. The handler is called synchronously
+// for each accepted upload, translated to a WU submission, before the
+// response is sent to the station.
+func NewEndpointAPI(handler func(ctx context.Context, s wu.Submission)) *EndpointAPI {
+	return &EndpointAPI{
+		handleSubmission: handler,
+	}
+}
+
+// SetStationIDFunc sets the function used to normalize the ID (PASSKEY) of the
+// station that sent an upload. If the function returns false, the upload is
+// rejected. It must be called before the API is served.
+func (api *EndpointAPI) SetStationIDFunc(fn func(stationID string) (string, bool)) {
+	api.stationID = fn
+}
+
+func (api *EndpointAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	remoteAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remoteAddr = req.RemoteAddr
+	}
+
+	ctx, span := tracer.Start(req.Context(), "ambient.upload",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("station_id", q.Get("PASSKEY")),
+			attribute.String("client.address", remoteAddr),
+		))
+	defer span.End()
+	if q.Get("PASSKEY") == "" {
+		slog.Warn("Rejected Ambient Weather upload with missing parameters",
+			slog.String("remote_addr", remoteAddr),
+			slog.String("outcome", "rejected"))
+		span.SetStatus(codes.Error, "missing parameters")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	stationID := q.Get("PASSKEY")
+	if api.stationID != nil {
+		id, ok := api.stationID(stationID)
+		if !ok {
+			slog.Warn("Rejected Ambient Weather upload from unknown station",
+				slog.String("station_id", stationID),
+				slog.String("remote_addr", remoteAddr),
+				slog.String("outcome", "rejected"))
+			span.SetStatus(codes.Error, "unknown station")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		stationID = id
+	}
+
+	receivedAt := time.Now()
+	_, parseSpan := tracer.Start(ctx, "ambient.parse")
+	wq := ecowitt.Translate(q)
+	dm, fieldErrs := wu.ParseMeasurement(wq, receivedAt)
+	parseSpan.End()
+	for _, fe := range fieldErrs {
+		span.RecordError(fe)
+		slog.Warn("Ignored invalid Ambient Weather upload field",
+			slog.String("station_id", stationID),
+			slog.String("remote_addr", remoteAddr),
+			slog.String("param", fe.Param),
+			slog.String("value", fe.Value),
+			slog.Any("err", fe.Err))
+	}
+
+	slog.Info("Received Ambient Weather data from station",
+		slog.String("station_id", stationID),
+		slog.String("remote_addr", remoteAddr),
+		slog.String("station_type", q.Get("stationtype")),
+		slog.String("outcome", "accepted"))
+
+	api.handleSubmission(ctx, wu.Submission{
+		StationID:   stationID,
+		ReceivedAt:  receivedAt,
+		RemoteAddr:  remoteAddr,
+		RawQuery:    wq.Encode(),
+		Measurement: dm,
+		Fields:      wu.SubmittedFields(wq, fieldErrs),
+		FieldErrors: fieldErrs,
+	})
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, "OK\n")
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ambient
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+const testUpload = EndpointPath + "?stationtype=AMBWeatherV4.3.4&PASSKEY=AA:BB:CC:DD:EE:FF&dateutc=2025-01-23+10:00:00" +
+	"&tempf=59.7&humidity=80&windspeedmph=2.5&windgustmph=4.5&maxdailygust=8.1&winddir=213&uv=4" +
+	"&solarradiation=512.3&hourlyrainin=0.020&dailyrainin=0.110&battout=1&tempinf=72.9&humidityin=43" +
+	"&baromrelin=29.898&baromabsin=29.610&temp1f=68.0&humidity1=51"
+
+func TestUpload(t *testing.T) {
+	var got *wu.Submission
+	api := NewEndpointAPI(func(_ context.Context, s wu.Submission) {
+		got = &s
+	})
+	api.SetStationIDFunc(func(stationID string) (string, bool) {
+		return stationID, stationID != "unknown"
+	})
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"missing passkey", "?tempf=59.7", http.StatusBadRequest},
+		{"unknown station", "?PASSKEY=unknown&tempf=59.7", http.StatusUnauthorized},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ts.Client().Get(ts.URL + EndpointPath + tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = res.Body.Close()
+			if res.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", res.StatusCode, tt.want)
+			}
+		})
+	}
+	if got != nil {
+		t.Fatalf("unexpected submission from rejected upload: %+v", got)
+	}
+
+	res, err := ts.Client().Get(ts.URL + testUpload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	if got == nil {
+		t.Fatal("upload was not handled")
+	}
+	if got.StationID != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("got station ID %q, want %q", got.StationID, "AA:BB:CC:DD:EE:FF")
+	}
+	if len(got.FieldErrors) != 0 {
+		t.Errorf("unexpected field errors: %v", got.FieldErrors)
+	}
+
+	dm := got.Measurement
+	for _, tt := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"temperature", dm.Temperature, 15.3889},
+		{"dew point", dm.DewPoint, 11.9444},
+		{"indoor temperature", dm.IndoorTemp, 22.7222},
+		{"indoor humidity", dm.IndoorHumidity, 43},
+		{"pressure", dm.Barometric, 1012.4625},
+		{"rain past hour", dm.RainPastHour, 0.508},
+	} {
+		if math.Abs(tt.got-tt.want) > 0.001 {
+			t.Errorf("got %s %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if len(dm.Channels) != 1 || dm.Channels[0].Channel != 1 {
+		t.Errorf("got channels %+v, want 1", dm.Channels)
+	}
+
+	q, err := url.ParseQuery(got.RawQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Get("ID") != "AA:BB:CC:DD:EE:FF" || q.Get("action") != "updateraww" {
+		t.Errorf("raw query is not a WU submission: %s", got.RawQuery)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/store"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/ingest"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// APIHandler returns a HTTP handler that serves the JSON API.
+func (e *Exporter) APIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/stations", e.handleStations)
+	mux.HandleFunc("GET /api/v1/stations/{id}", e.handleStation)
+	mux.HandleFunc("GET /api/v1/stations/{id}/latest", e.handleLatest)
+	mux.HandleFunc("GET /api/v1/stations/{id}/observations", e.handleObservations)
+	mux.HandleFunc("GET /api/v1/export.csv", e.handleExportCSV)
+	mux.HandleFunc("GET /api/v1/query", e.handleQuery)
+	mux.HandleFunc("GET /api/v1/gaps", e.handleGaps)
+	mux.HandleFunc("GET /api/v1/stream", e.handleStream)
+	mux.HandleFunc("GET /api/v1/grafana/{$}", e.handleGrafanaTest)
+	mux.HandleFunc("POST /api/v1/grafana/search", e.handleGrafanaSearch)
+	mux.HandleFunc("POST /api/v1/grafana/query", e.handleGrafanaQuery)
+
+	// Ingestion API
+	ingestAPI := ingest.NewAPI(e.handleWUSubmission)
+	ingestAPI.SetStationIDFunc(e.normalizeStationID)
+	ingestAPI.SetAuthorizeFunc(e.authorizedIngest)
+	mux.Handle("POST "+ingest.Path, ingestAPI)
+
+	// Admin API
+	mux.HandleFunc("GET /api/v1/admin/stations", e.requireAdmin(e.handleAdminStations))
+	mux.HandleFunc("DELETE /api/v1/admin/stations/{id}", e.requireAdmin(e.handleAdminDeleteStation))
+	mux.HandleFunc("POST /api/v1/admin/reload", e.requireAdmin(e.handleAdminReload))
+	mux.HandleFunc("GET /api/v1/admin/snapshot", e.requireAdmin(e.handleAdminSnapshot))
+	mux.HandleFunc("PUT /api/v1/admin/snapshot", e.requireAdmin(e.handleAdminRestore))
+	mux.HandleFunc("PUT /api/v1/admin/sinks/{name}", e.requireAdmin(e.handleAdminSink))
+	mux.HandleFunc("PUT /api/v1/admin/maintenance", e.requireAdmin(e.handleAdminMaintenance))
+	return mux
+}
+
+// authorizedIngest returns whether a request may submit measurements for a
+// station to the ingestion API. The request must have the ingest token or the
+// station's token as a bearer token, or the tenant's credentials if the
+// station belongs to a tenant. Requests are rejected if neither is configured.
+func (e *Exporter) authorizedIngest(r *http.Request, stationID string) bool {
+	e.cfgMu.RLock()
+	defer e.cfgMu.RUnlock()
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		for _, want := range []string{e.ingest.Token, e.ingest.StationTokens[stationID]} {
+			if want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+				return true
+			}
+		}
+	}
+	if t, ok := e.stationTenants[stationID]; ok {
+		username, password, ok := r.BasicAuth()
+		return ok && t.authenticate(username, password)
+	}
+	return false
+}
+
+// apiStation is a station in the JSON API.
+type apiStation struct {
+	StationID            string    `json:"station_id"`
+	LastSeen             time.Time `json:"last_seen"`
+	SubmissionsPerMinute float64   `json:"submissions_per_minute"`
+	RealTime             bool      `json:"realtime"`
+}
+
+// apiCurrent is the current conditions reported by a station in the JSON API.
+type apiCurrent struct {
+	apiStation
+	Conditions  []string       `json:"conditions"`
+	Observation apiObservation `json:"observation"`
+}
+
+// apiObservation is an observation in the JSON API, with explicit units.
+type apiObservation struct {
+	Time       time.Time           `json:"time"`
+	ReceivedAt time.Time           `json:"received_at"`
+	Values     map[string]apiValue `json:"values"`
+}
+
+// apiValue is a value with an explicit unit.
+type apiValue struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+func newAPIStation(s StationStatus) apiStation {
+	return apiStation{
+		StationID:            s.StationID,
+		LastSeen:             s.LastSeen,
+		SubmissionsPerMinute: s.SubmissionsPerMinute,
+		RealTime:             s.Latest.Measurement.RealTime,
+	}
+}
+
+func newAPIObservation(o weather.Observation) apiObservation {
+	values := make(map[string]apiValue, len(fields))
+	for _, f := range fields {
+		if o.Measurement.Has(f.param) {
+			values[f.name] = apiValue{Value: f.value(o), Unit: f.metric}
+		}
+	}
+	return apiObservation{
+		Time:       o.Measurement.DateUTC,
+		ReceivedAt: o.ReceivedAt,
+		Values:     values,
+	}
+}
+
+// handleStations serves the list of known stations.
+func (e *Exporter) handleStations(w http.ResponseWriter, r *http.Request) {
+	stations := make([]apiStation, 0)
+	for _, s := range e.Status(r).Stations {
+		stations = append(stations, newAPIStation(s))
+	}
+	writeJSON(w, http.StatusOK, stations)
+}
+
+// handleStation serves the current conditions reported by a station.
+func (e *Exporter) handleStation(w http.ResponseWriter, r *http.Request) {
+	stationID := r.PathValue("id")
+	for _, s := range e.Status(r).Stations {
+		if s.StationID == stationID {
+			writeJSON(w, http.StatusOK, apiCurrent{
+				apiStation:  newAPIStation(s),
+				Conditions:  classify(s.Latest.Measurement),
+				Observation: newAPIObservation(s.Latest),
+			})
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// handleLatest serves the most recent observation for a station.
+func (e *Exporter) handleLatest(w http.ResponseWriter, r *http.Request) {
+	stationID := r.PathValue("id")
+	if !e.authorized(r, stationID) {
+		http.NotFound(w, r)
+		return
+	}
+
+	o, ok := e.history.latest(stationID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, o)
+}
+
+// handleObservations serves the observations for a station that are stored in
+// memory, optionally filtered to those received after the "since" query
+// parameter (RFC 3339).
+func (e *Exporter) handleObservations(w http.ResponseWriter, r *http.Request) {
+	stationID := r.PathValue("id")
+	if !e.authorized(r, stationID) {
+		http.NotFound(w, r)
+		return
+	}
+
+	since, err := store.ParseTime(r.URL.Query().Get("since"))
+	if err != nil {
+		http.Error(w, "invalid since parameter", http.StatusBadRequest)
+		return
+	}
+
+	observations := e.history.since(stationID, since)
+	if observations == nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, observations)
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Debug("Failed to write JSON response", slog.Any("err", err))
+	}
+}
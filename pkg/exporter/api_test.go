@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/ingest"
+)
+
+func TestIngestAuthorization(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Tenants: []config.Tenant{
+			{Name: "a", Username: "a", Password: "pass-a", Stations: []string{"KA1"}},
+		},
+		Ingest: config.Ingest{
+			Token:         "token",
+			StationTokens: map[string]string{"garden": "garden-token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	type creds struct{ username, password string }
+	tts := []struct {
+		name      string
+		stationID string
+		token     string
+		creds     *creds
+		status    int
+	}{
+		{name: "unauthenticated", stationID: "garden", status: http.StatusUnauthorized},
+		{name: "wrong token", stationID: "garden", token: "wrong", status: http.StatusUnauthorized},
+		{name: "token", stationID: "garden", token: "token", status: http.StatusOK},
+		{name: "station token", stationID: "garden", token: "garden-token", status: http.StatusOK},
+		{name: "other station token", stationID: "shed", token: "garden-token", status: http.StatusUnauthorized},
+		{name: "tenant station without credentials", stationID: "KA1", status: http.StatusUnauthorized},
+		{name: "tenant station", stationID: "KA1", creds: &creds{"a", "pass-a"}, status: http.StatusOK},
+		{name: "tenant credentials", stationID: "garden", creds: &creds{"a", "pass-a"}, status: http.StatusUnauthorized},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"station_id":"` + tt.stationID + `","values":{"temperature":15.4}}`
+			req := httptest.NewRequest(http.MethodPost, ingest.Path, strings.NewReader(body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.creds != nil {
+				req.SetBasicAuth(tt.creds.username, tt.creds.password)
+			}
+			rec := httptest.NewRecorder()
+			e.APIHandler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d %q, want %d", rec.Code, rec.Body.String(), tt.status)
+			}
+			if tt.status == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestIngestNotConfigured(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	// Without an ingest token, unauthenticated measurements are refused.
+	body := `{"station_id":"garden","values":{"temperature":15.4}}`
+	req := httptest.NewRequest(http.MethodPost, ingest.Path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	e.APIHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if _, ok := e.history.latest("garden"); ok {
+		t.Error("refused measurement was recorded")
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package awekas implements the AWEKAS (Automatisches WEtterKArten System)
+// station upload protocol, which is supported by many European consoles and
+// weather station software.
+//
+// An upload is a GET request with a single "val" query parameter, which
+// contains the station's username, password hash, and metric measurement
+// values, separated by semicolons. Uploads are translated to the Weather
+// Underground PWS Upload Protocol, so that they are processed, journaled and
+// forwarded the same way as WU submissions.
+package awekas
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// tracer records spans for uploads. Spans are only recorded if a global
+// OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/exporter/awekas")
+
+// UploadPath is the path that stations send AWEKAS uploads to.
+const UploadPath = "/eingabe_pruefung.php"
+
+// Positions of the values in an upload. Values that are not listed are not
+// used by the exporter.
+const (
+	valUsername      = 0  // Station username
+	valTemperature   = 4  // Temperature, °C
+	valHumidity      = 5  // Humidity, %
+	valPressure      = 6  // Barometric (sea level) pressure, hPa
+	valRainToday     = 7  // Rain since midnight, mm
+	valWindSpeed     = 8  // Wind speed, km/h
+	valWindDirection = 9  // Wind direction, degrees
+	valSnowDepth     = 12 // Snow depth, cm
+	valWindGust      = 15 // Wind gust, km/h
+	valSolar         = 16 // Solar radiation, W/m²
+	valSunshine      = 19 // Sunshine duration since midnight, hours
+	valSoftware      = 22 // Station software or firmware
+)
+
+// UploadAPI implements the AWEKAS station upload protocol. The station is
+// identified by its AWEKAS username.
+type UploadAPI struct {
+	handleSubmission func(ctx context.Context, s wu.Submission)
+	stationID        func(stationID string) (string, bool)
+}
+
+// NewUploadAPI returns a new upload API. The handler is called synchronously
+// for each accepted upload, translated to a WU submission, before the
+// response is sent to the station.
+func NewUploadAPI(handler func(ctx context.Context, s wu.Submission)) *UploadAPI {
+	return &UploadAPI{
+		handleSubmission: handler,
+	}
+}
+
+// SetStationIDFunc sets the function used to normalize the ID (username) of
+// the station that sent an upload. If the function returns false, the upload
+// is rejected. It must be called before the API is served.
+func (api *UploadAPI) SetStationIDFunc(fn func(stationID string) (string, bool)) {
+	api.stationID = fn
+}
+
+func (api *UploadAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	vals := values(req.URL.RawQuery)
+	remoteAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remoteAddr = req.RemoteAddr
+	}
+
+	username := value(vals, valUsername)
+	ctx, span := tracer.Start(req.Context(), "awekas.upload",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("station_id", username),
+			attribute.String("client.address", remoteAddr),
+		))
+	defer span.End()
+	if username == "" {
+		slog.Warn("Rejected AWEKAS upload with missing parameters",
+			slog.String("remote_addr", remoteAddr),
+			slog.String("outcome", "rejected"))
+		span.SetStatus(codes.Error, "missing parameters")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	stationID := username
+	if api.stationID != nil {
+		id, ok := api.stationID(stationID)
+		if !ok {
+			slog.Warn("Rejected AWEKAS upload from unknown station",
+				slog.String("station_id", stationID),
+				slog.String("remote_addr", remoteAddr),
+				slog.String("outcome", "rejected"))
+			span.SetStatus(codes.Error, "unknown station")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		stationID = id
+	}
+
+	receivedAt := time.Now()
+	_, parseSpan := tracer.Start(ctx, "awekas.parse")
+	q := Translate(vals)
+	dm, fieldErrs := wu.ParseMeasurement(q, receivedAt)
+	parseSpan.End()
+	for _, fe := range fieldErrs {
+		span.RecordError(fe)
+		slog.Warn("Ignored invalid AWEKAS upload field",
+			slog.String("station_id", stationID),
+			slog.String("remote_addr", remoteAddr),
+			slog.String("param", fe.Param),
+			slog.String("value", fe.Value),
+			slog.Any("err", fe.Err))
+	}
+
+	slog.Info("Received AWEKAS weather data from station",
+		slog.String("station_id", stationID),
+		slog.String("remote_addr", remoteAddr),
+		slog.String("software", value(vals, valSoftware)),
+		slog.String("outcome", "accepted"))
+
+	api.handleSubmission(ctx, wu.Submission{
+		StationID:   stationID,
+		ReceivedAt:  receivedAt,
+		RemoteAddr:  remoteAddr,
+		RawQuery:    q.Encode(),
+		Measurement: dm,
+		Fields:      wu.SubmittedFields(q, fieldErrs),
+		FieldErrors: fieldErrs,
+	})
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, "OK\n")
+}
+
+// values returns the semicolon-separated values of the "val" parameter of the
+// raw query string. The query is parsed by hand, as url.ParseQuery rejects
+// parameters that contain semicolons.
+func values(rawQuery string) []string {
+	for _, p := range strings.Split(rawQuery, "&") {
+		key, v, _ := strings.Cut(p, "=")
+		if key != "val" {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(v); err == nil {
+			v = unescaped
+		}
+		return strings.Split(v, ";")
+	}
+	return nil
+}
+
+// value returns the trimmed value at position i, or an empty string if the
+// upload does not include it.
+func value(vals []string, i int) string {
+	if i >= len(vals) {
+		return ""
+	}
+	return strings.TrimSpace(vals[i])
+}
+
+// params maps the positions of AWEKAS values to the equivalent parameters of
+// the WU PWS Upload Protocol, and the conversion of the metric value to the
+// units of the parameter. Snow depth and sunshine duration are not part of
+// the WU protocol, and are passed in AWEKAS units.
+var params = []struct {
+	pos     int
+	param   string
+	convert func(float64) float64
+}{
+	{valTemperature, "tempf", wu.CToF},
+	{valHumidity, "humidity", nil},
+	{valPressure, "baromin", wu.HPAToInHg},
+	{valRainToday, "dailyrainin", wu.MMToIn},
+	{valWindSpeed, "windspeedmph", wu.KPHToMPH},
+	{valWindDirection, "winddir", nil},
+	{valSnowDepth, "snow_depth_cm", nil},
+	{valWindGust, "windgustmph", wu.KPHToMPH},
+	{valSolar, "solarradiation", nil},
+	{valSunshine, "sunshine_hours", nil},
+}
+
+// Translate translates the values of an AWEKAS upload to the query parameters
+// of an equivalent WU submission. The username is used as the station ID, and
+// the dew point, which AWEKAS uploads do not include, is derived from the
+// temperature and humidity.
+//
+// The upload's date and time are in the station's local time, so they are not
+// used, and observations are timestamped when they are received. Values that
+// cannot be parsed are passed through unconverted, so that they are reported
+// as field errors, and missing sentinel values (e.g. -9999) are dropped.
+func Translate(vals []string) url.Values {
+	q := url.Values{}
+	q.Set("ID", value(vals, valUsername))
+	q.Set("action", "updateraww")
+	q.Set("dateutc", "now")
+	for _, p := range params {
+		v := value(vals, p.pos)
+		if wu.IsMissing(v) {
+			continue
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil && p.convert != nil {
+			v = strconv.FormatFloat(p.convert(f), 'f', -1, 64)
+		}
+		q.Set(p.param, v)
+	}
+	wu.AddDewPoint(q)
+	return q
+}
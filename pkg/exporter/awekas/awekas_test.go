@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package awekas
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// testUpload is an upload as sent by WeeWX, with snow depth and sunshine
+// duration added.
+const testUpload = UploadPath + "?val=teststation;5f4dcc3b5aa765d61d8327deb882cf99;23.01.2025;10:00;15.4;80;1012.5;2.8;9.0;213" +
+	";;;12.5;en;;14.5;512.3;4;;3.5;;0.0;weewx_5.1.0;16.37;48.21"
+
+func TestUpload(t *testing.T) {
+	var got *wu.Submission
+	api := NewUploadAPI(func(_ context.Context, s wu.Submission) {
+		got = &s
+	})
+	api.SetStationIDFunc(func(stationID string) (string, bool) {
+		return stationID, stationID != "unknown"
+	})
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"missing val", "", http.StatusBadRequest},
+		{"missing username", "?val=;hash;23.01.2025;10:00;15.4", http.StatusBadRequest},
+		{"unknown station", "?val=unknown;hash;23.01.2025;10:00;15.4", http.StatusUnauthorized},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ts.Client().Get(ts.URL + UploadPath + tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = res.Body.Close()
+			if res.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", res.StatusCode, tt.want)
+			}
+		})
+	}
+	if got != nil {
+		t.Fatalf("unexpected submission from rejected upload: %+v", got)
+	}
+
+	res, err := ts.Client().Get(ts.URL + testUpload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	if got == nil {
+		t.Fatal("upload was not handled")
+	}
+	if got.StationID != "teststation" {
+		t.Errorf("got station ID %q, want %q", got.StationID, "teststation")
+	}
+	if len(got.FieldErrors) != 0 {
+		t.Errorf("unexpected field errors: %v", got.FieldErrors)
+	}
+
+	dm := got.Measurement
+	for _, tt := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"temperature", dm.Temperature, 15.4},
+		{"humidity", dm.Humidity, 80},
+		{"dew point", dm.DewPoint, 11.9444}, // Derived, rounded to 0.1 °F
+		{"pressure", dm.Barometric, 1012.5},
+		{"rain today", dm.RainToday, 2.8},
+		{"wind speed", dm.WindSpeed, 9},
+		{"wind direction", dm.WindDirection, 213},
+		{"wind gust", dm.WindGust, 14.5},
+		{"solar radiation", dm.SolarRadiation, 512.3},
+	} {
+		if math.Abs(tt.got-tt.want) > 0.001 {
+			t.Errorf("got %s %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if dm.SnowDepth == nil || *dm.SnowDepth != 125 {
+		t.Errorf("got snow depth %v, want 125 mm", dm.SnowDepth)
+	}
+	if dm.SunshineToday == nil || *dm.SunshineToday != 12600 {
+		t.Errorf("got sunshine %v, want 12600 seconds", dm.SunshineToday)
+	}
+
+	q, err := url.ParseQuery(got.RawQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Get("ID") != "teststation" || q.Get("action") != "updateraww" {
+		t.Errorf("raw query is not a WU submission: %s", got.RawQuery)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	// Missing and malformed values.
+	q := Translate([]string{"teststation", "hash", "23.01.2025", "10:00", "warm", "", "1012.5"})
+	if q.Get("tempf") != "warm" {
+		t.Errorf("got tempf %q, want the unconverted value", q.Get("tempf"))
+	}
+	for _, param := range []string{"humidity", "dewptf", "dailyrainin", "snow_depth_cm"} {
+		if q.Has(param) {
+			t.Errorf("got %s %q, want it to be missing", param, q.Get(param))
+		}
+	}
+	_, errs := wu.ParseMeasurement(q, time.Now())
+	if len(errs) != 1 || errs[0].Param != "tempf" {
+		t.Errorf("got field errors %v, want tempf", errs)
+	}
+}
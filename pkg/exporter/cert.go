@@ -0,0 +1,190 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// defaultCertValidity is the default validity period of the generated TLS
+// certificate.
+const defaultCertValidity = 10 * 365 * 24 * time.Hour
+
+// certCheckInterval is the maximum interval between checks of whether the TLS
+// certificate needs to be renewed.
+const certCheckInterval = time.Hour
+
+// certManager manages the self-signed TLS certificate used by the WU HTTPS
+// server, and renews it in the background once 90% of its validity period
+// has passed. The certificate is served using tls.Config.GetCertificate, so
+// a renewed certificate is used by new connections without a restart.
+type certManager struct {
+	validity time.Duration
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newCertManager generates a certificate with the given validity period, and
+// starts renewing it in the background.
+func newCertManager(validity time.Duration) (*certManager, error) {
+	m := &certManager{
+		validity: validity,
+		quit:     make(chan struct{}),
+	}
+	if err := m.rotate(); err != nil {
+		return nil, err
+	}
+	m.wg.Add(1)
+	go m.renewLoop()
+	return m, nil
+}
+
+// getCertificate returns the current certificate.
+func (m *certManager) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cert, nil
+}
+
+// notAfter returns the expiry time of the current certificate.
+func (m *certManager) notAfter() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cert.Leaf.NotAfter
+}
+
+// rotate generates a new certificate, replacing the current certificate.
+func (m *certManager) rotate() error {
+	slog.Debug("Generating temporary self-signed TLS certificate")
+	cert, err := genTLSCertificate(m.validity)
+	if err != nil {
+		return fmt.Errorf("generate self signed certificate: %w", err)
+	}
+	m.mu.Lock()
+	m.cert = &cert
+	m.mu.Unlock()
+	slog.Debug("Generated self-signed TLS certificate",
+		slog.Time("not_after", cert.Leaf.NotAfter))
+	return nil
+}
+
+// needsRenewal returns whether 90% of the current certificate's validity
+// period has passed at t.
+func (m *certManager) needsRenewal(t time.Time) bool {
+	m.mu.RLock()
+	leaf := m.cert.Leaf
+	m.mu.RUnlock()
+	lifetime := leaf.NotAfter.Sub(leaf.NotBefore)
+	return !t.Before(leaf.NotAfter.Add(-lifetime / 10))
+}
+
+// renewLoop periodically renews the certificate until the manager is closed.
+func (m *certManager) renewLoop() {
+	defer m.wg.Done()
+	t := time.NewTicker(min(certCheckInterval, m.validity/20))
+	defer t.Stop()
+	for {
+		select {
+		case <-m.quit:
+			return
+		case now := <-t.C:
+			if !m.needsRenewal(now) {
+				continue
+			}
+			if err := m.rotate(); err != nil {
+				slog.Error("Failed to renew TLS certificate", slog.Any("err", err))
+				continue
+			}
+			slog.Info("Renewed TLS certificate", slog.Time("not_after", m.notAfter()))
+		}
+	}
+}
+
+// close stops renewing the certificate.
+func (m *certManager) close() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// genTLSCertificate generates a temporary self-signed in-memory TLS
+// certificate with an RSA 2048-bit private key.
+//
+// This is not designed to be, nor needs to be secure, as it is only used for
+// TLS connections between the Weather Station and the exporter's WU API server.
+//
+// This only works if the Weather Station accepts any TLS certificate, which
+// appears to be the case most of the time.
+func genTLSCertificate(validity time.Duration) (tls.Certificate, error) {
+	var outCert tls.Certificate
+
+	// Generate private key
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return outCert, fmt.Errorf("generate RSA 2048 private key: %s", err)
+	}
+
+	// Generate certificate serial number
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return outCert, fmt.Errorf("generate serial number: %s", err)
+	}
+
+	// Create certificate
+	now := time.Now()
+	t := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: "Personal Weather Station Exporter",
+		},
+		NotBefore:             now,
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              interceptedDomains,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, &t, &t, priv.Public(), priv)
+	if err != nil {
+		return outCert, fmt.Errorf("create certificate: %w", err)
+	}
+
+	outCert.Leaf, err = x509.ParseCertificate(cert)
+	if err != nil {
+		return outCert, fmt.Errorf("parse certificate: %w", err)
+	}
+	outCert.Certificate = append(outCert.Certificate, cert)
+	outCert.PrivateKey = priv
+	return outCert, nil
+}
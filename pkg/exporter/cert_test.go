@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCertManagerRenewal(t *testing.T) {
+	m, err := newCertManager(time.Second)
+	if err != nil {
+		t.Fatalf("new cert manager: %v", err)
+	}
+	defer m.close()
+
+	first, _ := m.getCertificate(nil)
+	for _, domain := range interceptedDomains {
+		if err := first.Leaf.VerifyHostname(domain); err != nil {
+			t.Errorf("certificate is not valid for %s: %v", domain, err)
+		}
+	}
+	if m.needsRenewal(first.Leaf.NotBefore) {
+		t.Error("new certificate needs renewal")
+	}
+	if !m.needsRenewal(first.Leaf.NotAfter.Add(-50 * time.Millisecond)) {
+		t.Error("certificate does not need renewal close to expiry")
+	}
+
+	// The certificate is renewed in the background once 90% of its validity
+	// period has passed.
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cert, _ := m.getCertificate(nil); cert != first {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("certificate was not renewed")
+}
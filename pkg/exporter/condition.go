@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// Condition classification thresholds.
+const (
+	freezingCelsius   = 0    // Temperature at or below which it is freezing
+	fogSpreadCelsius  = 2.5  // Dew point spread at or below which fog is likely
+	windySpeedKPH     = 30   // Wind speed at or above which it is windy
+	windyGustSpeedKPH = 50   // Wind gust speed at or above which it is windy
+	rainingMM         = 0.05 // Rain over the past hour above which it is raining
+)
+
+// condition is a simple weather condition derived from an observation.
+type condition struct {
+	name  string
+	check func(dm wu.DeviceMeasurement) bool
+
+	// params are the WU query parameters of the fields the condition is
+	// derived from, which must all be measured to classify it.
+	params []string
+}
+
+// known returns whether the fields the condition is derived from were
+// measured.
+func (c condition) known(dm wu.DeviceMeasurement) bool {
+	for _, param := range c.params {
+		if !dm.Has(param) {
+			return false
+		}
+	}
+	return true
+}
+
+// conditions are the weather conditions that observations are classified
+// into. A measurement can match several conditions, except dry and raining,
+// which are mutually exclusive.
+var conditions = []condition{
+	{name: "dry", check: func(dm wu.DeviceMeasurement) bool { return !raining(dm) }, params: []string{"rainin"}},
+	{name: "raining", check: raining, params: []string{"rainin"}},
+	{name: "freezing", check: func(dm wu.DeviceMeasurement) bool {
+		return dm.Temperature <= freezingCelsius
+	}, params: []string{"tempf"}},
+	{name: "fog_likely", check: func(dm wu.DeviceMeasurement) bool {
+		// Stations without a humidity sensor report neither humidity nor
+		// dew point.
+		return dm.Humidity > 0 && dm.Temperature-dm.DewPoint <= fogSpreadCelsius
+	}, params: []string{"tempf", "humidity", "dewptf"}},
+	{name: "windy", check: func(dm wu.DeviceMeasurement) bool {
+		return dm.WindSpeed >= windySpeedKPH || dm.WindGust >= windyGustSpeedKPH
+	}, params: []string{"windspeedmph"}},
+}
+
+// raining returns whether it is raining, based on the rain over the past hour.
+func raining(dm wu.DeviceMeasurement) bool {
+	return dm.RainPastHour > rainingMM
+}
+
+// classify returns the names of the conditions that match the measurement.
+// Conditions derived from missing fields do not match.
+func classify(dm wu.DeviceMeasurement) []string {
+	names := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		if c.known(dm) && c.check(dm) {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
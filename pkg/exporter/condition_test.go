@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+func TestClassify(t *testing.T) {
+	tts := []struct {
+		name string
+		dm   wu.DeviceMeasurement
+		want []string
+	}{
+		{
+			name: "dry",
+			dm:   wu.DeviceMeasurement{Temperature: 20, DewPoint: 10, Humidity: 52},
+			want: []string{"dry"},
+		},
+		{
+			name: "raining and windy",
+			dm:   wu.DeviceMeasurement{Temperature: 12, DewPoint: 8, Humidity: 76, RainPastHour: 2.4, WindGust: 55},
+			want: []string{"raining", "windy"},
+		},
+		{
+			name: "freezing fog",
+			dm:   wu.DeviceMeasurement{Temperature: -1.5, DewPoint: -2, Humidity: 96},
+			want: []string{"dry", "freezing", "fog_likely"},
+		},
+		{
+			name: "no humidity sensor",
+			dm:   wu.DeviceMeasurement{Temperature: 5, WindSpeed: 30},
+			want: []string{"dry", "windy"},
+		},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classify(tt.dm); !slices.Equal(got, tt.want) {
+				t.Errorf("classify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/store"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// handleExportCSV serves stored observations for a station as CSV.
+//
+// Query parameters:
+//   - station: the station ID (required)
+//   - from, to: the time range to export (RFC 3339, optional)
+//   - columns: comma-separated list of columns to include (default all)
+//   - units: "metric" (default) or "imperial"
+func (e *Exporter) handleExportCSV(w http.ResponseWriter, r *http.Request) {
+	if e.store == nil {
+		http.Error(w, "observation store is not enabled", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	stationID := q.Get("station")
+	if stationID == "" {
+		http.Error(w, "station parameter is required", http.StatusBadRequest)
+		return
+	}
+	if !e.authorized(r, stationID) {
+		http.NotFound(w, r)
+		return
+	}
+
+	from, err := store.ParseTime(q.Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from parameter", http.StatusBadRequest)
+		return
+	}
+	to, err := store.ParseTime(q.Get("to"))
+	if err != nil {
+		http.Error(w, "invalid to parameter", http.StatusBadRequest)
+		return
+	}
+
+	var imperial bool
+	switch q.Get("units") {
+	case "", "metric":
+	case "imperial":
+		imperial = true
+	default:
+		http.Error(w, "invalid units parameter", http.StatusBadRequest)
+		return
+	}
+
+	columns, err := selectFields(q.Get("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	header := []string{"time", "station_id"}
+	for _, c := range columns {
+		unit := c.metric
+		if imperial {
+			unit = c.imperial
+		}
+		header = append(header, c.name+"_"+unit)
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="export.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(header)
+
+	record := make([]string, len(header))
+	err = e.store.Observations(r.Context(), store.Query{
+		StationID: stationID,
+		From:      from,
+		To:        to,
+	}, func(o weather.Observation) error {
+		record[0] = o.Measurement.DateUTC.Format(time.RFC3339)
+		record[1] = o.StationID
+		for i, c := range columns {
+			if !o.Measurement.Has(c.param) {
+				// Fields the station did not measure are empty.
+				record[i+2] = ""
+				continue
+			}
+			v := c.value(o)
+			if imperial && c.toImperial != nil {
+				v = c.toImperial(v)
+			}
+			record[i+2] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return cw.Write(record)
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		// Headers have already been written, so the error cannot be returned
+		// to the client.
+		slog.Error("Failed to export observations", slog.Any("err", err))
+	}
+}
+
+// selectFields returns the fields named in the comma-separated list, or all
+// fields if the list is empty.
+func selectFields(list string) ([]field, error) {
+	if list == "" {
+		return fields, nil
+	}
+
+	var columns []field
+	for _, name := range strings.Split(list, ",") {
+		var found bool
+		for _, c := range fields {
+			if c.name == name {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+	return columns, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// debugSubmission is the most recent raw submission received from a station.
+type debugSubmission struct {
+	StationID   string               `json:"station_id"`
+	ReceivedAt  time.Time            `json:"received_at"`
+	RemoteAddr  string               `json:"remote_addr"`
+	Polled      bool                 `json:"polled"`
+	RawQuery    string               `json:"raw_query"`
+	Measurement wu.DeviceMeasurement `json:"measurement"`
+	FieldErrors []debugFieldError    `json:"field_errors"`
+}
+
+// debugFieldError is a submitted field that could not be parsed.
+type debugFieldError struct {
+	Param string `json:"param"`
+	Value string `json:"value"`
+	Error string `json:"error"`
+}
+
+// lastSubmissions stores the most recent raw submission for each station.
+type lastSubmissions struct {
+	mu          sync.Mutex
+	submissions map[string]debugSubmission
+}
+
+// set records the submission, with the password redacted.
+func (l *lastSubmissions) set(s wu.Submission) {
+	d := debugSubmission{
+		StationID:   s.StationID,
+		ReceivedAt:  s.ReceivedAt,
+		RemoteAddr:  s.RemoteAddr,
+		Polled:      s.Polled,
+		RawQuery:    wu.RedactQuery(s.RawQuery),
+		Measurement: s.Measurement,
+		FieldErrors: make([]debugFieldError, 0, len(s.FieldErrors)),
+	}
+	for _, fe := range s.FieldErrors {
+		d.FieldErrors = append(d.FieldErrors, debugFieldError{
+			Param: fe.Param,
+			Value: fe.Value,
+			Error: fe.Err.Error(),
+		})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.submissions == nil {
+		l.submissions = make(map[string]debugSubmission)
+	}
+	l.submissions[s.StationID] = d
+}
+
+// get returns the most recent submission for a station.
+func (l *lastSubmissions) get(stationID string) (debugSubmission, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	d, ok := l.submissions[stationID]
+	return d, ok
+}
+
+// delete removes the submission for a station.
+func (l *lastSubmissions) delete(stationID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.submissions, stationID)
+}
+
+// handleLastSubmission serves the most recent raw submission for a station,
+// including the parsed measurement and any fields that could not be parsed.
+func (e *Exporter) handleLastSubmission(w http.ResponseWriter, r *http.Request) {
+	stationID := r.PathValue("station")
+	if !e.authorized(r, stationID) {
+		http.NotFound(w, r)
+		return
+	}
+	d, ok := e.lastSubmissions.get(stationID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
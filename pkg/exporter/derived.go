@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"math"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// Magnus formula coefficients for the saturation vapour pressure over water
+// and over ice, from WMO-No. 8 (2018), Annex 4.B.
+const (
+	magnusHPA    = 6.112
+	magnusWaterA = 17.62
+	magnusWaterB = 243.12
+	magnusIceA   = 22.46
+	magnusIceB   = 272.62
+)
+
+// vapourPressure returns the vapour pressure in hectopascals for the
+// temperature in Celsius and the relative humidity in percent, which weather
+// stations report with respect to water, even below freezing.
+func vapourPressure(tempC, humidity float64) float64 {
+	return humidity / 100 * magnusHPA * math.Exp(magnusWaterA*tempC/(magnusWaterB+tempC))
+}
+
+// frostPoint returns the frost point in Celsius, the temperature at which the
+// air becomes saturated with respect to ice and frost forms. The frost point
+// is only defined below freezing, and is not returned if the temperature is
+// above freezing or the station did not report humidity.
+func frostPoint(dm wu.DeviceMeasurement) (float64, bool) {
+	if !dm.Has("tempf") || !dm.Has("humidity") || dm.Temperature > 0 || dm.Humidity <= 0 {
+		return 0, false
+	}
+	x := math.Log(vapourPressure(dm.Temperature, dm.Humidity) / magnusHPA)
+	return magnusIceB * x / (magnusIceA - x), true
+}
+
+// epsilon is the ratio of the molar masses of water vapour and dry air.
+const epsilon = 0.622
+
+// moistureContent returns the mixing ratio and specific humidity in grams of
+// water vapour per kilogram of dry air and of moist air respectively, derived
+// from the temperature, humidity and pressure. They are not returned if the
+// station did not report humidity or pressure.
+func moistureContent(dm wu.DeviceMeasurement) (mixingRatio, specificHumidity float64, ok bool) {
+	if !dm.Has("tempf") || !dm.Has("humidity") || !dm.Has("baromin") || dm.Humidity <= 0 || dm.Barometric <= 0 {
+		return 0, 0, false
+	}
+	e, p := vapourPressure(dm.Temperature, dm.Humidity), dm.Barometric
+	mixingRatio = 1000 * epsilon * e / (p - e)
+	specificHumidity = 1000 * epsilon * e / (p - (1-epsilon)*e)
+	return mixingRatio, specificHumidity, true
+}
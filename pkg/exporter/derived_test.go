@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+func TestFrostPoint(t *testing.T) {
+	tts := []struct {
+		name   string
+		dm     wu.DeviceMeasurement
+		want   float64
+		wantOK bool
+	}{
+		{name: "below freezing", dm: wu.DeviceMeasurement{Temperature: -10, Humidity: 80}, want: -11.39, wantOK: true},
+		{name: "saturated at freezing", dm: wu.DeviceMeasurement{Temperature: 0, Humidity: 100}, want: 0, wantOK: true},
+		{name: "above freezing", dm: wu.DeviceMeasurement{Temperature: 5, Humidity: 80}},
+		{name: "no humidity", dm: wu.DeviceMeasurement{Temperature: -10}},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := frostPoint(tt.dm)
+			if ok != tt.wantOK {
+				t.Fatalf("frostPoint() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("frostPoint() = %.3f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoistureContent(t *testing.T) {
+	w, q, ok := moistureContent(wu.DeviceMeasurement{Temperature: 25, Humidity: 60, Barometric: 1013.25})
+	if !ok {
+		t.Fatal("moistureContent() ok = false")
+	}
+	if math.Abs(w-11.86) > 0.01 {
+		t.Errorf("mixing ratio = %.3f, want 11.86", w)
+	}
+	if math.Abs(q-11.72) > 0.01 {
+		t.Errorf("specific humidity = %.3f, want 11.72", q)
+	}
+
+	if _, _, ok := moistureContent(wu.DeviceMeasurement{Temperature: 25, Humidity: 60}); ok {
+		t.Error("moistureContent() without pressure ok = true")
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/dns"
+)
+
+// dnsListener is a DNS server listener.
+type dnsListener struct {
+	// name is the listener name, used in logs and metrics.
+	name string
+
+	// address is the listen address.
+	address string
+
+	// answer and answer6 are the IPv4 and IPv6 addresses that the WU
+	// domains are answered with. Either may be empty.
+	answer  string
+	answer6 string
+}
+
+// exporterIPs selects the exporter IPv4 and IPv6 addresses, which may be
+// given as an IP address, a CIDR or an interface name (see selectIP). If ip
+// selects an IPv6 address, it is used as the IPv6 address, and there is no
+// IPv4 address.
+func exporterIPs(ip, ip6 string) (string, string, error) {
+	var v4, v6 string
+	if ip != "" {
+		selected, err := selectIP(ip, false)
+		if err != nil {
+			return "", "", fmt.Errorf("exporter IP: %w", err)
+		}
+		if selected.To4() != nil {
+			v4 = selected.To4().String()
+		} else {
+			v6 = selected.String()
+		}
+	}
+	if ip6 != "" {
+		parsed, err := selectIP(ip6, true)
+		if err != nil {
+			return "", "", fmt.Errorf("exporter IPv6 address: %w", err)
+		}
+		if parsed.To4() != nil {
+			return "", "", fmt.Errorf("exporter IPv6 address %q is an IPv4 address", ip6)
+		}
+		if v6 != "" && v6 != parsed.String() {
+			return "", "", errors.New("exporter IP and exporter IPv6 address are both IPv6 addresses")
+		}
+		v6 = parsed.String()
+	}
+	return v4, v6, nil
+}
+
+// resolverAddress returns the upstream resolver address with the default DNS
+// port added, if it does not have a port. IPv6 addresses may be given with or
+// without brackets.
+func resolverAddress(address string) string {
+	if address == "" {
+		return address
+	}
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(strings.Trim(address, "[]"), "53")
+}
+
+// dnsListeners parses a comma-separated list of DNS server listen addresses.
+// The host of each address may be an IP address (IPv6 addresses in brackets),
+// or a CIDR or network interface name, which is replaced with the selected
+// local address (see selectIP).
+//
+// Unless the exporter IP was set explicitly, a listener bound to a specific
+// address answers the intercepted domains with that address, so that each
+// interface on a multi-homed host hands out its own address. Listeners bound
+// to all addresses answer with the exporter IPs.
+func dnsListeners(addresses, exporterIP, exporterIPv6 string, explicitIP bool) ([]dnsListener, error) {
+	var listeners []dnsListener
+	for _, address := range strings.Split(addresses, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DNS listen address %q: %w", address, err)
+		}
+		if host != "" && net.ParseIP(host) == nil {
+			ip, err := selectIP(host, false)
+			if err != nil {
+				return nil, fmt.Errorf("DNS listen address %q: %w", address, err)
+			}
+			host = ip.String()
+			address = net.JoinHostPort(host, port)
+		}
+
+		l := dnsListener{name: "dns", address: address, answer: exporterIP, answer6: exporterIPv6}
+		if ip := net.ParseIP(host); !explicitIP && ip != nil && !ip.IsUnspecified() {
+			if ip.To4() != nil {
+				l.answer = ip.String()
+			} else {
+				l.answer6 = ip.String()
+			}
+		}
+		listeners = append(listeners, l)
+	}
+
+	// Listeners are named by address if there is more than one, so that
+	// their metrics can be told apart.
+	if len(listeners) > 1 {
+		for i := range listeners {
+			listeners[i].name = "dns@" + listeners[i].address
+		}
+	}
+	return listeners, nil
+}
+
+// dnsServers tracks the running DNS servers, so that their records can be
+// updated when the DNS configuration is reloaded.
+type dnsServers struct {
+	upstreamResolver string
+
+	mu      sync.Mutex
+	config  config.DNS
+	running map[*dns.Server]dnsListener
+}
+
+// add creates a DNS server for the listener using the current configuration,
+// and tracks it until remove is called.
+func (s *dnsServers) add(l dnsListener, notifyStarted func()) *dns.Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.serverConfig(l)
+	c.NotifyStarted = notifyStarted
+	srv := dns.NewServer(c)
+	if s.running == nil {
+		s.running = make(map[*dns.Server]dnsListener)
+	}
+	s.running[srv] = l
+	return srv
+}
+
+// remove stops tracking a DNS server that is no longer running.
+func (s *dnsServers) remove(srv *dns.Server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, srv)
+}
+
+// set replaces the DNS configuration and updates the records of the running
+// servers. Queries are answered throughout the update.
+func (s *dnsServers) set(c config.DNS) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = c
+	for srv, l := range s.running {
+		srv.Update(s.serverConfig(l))
+	}
+}
+
+// configs returns the DNS server configuration of each listener.
+func (s *dnsServers) configs(listeners []dnsListener) []dns.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	configs := make([]dns.Config, 0, len(listeners))
+	for _, l := range listeners {
+		configs = append(configs, s.serverConfig(l))
+	}
+	return configs
+}
+
+// serverConfig returns the DNS server configuration for the listener. The
+// intercepted domains, and records with the value "exporter", are answered
+// with the listener's addresses. s.mu must be held.
+func (s *dnsServers) serverConfig(l dnsListener) dns.Config {
+	records := make(map[string]string, len(interceptedDomains)+len(s.config.Records))
+	aaaaRecords := make(map[string]string, len(interceptedDomains)+len(s.config.Records))
+	answer := func(domain, ip, ip6 string) {
+		domain = dnsName(domain)
+		if ip != "" {
+			records[domain] = ip
+		}
+		if ip6 != "" {
+			aaaaRecords[domain] = ip6
+		}
+	}
+	for _, domain := range interceptedDomains {
+		answer(domain, l.answer, l.answer6)
+	}
+	for domain, value := range s.config.Records {
+		switch ip := net.ParseIP(value); {
+		case value == config.DNSExporterAddress:
+			answer(domain, l.answer, l.answer6)
+		case ip.To4() != nil:
+			answer(domain, ip.String(), "")
+		default:
+			answer(domain, "", ip.String())
+		}
+	}
+
+	forward := make([]string, 0, len(forwardDomains)+len(s.config.ForwardDomains))
+	forward = append(forward, forwardDomains...)
+	for _, domain := range s.config.ForwardDomains {
+		forward = append(forward, dnsName(domain))
+	}
+	return dns.Config{
+		UpstreamResolver: s.upstreamResolver,
+		Records:          records,
+		AAAARecords:      aaaaRecords,
+		ForwardDomains:   forward,
+	}
+}
+
+// dnsName returns the fully qualified, lowercase form of a domain name, as
+// matched by the DNS server.
+func dnsName(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, ".")) + "."
+}
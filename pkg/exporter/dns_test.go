@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"net"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+func TestDNSListeners(t *testing.T) {
+	tests := []struct {
+		name       string
+		addresses  string
+		explicitIP bool
+		want       []dnsListener
+		wantErr    bool
+	}{
+		{
+			name:      "empty",
+			addresses: "",
+		},
+		{
+			name:      "all addresses",
+			addresses: ":53",
+			want:      []dnsListener{{name: "dns", address: ":53", answer: "192.0.2.1", answer6: "2001:db8::1"}},
+		},
+		{
+			name:      "multiple addresses",
+			addresses: "192.168.1.2:53, 10.0.20.2:53,0.0.0.0:5353,[2001:db8::2]:53",
+			want: []dnsListener{
+				{name: "dns@192.168.1.2:53", address: "192.168.1.2:53", answer: "192.168.1.2", answer6: "2001:db8::1"},
+				{name: "dns@10.0.20.2:53", address: "10.0.20.2:53", answer: "10.0.20.2", answer6: "2001:db8::1"},
+				{name: "dns@0.0.0.0:5353", address: "0.0.0.0:5353", answer: "192.0.2.1", answer6: "2001:db8::1"},
+				{name: "dns@[2001:db8::2]:53", address: "[2001:db8::2]:53", answer: "192.0.2.1", answer6: "2001:db8::2"},
+			},
+		},
+		{
+			name:       "explicit exporter IP",
+			addresses:  "192.168.1.2:53",
+			explicitIP: true,
+			want:       []dnsListener{{name: "dns", address: "192.168.1.2:53", answer: "192.0.2.1", answer6: "2001:db8::1"}},
+		},
+		{
+			name:      "IPv6 without brackets",
+			addresses: "2001:db8::2:53",
+			wantErr:   true,
+		},
+		{
+			name:      "missing port",
+			addresses: "192.168.1.2",
+			wantErr:   true,
+		},
+		{
+			name:      "unknown interface",
+			addresses: "nonexistent0:53",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dnsListeners(tt.addresses, "192.0.2.1", "2001:db8::1", tt.explicitIP)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dnsListeners() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dnsListeners() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSListenersInterface(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Skipf("list interfaces: %v", err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+		ip, err := interfaceIP(&iface, false)
+		if err != nil {
+			continue
+		}
+		got, err := dnsListeners(iface.Name+":53", "192.0.2.1", "", false)
+		if err != nil {
+			t.Fatalf("dnsListeners() error = %v", err)
+		}
+		want := []dnsListener{{name: "dns", address: net.JoinHostPort(ip.String(), "53"), answer: ip.String()}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("dnsListeners() = %+v, want %+v", got, want)
+		}
+		return
+	}
+	t.Skip("no loopback interface with an IPv4 address")
+}
+
+func TestExporterIPs(t *testing.T) {
+	tests := []struct {
+		ip, ip6        string
+		wantV4, wantV6 string
+		wantErr        bool
+	}{
+		{ip: "192.0.2.1", wantV4: "192.0.2.1"},
+		{ip: "192.0.2.1", ip6: "2001:db8::1", wantV4: "192.0.2.1", wantV6: "2001:db8::1"},
+		{ip: "2001:db8::1", wantV6: "2001:db8::1"},
+		{ip6: "2001:DB8::1", wantV6: "2001:db8::1"},
+		{ip: "2001:db8::1", ip6: "2001:db8::2", wantErr: true},
+		{ip6: "192.0.2.1", wantErr: true},
+		{ip: "exporter.local", wantErr: true},
+	}
+	for _, tt := range tests {
+		v4, v6, err := exporterIPs(tt.ip, tt.ip6)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("exporterIPs(%q, %q) error = %v, wantErr %v", tt.ip, tt.ip6, err, tt.wantErr)
+			continue
+		}
+		if v4 != tt.wantV4 || v6 != tt.wantV6 {
+			t.Errorf("exporterIPs(%q, %q) = %q, %q, want %q, %q", tt.ip, tt.ip6, v4, v6, tt.wantV4, tt.wantV6)
+		}
+	}
+}
+
+func TestResolverAddress(t *testing.T) {
+	tests := map[string]string{
+		"":                          "",
+		"8.8.8.8:53":                "8.8.8.8:53",
+		"8.8.8.8":                   "8.8.8.8:53",
+		"[2001:4860:4860::8888]:53": "[2001:4860:4860::8888]:53",
+		"[2001:4860:4860::8888]":    "[2001:4860:4860::8888]:53",
+		"2001:4860:4860::8888":      "[2001:4860:4860::8888]:53",
+	}
+	for in, want := range tests {
+		if got := resolverAddress(in); got != want {
+			t.Errorf("resolverAddress(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDNSServersConfig(t *testing.T) {
+	var s dnsServers
+	l := dnsListener{answer: "192.0.2.1", answer6: "2001:db8::1"}
+	s.set(config.DNS{
+		Records: map[string]string{
+			"api.ecowitt.net":  config.DNSExporterAddress,
+			"Example.com.":     "192.0.2.10",
+			"ipv6.example.com": "2001:db8::10",
+		},
+		ForwardDomains: []string{"pool.ntp.org"},
+	})
+
+	c := s.serverConfig(l)
+	wantRecords := map[string]string{
+		"api.ecowitt.net.": "192.0.2.1",
+		"example.com.":     "192.0.2.10",
+	}
+	wantAAAA := map[string]string{
+		"api.ecowitt.net.":  "2001:db8::1",
+		"ipv6.example.com.": "2001:db8::10",
+	}
+	for _, domain := range interceptedDomains {
+		wantRecords[domain+"."] = "192.0.2.1"
+		wantAAAA[domain+"."] = "2001:db8::1"
+	}
+	if !reflect.DeepEqual(c.Records, wantRecords) {
+		t.Errorf("Records = %v, want %v", c.Records, wantRecords)
+	}
+	if !reflect.DeepEqual(c.AAAARecords, wantAAAA) {
+		t.Errorf("AAAARecords = %v, want %v", c.AAAARecords, wantAAAA)
+	}
+	if !slices.Contains(c.ForwardDomains, "pool.ntp.org.") || !slices.Contains(c.ForwardDomains, "time.nist.gov.") {
+		t.Errorf("ForwardDomains = %v, want pool.ntp.org. and the default domains", c.ForwardDomains)
+	}
+}
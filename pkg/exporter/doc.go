@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package exporter captures data submitted by personal weather stations to
+// external APIs (such as Weather Underground), by answering the station's DNS
+// queries for the API domains with the exporter's address and serving the
+// submission APIs, and exports the data as Prometheus metrics.
+//
+// The exporter can be embedded in other programs:
+//
+//	ex, err := exporter.NewExporter(exporter.Config{
+//		ExporterIP:       "192.168.1.10",
+//		DNSListenAddress: ":53",
+//		Hooks: []exporter.Hook{{
+//			Stage:     exporter.StageValidate,
+//			Processor: exporter.ProcessorFunc(validate),
+//		}},
+//	})
+//	if err != nil {
+//		// ...
+//	}
+//	if err := ex.RegisterSink("database", sink); err != nil {
+//		// ...
+//	}
+//	if err := ex.ListenAndServe(ctx); err != nil {
+//		// ...
+//	}
+//
+// Observations are processed by a chain of processors, see Processor and
+// Hook, and then recorded to the exporter's metrics and written to each Sink.
+// Sinks that implement SinkCloser are closed on shutdown, after the queued
+// observations have been processed.
+package exporter
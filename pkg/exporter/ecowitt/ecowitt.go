@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package ecowitt implements the Ecowitt custom server upload protocol, used
+// by Ecowitt consoles and gateways (e.g. GW1000, GW2000 and WS2910) to send
+// data to a user-configured server.
+//
+// Reports are translated to the Weather Underground PWS Upload Protocol, so
+// that they are processed, journaled and forwarded the same way as WU
+// submissions.
+package ecowitt
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// tracer records spans for reports. Spans are only recorded if a global
+// OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/exporter/ecowitt")
+
+// ReportPath is the default path that Ecowitt consoles send reports to.
+const ReportPath = "/data/report/"
+
+// ReportAPI implements the Ecowitt custom server upload protocol. Reports are
+// form-encoded POST requests, and the station is identified by its PASSKEY,
+// a hash of the console's MAC address.
+type ReportAPI struct {
+	handleSubmission func(ctx context.Context, s wu.Submission)
+	stationID        func(stationID string) (string, bool)
+}
+
+// NewReportAPI returns a new report API. The handler is called synchronously
+// for each accepted report, translated to a WU submission, before the
+// response is sent to the station.
+func NewReportAPI(handler func(ctx context.Context, s wu.Submission)) *ReportAPI {
+	return &ReportAPI{
+		handleSubmission: handler,
+	}
+}
+
+// SetStationIDFunc sets the function used to normalize the ID (PASSKEY) of the
+// station that sent a report. If the function returns false, the report is
+// rejected. It must be called before the API is served.
+func (api *ReportAPI) SetStationIDFunc(fn func(stationID string) (string, bool)) {
+	api.stationID = fn
+}
+
+func (api *ReportAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	remoteAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remoteAddr = req.RemoteAddr
+	}
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if err := req.ParseForm(); err != nil {
+		slog.Warn("Rejected malformed Ecowitt report",
+			slog.String("remote_addr", remoteAddr),
+			slog.String("outcome", "rejected"),
+			slog.Any("err", err))
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	form := req.PostForm
+
+	ctx, span := tracer.Start(req.Context(), "ecowitt.report",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("station_id", form.Get("PASSKEY")),
+			attribute.String("client.address", remoteAddr),
+		))
+	defer span.End()
+	if form.Get("PASSKEY") == "" {
+		slog.Warn("Rejected Ecowitt report with missing parameters",
+			slog.String("remote_addr", remoteAddr),
+			slog.String("outcome", "rejected"))
+		span.SetStatus(codes.Error, "missing parameters")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	stationID := form.Get("PASSKEY")
+	if api.stationID != nil {
+		id, ok := api.stationID(stationID)
+		if !ok {
+			slog.Warn("Rejected Ecowitt report from unknown station",
+				slog.String("station_id", stationID),
+				slog.String("remote_addr", remoteAddr),
+				slog.String("outcome", "rejected"))
+			span.SetStatus(codes.Error, "unknown station")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		stationID = id
+	}
+
+	receivedAt := time.Now()
+	_, parseSpan := tracer.Start(ctx, "ecowitt.parse")
+	q := Translate(form)
+	dm, fieldErrs := wu.ParseMeasurement(q, receivedAt)
+	parseSpan.End()
+	for _, fe := range fieldErrs {
+		span.RecordError(fe)
+		slog.Warn("Ignored invalid Ecowitt report field",
+			slog.String("station_id", stationID),
+			slog.String("remote_addr", remoteAddr),
+			slog.String("param", fe.Param),
+			slog.String("value", fe.Value),
+			slog.Any("err", fe.Err))
+	}
+
+	slog.Info("Received Ecowitt weather data from station",
+		slog.String("station_id", stationID),
+		slog.String("remote_addr", remoteAddr),
+		slog.String("model", form.Get("model")),
+		slog.String("station_type", form.Get("stationtype")),
+		slog.String("outcome", "accepted"))
+
+	api.handleSubmission(ctx, wu.Submission{
+		StationID:   stationID,
+		ReceivedAt:  receivedAt,
+		RemoteAddr:  remoteAddr,
+		RawQuery:    q.Encode(),
+		Measurement: dm,
+		Fields:      wu.SubmittedFields(q, fieldErrs),
+		FieldErrors: fieldErrs,
+	})
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, "OK\n")
+}
+
+// renamedParams maps Ecowitt report parameters to the equivalent parameters
+// of the WU PWS Upload Protocol. Other parameters use the same names in both
+// protocols, or are specific to Ecowitt and are passed through unchanged.
+var renamedParams = map[string]string{
+	"tempinf":      "indoortempf",
+	"humidityin":   "indoorhumidity",
+	"baromrelin":   "baromin",
+	"hourlyrainin": "rainin",
+}
+
+// Translate translates an Ecowitt report to the query parameters of an
+// equivalent WU submission. The PASSKEY is used as the station ID, and the
+// dew point, which Ecowitt consoles do not report, is derived from the
+// temperature and humidity.
+func Translate(form url.Values) url.Values {
+	q := make(url.Values, len(form)+3)
+	for param, values := range form {
+		if wuParam, ok := renamedParams[param]; ok {
+			param = wuParam
+		}
+		q[param] = values
+	}
+	q.Del("PASSKEY")
+	q.Set("ID", form.Get("PASSKEY"))
+	q.Set("action", "updateraww")
+	wu.AddDewPoint(q)
+	return q
+}
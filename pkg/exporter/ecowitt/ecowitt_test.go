@@ -0,0 +1,172 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ecowitt
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+const testReport = "PASSKEY=0123456789ABCDEF0123456789ABCDEF&stationtype=GW2000A_V3.1.2&runtime=12&dateutc=2025-01-23+10:00:00" +
+	"&tempinf=72.9&humidityin=43&baromrelin=29.898&baromabsin=29.610&tempf=59.7&humidity=80&winddir=213" +
+	"&windspeedmph=2.5&windgustmph=4.5&maxdailygust=8.1&solarradiation=512.3&uv=4&rainratein=0.000" +
+	"&hourlyrainin=0.020&dailyrainin=0.110&temp1f=68.0&humidity1=51&temp3f=41.0&humidity3=92&wh65batt=0" +
+	"&freq=915M&model=GW2000A"
+
+func TestReport(t *testing.T) {
+	var got *wu.Submission
+	api := NewReportAPI(func(_ context.Context, s wu.Submission) {
+		got = &s
+	})
+	api.SetStationIDFunc(func(stationID string) (string, bool) {
+		return "ecowitt-" + strings.ToLower(stationID[:4]), stationID != "unknown"
+	})
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{"get", http.MethodGet, "", http.StatusMethodNotAllowed},
+		{"missing passkey", http.MethodPost, "tempf=59.7", http.StatusBadRequest},
+		{"unknown station", http.MethodPost, "PASSKEY=unknown&tempf=59.7", http.StatusUnauthorized},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL+ReportPath, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			res, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = res.Body.Close()
+			if res.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", res.StatusCode, tt.want)
+			}
+		})
+	}
+	if got != nil {
+		t.Fatalf("unexpected submission from rejected report: %+v", got)
+	}
+
+	res, err := ts.Client().Post(ts.URL+ReportPath, "application/x-www-form-urlencoded", strings.NewReader(testReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	if got == nil {
+		t.Fatal("report was not handled")
+	}
+	if got.StationID != "ecowitt-0123" {
+		t.Errorf("got station ID %q, want %q", got.StationID, "ecowitt-0123")
+	}
+	if len(got.FieldErrors) != 0 {
+		t.Errorf("unexpected field errors: %v", got.FieldErrors)
+	}
+
+	dm := got.Measurement
+	if want := "2025-01-23 10:00:00"; dm.DateUTC.Format("2006-01-02 15:04:05") != want {
+		t.Errorf("got date %v, want %s", dm.DateUTC, want)
+	}
+	for _, tt := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"temperature", dm.Temperature, 15.3889},
+		{"humidity", dm.Humidity, 80},
+		{"dew point", dm.DewPoint, 11.9444}, // Derived, rounded to 0.1 °F
+		{"indoor temperature", dm.IndoorTemp, 22.7222},
+		{"indoor humidity", dm.IndoorHumidity, 43},
+		{"pressure", dm.Barometric, 1012.4625},
+		{"rain past hour", dm.RainPastHour, 0.508},
+		{"rain today", dm.RainToday, 2.794},
+		{"solar radiation", dm.SolarRadiation, 512.3},
+	} {
+		if math.Abs(tt.got-tt.want) > 0.001 {
+			t.Errorf("got %s %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if dm.AbsBarometric == nil {
+		t.Error("absolute pressure was not parsed")
+	} else if math.Abs(*dm.AbsBarometric-1002.7097) > 0.001 {
+		t.Errorf("got absolute pressure %v, want 1002.7097", *dm.AbsBarometric)
+	}
+	if len(dm.Channels) != 2 || dm.Channels[0].Channel != 1 || dm.Channels[1].Channel != 3 {
+		t.Errorf("got channels %+v, want 1 and 3", dm.Channels)
+	}
+
+	q, err := url.ParseQuery(got.RawQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Get("ID") != "0123456789ABCDEF0123456789ABCDEF" || q.Has("PASSKEY") {
+		t.Errorf("raw query does not use the PASSKEY as the station ID: %s", got.RawQuery)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	form, err := url.ParseQuery("PASSKEY=abc&tempinf=72.9&humidityin=43&baromrelin=29.898&hourlyrainin=0.02&tempf=59.7&humidity=80&temp2f=70.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := Translate(form)
+	for param, want := range map[string]string{
+		"ID":             "abc",
+		"action":         "updateraww",
+		"indoortempf":    "72.9",
+		"indoorhumidity": "43",
+		"baromin":        "29.898",
+		"rainin":         "0.02",
+		"tempf":          "59.7",
+		"dewptf":         "53.5",
+		"temp2f":         "70.1",
+	} {
+		if got := q.Get(param); got != want {
+			t.Errorf("got %s %q, want %q", param, got, want)
+		}
+	}
+	for _, param := range []string{"PASSKEY", "tempinf", "humidityin", "baromrelin", "hourlyrainin"} {
+		if q.Has(param) {
+			t.Errorf("translated query has Ecowitt parameter %s", param)
+		}
+	}
+
+	// The dew point is not derived without humidity.
+	form.Del("humidity")
+	if q := Translate(form); q.Has("dewptf") {
+		t.Errorf("got dew point %q without humidity", q.Get("dewptf"))
+	}
+}
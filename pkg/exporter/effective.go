@@ -0,0 +1,193 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+// EffectiveConfig is the effective configuration of an exporter: the
+// configuration with defaults filled in, the exporter IP addresses and DNS
+// records resolved, and secrets redacted. The listen addresses of disabled
+// servers are empty.
+type EffectiveConfig struct {
+	ListenAddress string `yaml:"listen_address"`
+
+	// ExporterIP and ExporterIPv6 are the addresses that the intercepted
+	// domains are answered with. ExporterIPDetected is whether ExporterIP was
+	// detected from the address used to reach the internet.
+	ExporterIP         string `yaml:"exporter_ip"`
+	ExporterIPv6       string `yaml:"exporter_ipv6"`
+	ExporterIPDetected bool   `yaml:"exporter_ip_detected"`
+
+	UpstreamResolver string                 `yaml:"upstream_resolver"`
+	DNSListeners     []EffectiveDNSListener `yaml:"dns_listeners"`
+
+	WUListenAddress    string        `yaml:"wu_listen_address"`
+	WUTLSListenAddress string        `yaml:"wu_tls_listen_address"`
+	WUSinglePort       bool          `yaml:"wu_single_port"`
+	SingleServer       bool          `yaml:"single_server"`
+	WUTLS              bool          `yaml:"wu_tls"`
+	CertValidity       time.Duration `yaml:"cert_validity"`
+	ReusePort          bool          `yaml:"reuse_port"`
+
+	FeedPath          string `yaml:"feed_path"`
+	SNMPListenAddress string `yaml:"snmp_listen_address"`
+	SNMPCommunity     string `yaml:"snmp_community"`
+	SNMPRootOID       string `yaml:"snmp_root_oid"`
+	GRPCListenAddress string `yaml:"grpc_listen_address"`
+	WeeWXAddress      string `yaml:"weewx_address"`
+
+	ConfigPath     string        `yaml:"config_path"`
+	HistorySize    int           `yaml:"history_size"`
+	RateWindow     time.Duration `yaml:"rate_window"`
+	StorePath      string        `yaml:"store_path"`
+	StoreRetention time.Duration `yaml:"store_retention"`
+	StatePath      string        `yaml:"state_path"`
+	JournalPath    string        `yaml:"journal_path"`
+
+	// Config is the configuration file, including changes applied by
+	// reloading it.
+	Config config.Config `yaml:"config"`
+}
+
+// EffectiveDNSListener is a DNS server listener, and the records it answers.
+type EffectiveDNSListener struct {
+	Name           string            `yaml:"name"`
+	Address        string            `yaml:"address"`
+	Records        map[string]string `yaml:"records"`
+	AAAARecords    map[string]string `yaml:"aaaa_records"`
+	ForwardDomains []string          `yaml:"forward_domains"`
+}
+
+// ResolveConfig returns the effective configuration of an exporter created
+// with c, without creating the exporter.
+func ResolveConfig(c Config) (*EffectiveConfig, error) {
+	rc, err := resolveConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	servers := dnsServers{upstreamResolver: rc.UpstreamResolver}
+	servers.set(rc.DNS)
+	return rc.effective(&servers), nil
+}
+
+// EffectiveConfig returns the effective configuration of the exporter.
+func (e *Exporter) EffectiveConfig() *EffectiveConfig {
+	e.cfgMu.RLock()
+	rc := e.config
+	e.cfgMu.RUnlock()
+	return rc.effective(&e.dnsServers)
+}
+
+// effective returns the effective configuration, using the records of the
+// DNS servers.
+func (rc resolvedConfig) effective(servers *dnsServers) *EffectiveConfig {
+	ec := &EffectiveConfig{
+		ListenAddress:      rc.ListenAddress,
+		ExporterIP:         rc.ExporterIP,
+		ExporterIPv6:       rc.ExporterIPv6,
+		ExporterIPDetected: rc.detectedIP,
+		UpstreamResolver:   rc.UpstreamResolver,
+		DNSListeners:       make([]EffectiveDNSListener, 0, len(rc.dnsListeners)),
+		WUListenAddress:    rc.WUListenAddress,
+		WUTLSListenAddress: rc.WUTLSListenAddress,
+		WUSinglePort:       rc.WUSinglePort,
+		SingleServer:       rc.SingleServer,
+		WUTLS:              rc.wuTLSEnabled(),
+		CertValidity:       rc.CertValidity,
+		ReusePort:          rc.ReusePort,
+		FeedPath:           rc.FeedPath,
+		SNMPListenAddress:  rc.SNMPListenAddress,
+		SNMPCommunity:      config.Redacted,
+		SNMPRootOID:        rc.SNMPRootOID,
+		GRPCListenAddress:  rc.GRPCListenAddress,
+		WeeWXAddress:       rc.WeeWXAddress,
+		ConfigPath:         rc.ConfigPath,
+		HistorySize:        rc.HistorySize,
+		RateWindow:         rc.RateWindow,
+		StorePath:          rc.StorePath,
+		StoreRetention:     rc.StoreRetention,
+		StatePath:          rc.StatePath,
+		JournalPath:        rc.JournalPath,
+		Config: config.Config{
+			Tenants:             rc.Tenants,
+			Stations:            rc.Stations,
+			Sites:               rc.Sites,
+			NormalizeStationIDs: rc.StationIDs,
+			Relabel:             rc.Relabel,
+			Store:               config.Store{Downsample: rc.StoreDownsample},
+			Admin:               rc.Admin,
+			Ingest:              rc.Ingest,
+			WUServer:            rc.WUServer,
+			Alerts:              rc.Alerts,
+			WUForward:           rc.WUForward,
+			OpenSenseMap:        rc.OpenSenseMap,
+			Federation:          rc.Federation,
+			GrafanaAnnotations:  rc.GrafanaAnnotations,
+			DNS:                 rc.DNS,
+		}.Redacted(),
+	}
+	for i, c := range servers.configs(rc.dnsListeners) {
+		ec.DNSListeners = append(ec.DNSListeners, EffectiveDNSListener{
+			Name:           rc.dnsListeners[i].name,
+			Address:        rc.dnsListeners[i].address,
+			Records:        c.Records,
+			AAAARecords:    c.AAAARecords,
+			ForwardDomains: c.ForwardDomains,
+		})
+	}
+	return ec
+}
+
+// handleDebugConfig serves the effective configuration as YAML. If admin
+// credentials are configured, they are required.
+func (e *Exporter) handleDebugConfig(w http.ResponseWriter, r *http.Request) {
+	e.cfgMu.RLock()
+	adminEnabled := e.admin.Enabled()
+	e.cfgMu.RUnlock()
+
+	serve := func(w http.ResponseWriter, _ *http.Request) {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(e.EffectiveConfig()); err != nil {
+			slog.Error("Failed to encode effective configuration", slog.Any("err", err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+	}
+	if adminEnabled {
+		e.requireAdmin(serve)(w, r)
+		return
+	}
+	serve(w, r)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	c := Config{
+		ExporterIP:       "192.0.2.1",
+		DNSListenAddress: ":53",
+		Tenants: []config.Tenant{
+			{Name: "a", Username: "a", Password: "secret", Stations: []string{"A"}},
+		},
+		DNS: config.DNS{Records: map[string]string{"api.ecowitt.net": config.DNSExporterAddress}},
+	}
+	ec, err := ResolveConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ec.ExporterIP != "192.0.2.1" || ec.ExporterIPDetected {
+		t.Errorf("exporter IP = %q (detected %t), want 192.0.2.1", ec.ExporterIP, ec.ExporterIPDetected)
+	}
+	if ec.WUListenAddress != ":80" || ec.UpstreamResolver != "" {
+		t.Errorf("defaults not resolved: WU listen address %q, upstream resolver %q",
+			ec.WUListenAddress, ec.UpstreamResolver)
+	}
+	if len(ec.DNSListeners) != 1 {
+		t.Fatalf("got %d DNS listeners, want 1", len(ec.DNSListeners))
+	}
+	if got := ec.DNSListeners[0].Records["api.ecowitt.net."]; got != "192.0.2.1" {
+		t.Errorf("api.ecowitt.net. record = %q, want 192.0.2.1", got)
+	}
+	if got := ec.Config.Tenants[0].Password; got != config.Redacted {
+		t.Errorf("tenant password = %q, want %q", got, config.Redacted)
+	}
+	if c.Tenants[0].Password != "secret" {
+		t.Error("ResolveConfig modified the configuration")
+	}
+}
+
+func TestDebugConfigHandler(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Admin:      config.Admin{Username: "admin", Password: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+	h := e.Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("without credentials: status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+	req.SetBasicAuth("admin", "secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "exporter_ip: 127.0.0.1") {
+		t.Errorf("body does not contain the exporter IP:\n%s", body)
+	}
+	if strings.Contains(body, "secret") {
+		t.Errorf("body contains the admin password:\n%s", body)
+	}
+}
+
+func TestEffectiveConfigListenOff(t *testing.T) {
+	tests := []struct {
+		name       string
+		c          Config
+		wantWU     string
+		wantWUTLS  string
+		wantTLS    bool
+		wantListen string
+	}{
+		{name: "defaults", wantWU: ":80", wantWUTLS: ":443", wantTLS: true},
+		{name: "TLS off", c: Config{WUTLSListenAddress: ListenOff}, wantWU: ":80"},
+		{name: "WU off", c: Config{WUListenAddress: ListenOff}, wantWUTLS: ":443", wantTLS: true},
+		{
+			name:      "single port with WU off",
+			c:         Config{WUListenAddress: ListenOff, WUSinglePort: true},
+			wantWUTLS: ":443",
+		},
+		{
+			name:   "metrics off",
+			c:      Config{ListenAddress: ListenOff, DNSListenAddress: ListenOff},
+			wantWU: ":80", wantWUTLS: ":443", wantTLS: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.c.ExporterIP = "127.0.0.1"
+			ec, err := ResolveConfig(tt.c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ec.WUListenAddress != tt.wantWU || ec.WUTLSListenAddress != tt.wantWUTLS {
+				t.Errorf("WU listen addresses = %q, %q, want %q, %q",
+					ec.WUListenAddress, ec.WUTLSListenAddress, tt.wantWU, tt.wantWUTLS)
+			}
+			if ec.WUTLS != tt.wantTLS {
+				t.Errorf("WUTLS = %t, want %t", ec.WUTLS, tt.wantTLS)
+			}
+			if ec.ListenAddress != tt.wantListen || len(ec.DNSListeners) != 0 {
+				t.Errorf("listen address = %q, DNS listeners = %v, want %q and none",
+					ec.ListenAddress, ec.DNSListeners, tt.wantListen)
+			}
+		})
+	}
+}
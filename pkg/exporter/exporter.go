@@ -0,0 +1,1044 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/joshuasing/pws_exporter/internal/alert"
+	"github.com/joshuasing/pws_exporter/internal/federation"
+	"github.com/joshuasing/pws_exporter/internal/grafana"
+	"github.com/joshuasing/pws_exporter/internal/journal"
+	"github.com/joshuasing/pws_exporter/internal/snmp"
+	"github.com/joshuasing/pws_exporter/internal/store"
+	"github.com/joshuasing/pws_exporter/internal/upstream"
+	"github.com/joshuasing/pws_exporter/internal/vantage"
+	"github.com/joshuasing/pws_exporter/internal/weewx"
+	"github.com/joshuasing/pws_exporter/internal/wuapi"
+	"github.com/joshuasing/pws_exporter/internal/wuforward"
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/acurite"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/ambient"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/awekas"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/ecowitt"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/mqttjson"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/windy"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+var (
+	// wuDomains are domains used to submit data to the Weather Underground (WU)
+	// API. The DNS resolver will answer A queries for these domains with the
+	// exporter IP address.
+	//
+	// The self-signed TLS certificate used by the WU API server will be issued
+	// to all of these domains.
+	wuDomains = []string{
+		"weatherstation.wunderground.com", // Standard submission API
+		"rtupdate.wunderground.com",       // RapidFire (real-time) submission API
+	}
+
+	// ambientDomains are domains used to submit data to the AmbientWeather.net
+	// API, which is used by Ambient Weather stations that do not support the
+	// WU API.
+	ambientDomains = []string{
+		"ambientweather.net",
+		"rt.ambientweather.net",  // Real-time upload API
+		"api.ambientweather.net", // Upload API used by newer firmware
+	}
+
+	// awekasDomains are domains used to submit data to AWEKAS, either with the
+	// AWEKAS upload protocol, or the WU protocol.
+	awekasDomains = []string{
+		"data.awekas.at", // AWEKAS upload API
+		"ws.awekas.at",   // WU-compatible submission API
+	}
+
+	// windyDomains are domains used to submit data to Windy.com.
+	windyDomains = []string{
+		"stations.windy.com",
+	}
+
+	// acuriteDomains are domains used by Acurite hubs to submit data to
+	// myacurite.com.
+	acuriteDomains = []string{
+		"atlasapi.myacurite.com", // Acurite Access
+		"hubapi.myacurite.com",   // smartHUB
+	}
+
+	// interceptedDomains are all domains of the submission APIs served by the
+	// exporter. The DNS resolver answers these domains with the exporter IP
+	// address, and the self-signed TLS certificate is issued to them.
+	interceptedDomains = slices.Concat(wuDomains, ambientDomains, awekasDomains, windyDomains, acuriteDomains)
+
+	// forwardDomains are domains that should be forwarded to the upstream DNS
+	// resolver. They are necessary for the function of the Weather Station.
+	//
+	// Queried domains that are not in this list are black holed (NXDOMAIN) to
+	// prevent unwanted connections.
+	forwardDomains = []string{
+		"time.nist.gov.",
+		"time-nw.nist.gov.",
+		"time-a.nist.gov.",
+		"time-b.nist.gov.",
+		"time.windows.com.",
+	}
+)
+
+// WUDomains returns the WU submission domains that the DNS server answers with
+// the exporter IP address.
+func WUDomains() []string {
+	return slices.Clone(wuDomains)
+}
+
+// InterceptedDomains returns the domains of all submission APIs, including the
+// WU domains, that the DNS server answers with the exporter IP address.
+func InterceptedDomains() []string {
+	return slices.Clone(interceptedDomains)
+}
+
+// ForwardDomains returns the domains that the DNS server forwards to the
+// upstream resolver. Queries for other domains are answered with NXDOMAIN.
+func ForwardDomains() []string {
+	return slices.Clone(forwardDomains)
+}
+
+// ListenOff is the listen address that disables a server. It can be used for
+// the ListenAddress, DNSListenAddress, WUListenAddress, WUTLSListenAddress,
+// SNMPListenAddress and GRPCListenAddress of Config. Unlike an empty address,
+// which starts the WU servers on their default ports, it disables them.
+const ListenOff = "off"
+
+// defaultShutdownTimeout is the maximum time Close waits for in-flight
+// observations to be processed.
+const defaultShutdownTimeout = 10 * time.Second
+
+// Default WU server timeouts and limits. Weather stations send small requests
+// and some never close their connections, so these are much lower than the
+// net/http defaults.
+const (
+	defaultWUReadTimeout    = 10 * time.Second
+	defaultWUWriteTimeout   = 10 * time.Second
+	defaultWUIdleTimeout    = 60 * time.Second
+	defaultWUMaxHeaderBytes = 8 << 10
+	defaultWUMaxBodyBytes   = 64 << 10
+	defaultWUMaxConnections = 128
+)
+
+// Exporter captures weather station submissions and exports them as
+// Prometheus metrics.
+type Exporter struct {
+	listenAddress      string
+	upstreamResolver   string
+	dnsListeners       []dnsListener
+	dnsServers         dnsServers
+	wuListenAddress    string
+	wuTLSListenAddress string
+	wuSinglePort       bool
+	singleServer       bool
+	wuTLS              bool
+	certValidity       time.Duration
+	certs              atomic.Pointer[certManager]
+	feedPath           string
+	snmpListenAddress  string
+	grpcListenAddress  string
+	snmpCommunity      string
+	snmpRootOID        snmp.OID
+	wuServer           config.HTTPServer
+	rateWindow         time.Duration
+	rtl433             config.RTL433
+	mqttInput          config.MQTT
+	mqttSubscriptions  []mqttjson.Subscription
+
+	running   atomic.Bool
+	listeners listeners
+
+	registry    *prometheus.Registry
+	metricNames map[string]struct{} // names of the metrics on registry
+	metrics     *Metrics
+	httpMetrics *httpMetrics
+
+	// cfgMu protects the configuration that can be reloaded at runtime.
+	cfgMu          sync.RWMutex
+	configPath     string
+	tenants        []*tenant
+	stationTenants map[string]*tenant
+	stations       map[string]stationConfig
+	sites          []config.Site
+	stationIDs     stationIDNormalizer
+	relabel        config.Relabel
+	admin          config.Admin
+	ingest         config.Ingest
+	config         resolvedConfig
+
+	maintenance atomic.Bool
+
+	rain            rainState
+	sunshine        sunshineState
+	rainIntensity   rainIntensityState
+	quality         qualityState
+	lastSubmissions lastSubmissions
+	history         *history
+	hub             *hub
+	pipeline        *pipeline
+	rapidFire       *rapidFireBuffer
+	alerts          *alert.Engine
+	weewx           *weewx.Bridge
+	wuForward       *wuforward.Forwarder
+	upstreams       *upstream.Forwarder
+	annotator       *grafana.Annotator
+	federation      *federation.Federator
+	wuAPI           *wuapi.Poller
+	weatherLinkIP   *vantage.Poller
+	processors      processorChain
+	store           *store.Store
+	journal         *journal.Journal
+
+	sinks         sinks
+	storeHealth   sinkHealth
+	journalHealth sinkHealth
+	stateHealth   sinkHealth
+
+	statePath  string
+	stateDirty atomic.Bool
+	stateQuit  chan struct{}
+	stateWG    sync.WaitGroup
+
+	supervisor   *supervisor
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// Config is the exporter configuration.
+type Config struct {
+	// ListenAddress is the listen address of the HTTP server serving the
+	// metrics, status page and APIs. If empty or ListenOff, the server is not
+	// started, and Handler may be used to serve them.
+	ListenAddress string
+
+	ExporterIP         string
+	ExporterIPv6       string
+	UpstreamResolver   string
+	DNSListenAddress   string
+	WUListenAddress    string
+	WUTLSListenAddress string
+	Tenants            []config.Tenant
+	Stations           map[string]config.Station
+	Sites              []config.Site
+	StationIDs         config.StationIDNormalization
+	Relabel            config.Relabel
+	Admin              config.Admin
+	Ingest             config.Ingest
+	DNS                config.DNS
+
+	// WUSinglePort serves both plaintext HTTP and HTTPS submissions on
+	// WUListenAddress, detecting TLS connections from the first byte sent by
+	// the client. WUTLSListenAddress is not used.
+	WUSinglePort bool
+
+	// SingleServer serves the WU submission API from the metrics HTTP server
+	// on ListenAddress, routing requests by path, instead of starting
+	// separate WU HTTP and HTTPS servers. If WUSinglePort is also set, TLS
+	// connections are accepted on ListenAddress.
+	SingleServer bool
+
+	// CertValidity is the validity period of the generated self-signed TLS
+	// certificate. The certificate is renewed once 90% of the period has
+	// passed. If zero, the certificate is valid for 10 years.
+	CertValidity time.Duration
+
+	// ReusePort sets SO_REUSEPORT on all listeners, allowing a new exporter
+	// to start listening before the old exporter is stopped during upgrades.
+	ReusePort bool
+
+	// SocketActivation uses the sockets passed by systemd socket activation
+	// (LISTEN_FDS), if any, allowing privileged ports to be bound without
+	// running as root. A socket is used by the server named by its
+	// FileDescriptorName (dns, wu, wu_tls, metrics, grpc or snmp), or
+	// otherwise by the server with the same listen address. The listen
+	// address of each server must still be set.
+	SocketActivation bool
+
+	// FeedPath is the path of a unix socket, or of an existing named pipe,
+	// that each new observation is written to as a line of JSON. If empty,
+	// the feed is disabled.
+	FeedPath string
+
+	// SNMPListenAddress is the UDP listen address of the SNMP agent serving
+	// the latest observation of each station. If empty, the agent is not
+	// started.
+	SNMPListenAddress string
+
+	// SNMPCommunity is the community string required by the SNMP agent.
+	// Defaults to "public".
+	SNMPCommunity string
+
+	// SNMPRootOID is the root OID of the SNMP agent's MIB subtree. Defaults
+	// to 1.3.6.1.3.9452.
+	SNMPRootOID string
+
+	// GRPCListenAddress is the listen address of the gRPC server serving the
+	// observation service (see GRPCService). If empty, the server is not
+	// started.
+	GRPCListenAddress string
+
+	// WUServer are the timeouts and limits of the WU HTTP and HTTPS servers.
+	// Zero values are replaced with defaults suitable for weather stations.
+	WUServer config.HTTPServer
+
+	// Alerts is the alerting configuration.
+	Alerts config.Alerts
+
+	// WeeWXAddress is the address of a WeeWX interceptor driver that
+	// accepted WU submissions are forwarded to, either host:port or a unix
+	// socket path prefixed with "unix:". If empty, submissions are not
+	// forwarded.
+	WeeWXAddress string
+
+	// WUForward forwards the accepted WU submissions of stations to Weather
+	// Underground, under one or more WU station IDs.
+	WUForward []config.WUForward
+
+	// Upstreams re-submit the accepted submissions of stations to upstream
+	// weather services.
+	Upstreams []config.Upstream
+
+	// OpenSenseMap uploads the observations of stations to openSenseMap
+	// senseBoxes, using a sink named "opensensemap".
+	OpenSenseMap []config.OpenSenseMap
+
+	// Federation pulls the station metrics of remote exporters, which are
+	// exported on the default registry with a site label.
+	Federation config.Federation
+
+	// GrafanaAnnotations writes Grafana annotations for weather events
+	// detected from the observations of stations.
+	GrafanaAnnotations config.GrafanaAnnotations
+
+	// RTL433 submits the events of rtl_433 sensors, received from an MQTT
+	// broker, as observations of stations. Disabled if no broker is set.
+	RTL433 config.RTL433
+
+	// MQTTInput submits weather data published to an MQTT broker as JSON
+	// as observations of stations. Disabled if no broker is set.
+	MQTTInput config.MQTTInput
+
+	// WUAPI polls the current observations of stations that upload to
+	// Weather Underground from the WU PWS API, which are handled as WU
+	// submissions.
+	WUAPI config.WUAPI
+
+	// WeatherLinkIP polls the current conditions of Davis Vantage consoles
+	// from WeatherLink IP data loggers, which are handled as WU submissions.
+	WeatherLinkIP config.WeatherLinkIP
+
+	// Hooks are processors that are added to the observation processing
+	// chain.
+	Hooks []Hook
+
+	// ConfigPath is the path to the configuration file, which is read again
+	// when the configuration is reloaded.
+	ConfigPath string
+
+	// HistorySize is the number of observations kept in memory for each
+	// station.
+	HistorySize int
+
+	// RateWindow is the rolling window that rates of change are computed
+	// over, which is limited by the observations kept in memory. Defaults to
+	// 30 minutes.
+	RateWindow time.Duration
+
+	// StorePath is the path to the SQLite database used to store
+	// observations. If empty, observations are not stored.
+	StorePath string
+
+	// StoreRetention is the duration that stored observations are kept for.
+	// If zero, observations are kept forever.
+	StoreRetention time.Duration
+
+	// StoreDownsample are the rules used to downsample old observations in
+	// the store.
+	StoreDownsample []config.DownsampleRule
+
+	// StatePath is the path to the file used to persist derived exporter
+	// state across restarts. If empty, state is not persisted.
+	StatePath string
+
+	// JournalPath is the path to the write-ahead journal of raw
+	// submissions. If empty, submissions are not journaled.
+	JournalPath string
+}
+
+// resolvedConfig is an exporter configuration with the defaults filled in
+// and the exporter addresses resolved.
+type resolvedConfig struct {
+	Config
+
+	// detectedIP is whether the exporter IP address was detected, rather
+	// than set in the configuration.
+	detectedIP bool
+
+	dnsListeners []dnsListener
+	snmpRootOID  snmp.OID
+}
+
+// resolveConfig fills in the defaults of c, and resolves the exporter IP
+// addresses (detecting the IPv4 address if neither is set) and the DNS
+// listeners. ExporterIP and ExporterIPv6 are replaced with the selected
+// addresses.
+func resolveConfig(c Config) (resolvedConfig, error) {
+	explicitIP := c.ExporterIP != "" || c.ExporterIPv6 != ""
+	if !explicitIP {
+		ip, err := outboundIP()
+		if err != nil {
+			return resolvedConfig{}, fmt.Errorf("could not determine exporter IP address: %w", err)
+		}
+		c.ExporterIP = ip.String()
+	}
+	exporterIP, exporterIPv6, err := exporterIPs(c.ExporterIP, c.ExporterIPv6)
+	if err != nil {
+		return resolvedConfig{}, err
+	}
+	c.ExporterIP, c.ExporterIPv6 = exporterIP, exporterIPv6
+	c.UpstreamResolver = resolverAddress(c.UpstreamResolver)
+	c.ListenAddress = listenAddress(c.ListenAddress, "")
+	c.DNSListenAddress = listenAddress(c.DNSListenAddress, "")
+	c.WUListenAddress = listenAddress(c.WUListenAddress, ":80")
+	c.WUTLSListenAddress = listenAddress(c.WUTLSListenAddress, ":443")
+	c.SNMPListenAddress = listenAddress(c.SNMPListenAddress, "")
+	c.GRPCListenAddress = listenAddress(c.GRPCListenAddress, "")
+	if c.HistorySize <= 0 {
+		c.HistorySize = defaultHistorySize
+	}
+	if c.RateWindow <= 0 {
+		c.RateWindow = defaultRateWindow
+	}
+	if c.CertValidity <= 0 {
+		c.CertValidity = defaultCertValidity
+	}
+	c.WUServer = wuServerDefaults(c.WUServer)
+	if c.SNMPCommunity == "" {
+		c.SNMPCommunity = "public"
+	}
+	if c.SNMPRootOID == "" {
+		c.SNMPRootOID = defaultSNMPRootOID
+	}
+	snmpRootOID, err := snmp.ParseOID(c.SNMPRootOID)
+	if err != nil {
+		return resolvedConfig{}, fmt.Errorf("SNMP root OID: %w", err)
+	}
+	dnsListeners, err := dnsListeners(c.DNSListenAddress, exporterIP, exporterIPv6, explicitIP)
+	if err != nil {
+		return resolvedConfig{}, err
+	}
+	return resolvedConfig{
+		Config:       c,
+		detectedIP:   !explicitIP,
+		dnsListeners: dnsListeners,
+		snmpRootOID:  snmpRootOID,
+	}, nil
+}
+
+// NewExporter returns a new exporter.
+func NewExporter(c Config) (_ *Exporter, err error) {
+	rc, err := resolveConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	c = rc.Config
+
+	var lc net.ListenConfig
+	if c.ReusePort {
+		if !reusePortSupported {
+			return nil, errors.New("SO_REUSEPORT is not supported on this platform")
+		}
+		lc.Control = reusePort
+	}
+
+	registry := prometheus.NewRegistry()
+	reg := newDescribingRegisterer(registry)
+	e := &Exporter{
+		listenAddress:      c.ListenAddress,
+		dnsListeners:       rc.dnsListeners,
+		upstreamResolver:   c.UpstreamResolver,
+		wuListenAddress:    c.WUListenAddress,
+		wuTLSListenAddress: c.WUTLSListenAddress,
+		wuSinglePort:       c.WUSinglePort,
+		singleServer:       c.SingleServer,
+		wuTLS:              c.wuTLSEnabled(),
+		certValidity:       c.CertValidity,
+		feedPath:           c.FeedPath,
+		snmpListenAddress:  c.SNMPListenAddress,
+		grpcListenAddress:  c.GRPCListenAddress,
+		snmpCommunity:      c.SNMPCommunity,
+		snmpRootOID:        rc.snmpRootOID,
+		wuServer:           c.WUServer,
+		rateWindow:         c.RateWindow,
+		rtl433:             c.RTL433,
+		mqttInput:          c.MQTTInput.MQTT,
+		registry:           registry,
+		metrics:            newMetrics("weather", reg),
+		httpMetrics:        newHTTPMetrics("pws_exporter", reg),
+		stationTenants:     make(map[string]*tenant),
+		configPath:         c.ConfigPath,
+		relabel:            c.Relabel,
+		admin:              c.Admin,
+		ingest:             c.Ingest,
+		config:             rc,
+		rain:               rainState{today: make(map[string]float64)},
+		sunshine:           sunshineState{today: make(map[string]float64)},
+		rainIntensity:      rainIntensityState{stations: make(map[string]*rainIntensity)},
+		quality:            qualityState{stations: make(map[string]*stationQuality)},
+		history:            newHistory(c.HistorySize),
+		hub:                newHub(),
+		stateQuit:          make(chan struct{}),
+	}
+	reg.MustRegister(&upCollector{e: e, metrics: e.metrics})
+	reg.MustRegister(&qualityCollector{e: e, metrics: e.metrics})
+	reg.MustRegister(&siteCollector{e: e, metrics: e.metrics})
+	e.supervisor = newSupervisor("pws_exporter", &e.listeners, lc, reg)
+	if c.SocketActivation {
+		sockets, err := systemdSockets()
+		if err != nil {
+			return nil, fmt.Errorf("socket activation: %w", err)
+		}
+		e.supervisor.sockets = sockets
+	}
+	if e.wuTLS {
+		reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "pws_exporter",
+			Subsystem: "tls",
+			Name:      "certificate_expiry_timestamp_seconds",
+			Help:      "Expiry time of the generated TLS certificate in seconds since the Unix epoch",
+		}, e.certExpiry))
+	}
+	e.processors = newProcessorChain(c.Hooks, ProcessorFunc(e.recordObservation))
+	e.pipeline = newPipeline(e.processObservation)
+	e.rapidFire = newRapidFireBuffer(e.pipeline.enqueue)
+	// The resources started from here on are released if the exporter
+	// cannot be created.
+	defer func() {
+		if err != nil {
+			_ = e.Close()
+		}
+	}()
+	if len(c.Alerts.Rules) > 0 || c.Alerts.Offline != nil {
+		ac, err := alertConfig(c.Alerts)
+		if err != nil {
+			return nil, err
+		}
+		ac.StaleAfter = e.staleAfter
+		e.alerts = alert.NewEngine(ac)
+	}
+	if c.MQTTInput.MQTT.Broker != "" {
+		subs, err := mqttSubscriptions(c.MQTTInput)
+		if err != nil {
+			return nil, err
+		}
+		e.mqttSubscriptions = subs
+	}
+	if g := c.GrafanaAnnotations; g.URL != "" {
+		events := make([]grafana.Event, 0, len(g.Events))
+		for _, ev := range g.Events {
+			events = append(events, grafana.Event(ev))
+		}
+		e.annotator = grafana.NewAnnotator(grafana.Config{
+			Client:        &grafana.Client{URL: g.URL, Token: g.Token},
+			DashboardUID:  g.DashboardUID,
+			Tags:          g.Tags,
+			Events:        events,
+			Stations:      g.Stations,
+			GustThreshold: g.GustThreshold,
+			RainStopAfter: g.RainStopAfter,
+			StaleAfter:    e.staleAfter,
+			Location: func(stationID string) *time.Location {
+				return e.stationConfig(stationID).location
+			},
+		})
+	}
+	if c.WeeWXAddress != "" {
+		b, err := weewx.NewBridge(c.WeeWXAddress)
+		if err != nil {
+			return nil, err
+		}
+		e.weewx = b
+	}
+	if len(c.WUForward) > 0 {
+		targets := make(map[string][]wuforward.Target, len(c.WUForward))
+		for _, f := range c.WUForward {
+			for _, t := range f.Targets {
+				targets[f.Station] = append(targets[f.Station], wuforward.Target{
+					ID:       t.ID,
+					Password: t.Password,
+					URL:      t.URL,
+				})
+			}
+		}
+		e.wuForward = wuforward.NewForwarder(targets)
+	}
+	if len(c.Upstreams) > 0 {
+		upstreams := make([]upstream.Upstream, len(c.Upstreams))
+		for i, u := range c.Upstreams {
+			upstreams[i] = upstream.Upstream{
+				Name:        u.Name,
+				Service:     upstream.Service(u.Service),
+				Stations:    u.Stations,
+				ID:          u.ID,
+				Password:    u.Password,
+				URL:         u.URL,
+				Interval:    u.Interval,
+				MaxAttempts: u.MaxAttempts,
+				Backoff:     u.Backoff,
+				BufferFile:  u.BufferFile,
+				BufferSize:  u.BufferSize,
+			}
+		}
+		f, err := upstream.NewForwarder(upstreams, reg)
+		if err != nil {
+			return nil, err
+		}
+		e.upstreams = f
+	}
+	if len(c.OpenSenseMap) > 0 {
+		s, err := newOpenSenseMapSink(c.OpenSenseMap)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.RegisterSink("opensensemap", s); err != nil {
+			_ = s.Close(context.Background())
+			return nil, err
+		}
+	}
+	if c.StorePath != "" {
+		opts := store.Options{Retention: c.StoreRetention}
+		for _, r := range c.StoreDownsample {
+			opts.Downsample = append(opts.Downsample, store.DownsampleRule{
+				After:      r.After,
+				Resolution: r.Resolution,
+			})
+		}
+		st, err := store.Open(c.StorePath, opts)
+		if err != nil {
+			return nil, fmt.Errorf("open observation store: %w", err)
+		}
+		e.store = st
+	}
+	e.dnsServers.upstreamResolver = e.upstreamResolver
+	e.dnsServers.set(c.DNS)
+	e.setTenants(c.Tenants)
+	e.setSites(c.Sites)
+	if err := e.setStations(c.Stations); err != nil {
+		return nil, err
+	}
+	if err := e.setStationIDNormalization(c.StationIDs); err != nil {
+		return nil, err
+	}
+	if c.StatePath != "" {
+		// The state is only saved on close once it has been loaded, so that
+		// the state file is not overwritten if the exporter cannot be
+		// created.
+		e.statePath = c.StatePath
+		if err := e.loadState(); err != nil {
+			e.statePath = ""
+			return nil, fmt.Errorf("load exporter state: %w", err)
+		}
+		e.stateWG.Add(1)
+		go e.saveStateLoop()
+	}
+	if c.JournalPath != "" {
+		j, pending, err := journal.Open(c.JournalPath)
+		if err != nil {
+			return nil, fmt.Errorf("open journal: %w", err)
+		}
+		if n := j.Corrupt(); n > 0 {
+			slog.Warn("Skipped corrupt journal records", slog.Int("count", n))
+		}
+		e.journal = j
+		e.replayJournal(pending)
+	}
+	if len(c.Federation.Sites) > 0 {
+		sites := make([]federation.Site, 0, len(c.Federation.Sites))
+		for _, s := range c.Federation.Sites {
+			sites = append(sites, federation.Site{
+				Name:     s.Name,
+				URL:      s.URL,
+				Username: s.Username,
+				Password: s.Password,
+			})
+		}
+		e.federation = federation.NewFederator(sites, c.Federation.Interval, reg)
+	}
+	if len(c.WUAPI.Stations) > 0 {
+		e.wuAPI = wuapi.NewPoller(&wuapi.Client{APIKey: c.WUAPI.APIKey}, c.WUAPI.Stations,
+			c.WUAPI.Interval, e.handleWUAPIObservation, reg)
+	}
+	if len(c.WeatherLinkIP.Loggers) > 0 {
+		loggers := make([]vantage.Logger, len(c.WeatherLinkIP.Loggers))
+		for i, l := range c.WeatherLinkIP.Loggers {
+			loggers[i] = vantage.Logger{
+				StationID: l.Station,
+				Client:    &vantage.Client{Address: l.Address},
+				RainClick: l.RainClick(),
+			}
+		}
+		e.weatherLinkIP = vantage.NewPoller(loggers, c.WeatherLinkIP.Interval,
+			e.handleWeatherLinkIPObservation, reg)
+	}
+
+	e.metricNames = reg.metricNames()
+	if err := checkRelabel(c.Relabel, e.metricNames); err != nil {
+		return nil, fmt.Errorf("relabel: %w", err)
+	}
+	return e, nil
+}
+
+// listenAddress returns the listen address of a server, or def if address is
+// empty. It returns an empty address if the server is disabled.
+func listenAddress(address, def string) string {
+	switch address {
+	case "":
+		return def
+	case ListenOff:
+		return ""
+	}
+	return address
+}
+
+// wuServerDefaults returns c with zero values replaced by the default WU
+// server timeouts and limits.
+func wuServerDefaults(c config.HTTPServer) config.HTTPServer {
+	if c.ReadTimeout == 0 {
+		c.ReadTimeout = defaultWUReadTimeout
+	}
+	if c.WriteTimeout == 0 {
+		c.WriteTimeout = defaultWUWriteTimeout
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = defaultWUIdleTimeout
+	}
+	if c.MaxHeaderBytes == 0 {
+		c.MaxHeaderBytes = defaultWUMaxHeaderBytes
+	}
+	if c.MaxBodyBytes == 0 {
+		c.MaxBodyBytes = defaultWUMaxBodyBytes
+	}
+	if c.MaxConnections == 0 {
+		c.MaxConnections = defaultWUMaxConnections
+	}
+	return c
+}
+
+// Registry returns the registry of the default (non-tenant) metrics.
+func (e *Exporter) Registry() *prometheus.Registry {
+	return e.registry
+}
+
+// ListenAndServe starts the exporter's listeners, and blocks until ctx is done
+// or the exporter is closed. Listeners are started independently, and
+// restarted if they fail. When ctx is done, the exporter is closed and the
+// result of Close is returned.
+func (e *Exporter) ListenAndServe(ctx context.Context) error {
+	if !e.running.CompareAndSwap(false, true) {
+		return errors.New("already running")
+	}
+	defer e.running.CompareAndSwap(true, false)
+
+	// TLS configuration.
+	var tlsConfig *tls.Config
+	if e.wuTLS {
+		// Generate temporary TLS certificate, which is renewed before it
+		// expires.
+		certs, err := newCertManager(e.certValidity)
+		if err != nil {
+			return errors.Join(err, e.Close())
+		}
+		e.certs.Store(certs)
+
+		tlsConfig = &tls.Config{ //nolint:gosec
+			// TLS v1.0 is used for compatibility reasons, as many weather
+			// stations do not support modern TLS connections, or TLS at all.
+			//
+			// Whilst not ideal, traffic to the exporter should be entirely
+			// local, limiting the risk of using an older and deprecated TLS
+			// version.
+			GetCertificate: certs.getCertificate,
+			MinVersion:     tls.VersionTLS10,
+		}
+	}
+
+	// Submission APIs. Submissions received by the other APIs are translated
+	// to WU submissions.
+	submissionAPI := wu.NewSubmissionAPI(e.handleWUSubmission)
+	submissionAPI.SetResponseFunc(e.stationResponse)
+	submissionAPI.SetStationIDFunc(e.normalizeStationID)
+	reportAPI := ecowitt.NewReportAPI(e.handleWUSubmission)
+	reportAPI.SetStationIDFunc(e.normalizeStationID)
+	endpointAPI := ambient.NewEndpointAPI(e.handleWUSubmission)
+	endpointAPI.SetStationIDFunc(e.normalizeStationID)
+	uploadAPI := awekas.NewUploadAPI(e.handleWUSubmission)
+	uploadAPI.SetStationIDFunc(e.normalizeStationID)
+	updateAPI := windy.NewUpdateAPI(e.handleWUSubmission)
+	updateAPI.SetStationIDFunc(e.normalizeStationID)
+	acuriteAPI := acurite.NewUpdateAPI(e.handleWUSubmission)
+	acuriteAPI.SetStationIDFunc(e.normalizeStationID)
+	submissionRoutes := []struct {
+		path, name string
+		handler    http.Handler
+	}{
+		{wu.SubmissionPath, "wu_submission", submissionAPI},
+		{ecowitt.ReportPath, "ecowitt_report", reportAPI},
+		{ambient.EndpointPath, "ambient_upload", endpointAPI},
+		{awekas.UploadPath, "awekas_upload", uploadAPI},
+		{windy.UpdatePath, "windy_upload", updateAPI},
+		{acurite.UpdatePath, "acurite_upload", acuriteAPI},
+	}
+	mux := http.NewServeMux()
+	for _, r := range submissionRoutes {
+		mux.Handle(r.path, e.InstrumentHandler(r.name, r.handler))
+	}
+
+	// DNS servers
+	for _, l := range e.dnsListeners {
+		e.supervisor.start(dnsService(l, &e.dnsServers))
+	}
+	switch {
+	case e.singleServer:
+		// The WU API is served by the metrics server.
+	case e.wuSinglePort:
+		if e.wuListenAddress != "" {
+			e.supervisor.start(httpService("wu", e.wuListenAddress, mux, tlsConfig, true, e.wuServer))
+		}
+	default:
+		if e.wuListenAddress != "" {
+			e.supervisor.start(httpService("wu", e.wuListenAddress, mux, nil, false, e.wuServer))
+		}
+		if tlsConfig != nil {
+			e.supervisor.start(httpService("wu_tls", e.wuTLSListenAddress, mux, tlsConfig, false, e.wuServer))
+		}
+	}
+	if e.grpcListenAddress != "" {
+		e.supervisor.start(e.grpcServerService(e.grpcListenAddress))
+	}
+	if e.snmpListenAddress != "" {
+		e.supervisor.start(e.snmpService(e.snmpListenAddress, e.snmpCommunity, e.snmpRootOID))
+	}
+	if e.feedPath != "" {
+		e.supervisor.start(e.feedService(e.feedPath))
+	}
+	if e.rtl433.MQTT.Broker != "" {
+		e.supervisor.start(e.rtl433Service(e.rtl433))
+	}
+	if e.mqttInput.Broker != "" {
+		e.supervisor.start(e.mqttInputService(e.mqttInput, e.mqttSubscriptions))
+	}
+	switch {
+	case e.singleServer:
+		// Only the WU server idle timeout and header limit are used, as
+		// the other limits would also apply to scrapes and streaming API
+		// requests.
+		singleMux := http.NewServeMux()
+		for _, r := range submissionRoutes {
+			handler := r.handler
+			if e.wuServer.MaxBodyBytes > 0 {
+				handler = http.MaxBytesHandler(handler, e.wuServer.MaxBodyBytes)
+			}
+			singleMux.Handle(r.path, e.InstrumentHandler(r.name, handler))
+		}
+		singleMux.Handle("/", e.Handler())
+		e.supervisor.start(httpService("http", e.listenAddress, singleMux, tlsConfig, tlsConfig != nil, config.HTTPServer{
+			IdleTimeout:    e.wuServer.IdleTimeout,
+			MaxHeaderBytes: e.wuServer.MaxHeaderBytes,
+		}))
+	case e.listenAddress != "":
+		e.supervisor.start(httpService("metrics", e.listenAddress, e.Handler(), nil, false, config.HTTPServer{}))
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		e.supervisor.wait()
+		close(stopped)
+	}()
+	select {
+	case <-ctx.Done():
+		return e.Close()
+	case <-stopped:
+		return nil
+	}
+}
+
+// wuTLSEnabled returns whether the WU API is served over TLS by an exporter
+// using the resolved configuration.
+func (c *Config) wuTLSEnabled() bool {
+	switch {
+	case c.SingleServer:
+		return c.WUSinglePort
+	case c.WUSinglePort:
+		return c.WUListenAddress != ""
+	default:
+		return c.WUTLSListenAddress != ""
+	}
+}
+
+// certExpiry returns the expiry time of the generated TLS certificate in
+// seconds since the Unix epoch, or 0 if it has not been generated yet.
+func (e *Exporter) certExpiry() float64 {
+	certs := e.certs.Load()
+	if certs == nil {
+		return 0
+	}
+	return float64(certs.notAfter().Unix())
+}
+
+// RotateCertificate replaces the generated TLS certificate used by the WU
+// HTTPS server with a newly generated certificate. New connections use the new
+// certificate immediately. It does nothing if the exporter is not serving
+// TLS.
+func (e *Exporter) RotateCertificate() error {
+	certs := e.certs.Load()
+	if certs == nil {
+		return nil
+	}
+	if err := certs.rotate(); err != nil {
+		return err
+	}
+	slog.Info("Rotated TLS certificate", slog.Time("not_after", certs.notAfter()))
+	return nil
+}
+
+// Close shuts down the exporter, waiting up to defaultShutdownTimeout for
+// in-flight observations to be processed. See Shutdown.
+func (e *Exporter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+	return e.Shutdown(ctx)
+}
+
+// Shutdown gracefully shuts down the exporter. The listeners are stopped, and
+// then in-flight and queued observations are processed before the sinks are
+// closed. If ctx is done before all observations have been processed, the
+// remaining observations are discarded and an error is returned. Discarded
+// observations are replayed from the journal, if enabled, when the exporter
+// is next started. Calling Shutdown more than once returns the result of the
+// first call.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	e.shutdownOnce.Do(func() {
+		e.shutdownErr = e.shutdown(ctx)
+	})
+	return e.shutdownErr
+}
+
+func (e *Exporter) shutdown(ctx context.Context) error {
+	if e.store != nil {
+		defer e.store.Close()
+	}
+	if e.journal != nil {
+		defer e.journal.Close()
+	}
+	if e.statePath != "" {
+		defer func() {
+			if err := e.closeState(); err != nil {
+				slog.Error("Failed to close exporter state", slog.Any("err", err))
+			}
+		}()
+	}
+
+	e.supervisor.stop()
+	if certs := e.certs.Load(); certs != nil {
+		certs.close()
+	}
+	if e.federation != nil {
+		e.federation.Close()
+	}
+	if e.wuAPI != nil {
+		e.wuAPI.Close()
+	}
+	if e.weatherLinkIP != nil {
+		e.weatherLinkIP.Close()
+	}
+
+	// Finish processing queued observations before closing the sinks. The
+	// sinks are closed even if the pipeline could not be drained in time, so
+	// that they can persist or flush what was already queued.
+	var errs []error
+	e.rapidFire.flushAll()
+	if err := e.pipeline.drain(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("drain observations: %w", err))
+	}
+	if err := e.closeSinks(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if e.alerts != nil {
+		if err := e.alerts.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("send alert notifications: %w", err))
+		}
+	}
+	if e.annotator != nil {
+		if err := e.annotator.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("create Grafana annotations: %w", err))
+		}
+	}
+	if e.weewx != nil {
+		if err := e.weewx.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("forward submissions to WeeWX: %w", err))
+		}
+	}
+	if e.wuForward != nil {
+		if err := e.wuForward.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("forward submissions to WU: %w", err))
+		}
+	}
+	if e.upstreams != nil {
+		if err := e.upstreams.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("forward submissions to upstreams: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// outboundIP returns the local outbound address of the machine, preferring
+// IPv4 and falling back to IPv6 on IPv6-only hosts.
+// This is used for attempting to guess the exporter IP address when it is not
+// explicitly configured.
+func outboundIP() (net.IP, error) {
+	conn, err := net.Dial("udp4", "8.8.8.8:80")
+	if err != nil {
+		var err6 error
+		conn, err6 = net.Dial("udp6", "[2001:4860:4860::8888]:80")
+		if err6 != nil {
+			return nil, errors.Join(err, err6)
+		}
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
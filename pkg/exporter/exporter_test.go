@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+func TestNewExporterCleanup(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(statePath, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	before := runtime.NumGoroutine()
+	_, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		StatePath:  statePath,
+		StorePath:  filepath.Join(dir, "store.db"),
+		Upstreams: []config.Upstream{{
+			Name: "archive", Service: "custom", URL: "http://127.0.0.1/?{{.Query}}",
+			BufferFile: filepath.Join(dir, "upstream.jsonl"),
+		}},
+		WUForward: []config.WUForward{{
+			Station: "KTEST1",
+			Targets: []config.WUTarget{{ID: "KNEW1", Password: "key"}},
+		}},
+		OpenSenseMap: []config.OpenSenseMap{{
+			Station: "KTEST1", BoxID: "box1", Sensors: map[string]string{"temperature": "sensor1"},
+		}},
+		Stations: map[string]config.Station{
+			"KTEST1": {DisabledFields: []string{"unknown"}},
+		},
+	})
+	if err == nil {
+		t.Fatal("NewExporter() with an unknown disabled field should fail")
+	}
+
+	// The forwarders and sinks started before the error are stopped.
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("got %d goroutines after NewExporter failed, want at most %d", n, before)
+	}
+
+	// The state file is not overwritten, as it was never loaded.
+	if b, err := os.ReadFile(statePath); err != nil || string(b) != "{}" {
+		t.Errorf("state file = %q (%v), want it unchanged", b, err)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// federatedGatherer is a prometheus.Gatherer that adds the metrics of
+// federated sites to the metrics of a local gatherer. Federated metrics are
+// merged into local metric families with the same name, keeping the local
+// help text. Federated families with a different type than the local family
+// are dropped.
+type federatedGatherer struct {
+	gatherer   prometheus.Gatherer
+	federation prometheus.Gatherer
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *federatedGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+	fmfs, err := g.federation.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*dto.MetricFamily, len(mfs))
+	for _, mf := range mfs {
+		byName[mf.GetName()] = mf
+	}
+	for _, fmf := range fmfs {
+		mf, ok := byName[fmf.GetName()]
+		if !ok {
+			mfs = append(mfs, fmf)
+			continue
+		}
+		if mf.GetType() == fmf.GetType() {
+			mf.Metric = append(mf.Metric, fmf.Metric...)
+		}
+	}
+	sort.Slice(mfs, func(i, j int) bool {
+		return mfs[i].GetName() < mfs[j].GetName()
+	})
+	return mfs, nil
+}
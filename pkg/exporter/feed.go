@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// feedRetryInterval is the interval at which a named pipe is opened again
+// while it has no reader.
+const feedRetryInterval = time.Second
+
+// feedService returns a service that writes each new observation as a line of
+// JSON to the clients connected to the unix socket at path, or to the named
+// pipe at path if one exists.
+func (e *Exporter) feedService(path string) service {
+	return service{
+		name:    "feed",
+		address: path,
+		serve: func(ctx context.Context, _ listenConfig, ready func(addr string)) error {
+			if fi, err := os.Stat(path); err == nil && fi.Mode()&fs.ModeNamedPipe != 0 {
+				ready(path)
+				return e.serveFeedPipe(ctx, path)
+			}
+			return e.serveFeedSocket(ctx, path, ready)
+		},
+	}
+}
+
+// serveFeedSocket listens on the unix socket at path, and writes new
+// observations to connected clients until ctx is done. SO_REUSEPORT is not
+// used, as only one exporter can own the socket path.
+func (e *Exporter) serveFeedSocket(ctx context.Context, path string, ready func(addr string)) error {
+	// Remove a stale socket left behind by a previous exporter.
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&fs.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+	ready(path)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+
+			// Detect the client closing the connection. Data sent by the
+			// client is ignored.
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				_, _ = io.Copy(io.Discard, conn)
+			}()
+			err := e.writeFeed(ctx, closed, func(b []byte) error {
+				_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+				_, err := conn.Write(b)
+				return err
+			})
+			if err != nil {
+				slog.Debug("Failed to write to feed", slog.Any("err", err))
+			}
+		}()
+	}
+}
+
+// serveFeedPipe writes new observations to the named pipe at path while it
+// has a reader, until ctx is done. Observations received while the pipe has
+// no reader are dropped.
+func (e *Exporter) serveFeedPipe(ctx context.Context, path string) error {
+	for {
+		// Opening a named pipe for writing in non-blocking mode fails with
+		// ENXIO until it has a reader.
+		f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+		if err == nil {
+			err = e.writeFeed(ctx, nil, func(b []byte) error {
+				_ = f.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+				_, err := f.Write(b)
+				return err
+			})
+			_ = f.Close()
+			if err != nil && !errors.Is(err, syscall.EPIPE) {
+				slog.Debug("Failed to write to feed", slog.Any("err", err))
+			}
+		} else if !errors.Is(err, syscall.ENXIO) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(feedRetryInterval):
+		}
+	}
+}
+
+// writeFeed writes each new observation as a line of JSON using write, until
+// ctx is done, closed is closed, or write returns an error.
+func (e *Exporter) writeFeed(ctx context.Context, closed <-chan struct{}, write func([]byte) error) error {
+	sub := e.hub.subscribe("")
+	defer e.hub.unsubscribe(sub)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-closed:
+			return nil
+		case o := <-sub.c:
+			b, err := json.Marshal(apiStreamObservation{
+				StationID:   o.StationID,
+				Observation: newAPIObservation(o),
+			})
+			if err != nil {
+				return err
+			}
+			if err := write(append(b, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+}
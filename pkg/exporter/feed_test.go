@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestFeedSocket(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	path := filepath.Join(t.TempDir(), "feed.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	served := make(chan error, 1)
+	go func() {
+		served <- e.feedService(path).serve(ctx, &net.ListenConfig{}, func(string) {
+			close(started)
+		})
+	}()
+	<-started
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Wait for the connection to be subscribed to the hub.
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		e.hub.mu.Lock()
+		n := len(e.hub.subs)
+		e.hub.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("connection was not subscribed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	e.hub.publish(weather.Observation{
+		StationID:   "a",
+		Measurement: wu.DeviceMeasurement{Temperature: 17.5},
+	})
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var got apiStreamObservation
+	if err := json.Unmarshal(line, &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", line, err)
+	}
+	if got.StationID != "a" {
+		t.Errorf("got station %q, want a", got.StationID)
+	}
+
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("serve: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// field is a numeric observation field exposed by the APIs.
+type field struct {
+	name string
+
+	// param is the WU query parameter of the field, which is missing from
+	// observations that did not measure it.
+	param string
+
+	// metric and imperial are the units of the field.
+	metric   string
+	imperial string
+
+	// value returns the metric value of the field for an observation.
+	value func(o weather.Observation) float64
+
+	// toImperial converts the metric value to imperial. Nil if the value is
+	// the same in both unit systems.
+	toImperial func(float64) float64
+
+	// metrics are the names of the station metrics exported for the field,
+	// which are not exported for stations that disable the field.
+	metrics []string
+}
+
+// fields are the numeric observation fields exposed by the APIs.
+var fields = []field{
+	{
+		name: "temperature", param: "tempf", metric: "celsius", imperial: "fahrenheit",
+		value:      func(o weather.Observation) float64 { return o.Measurement.Temperature },
+		toImperial: wu.CToF,
+		metrics: []string{
+			"weather_station_temperature_celsius",
+			"weather_station_temperature_change_celsius_per_hour",
+		},
+	},
+	{
+		name: "dew_point", param: "dewptf", metric: "celsius", imperial: "fahrenheit",
+		value:      func(o weather.Observation) float64 { return o.Measurement.DewPoint },
+		toImperial: wu.CToF,
+		metrics:    []string{"weather_station_dew_point_celsius"},
+	},
+	{
+		name: "humidity", param: "humidity", metric: "percent", imperial: "percent",
+		value: func(o weather.Observation) float64 { return o.Measurement.Humidity },
+		metrics: []string{
+			"weather_station_humidity_percent",
+			"weather_station_humidity_change_percent_per_hour",
+		},
+	},
+	{
+		name: "indoor_temperature", param: "indoortempf", metric: "celsius", imperial: "fahrenheit",
+		value:      func(o weather.Observation) float64 { return o.Measurement.IndoorTemp },
+		toImperial: wu.CToF,
+		metrics:    []string{"weather_station_indoor_temperature_celsius"},
+	},
+	{
+		name: "indoor_humidity", param: "indoorhumidity", metric: "percent", imperial: "percent",
+		value:   func(o weather.Observation) float64 { return o.Measurement.IndoorHumidity },
+		metrics: []string{"weather_station_indoor_humidity_percent"},
+	},
+	{
+		name: "barometric_pressure", param: "baromin", metric: "hpa", imperial: "inhg",
+		value:      func(o weather.Observation) float64 { return o.Measurement.Barometric },
+		toImperial: wu.HPAToInHg,
+		metrics:    []string{"weather_station_barometric_pressure_hpa"},
+	},
+	{
+		name: "wind_speed", param: "windspeedmph", metric: "kph", imperial: "mph",
+		value:      func(o weather.Observation) float64 { return o.Measurement.WindSpeed },
+		toImperial: wu.KPHToMPH,
+		metrics: []string{
+			"weather_station_wind_speed_kph",
+			"weather_station_wind_rose_seconds_total",
+		},
+	},
+	{
+		name: "wind_gust_speed", param: "windgustmph", metric: "kph", imperial: "mph",
+		value:      func(o weather.Observation) float64 { return o.Measurement.WindGust },
+		toImperial: wu.KPHToMPH,
+		metrics: []string{
+			"weather_station_wind_gust_speed_kph",
+			"weather_station_wind_gust_factor",
+		},
+	},
+	{
+		name: "wind_direction", param: "winddir", metric: "degrees", imperial: "degrees",
+		value:   func(o weather.Observation) float64 { return o.Measurement.WindDirection },
+		metrics: []string{"weather_station_wind_direction_degrees"},
+	},
+	{
+		name: "rain_past_hour", param: "rainin", metric: "mm", imperial: "in",
+		value:      func(o weather.Observation) float64 { return o.Measurement.RainPastHour },
+		toImperial: wu.MMToIn,
+		metrics:    []string{"weather_station_rain_past_hour_mm"},
+	},
+	{
+		name: "rain_today", param: "dailyrainin", metric: "mm", imperial: "in",
+		value:      func(o weather.Observation) float64 { return o.Measurement.RainToday },
+		toImperial: wu.MMToIn,
+		metrics: []string{
+			"weather_station_rain_mm_total",
+			"weather_station_rain_intensity_mm_per_hour",
+		},
+	},
+	{
+		name: "solar_radiation", param: "solarradiation", metric: "watts_per_square_meter", imperial: "watts_per_square_meter",
+		value: func(o weather.Observation) float64 { return o.Measurement.SolarRadiation },
+		metrics: []string{
+			"weather_station_solar_radiation_watts_per_square_meter",
+			"weather_station_sunshine_seconds_total",
+		},
+	},
+}
+
+// measured returns the value of a weather data field, identified by its WU
+// query parameter, or nil if the field is missing from the measurement.
+func measured(dm wu.DeviceMeasurement, param string, v float64) *float64 {
+	if !dm.Has(param) {
+		return nil
+	}
+	return &v
+}
+
+// fieldValues returns the metric values of the measured fields of the
+// observation, keyed by field name.
+func fieldValues(o weather.Observation) map[string]float64 {
+	values := make(map[string]float64, len(fields))
+	for _, f := range fields {
+		if o.Measurement.Has(f.param) {
+			values[f.name] = f.value(o)
+		}
+	}
+	return values
+}
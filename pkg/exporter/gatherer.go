@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// stationGatherer is a prometheus.Gatherer that only returns the metrics
+// belonging to a single station, identified by the station_id label.
+type stationGatherer struct {
+	gatherer  prometheus.Gatherer
+	stationID string
+}
+
+// Gather implements prometheus.Gatherer.
+func (s *stationGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := s.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	out := mfs[:0]
+	for _, mf := range mfs {
+		metrics := mf.Metric[:0]
+		for _, m := range mf.Metric {
+			if labelValue(m, "station_id") == s.stationID {
+				metrics = append(metrics, m)
+			}
+		}
+		if len(metrics) > 0 {
+			mf.Metric = metrics
+			out = append(out, mf)
+		}
+	}
+	return out, nil
+}
+
+// disabledFieldsGatherer is a prometheus.Gatherer that drops the series of
+// the fields that are disabled for each station.
+type disabledFieldsGatherer struct {
+	gatherer prometheus.Gatherer
+	stations map[string]stationConfig
+}
+
+// newDisabledFieldsGatherer returns a gatherer that drops the series of the
+// disabled fields of the given stations from the given gatherer. If no
+// station disables any fields, the gatherer is returned unchanged.
+func newDisabledFieldsGatherer(g prometheus.Gatherer, stations map[string]stationConfig) prometheus.Gatherer {
+	for _, sc := range stations {
+		if len(sc.disabledMetrics) > 0 {
+			return &disabledFieldsGatherer{gatherer: g, stations: stations}
+		}
+	}
+	return g
+}
+
+// Gather implements prometheus.Gatherer.
+func (d *disabledFieldsGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := d.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	out := mfs[:0]
+	for _, mf := range mfs {
+		metrics := mf.Metric[:0]
+		for _, m := range mf.Metric {
+			if !d.disabled(mf.GetName(), m) {
+				metrics = append(metrics, m)
+			}
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		mf.Metric = metrics
+		out = append(out, mf)
+	}
+	return out, nil
+}
+
+// disabled returns whether the metric belongs to a field that is disabled for
+// its station.
+func (d *disabledFieldsGatherer) disabled(name string, m *dto.Metric) bool {
+	_, ok := d.stations[labelValue(m, "station_id")].disabledMetrics[name]
+	return ok
+}
+
+// labelValue returns the value of the label with the given name, or an empty
+// string if the metric does not have the label.
+func labelValue(m *dto.Metric, name string) string {
+	for _, lp := range m.GetLabel() {
+		if lp.GetName() == name {
+			return lp.GetValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+func TestStationGatherer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := newMetrics("weather", reg)
+	m.Temperature.WithLabelValues("a").Set(10)
+	m.Temperature.WithLabelValues("b").Set(20)
+	m.Humidity.WithLabelValues("b").Set(0.5)
+
+	mfs, err := (&stationGatherer{gatherer: reg, stationID: "a"}).Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if len(mfs) != 1 {
+		t.Fatalf("got %d metric families, want 1", len(mfs))
+	}
+	if got := mfs[0].GetName(); got != "weather_station_temperature_celsius" {
+		t.Errorf("metric family got %s, want %s", got, "weather_station_temperature_celsius")
+	}
+	if len(mfs[0].Metric) != 1 || mfs[0].Metric[0].GetGauge().GetValue() != 10 {
+		t.Errorf("unexpected metrics for station a: %v", mfs[0].Metric)
+	}
+
+	mfs, err = (&stationGatherer{gatherer: reg, stationID: "unknown"}).Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if len(mfs) != 0 {
+		t.Errorf("got %d metric families for unknown station, want 0", len(mfs))
+	}
+}
+
+func TestRelabelGatherer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := newMetrics("weather", reg)
+	m.Temperature.WithLabelValues("KABC123").Set(10)
+	m.IndoorTemperature.WithLabelValues("KABC123").Set(20)
+
+	g := newRelabelGatherer(reg, config.Relabel{
+		Rename:     map[string]string{"weather_station_temperature_celsius": "outdoor_temperature_celsius"},
+		Drop:       []string{"weather_station_indoor_temperature_celsius"},
+		StationIDs: map[string]string{"KABC123": "backyard"},
+	})
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if len(mfs) != 1 {
+		t.Fatalf("got %d metric families, want 1", len(mfs))
+	}
+	if got := mfs[0].GetName(); got != "outdoor_temperature_celsius" {
+		t.Errorf("metric family got %s, want %s", got, "outdoor_temperature_celsius")
+	}
+	if got := labelValue(mfs[0].Metric[0], "station_id"); got != "backyard" {
+		t.Errorf("station_id got %s, want %s", got, "backyard")
+	}
+}
+
+func TestCheckRelabel(t *testing.T) {
+	tts := []struct {
+		name    string
+		relabel config.Relabel
+		wantErr bool
+	}{
+		{name: "new name", relabel: config.Relabel{Rename: map[string]string{
+			"weather_station_temperature_celsius": "outdoor_temperature_celsius",
+		}}},
+		{name: "exported name", relabel: config.Relabel{Rename: map[string]string{
+			"weather_station_temperature_celsius": "weather_station_humidity_percent",
+		}}, wantErr: true},
+		{name: "exporter metric name", relabel: config.Relabel{Rename: map[string]string{
+			"weather_station_temperature_celsius": "pws_exporter_listener_up",
+		}}, wantErr: true},
+		{name: "swapped names", relabel: config.Relabel{Rename: map[string]string{
+			"weather_station_temperature_celsius": "weather_station_humidity_percent",
+			"weather_station_humidity_percent":    "weather_station_temperature_celsius",
+		}}},
+		{name: "dropped name", relabel: config.Relabel{
+			Rename: map[string]string{"weather_station_temperature_celsius": "weather_station_humidity_percent"},
+			Drop:   []string{"weather_station_humidity_percent"},
+		}},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewExporter(Config{ExporterIP: "127.0.0.1", Relabel: tt.relabel})
+			if err == nil {
+				defer e.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewExporter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDescribingRegistererMetricNames(t *testing.T) {
+	reg := newDescribingRegisterer(prometheus.NewRegistry())
+	newMetrics("weather", reg)
+	reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   "pws_exporter",
+		Name:        "info",
+		Help:        "Info",
+		ConstLabels: prometheus.Labels{"version": "dev"},
+	}, func() float64 { return 1 }))
+
+	// Metrics that have not been collected yet are included.
+	names := reg.metricNames()
+	for _, name := range []string{"weather_station_temperature_celsius", "pws_exporter_info"} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("metric %q is missing from %v", name, slices.Sorted(maps.Keys(names)))
+		}
+	}
+}
+
+func TestDisabledFieldsGatherer(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Stations: map[string]config.Station{
+			"private": {DisabledFields: []string{"indoor_temperature", "solar_radiation"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	reg := prometheus.NewRegistry()
+	m := newMetrics("weather", reg)
+	for _, id := range []string{"private", "public"} {
+		m.Temperature.WithLabelValues(id).Set(10)
+		m.IndoorTemperature.WithLabelValues(id).Set(20)
+		m.Sunshine.WithLabelValues(id).Add(60)
+	}
+
+	mfs, err := newDisabledFieldsGatherer(reg, e.stations).Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if len(mfs) != 3 {
+		t.Fatalf("got %d metric families, want 3", len(mfs))
+	}
+	for _, mf := range mfs {
+		want := []string{"private", "public"}
+		if mf.GetName() != "weather_station_temperature_celsius" {
+			want = []string{"public"}
+		}
+		var got []string
+		for _, m := range mf.Metric {
+			got = append(got, labelValue(m, "station_id"))
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: got stations %v, want %v", mf.GetName(), got, want)
+		}
+	}
+
+	if err := e.setStations(map[string]config.Station{
+		"private": {DisabledFields: []string{"indoor_temp"}},
+	}); err == nil {
+		t.Error("unknown disabled field should fail")
+	}
+}
+
+func TestMetricsHandlerRelabelledStation(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Relabel:    config.Relabel{StationIDs: map[string]string{"KABC123": "backyard"}},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+	e.metrics.Temperature.WithLabelValues("KABC123").Set(10)
+
+	// Stations are identified by the ID they submit, and their metrics are
+	// relabelled.
+	for id, want := range map[string]bool{"KABC123": true, "backyard": false} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics/station/"+id, nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		e.MetricsHandler(promhttp.HandlerOpts{}).ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d, want 200", id, rec.Code)
+		}
+		if got := strings.Contains(rec.Body.String(), `station_id="backyard"`); got != want {
+			t.Errorf("%s: got metrics %v, want %v:\n%s", id, got, want, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/store"
+)
+
+// maxGrafanaRequestSize is the maximum size of a Grafana request body.
+const maxGrafanaRequestSize = 1 << 20
+
+// grafanaSearchRequest is a Grafana JSON datasource metric search request.
+type grafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+// grafanaQueryRequest is a Grafana JSON datasource query request.
+type grafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	IntervalMS    int64 `json:"intervalMs"`
+	MaxDataPoints int64 `json:"maxDataPoints"`
+	Targets       []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+// grafanaTimeSeries is a time series in a Grafana JSON datasource query
+// response. Data points are [value, unix milliseconds] pairs.
+type grafanaTimeSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// handleGrafanaTest responds to Grafana JSON datasource connection tests.
+func (e *Exporter) handleGrafanaTest(w http.ResponseWriter, _ *http.Request) {
+	if e.store == nil {
+		http.Error(w, "observation store is not enabled", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleGrafanaSearch serves the metrics that can be queried, as
+// "<station_id>/<field>" targets containing the requested target.
+func (e *Exporter) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	if e.store == nil {
+		http.Error(w, "observation store is not enabled", http.StatusNotFound)
+		return
+	}
+
+	var req grafanaSearchRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxGrafanaRequestSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	stations, err := e.store.Stations(r.Context())
+	if err != nil {
+		slog.Error("Failed to query stations", slog.Any("err", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+	names := store.Fields()
+	sort.Strings(names)
+
+	targets := make([]string, 0)
+	for _, stationID := range stations {
+		if !e.authorized(r, stationID) {
+			continue
+		}
+		for _, name := range names {
+			target := stationID + "/" + name
+			if strings.Contains(target, req.Target) {
+				targets = append(targets, target)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, targets)
+}
+
+// handleGrafanaQuery serves aggregated time series for the requested targets
+// from the observation store.
+func (e *Exporter) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	if e.store == nil {
+		http.Error(w, "observation store is not enabled", http.StatusNotFound)
+		return
+	}
+
+	var req grafanaQueryRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxGrafanaRequestSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	from, to := req.Range.From, req.Range.To
+	if !from.Before(to) {
+		http.Error(w, "invalid range", http.StatusBadRequest)
+		return
+	}
+
+	// Use the interval requested by Grafana, limited to the maximum number of
+	// points.
+	step := time.Duration(req.IntervalMS) * time.Millisecond
+	maxPoints := req.MaxDataPoints
+	if maxPoints <= 0 || maxPoints > maxQueryPoints {
+		maxPoints = maxQueryPoints
+	}
+	if minStep := to.Sub(from) / time.Duration(maxPoints); step < minStep {
+		step = minStep
+	}
+	step = max(step, time.Second)
+
+	resp := make([]grafanaTimeSeries, 0, len(req.Targets))
+	for _, t := range req.Targets {
+		stationID, field, ok := parseGrafanaTarget(t.Target)
+		if !ok {
+			http.Error(w, "invalid target "+t.Target, http.StatusBadRequest)
+			return
+		}
+		if !e.authorized(r, stationID) {
+			http.NotFound(w, r)
+			return
+		}
+
+		points, err := e.store.Aggregate(r.Context(), stationID, field, from, to, step,
+			e.stationConfig(stationID).location)
+		if err != nil {
+			slog.Error("Failed to query observations", slog.Any("err", err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError)
+			return
+		}
+		ts := grafanaTimeSeries{
+			Target:     t.Target,
+			Datapoints: make([][2]float64, 0, len(points)),
+		}
+		for _, p := range points {
+			ts.Datapoints = append(ts.Datapoints, [2]float64{p.Avg, float64(p.Time.UnixMilli())})
+		}
+		resp = append(resp, ts)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseGrafanaTarget parses a "<station_id>/<field>" Grafana target.
+func parseGrafanaTarget(target string) (stationID, field string, ok bool) {
+	i := strings.LastIndexByte(target, '/')
+	if i <= 0 {
+		return "", "", false
+	}
+	stationID, field = target[:i], target[i+1:]
+	return stationID, field, slices.Contains(store.Fields(), field)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pwsv1 "github.com/joshuasing/pws_exporter/api/pws/v1"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// grpcService implements the gRPC observation service.
+type grpcService struct {
+	pwsv1.UnimplementedObservationServiceServer
+	e *Exporter
+
+	// done is closed when the server is shutting down, which ends open
+	// streams so that the server can stop gracefully.
+	done <-chan struct{}
+}
+
+// GRPCService returns the gRPC observation service. Stations that belong to a
+// tenant require the tenant's credentials, sent as basic authentication in the
+// "authorization" request metadata.
+func (e *Exporter) GRPCService() pwsv1.ObservationServiceServer {
+	return &grpcService{e: e, done: e.supervisor.ctx.Done()}
+}
+
+// grpcServerService returns a service that serves the gRPC observation
+// service. When ctx is done, open streams are ended and the server waits for
+// in-flight requests to finish, which are cancelled if they do not finish
+// before the shutdown timeout.
+func (e *Exporter) grpcServerService(address string) service {
+	return service{
+		name:    "grpc",
+		address: address,
+		serve: func(ctx context.Context, lc listenConfig, ready func(addr string)) error {
+			ln, err := lc.Listen(ctx, "tcp", address)
+			if err != nil {
+				return err
+			}
+			srv := grpc.NewServer()
+			pwsv1.RegisterObservationServiceServer(srv, &grpcService{e: e, done: ctx.Done()})
+			ready(ln.Addr().String())
+			return serveUntilDone(ctx, func() error {
+				return srv.Serve(ln)
+			}, func(ctx context.Context) error {
+				stopped := make(chan struct{})
+				go func() {
+					srv.GracefulStop()
+					close(stopped)
+				}()
+				select {
+				case <-stopped:
+					return nil
+				case <-ctx.Done():
+					srv.Stop()
+					return ctx.Err()
+				}
+			})
+		},
+	}
+}
+
+// ListStations returns the stations that have submitted observations.
+func (s *grpcService) ListStations(ctx context.Context, _ *pwsv1.ListStationsRequest) (*pwsv1.ListStationsResponse, error) {
+	resp := &pwsv1.ListStationsResponse{}
+	for _, st := range s.e.Status(grpcRequest(ctx)).Stations {
+		resp.Stations = append(resp.Stations, &pwsv1.Station{
+			StationId:            st.StationID,
+			LastSeen:             timestamppb.New(st.LastSeen),
+			SubmissionsPerMinute: st.SubmissionsPerMinute,
+			Realtime:             st.Latest.Measurement.RealTime,
+		})
+	}
+	return resp, nil
+}
+
+// GetLatest returns the most recent observation for a station.
+func (s *grpcService) GetLatest(ctx context.Context, req *pwsv1.GetLatestRequest) (*pwsv1.GetLatestResponse, error) {
+	if req.GetStationId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "station_id is required")
+	}
+	if !s.e.authorized(grpcRequest(ctx), req.GetStationId()) {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	o, ok := s.e.history.latest(req.GetStationId())
+	if !ok {
+		return nil, status.Error(codes.NotFound, "station not found")
+	}
+	return &pwsv1.GetLatestResponse{Observation: newProtoObservation(o)}, nil
+}
+
+// StreamObservations streams new observations as they are received, until the
+// client cancels the stream or the server shuts down.
+func (s *grpcService) StreamObservations(req *pwsv1.StreamObservationsRequest, stream grpc.ServerStreamingServer[pwsv1.StreamObservationsResponse]) error {
+	ctx := stream.Context()
+	r := grpcRequest(ctx)
+	if req.GetStationId() != "" && !s.e.authorized(r, req.GetStationId()) {
+		return status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	sub := s.e.hub.subscribe(req.GetStationId())
+	defer s.e.hub.unsubscribe(sub)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return nil
+		case o := <-sub.c:
+			if !s.e.authorized(r, o.StationID) {
+				continue
+			}
+			err := stream.Send(&pwsv1.StreamObservationsResponse{
+				Observation: newProtoObservation(o),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// grpcRequest returns a HTTP request carrying the credentials from the gRPC
+// request metadata, used to authorize access to tenant stations.
+func grpcRequest(ctx context.Context) *http.Request {
+	r := &http.Request{Header: make(http.Header)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			r.Header.Add("Authorization", v)
+		}
+	}
+	return r
+}
+
+func newProtoObservation(o weather.Observation) *pwsv1.Observation {
+	dm := o.Measurement
+	return &pwsv1.Observation{
+		StationId:                o.StationID,
+		Time:                     timestamppb.New(dm.DateUTC),
+		ReceivedAt:               timestamppb.New(o.ReceivedAt),
+		TemperatureCelsius:       measured(dm, "tempf", dm.Temperature),
+		DewPointCelsius:          measured(dm, "dewptf", dm.DewPoint),
+		HumidityPercent:          measured(dm, "humidity", dm.Humidity),
+		IndoorTemperatureCelsius: measured(dm, "indoortempf", dm.IndoorTemp),
+		IndoorHumidityPercent:    measured(dm, "indoorhumidity", dm.IndoorHumidity),
+		BarometricPressureHpa:    measured(dm, "baromin", dm.Barometric),
+		WindSpeedKph:             measured(dm, "windspeedmph", dm.WindSpeed),
+		WindGustSpeedKph:         measured(dm, "windgustmph", dm.WindGust),
+		WindDirectionDegrees:     measured(dm, "winddir", dm.WindDirection),
+		RainPastHourMm:           measured(dm, "rainin", dm.RainPastHour),
+		RainTodayMm:              measured(dm, "dailyrainin", dm.RainToday),
+	}
+}
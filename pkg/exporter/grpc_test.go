@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pwsv1 "github.com/joshuasing/pws_exporter/api/pws/v1"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestGRPCServerGracefulStop(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc := e.grpcServerService("127.0.0.1:0")
+	addrc := make(chan string, 1)
+	served := make(chan error, 1)
+	go func() {
+		served <- svc.serve(ctx, &net.ListenConfig{}, func(addr string) {
+			addrc <- addr
+		})
+	}()
+
+	var addr string
+	select {
+	case addr = <-addrc:
+	case err := <-served:
+		t.Fatalf("serve: %v", err)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	defer conn.Close()
+
+	stream, err := pwsv1.NewObservationServiceClient(conn).
+		StreamObservations(context.Background(), &pwsv1.StreamObservationsRequest{})
+	if err != nil {
+		t.Fatalf("stream observations: %v", err)
+	}
+	publishWhenSubscribed(t, e, weather.Observation{StationID: "a"})
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if got := resp.GetObservation().GetStationId(); got != "a" {
+		t.Errorf("got station %q, want a", got)
+	}
+
+	// Open streams end when the server shuts down, so it stops gracefully
+	// without waiting for the shutdown timeout.
+	start := time.Now()
+	cancel()
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("receive after shutdown: got %v, want EOF", err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	case <-time.After(2 * shutdownTimeout):
+		t.Fatal("serve did not return")
+	}
+	if elapsed := time.Since(start); elapsed >= shutdownTimeout {
+		t.Errorf("shutdown took %v, want < %v", elapsed, shutdownTimeout)
+	}
+}
+
+func TestNewProtoObservationMissing(t *testing.T) {
+	o := newProtoObservation(weather.Observation{
+		StationID: "a",
+		Measurement: wu.DeviceMeasurement{
+			Temperature: 20.5,
+			Missing:     []string{"humidity"},
+		},
+	})
+	if o.TemperatureCelsius == nil || *o.TemperatureCelsius != 20.5 {
+		t.Errorf("got temperature %v, want 20.5", o.TemperatureCelsius)
+	}
+	if o.HumidityPercent != nil {
+		t.Errorf("missing humidity is set to %v", *o.HumidityPercent)
+	}
+}
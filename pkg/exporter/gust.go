@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+const (
+	// gustFactorWindow is the rolling window the gust factor is computed
+	// over, which is the standard averaging period of sustained wind.
+	gustFactorWindow = 10 * time.Minute
+
+	// minGustFactorSpeedKPH is the minimum mean wind speed for the gust
+	// factor to be computed, as the ratio is meaningless in light winds.
+	minGustFactorSpeedKPH = 5
+)
+
+// updateGustFactor updates the gust factor gauge of a station from the
+// observations in memory that were taken within the gust factor window of the
+// latest observation that measured the wind speed. The gust factor is deleted
+// while it cannot be computed.
+func (e *Exporter) updateGustFactor(m *Metrics, l prometheus.Labels, stationID string, latest time.Time) {
+	var window []weather.Observation
+	for _, o := range e.history.since(stationID, time.Time{}) {
+		if o.Measurement.Has("windspeedmph") && !o.Measurement.DateUTC.Before(latest.Add(-gustFactorWindow)) && !o.Measurement.DateUTC.After(latest) {
+			window = append(window, o)
+		}
+	}
+	if g, ok := gustFactor(window); ok {
+		m.GustFactor.With(l).Set(g)
+	} else {
+		m.GustFactor.Delete(l)
+	}
+}
+
+// gustFactor returns the ratio of the highest wind gust to the mean wind speed
+// of the observations. It is not ok if there are fewer than two observations,
+// or the mean wind speed is too low.
+func gustFactor(observations []weather.Observation) (float64, bool) {
+	if len(observations) < 2 {
+		return 0, false
+	}
+	var sum, gust float64
+	for _, o := range observations {
+		sum += o.Measurement.WindSpeed
+		gust = max(gust, o.Measurement.WindGust, o.Measurement.WindSpeed)
+	}
+	mean := sum / float64(len(observations))
+	if mean < minGustFactorSpeedKPH {
+		return 0, false
+	}
+	return gust / mean, true
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestGustFactor(t *testing.T) {
+	start := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	observations := func(winds ...[2]float64) []weather.Observation {
+		obs := make([]weather.Observation, 0, len(winds))
+		for i, w := range winds {
+			obs = append(obs, weather.Observation{Measurement: wu.DeviceMeasurement{
+				DateUTC:   start.Add(time.Duration(i) * time.Minute),
+				WindSpeed: w[0],
+				WindGust:  w[1],
+			}})
+		}
+		return obs
+	}
+
+	g, ok := gustFactor(observations([2]float64{18, 25}, [2]float64{22, 34}, [2]float64{20, 28}))
+	if !ok || math.Abs(g-1.7) > 0.001 {
+		t.Errorf("got gust factor %v (ok %v), want 1.7", g, ok)
+	}
+
+	if _, ok := gustFactor(observations([2]float64{20, 30})); ok {
+		t.Error("gust factor of a single observation should not be ok")
+	}
+	if _, ok := gustFactor(observations([2]float64{2, 9}, [2]float64{3, 12})); ok {
+		t.Error("gust factor in light winds should not be ok")
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Healthy returns an error if the exporter is unhealthy, which is the case
+// when any of its listeners have failed.
+func (e *Exporter) Healthy() error {
+	var errs []error
+	for _, l := range e.listeners.snapshot() {
+		if !l.Listening && l.Error != "" {
+			errs = append(errs, fmt.Errorf("%s listener failed: %s", l.Name, l.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ready returns an error if the exporter is not ready to receive submissions,
+// which is the case until all of its listeners are listening.
+func (e *Exporter) Ready() error {
+	ls := e.listeners.snapshot()
+	if len(ls) == 0 {
+		return errors.New("exporter is not running")
+	}
+	var errs []error
+	for _, l := range ls {
+		if !l.Listening {
+			errs = append(errs, fmt.Errorf("%s listener is not listening", l.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HealthHandler returns a HTTP handler for liveness probes, which responds
+// with 503 Service Unavailable if the exporter is unhealthy.
+func (e *Exporter) HealthHandler() http.Handler {
+	return probeHandler(e.Healthy)
+}
+
+// ReadyHandler returns a HTTP handler for readiness probes, which responds
+// with 503 Service Unavailable if the exporter is not ready.
+func (e *Exporter) ReadyHandler() http.Handler {
+	return probeHandler(e.Ready)
+}
+
+// probeHandler returns a HTTP handler that responds with the result of check.
+func probeHandler(check func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		if err := check(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintln(w, err)
+			return
+		}
+		_, _ = fmt.Fprintln(w, "OK")
+	})
+}
@@ -21,25 +21,32 @@
 package exporter
 
 import (
-	"github.com/prometheus/client_golang/prometheus"
-
-	"github.com/joshuasing/pws_exporter/internal/exporter/wu"
+	"errors"
+	"testing"
 )
 
-func (e *Exporter) handleWUSubmission(deviceID string, dm wu.DeviceMeasurement) {
-	m := e.metrics
-	l := prometheus.Labels{"station_id": deviceID}
+func TestHealth(t *testing.T) {
+	e := &Exporter{}
+	if err := e.Ready(); err == nil {
+		t.Error("exporter without listeners is ready")
+	}
+
+	e.listeners.set("dns", ":53", false, nil)
+	e.listeners.set("wu", ":80", true, nil)
+	if err := e.Ready(); err == nil {
+		t.Error("exporter with pending listener is ready")
+	}
+	if err := e.Healthy(); err != nil {
+		t.Errorf("exporter with pending listener is unhealthy: %v", err)
+	}
+
+	e.listeners.set("dns", ":53", true, nil)
+	if err := e.Ready(); err != nil {
+		t.Errorf("exporter with all listeners listening is not ready: %v", err)
+	}
 
-	m.BarometricPressure.With(l).Set(float64(dm.Barometric))
-	m.DewPoint.With(l).Set(float64(dm.DewPoint))
-	m.Humidity.With(l).Set(float64(dm.Humidity / 100))
-	m.IndoorHumidity.With(l).Set(float64(dm.IndoorHumidity / 100))
-	m.IndoorTemperature.With(l).Set(float64(dm.IndoorTemp))
-	m.RainPastHour.With(l).Set(float64(dm.RainPastHour))
-	m.Rain.Delete(l) // Counter state is stored on the station, not in the exporter.
-	m.Rain.With(l).Add(float64(dm.RainToday))
-	m.Temperature.With(l).Set(float64(dm.Temperature))
-	m.WindDirection.With(l).Set(float64(dm.WindDirection))
-	m.WindGustSpeed.With(l).Set(float64(dm.WindGust))
-	m.WindSpeed.With(l).Set(float64(dm.WindSpeed))
+	e.listeners.set("wu", ":80", false, errors.New("address already in use"))
+	if err := e.Healthy(); err == nil {
+		t.Error("exporter with failed listener is healthy")
+	}
 }
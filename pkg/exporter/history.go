@@ -0,0 +1,128 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// defaultHistorySize is the default number of observations kept in memory for
+// each station.
+const defaultHistorySize = 100
+
+// history stores the most recent observations for each station in memory.
+type history struct {
+	mu       sync.RWMutex
+	size     int
+	stations map[string]*ring
+}
+
+// ring is a fixed-size ring buffer of observations.
+type ring struct {
+	buf  []weather.Observation
+	next int
+	full bool
+}
+
+func newHistory(size int) *history {
+	return &history{
+		size:     size,
+		stations: make(map[string]*ring),
+	}
+}
+
+// add adds an observation to the history, replacing the oldest observation
+// for the station if the buffer is full.
+func (h *history) add(o weather.Observation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.stations[o.StationID]
+	if !ok {
+		r = &ring{buf: make([]weather.Observation, h.size)}
+		h.stations[o.StationID] = r
+	}
+	r.buf[r.next] = o
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// delete removes all observations for a station.
+func (h *history) delete(stationID string) {
+	h.mu.Lock()
+	delete(h.stations, stationID)
+	h.mu.Unlock()
+}
+
+// stationIDs returns the IDs of all stations with observations.
+func (h *history) stationIDs() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ids := make([]string, 0, len(h.stations))
+	for id := range h.stations {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// latest returns the most recent observation for the station.
+func (h *history) latest(stationID string) (weather.Observation, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	r, ok := h.stations[stationID]
+	if !ok {
+		return weather.Observation{}, false
+	}
+	return r.buf[(r.next-1+len(r.buf))%len(r.buf)], true
+}
+
+// since returns the observations for the station that were received after the
+// given time, in the order they were received.
+func (h *history) since(stationID string, t time.Time) []weather.Observation {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	r, ok := h.stations[stationID]
+	if !ok {
+		return nil
+	}
+
+	var ordered []weather.Observation
+	if r.full {
+		ordered = append(ordered, r.buf[r.next:]...)
+	}
+	ordered = append(ordered, r.buf[:r.next]...)
+
+	out := make([]weather.Observation, 0, len(ordered))
+	for _, o := range ordered {
+		if o.ReceivedAt.After(t) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestHistory(t *testing.T) {
+	h := newHistory(3)
+	start := time.Now()
+	for i := range 5 {
+		h.add(weather.Observation{
+			StationID:  "test",
+			ReceivedAt: start.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	latest, ok := h.latest("test")
+	if !ok {
+		t.Fatal("latest observation not found")
+	}
+	if want := start.Add(4 * time.Second); !latest.ReceivedAt.Equal(want) {
+		t.Errorf("latest got %v, want %v", latest.ReceivedAt, want)
+	}
+
+	all := h.since("test", time.Time{})
+	if len(all) != 3 {
+		t.Fatalf("got %d observations, want 3", len(all))
+	}
+	for i, o := range all {
+		if want := start.Add(time.Duration(i+2) * time.Second); !o.ReceivedAt.Equal(want) {
+			t.Errorf("observation %d got %v, want %v", i, o.ReceivedAt, want)
+		}
+	}
+
+	if got := len(h.since("test", start.Add(3*time.Second))); got != 1 {
+		t.Errorf("got %d observations since 3s, want 1", got)
+	}
+	if _, ok := h.latest("unknown"); ok {
+		t.Error("latest observation found for unknown station")
+	}
+}
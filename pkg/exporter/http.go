@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// httpMetrics contains the metrics used to instrument HTTP handlers.
+type httpMetrics struct {
+	InFlight *prometheus.GaugeVec
+	Requests *prometheus.CounterVec
+	Duration *prometheus.HistogramVec
+}
+
+func newHTTPMetrics(namespace string, reg prometheus.Registerer) *httpMetrics {
+	m := &httpMetrics{
+		InFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of HTTP requests currently being served",
+		}, []string{"handler"}),
+		Requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Total number of HTTP requests by handler, method and status code",
+		}, []string{"handler", "method", "code"}),
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests in seconds",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"handler", "method", "code"}),
+	}
+	reg.MustRegister(m.InFlight, m.Requests, m.Duration)
+	return m
+}
+
+// Handler returns a HTTP handler that serves the metrics, health checks,
+// status page and APIs.
+func (e *Exporter) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// Metrics handlers
+	metricsHandler := e.MetricsHandler(promhttp.HandlerOpts{
+		EnableOpenMetrics:                   true,
+		EnableOpenMetricsTextCreatedSamples: true,
+	})
+	mux.Handle("/metrics", e.InstrumentHandler("metrics", metricsHandler))
+	mux.Handle("/metrics/station/{id}", e.InstrumentHandler("station_metrics", metricsHandler))
+
+	// Health check handlers
+	mux.Handle("GET /healthz", e.HealthHandler())
+	mux.Handle("GET /readyz", e.ReadyHandler())
+
+	// Debug handlers
+	mux.Handle("GET /debug/last-submission/{station}",
+		e.InstrumentHandler("debug", http.HandlerFunc(e.handleLastSubmission)))
+	mux.Handle("GET /debug/config",
+		e.InstrumentHandler("debug", http.HandlerFunc(e.handleDebugConfig)))
+
+	// Status UI handler
+	mux.Handle("/", e.InstrumentHandler("ui", e.UIHandler()))
+
+	// JSON API handler
+	mux.Handle("/api/", e.InstrumentHandler("api", e.APIHandler()))
+
+	return mux
+}
+
+// InstrumentHandler wraps the HTTP handler with middleware that records the
+// number of in-flight requests, request durations and response codes, using
+// the given handler name as a label.
+func (e *Exporter) InstrumentHandler(name string, h http.Handler) http.Handler {
+	l := prometheus.Labels{"handler": name}
+	m := e.httpMetrics
+	return promhttp.InstrumentHandlerInFlight(m.InFlight.With(l),
+		promhttp.InstrumentHandlerDuration(m.Duration.MustCurryWith(l),
+			promhttp.InstrumentHandlerCounter(m.Requests.MustCurryWith(l), h),
+		),
+	)
+}
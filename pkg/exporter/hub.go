@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"sync"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// subscriptionBuffer is the number of observations buffered for each
+// subscriber. Observations are dropped for subscribers that fall behind.
+const subscriptionBuffer = 64
+
+// hub fans out new observations to live subscribers.
+type hub struct {
+	mu   sync.Mutex
+	subs map[*subscription]struct{}
+}
+
+// subscription receives new observations from a hub.
+type subscription struct {
+	stationID string
+	c         chan weather.Observation
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[*subscription]struct{})}
+}
+
+// subscribe returns a new subscription to observations from the station. If
+// stationID is empty, observations from all stations are received.
+func (h *hub) subscribe(stationID string) *subscription {
+	s := &subscription{
+		stationID: stationID,
+		c:         make(chan weather.Observation, subscriptionBuffer),
+	}
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// unsubscribe removes a subscription from the hub.
+func (h *hub) unsubscribe(s *subscription) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+}
+
+// publish sends an observation to all matching subscribers without blocking.
+func (h *hub) publish(o weather.Observation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subs {
+		if s.stationID != "" && s.stationID != o.StationID {
+			continue
+		}
+		select {
+		case s.c <- o:
+		default:
+			// Subscriber is not keeping up, drop the observation.
+		}
+	}
+}
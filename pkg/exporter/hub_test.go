@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestHub(t *testing.T) {
+	h := newHub()
+	all := h.subscribe("")
+	one := h.subscribe("a")
+	defer h.unsubscribe(all)
+
+	h.publish(weather.Observation{StationID: "a"})
+	h.publish(weather.Observation{StationID: "b"})
+
+	if got := len(all.c); got != 2 {
+		t.Errorf("all stations subscriber got %d observations, want 2", got)
+	}
+	if got := len(one.c); got != 1 {
+		t.Errorf("single station subscriber got %d observations, want 1", got)
+	}
+
+	// Unsubscribed subscribers do not receive observations.
+	h.unsubscribe(one)
+	h.publish(weather.Observation{StationID: "a"})
+	if got := len(one.c); got != 1 {
+		t.Errorf("unsubscribed subscriber got %d observations, want 1", got)
+	}
+
+	// Slow subscribers do not block publishing.
+	for range subscriptionBuffer * 2 {
+		h.publish(weather.Observation{StationID: "a"})
+	}
+	if got := len(all.c); got != subscriptionBuffer {
+		t.Errorf("slow subscriber got %d observations, want %d", got, subscriptionBuffer)
+	}
+}
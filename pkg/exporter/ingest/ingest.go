@@ -0,0 +1,299 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package ingest implements the JSON ingestion API, which accepts measurements
+// in a documented JSON schema from custom firmware, ESPHome nodes and scripts,
+// so they can push readings without imitating a WU station.
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// tracer records spans for ingested measurements. Spans are only recorded if a
+// global OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/exporter/ingest")
+
+// Path is the path of the ingestion endpoint.
+const Path = "/api/v1/ingest"
+
+// MaxBodySize is the maximum size of a request body.
+const MaxBodySize = 1 << 20
+
+// Units are the units that the values of a measurement are in.
+type Units string
+
+const (
+	// UnitsMetric are the metric units of the fields, e.g. °C, hPa, km/h and
+	// mm. Used if no units are specified.
+	UnitsMetric Units = "metric"
+
+	// UnitsImperial are the imperial units of the fields, e.g. °F, inHg, mph
+	// and in.
+	UnitsImperial Units = "imperial"
+)
+
+// Measurement is a measurement submitted to the ingestion API.
+type Measurement struct {
+	// StationID is the ID of the station the measurement is from.
+	StationID string `json:"station_id"`
+
+	// Time is the time of the measurement. If nil, the measurement is
+	// timestamped when it is received.
+	Time *time.Time `json:"time,omitempty"`
+
+	// Units are the units of the values. Defaults to UnitsMetric.
+	Units Units `json:"units,omitempty"`
+
+	// Values maps measurement fields (e.g. "temperature") to their values.
+	// Null values are missing.
+	Values map[string]*float64 `json:"values"`
+}
+
+// Decode decodes the measurements of a request body, which is either a single
+// measurement object or an array of measurements. Unknown object keys are
+// rejected, so that misspelled keys are not silently ignored.
+func Decode(r io.Reader) ([]Measurement, error) {
+	br := bufio.NewReader(r)
+	batch := false
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("empty request body")
+			}
+			return nil, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		batch = b == '['
+		_ = br.UnreadByte()
+		break
+	}
+
+	d := json.NewDecoder(br)
+	d.DisallowUnknownFields()
+	var ms []Measurement
+	if batch {
+		if err := d.Decode(&ms); err != nil {
+			return nil, fmt.Errorf("decode measurements: %w", err)
+		}
+	} else {
+		var m Measurement
+		if err := d.Decode(&m); err != nil {
+			return nil, fmt.Errorf("decode measurement: %w", err)
+		}
+		ms = append(ms, m)
+	}
+	if d.More() {
+		return nil, errors.New("unexpected data after measurements")
+	}
+	if len(ms) == 0 {
+		return nil, errors.New("no measurements")
+	}
+	return ms, nil
+}
+
+// Translate translates a measurement to the query parameters of an equivalent
+// WU submission from the station. The dew point is derived from the
+// temperature and humidity if it is not submitted. An error is returned if the
+// measurement is invalid, e.g. if it has an unknown field.
+func (m Measurement) Translate() (url.Values, error) {
+	if m.StationID == "" {
+		return nil, errors.New("station_id is required")
+	}
+	switch m.Units {
+	case "", UnitsMetric, UnitsImperial:
+	default:
+		return nil, fmt.Errorf("unknown units %q, must be %q or %q", m.Units, UnitsMetric, UnitsImperial)
+	}
+	if len(m.Values) == 0 {
+		return nil, errors.New("values are required")
+	}
+
+	names := make([]string, 0, len(m.Values))
+	for name := range m.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	q := url.Values{}
+	q.Set("ID", m.StationID)
+	q.Set("action", "updateraww")
+	q.Set("dateutc", "now")
+	if m.Time != nil {
+		q.Set("dateutc", m.Time.UTC().Format("2006-01-02 15:04:05"))
+	}
+	for _, name := range names {
+		nf, ok := wu.LookupField(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		v := m.Values[name]
+		if v == nil {
+			continue
+		}
+		f := *v
+		if m.Units != UnitsImperial && nf.FromMetric != nil {
+			f = nf.FromMetric(f)
+		}
+		q.Set(nf.Param, strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	wu.AddDewPoint(q)
+	return q, nil
+}
+
+// API is the JSON ingestion API.
+type API struct {
+	handleSubmission func(ctx context.Context, s wu.Submission)
+	stationID        func(stationID string) (string, bool)
+	authorize        func(r *http.Request, stationID string) bool
+}
+
+// NewAPI returns a new ingestion API. The handler is called synchronously for
+// each measurement of a request, translated to a WU submission, once all of
+// the request's measurements have been validated.
+func NewAPI(handler func(ctx context.Context, s wu.Submission)) *API {
+	return &API{handleSubmission: handler}
+}
+
+// SetStationIDFunc sets the function used to normalize the ID of the station
+// that a measurement is from. If the function returns false, the request is
+// rejected. It must be called before the API is served.
+func (api *API) SetStationIDFunc(fn func(stationID string) (string, bool)) {
+	api.stationID = fn
+}
+
+// SetAuthorizeFunc sets the function used to check whether a request may
+// submit measurements for a station, given its normalized ID. If the function
+// returns false, the request is rejected. It must be called before the API is
+// served.
+func (api *API) SetAuthorizeFunc(fn func(r *http.Request, stationID string) bool) {
+	api.authorize = fn
+}
+
+func (api *API) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	remoteAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remoteAddr = req.RemoteAddr
+	}
+
+	ctx, span := tracer.Start(req.Context(), "ingest.request",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("client.address", remoteAddr)))
+	defer span.End()
+
+	reject := func(status int, msg string) {
+		slog.Warn("Rejected ingested measurements",
+			slog.String("remote_addr", remoteAddr),
+			slog.String("reason", msg),
+			slog.String("outcome", "rejected"))
+		span.SetStatus(codes.Error, msg)
+		http.Error(w, msg, status)
+	}
+
+	ms, err := Decode(http.MaxBytesReader(w, req.Body, MaxBodySize))
+	if err != nil {
+		reject(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Every measurement is validated before any are submitted, so that a
+	// rejected request can be retried as a whole.
+	receivedAt := time.Now()
+	submissions := make([]wu.Submission, 0, len(ms))
+	for i, m := range ms {
+		q, err := m.Translate()
+		if err != nil {
+			reject(http.StatusBadRequest, fmt.Sprintf("measurement %d: %v", i, err))
+			return
+		}
+		stationID := m.StationID
+		if api.stationID != nil {
+			id, ok := api.stationID(stationID)
+			if !ok {
+				reject(http.StatusForbidden, fmt.Sprintf("measurement %d: unknown station %q", i, stationID))
+				return
+			}
+			stationID = id
+		}
+		if api.authorize != nil && !api.authorize(req, stationID) {
+			w.Header().Add("WWW-Authenticate", `Bearer realm="pws_exporter"`)
+			w.Header().Add("WWW-Authenticate", `Basic realm="pws_exporter"`)
+			reject(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			return
+		}
+		q.Set("ID", stationID)
+
+		dm, fieldErrs := wu.ParseMeasurement(q, receivedAt)
+		for _, fe := range fieldErrs {
+			span.RecordError(fe)
+			slog.Warn("Ignored invalid ingested measurement field",
+				slog.String("station_id", stationID),
+				slog.String("remote_addr", remoteAddr),
+				slog.String("param", fe.Param),
+				slog.String("value", fe.Value),
+				slog.Any("err", fe.Err))
+		}
+		submissions = append(submissions, wu.Submission{
+			StationID:   stationID,
+			ReceivedAt:  receivedAt,
+			RemoteAddr:  remoteAddr,
+			RawQuery:    q.Encode(),
+			Measurement: dm,
+			Fields:      wu.SubmittedFields(q, fieldErrs),
+			FieldErrors: fieldErrs,
+		})
+	}
+
+	span.SetAttributes(attribute.Int("measurements", len(submissions)))
+	for _, s := range submissions {
+		slog.Info("Received ingested weather data from station",
+			slog.String("station_id", s.StationID),
+			slog.String("remote_addr", remoteAddr),
+			slog.String("outcome", "accepted"))
+		api.handleSubmission(ctx, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "{\"accepted\":%d}\n", len(submissions))
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ingest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+func TestDecode(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		body    string
+		want    int
+		wantErr bool
+	}{
+		{name: "object", body: `{"station_id":"garden","values":{"temperature":21.5}}`, want: 1},
+		{name: "array", body: ` [{"station_id":"a","values":{}},{"station_id":"b","values":{}}]`, want: 2},
+		{name: "empty", body: " \n", wantErr: true},
+		{name: "empty array", body: `[]`, wantErr: true},
+		{name: "unknown key", body: `{"station_id":"garden","value":{"temperature":21.5}}`, wantErr: true},
+		{name: "trailing data", body: `{"station_id":"garden"} {}`, wantErr: true},
+		{name: "invalid", body: `station_id=garden`, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, err := Decode(strings.NewReader(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(ms) != tt.want {
+				t.Errorf("got %d measurements, want %d", len(ms), tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	ms, err := Decode(strings.NewReader(`{"station_id":"garden","time":"2025-10-15T14:30:00+02:00",
+"values":{"temperature":15.4,"humidity":80,"barometric_pressure":1013.25,"wind_speed":null}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := ms[0].Translate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for param, want := range map[string]string{
+		"ID":       "garden",
+		"action":   "updateraww",
+		"dateutc":  "2025-10-15 12:30:00",
+		"humidity": "80",
+		"dewptf":   "53.5",
+	} {
+		if got := q.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+	dm, errs := wu.ParseMeasurement(q, ms[0].Time.UTC())
+	if len(errs) != 0 {
+		t.Fatalf("unexpected field errors: %v", errs)
+	}
+	if diff := dm.Temperature - 15.4; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("temperature = %v, want 15.4", dm.Temperature)
+	}
+	if diff := dm.Barometric - 1013.25; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("barometric pressure = %v, want 1013.25", dm.Barometric)
+	}
+	if q.Has("windspeedmph") {
+		t.Errorf("windspeedmph = %q, want unset", q.Get("windspeedmph"))
+	}
+
+	imperial := Measurement{StationID: "garden", Units: UnitsImperial, Values: map[string]*float64{"temperature": ptr(59.7)}}
+	if q, err := imperial.Translate(); err != nil || q.Get("tempf") != "59.7" || q.Get("dateutc") != "now" {
+		t.Errorf("imperial Translate() = %q, %v, want tempf=59.7 received now", q.Encode(), err)
+	}
+
+	for _, m := range []Measurement{
+		{Values: map[string]*float64{"temperature": ptr(1)}},
+		{StationID: "garden"},
+		{StationID: "garden", Values: map[string]*float64{"temprature": ptr(1)}},
+		{StationID: "garden", Units: "kelvin", Values: map[string]*float64{"temperature": ptr(1)}},
+	} {
+		if _, err := m.Translate(); err == nil {
+			t.Errorf("Translate(%+v) succeeded, want error", m)
+		}
+	}
+}
+
+func TestAPI(t *testing.T) {
+	var submissions []wu.Submission
+	api := NewAPI(func(_ context.Context, s wu.Submission) {
+		submissions = append(submissions, s)
+	})
+	api.SetStationIDFunc(func(stationID string) (string, bool) {
+		return strings.ToLower(stationID), stationID != "blocked"
+	})
+	api.SetAuthorizeFunc(func(r *http.Request, stationID string) bool {
+		if stationID != "private" {
+			return true
+		}
+		_, password, _ := r.BasicAuth()
+		return password == "secret"
+	})
+
+	post := func(body string, auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(body))
+		if auth {
+			req.SetBasicAuth("tenant", "secret")
+		}
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`[{"station_id":"Garden","values":{"temperature":21.5}},{"station_id":"roof","values":{"wind_speed":12}}]`, false)
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"accepted":2}` {
+		t.Fatalf("got %d %q, want 200 with 2 accepted", w.Code, w.Body.String())
+	}
+	if len(submissions) != 2 || submissions[0].StationID != "garden" || submissions[1].StationID != "roof" {
+		t.Fatalf("got submissions %+v, want garden and roof", submissions)
+	}
+	if got := submissions[0].Measurement.Temperature; got != 21.5 {
+		t.Errorf("temperature = %v, want 21.5", got)
+	}
+
+	// Requests with an invalid measurement are rejected as a whole.
+	submissions = nil
+	for _, tt := range []struct {
+		body string
+		auth bool
+		want int
+	}{
+		{`[{"station_id":"garden","values":{"temperature":1}},{"station_id":"roof","values":{"snow":1}}]`, false, http.StatusBadRequest},
+		{`{"station_id":"blocked","values":{"temperature":1}}`, false, http.StatusForbidden},
+		{`{"station_id":"private","values":{"temperature":1}}`, false, http.StatusUnauthorized},
+		{`{"station_id":"garden","values":{"temperature":1}}` + strings.Repeat(" ", MaxBodySize), false, http.StatusBadRequest},
+	} {
+		if w := post(tt.body, tt.auth); w.Code != tt.want {
+			t.Errorf("got %d %q, want %d", w.Code, w.Body.String(), tt.want)
+		}
+	}
+	if len(submissions) != 0 {
+		t.Errorf("got submissions %+v from rejected requests", submissions)
+	}
+
+	if w := post(`{"station_id":"private","values":{"temperature":1}}`, true); w.Code != http.StatusOK {
+		t.Errorf("authorized request got %d %q, want 200", w.Code, w.Body.String())
+	}
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
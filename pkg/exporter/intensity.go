@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// rainIntensityWindow is the rolling window the rain rate is derived
+	// over. Rain gauges report rain in tips of 0.2-0.25 mm, so a shorter
+	// window makes the rate too coarse.
+	rainIntensityWindow = 10 * time.Minute
+
+	// rainIntensityInterval is the interval at which the rain rate is
+	// sampled into the rain intensity histogram, so that each sample
+	// represents roughly the same duration of rain regardless of how often
+	// the station submits.
+	rainIntensityInterval = time.Minute
+)
+
+// rainIntensityBuckets are the upper bounds of the WMO rain intensity
+// classes, in mm/h: light, moderate, heavy and violent (+Inf).
+var rainIntensityBuckets = []float64{2.5, 10, 50}
+
+// rainSample is the cumulative rain of a station at a point in time.
+type rainSample struct {
+	t     time.Time
+	total float64
+}
+
+// rainIntensity is the rain rate state of a station.
+type rainIntensity struct {
+	// total is the cumulative rain since the exporter started, which unlike
+	// the daily total does not reset at midnight, and lastToday is the last
+	// daily total submitted by the station.
+	total     float64
+	lastToday float64
+
+	// samples are the cumulative rain totals sampled at the sample
+	// interval, covering at least the rolling window.
+	samples []rainSample
+}
+
+// rainIntensityState stores the rain rate state of each station.
+type rainIntensityState struct {
+	mu       sync.Mutex
+	stations map[string]*rainIntensity
+}
+
+// updateRainIntensity records a station's daily rain total at t, and once per
+// sample interval, observes the rain rate over the rolling window in the rain
+// intensity histogram while it is raining.
+func (e *Exporter) updateRainIntensity(m *Metrics, l prometheus.Labels, stationID string, t time.Time, rainToday float64) {
+	e.rainIntensity.mu.Lock()
+	defer e.rainIntensity.mu.Unlock()
+
+	ri, ok := e.rainIntensity.stations[stationID]
+	if !ok {
+		ri = &rainIntensity{lastToday: rainToday}
+		e.rainIntensity.stations[stationID] = ri
+	}
+	if rainToday < ri.lastToday {
+		// The daily total was reset, so all of it fell since midnight.
+		ri.total += rainToday
+	} else {
+		ri.total += rainToday - ri.lastToday
+	}
+	ri.lastToday = rainToday
+
+	if n := len(ri.samples); n > 0 && t.Sub(ri.samples[n-1].t) < rainIntensityInterval {
+		return
+	}
+	ri.samples = append(ri.samples, rainSample{t: t, total: ri.total})
+
+	// Keep the newest sample taken at or before the start of the window.
+	for len(ri.samples) > 1 && !ri.samples[1].t.After(t.Add(-rainIntensityWindow)) {
+		ri.samples = ri.samples[1:]
+	}
+	base := ri.samples[0]
+	span := t.Sub(base.t)
+	if span < rainIntensityWindow || span > 2*rainIntensityWindow {
+		// Not enough samples yet, or the station was offline.
+		return
+	}
+	if rate := (ri.total - base.total) / span.Hours(); rate > 0 {
+		m.RainIntensity.With(l).Observe(rate)
+	}
+}
+
+// delete removes the rain rate state of a station.
+func (s *rainIntensityState) delete(stationID string) {
+	s.mu.Lock()
+	delete(s.stations, stationID)
+	s.mu.Unlock()
+}
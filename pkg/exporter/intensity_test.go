@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestRainIntensity(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	// The station submits every 30 seconds, and 0.2 mm of rain falls every
+	// minute from 5 minutes in. Samples are taken every minute, and the rate
+	// is observed once the samples cover the 10 minute window.
+	start := time.Date(2025, 1, 23, 12, 0, 0, 0, time.UTC)
+	for i := 0; i <= 30; i++ {
+		ts := start.Add(time.Duration(i) * 30 * time.Second)
+		var rain float64
+		if minutes := i / 2; minutes > 5 {
+			rain = float64(minutes-5) * 0.2
+		}
+		o := weather.Observation{
+			StationID:   "KTEST1",
+			Measurement: wu.DeviceMeasurement{DateUTC: ts, RainToday: rain},
+		}
+		if err := e.recordObservation(context.Background(), &o); err != nil {
+			t.Fatalf("record observation: %v", err)
+		}
+	}
+
+	var m dto.Metric
+	h := e.metrics.RainIntensity.WithLabelValues("KTEST1").(prometheus.Histogram)
+	if err := h.Write(&m); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	// Rates of 6, 7.2, 8.4, 9.6, 10.8 and 12 mm/h.
+	hist := m.GetHistogram()
+	if got := hist.GetSampleCount(); got != 6 {
+		t.Fatalf("got %d samples, want 6", got)
+	}
+	want := []uint64{0, 4, 6}
+	for i, b := range hist.GetBucket() {
+		if b.GetCumulativeCount() != want[i] {
+			t.Errorf("bucket le=%v: got %d, want %d", b.GetUpperBound(), b.GetCumulativeCount(), want[i])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// selectIP selects a local IP address from spec, which is one of:
+//   - an IP address, which is returned as is.
+//   - a CIDR, e.g. "192.168.20.0/24", which selects the first local address
+//     in the network.
+//   - an interface name, e.g. "vlan20", which selects the first IPv4 address
+//     of the interface, or its first global IPv6 address if it has no IPv4
+//     address. If ipv6 is true, only IPv6 addresses are selected.
+//
+// This allows the exporter IP to be selected on multi-homed hosts, where the
+// outbound address may be on a different network to the weather stations.
+func selectIP(spec string, ipv6 bool) (net.IP, error) {
+	if ip := net.ParseIP(spec); ip != nil {
+		return ip, nil
+	}
+	if _, network, err := net.ParseCIDR(spec); err == nil {
+		addrs, err := net.InterfaceAddrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && network.Contains(ipNet.IP) {
+				return ipNet.IP, nil
+			}
+		}
+		return nil, fmt.Errorf("no local address in %s", network)
+	}
+
+	iface, err := net.InterfaceByName(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not an IP address, CIDR or interface name: %w", spec, err)
+	}
+	return interfaceIP(iface, ipv6)
+}
+
+// interfaceIP returns the first IPv4 address of the network interface, or its
+// first global unicast IPv6 address if it has no IPv4 address or ipv6 is true.
+// Link-local IPv6 addresses are not used, as they cannot be used without a
+// zone.
+func interfaceIP(iface *net.Interface, ipv6 bool) (net.IP, error) {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("interface %s: %w", iface.Name, err)
+	}
+	var ip6 net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			if !ipv6 {
+				return ip4, nil
+			}
+			continue
+		}
+		if ip6 == nil && (ipNet.IP.IsGlobalUnicast() || ipNet.IP.IsLoopback()) {
+			ip6 = ipNet.IP
+		}
+	}
+	if ip6 != nil {
+		return ip6, nil
+	}
+	return nil, errors.New("interface " + iface.Name + " has no usable IP address")
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"net"
+	"testing"
+)
+
+func TestSelectIP(t *testing.T) {
+	tests := []struct {
+		spec    string
+		ipv6    bool
+		want    string
+		wantErr bool
+	}{
+		{spec: "192.0.2.1", want: "192.0.2.1"},
+		{spec: "2001:db8::1", ipv6: true, want: "2001:db8::1"},
+		{spec: "127.0.0.0/8", want: "127.0.0.1"},
+		{spec: "203.0.113.0/24", wantErr: true},
+		{spec: "nonexistent0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := selectIP(tt.spec, tt.ipv6)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("selectIP(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("selectIP(%q) = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestSelectIPInterface(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Skipf("list interfaces: %v", err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+		got, err := selectIP(iface.Name, false)
+		if err != nil {
+			t.Fatalf("selectIP(%q) error = %v", iface.Name, err)
+		}
+		if !got.IsLoopback() {
+			t.Errorf("selectIP(%q) = %s, want loopback address", iface.Name, got)
+		}
+		return
+	}
+	t.Skip("no loopback interface")
+}
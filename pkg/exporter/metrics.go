@@ -0,0 +1,396 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	stationSubsystem = "station"
+)
+
+type Metrics struct {
+	AbsoluteBarometricPressure *prometheus.GaugeVec
+	BarometricPressure         *prometheus.GaugeVec
+	BatteryVoltage             *prometheus.GaugeVec
+	CapacitorVoltage           *prometheus.GaugeVec
+	ChannelHumidity            *prometheus.GaugeVec
+	ChannelTemperature         *prometheus.GaugeVec
+	Condition                  *prometheus.GaugeVec
+	DewPoint                   *prometheus.GaugeVec
+	FieldErrors                *prometheus.CounterVec
+	FrostPoint                 *prometheus.GaugeVec
+	GustFactor                 *prometheus.GaugeVec
+	HeaterOn                   *prometheus.GaugeVec
+	Humidity                   *prometheus.GaugeVec
+	HumidityChange             *prometheus.GaugeVec
+	IndoorHumidity             *prometheus.GaugeVec
+	IndoorTemperature          *prometheus.GaugeVec
+	MixingRatio                *prometheus.GaugeVec
+	RainIntensity              *prometheus.HistogramVec
+	RainPastHour               *prometheus.GaugeVec
+	Rain                       *prometheus.CounterVec
+	RealTime                   *prometheus.GaugeVec
+	RealTimeFrequency          *prometheus.GaugeVec
+	SolarRadiation             *prometheus.GaugeVec
+	SolarVoltage               *prometheus.GaugeVec
+	SnowDepth                  *prometheus.GaugeVec
+	SpecificHumidity           *prometheus.GaugeVec
+	StormRain                  *prometheus.GaugeVec
+	StormStart                 *prometheus.GaugeVec
+	SubmissionLatency          *prometheus.GaugeVec
+	Submissions                *prometheus.CounterVec
+	Sunshine                   *prometheus.CounterVec
+	SupplyVoltage              *prometheus.GaugeVec
+	Temperature                *prometheus.GaugeVec
+	TemperatureChange          *prometheus.GaugeVec
+	WindDirection              *prometheus.GaugeVec
+	WindGustSpeed              *prometheus.GaugeVec
+	WindRose                   *prometheus.CounterVec
+	WindSpeed                  *prometheus.GaugeVec
+}
+
+func newMetrics(namespace string, reg prometheus.Registerer) *Metrics {
+	labels := []string{"station_id"}
+
+	channelLabels := []string{"station_id", "channel"}
+
+	m := &Metrics{
+		AbsoluteBarometricPressure: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "absolute_barometric_pressure_hpa",
+			Help:      "Absolute (station) barometric pressure in hectopascals, not adjusted to sea level",
+		}, labels),
+		BarometricPressure: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "barometric_pressure_hpa",
+			Help:      "Barometric pressure in hectopascals",
+		}, labels),
+		BatteryVoltage: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "battery_voltage_volts",
+			Help:      "Sensor battery voltage in volts",
+		}, labels),
+		CapacitorVoltage: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "capacitor_voltage_volts",
+			Help:      "Sensor super-capacitor voltage in volts",
+		}, labels),
+		ChannelHumidity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "channel_humidity_percent",
+			Help:      "Humidity percentage (0-1) of each additional sensor channel",
+		}, channelLabels),
+		ChannelTemperature: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "channel_temperature_celsius",
+			Help:      "Temperature in Celsius of each additional sensor channel",
+		}, channelLabels),
+		Condition: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "condition",
+			Help:      "Whether the weather condition applies to the station's latest observation (1) or not (0)",
+		}, []string{"station_id", "condition"}),
+		DewPoint: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "dew_point_celsius",
+			Help:      "Dew point in celsius",
+		}, labels),
+		FieldErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "field_parse_errors_total",
+			Help:      "Total number of submitted fields that could not be parsed and were ignored",
+		}, []string{"station_id", "field"}),
+		FrostPoint: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "frost_point_celsius",
+			Help:      "Frost point in Celsius, derived from the temperature and humidity while below freezing",
+		}, labels),
+		GustFactor: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "wind_gust_factor",
+			Help:      "Ratio of the highest wind gust to the mean wind speed over a rolling 10 minute window",
+		}, labels),
+		HeaterOn: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "heater_on",
+			Help:      "Whether the sensor heater is on",
+		}, labels),
+		Humidity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "humidity_percent",
+			Help:      "Humidity percentage (0-1)",
+		}, labels),
+		HumidityChange: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "humidity_change_percent_per_hour",
+			Help:      "Rate of change of the humidity percentage (0-1) per hour over a rolling window",
+		}, labels),
+		IndoorHumidity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "indoor_humidity_percent",
+			Help:      "Indoor humidity percentage (0-1)",
+		}, labels),
+		IndoorTemperature: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "indoor_temperature_celsius",
+			Help:      "Indoor temperature in Celsius",
+		}, labels),
+		MixingRatio: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "mixing_ratio_grams_per_kilogram",
+			Help:      "Mass of water vapour per mass of dry air in grams per kilogram",
+		}, labels),
+		RainIntensity: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "rain_intensity_mm_per_hour",
+			Help:      "Rain rate over a rolling 10 minute window in millimeters per hour, sampled every minute while raining",
+			Buckets:   rainIntensityBuckets,
+		}, labels),
+		RainPastHour: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "rain_past_hour_mm",
+			Help:      "Amount of rain over the past hour in millimeters",
+		}, labels),
+		Rain: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "rain_mm_total",
+			Help:      "Rain since the start of the station's day in millimeters",
+		}, labels),
+		RealTime: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "realtime",
+			Help:      "Whether the station's last submission was a RapidFire (real-time) submission (1) or not (0)",
+		}, labels),
+		RealTimeFrequency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "realtime_frequency_seconds",
+			Help:      "RapidFire submission frequency reported by the station in seconds",
+		}, labels),
+		SolarRadiation: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "solar_radiation_watts_per_square_meter",
+			Help:      "Solar radiation in watts per square meter",
+		}, labels),
+		SolarVoltage: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "solar_voltage_volts",
+			Help:      "Sensor solar panel voltage in volts",
+		}, labels),
+		SnowDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "snow_depth_mm",
+			Help:      "Snow depth in millimeters",
+		}, labels),
+		SpecificHumidity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "specific_humidity_grams_per_kilogram",
+			Help:      "Mass of water vapour per mass of moist air in grams per kilogram",
+		}, labels),
+		StormRain: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "storm_rain_mm",
+			Help:      "Amount of rain since the start of the current storm in millimeters",
+		}, labels),
+		StormStart: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "storm_start_timestamp_seconds",
+			Help:      "Start time of the current storm in seconds since the Unix epoch",
+		}, labels),
+		SubmissionLatency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "submission_latency_seconds",
+			Help:      "Time between the observation time reported by the station and the time the submission was received",
+		}, labels),
+		Submissions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "submissions_total",
+			Help:      "Total number of submissions received from the station, by type (standard or rapidfire)",
+		}, []string{"station_id", "type"}),
+		Sunshine: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "sunshine_seconds_total",
+			Help:      "Sunshine duration (solar radiation of at least 120 W/m²) since the start of the station's day in seconds",
+		}, labels),
+		SupplyVoltage: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "supply_voltage_volts",
+			Help:      "Console supply voltage in volts",
+		}, labels),
+		Temperature: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "temperature_celsius",
+			Help:      "Temperature in Celsius",
+		}, labels),
+		TemperatureChange: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "temperature_change_celsius_per_hour",
+			Help:      "Rate of change of the temperature in Celsius per hour over a rolling window",
+		}, labels),
+		WindDirection: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "wind_direction_degrees",
+			Help:      "Wind direction in degrees",
+		}, labels),
+		WindGustSpeed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "wind_gust_speed_kph",
+			Help:      "Wind gust speed in KM/h",
+		}, labels),
+		WindRose: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "wind_rose_seconds_total",
+			Help:      "Time the wind blew from each direction sector in each speed range in km/h, in seconds",
+		}, []string{"station_id", "direction", "speed_kph"}),
+		WindSpeed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: stationSubsystem,
+			Name:      "wind_speed_kph",
+			Help:      "Wind speed in KM/h",
+		}, labels),
+	}
+	reg.MustRegister(
+		m.AbsoluteBarometricPressure,
+		m.BarometricPressure,
+		m.BatteryVoltage,
+		m.CapacitorVoltage,
+		m.ChannelHumidity,
+		m.ChannelTemperature,
+		m.Condition,
+		m.DewPoint,
+		m.FieldErrors,
+		m.FrostPoint,
+		m.GustFactor,
+		m.HeaterOn,
+		m.Humidity,
+		m.HumidityChange,
+		m.IndoorHumidity,
+		m.IndoorTemperature,
+		m.MixingRatio,
+		m.RainIntensity,
+		m.RainPastHour,
+		m.Rain,
+		m.RealTime,
+		m.RealTimeFrequency,
+		m.SolarRadiation,
+		m.SolarVoltage,
+		m.SnowDepth,
+		m.SpecificHumidity,
+		m.StormRain,
+		m.StormStart,
+		m.SubmissionLatency,
+		m.Submissions,
+		m.Sunshine,
+		m.SupplyVoltage,
+		m.Temperature,
+		m.TemperatureChange,
+		m.WindDirection,
+		m.WindGustSpeed,
+		m.WindRose,
+		m.WindSpeed,
+	)
+	return m
+}
+
+// deleteStation deletes all series of the given station.
+func (m *Metrics) deleteStation(stationID string) {
+	l := prometheus.Labels{"station_id": stationID}
+	for _, v := range []interface {
+		DeletePartialMatch(labels prometheus.Labels) int
+	}{
+		m.AbsoluteBarometricPressure,
+		m.BarometricPressure,
+		m.BatteryVoltage,
+		m.CapacitorVoltage,
+		m.ChannelHumidity,
+		m.ChannelTemperature,
+		m.Condition,
+		m.DewPoint,
+		m.FieldErrors,
+		m.FrostPoint,
+		m.GustFactor,
+		m.HeaterOn,
+		m.Humidity,
+		m.HumidityChange,
+		m.IndoorHumidity,
+		m.IndoorTemperature,
+		m.MixingRatio,
+		m.RainIntensity,
+		m.RainPastHour,
+		m.Rain,
+		m.RealTime,
+		m.RealTimeFrequency,
+		m.SolarRadiation,
+		m.SolarVoltage,
+		m.SnowDepth,
+		m.SpecificHumidity,
+		m.StormRain,
+		m.StormStart,
+		m.SubmissionLatency,
+		m.Submissions,
+		m.Sunshine,
+		m.SupplyVoltage,
+		m.Temperature,
+		m.TemperatureChange,
+		m.WindDirection,
+		m.WindGustSpeed,
+		m.WindRose,
+		m.WindSpeed,
+	} {
+		v.DeletePartialMatch(l)
+	}
+}
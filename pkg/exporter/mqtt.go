@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joshuasing/pws_exporter/internal/mqtt"
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/mqttjson"
+)
+
+// mqttService returns a service that subscribes to the topic filters on the
+// MQTT broker, and calls handle with each message received. The connection is
+// retried by the supervisor if it fails.
+func mqttService(name string, c config.MQTT, topics []string, handle func(ctx context.Context, msg mqtt.Message)) service {
+	o := mqtt.Options{
+		Broker:   c.Broker,
+		ClientID: c.ClientID,
+		Username: c.Username,
+		Password: c.Password,
+	}
+	// The broker URL is not shown, as it may contain credentials.
+	address, _, err := mqtt.ParseBroker(c.Broker)
+	if err != nil {
+		address = "mqtt"
+	}
+	return service{
+		name:    name,
+		address: address,
+		serve: func(ctx context.Context, _ listenConfig, ready func(addr string)) error {
+			return mqtt.Subscribe(ctx, o, topics, ready, func(msg mqtt.Message) {
+				handle(ctx, msg)
+			})
+		},
+	}
+}
+
+// mqttSubscriptions returns the subscriptions of the MQTT input.
+func mqttSubscriptions(c config.MQTTInput) ([]mqttjson.Subscription, error) {
+	subs := make([]mqttjson.Subscription, 0, len(c.Subscriptions))
+	for _, s := range c.Subscriptions {
+		for name := range s.Fields {
+			if !mqttjson.Field(name) {
+				return nil, fmt.Errorf("mqtt_input subscription %q: unknown field %q", s.Topic, name)
+			}
+		}
+		units := mqttjson.UnitsMetric
+		if s.Units != "" {
+			units = mqttjson.Units(s.Units)
+		}
+		subs = append(subs, mqttjson.Subscription{
+			Topic:             s.Topic,
+			Station:           s.Station,
+			StationField:      s.StationField,
+			StationTopicLevel: s.StationTopicLevel,
+			Units:             units,
+			Fields:            s.Fields,
+		})
+	}
+	return subs, nil
+}
+
+// mqttInputService returns a service that subscribes to the topics of the
+// MQTT input, and submits the mapped fields of messages as observations of
+// their stations.
+func (e *Exporter) mqttInputService(c config.MQTT, subs []mqttjson.Subscription) service {
+	input := mqttjson.NewInput(subs, e.handleWUSubmission)
+	input.SetStationIDFunc(e.normalizeStationID)
+	return mqttService("mqtt", c, input.Topics(), input.HandleMessage)
+}
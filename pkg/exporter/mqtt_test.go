@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/mqttjson"
+)
+
+func TestMQTTSubscriptions(t *testing.T) {
+	subs, err := mqttSubscriptions(config.MQTTInput{
+		Subscriptions: []config.MQTTSubscription{
+			{Topic: "weather/a", Station: "a", Fields: map[string]string{"temperature": "temp"}},
+			{Topic: "weather/b", Station: "b", Units: "imperial", Fields: map[string]string{"rain_today": "rain"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 || subs[0].Units != mqttjson.UnitsMetric || subs[1].Units != mqttjson.UnitsImperial {
+		t.Errorf("got subscriptions %+v", subs)
+	}
+
+	_, err = NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		MQTTInput: config.MQTTInput{
+			MQTT: config.MQTT{Broker: "mqtt://localhost"},
+			Subscriptions: []config.MQTTSubscription{
+				{Topic: "weather", Station: "a", Fields: map[string]string{"temp": "temp"}},
+			},
+		},
+	})
+	if err == nil {
+		t.Error("unknown field should fail")
+	}
+}
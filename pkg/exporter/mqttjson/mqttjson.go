@@ -0,0 +1,375 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package mqttjson implements an input for weather data published to MQTT as
+// JSON, e.g. by home-grown sensor bridges. Fields of the JSON payloads are
+// mapped to measurement fields by configurable subscriptions.
+//
+// The latest values of the fields of each station are merged into a
+// submission translated to the Weather Underground PWS Upload Protocol, so
+// that stations publishing each field to a separate topic are supported, and
+// submissions are processed, journaled and forwarded the same way as WU
+// submissions.
+package mqttjson
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/internal/mqtt"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// tracer records spans for messages. Spans are only recorded if a global
+// OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/exporter/mqttjson")
+
+// staleAfter is the duration after which the last value of a field is no
+// longer included in the submissions of its station.
+const staleAfter = 15 * time.Minute
+
+// WholePayload is the path that selects the whole payload, for topics that
+// are published a single value.
+const WholePayload = "."
+
+// Units are the units that the values of a subscription are published in.
+type Units string
+
+const (
+	// UnitsMetric are the metric units of the fields, e.g. °C, hPa, km/h and
+	// mm.
+	UnitsMetric Units = "metric"
+
+	// UnitsImperial are the imperial units of the fields, e.g. °F, inHg, mph
+	// and in.
+	UnitsImperial Units = "imperial"
+)
+
+// Field returns whether name is a measurement field that can be mapped.
+func Field(name string) bool {
+	_, ok := wu.LookupField(name)
+	return ok
+}
+
+// Subscription maps the JSON payloads of the messages published to a topic
+// to measurement fields.
+type Subscription struct {
+	// Topic is the topic filter subscribed to.
+	Topic string
+
+	// Station is the ID of the station that messages are submitted for.
+	// If empty, the station ID is read from the payload at StationField, or
+	// from level StationTopicLevel (0-based) of the topic.
+	Station           string
+	StationField      string
+	StationTopicLevel *int
+
+	// Units are the units of the published values. Defaults to UnitsMetric.
+	Units Units
+
+	// Fields maps measurement fields to the path of their value in the
+	// payload, which is a dot-separated list of object keys and array
+	// indexes, or WholePayload. Values may be JSON numbers or strings.
+	Fields map[string]string
+}
+
+// stationID returns the station ID of a message.
+func (s Subscription) stationID(topic string, payload any) (string, error) {
+	switch {
+	case s.Station != "":
+		return s.Station, nil
+	case s.StationField != "":
+		v, ok := lookup(payload, s.StationField)
+		if !ok {
+			return "", fmt.Errorf("missing station field %q", s.StationField)
+		}
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		}
+		return "", fmt.Errorf("invalid station field %q", s.StationField)
+	case s.StationTopicLevel != nil:
+		levels := strings.Split(topic, "/")
+		if *s.StationTopicLevel >= len(levels) || levels[*s.StationTopicLevel] == "" {
+			return "", errors.New("missing station topic level")
+		}
+		return levels[*s.StationTopicLevel], nil
+	}
+	return "", errors.New("no station")
+}
+
+// Translate translates the mapped fields of a payload to WU query parameters,
+// and returns the fields that are missing or invalid.
+func (s Subscription) Translate(payload any) (url.Values, []string) {
+	q := make(url.Values, len(s.Fields))
+	var invalid []string
+	for name, path := range s.Fields {
+		nf, ok := wu.LookupField(name)
+		if !ok {
+			continue
+		}
+		f, ok := lookupFloat(payload, path)
+		if !ok {
+			invalid = append(invalid, name)
+			continue
+		}
+		if s.Units != UnitsImperial && nf.FromMetric != nil {
+			f = nf.FromMetric(f)
+		}
+		q.Set(nf.Param, strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return q, invalid
+}
+
+// lookup returns the value at a path in a decoded JSON payload.
+func lookup(v any, path string) (any, bool) {
+	if path == WholePayload {
+		return v, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch c := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = c[key]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, false
+			}
+			v = c[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// lookupFloat returns the number at a path in a decoded JSON payload.
+func lookupFloat(v any, path string) (float64, bool) {
+	v, ok := lookup(v, path)
+	if !ok {
+		return 0, false
+	}
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = strings.TrimSpace(v)
+	default:
+		return 0, false
+	}
+	if wu.IsMissing(s) {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+// decode decodes a JSON payload. Payloads that are not valid JSON are decoded
+// as a string, as bridges often publish single values as plain text.
+func decode(b []byte) any {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil || d.More() {
+		return string(b)
+	}
+	return v
+}
+
+// Input translates MQTT messages to WU submissions.
+type Input struct {
+	subs             []Subscription
+	handleSubmission func(ctx context.Context, s wu.Submission)
+	stationID        func(stationID string) (string, bool)
+
+	mu     sync.Mutex
+	values map[string]map[string]value // By station ID, then WU parameter
+}
+
+// value is the last value of a WU parameter.
+type value struct {
+	time time.Time
+	v    string
+}
+
+// NewInput returns a new input for the given subscriptions. The handler is
+// called synchronously for each message with mapped fields, with the latest
+// values of the fields of its station merged into a WU submission.
+func NewInput(subs []Subscription, handler func(ctx context.Context, s wu.Submission)) *Input {
+	return &Input{
+		subs:             subs,
+		handleSubmission: handler,
+		values:           make(map[string]map[string]value),
+	}
+}
+
+// Topics returns the topic filters of the input's subscriptions.
+func (in *Input) Topics() []string {
+	topics := make([]string, 0, len(in.subs))
+	for _, s := range in.subs {
+		topics = append(topics, s.Topic)
+	}
+	return topics
+}
+
+// SetStationIDFunc sets the function used to normalize the ID of the station
+// that a message is submitted for. If the function returns false, the message
+// is dropped. It must be called before messages are handled.
+func (in *Input) SetStationIDFunc(fn func(stationID string) (string, bool)) {
+	in.stationID = fn
+}
+
+// HandleMessage handles an MQTT message, using the first subscription whose
+// topic filter matches the message's topic.
+func (in *Input) HandleMessage(ctx context.Context, msg mqtt.Message) {
+	si := -1
+	for i, s := range in.subs {
+		if mqtt.Match(s.Topic, msg.Topic) {
+			si = i
+			break
+		}
+	}
+	if si < 0 {
+		return
+	}
+	sub := in.subs[si]
+	payload := decode(msg.Payload)
+
+	stationID, err := sub.stationID(msg.Topic, payload)
+	if err != nil {
+		slog.Warn("Ignored MQTT message without station",
+			slog.String("topic", msg.Topic),
+			slog.String("outcome", "rejected"),
+			slog.Any("err", err))
+		return
+	}
+
+	ctx, span := tracer.Start(ctx, "mqttjson.message",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("station_id", stationID),
+			attribute.String("topic", msg.Topic),
+		))
+	defer span.End()
+
+	if in.stationID != nil {
+		id, ok := in.stationID(stationID)
+		if !ok {
+			slog.Warn("Dropped MQTT message for unknown station",
+				slog.String("station_id", stationID),
+				slog.String("topic", msg.Topic),
+				slog.String("outcome", "rejected"))
+			return
+		}
+		stationID = id
+	}
+
+	fields, invalid := sub.Translate(payload)
+	for _, name := range invalid {
+		slog.Debug("Ignored missing or invalid MQTT message field",
+			slog.String("station_id", stationID),
+			slog.String("topic", msg.Topic),
+			slog.String("field", name))
+	}
+	if len(fields) == 0 {
+		slog.Warn("Ignored MQTT message without fields",
+			slog.String("station_id", stationID),
+			slog.String("topic", msg.Topic),
+			slog.String("outcome", "rejected"))
+		return
+	}
+
+	receivedAt := time.Now()
+	q := in.update(stationID, fields, receivedAt)
+	q.Set("ID", stationID)
+	q.Set("action", "updateraww")
+	q.Set("dateutc", receivedAt.UTC().Format("2006-01-02 15:04:05"))
+	wu.AddDewPoint(q)
+
+	dm, fieldErrs := wu.ParseMeasurement(q, receivedAt)
+	for _, fe := range fieldErrs {
+		span.RecordError(fe)
+		slog.Warn("Ignored invalid MQTT message field",
+			slog.String("station_id", stationID),
+			slog.String("topic", msg.Topic),
+			slog.String("param", fe.Param),
+			slog.String("value", fe.Value),
+			slog.Any("err", fe.Err))
+	}
+
+	slog.Debug("Received MQTT weather data for station",
+		slog.String("station_id", stationID),
+		slog.String("topic", msg.Topic),
+		slog.String("outcome", "accepted"))
+
+	in.handleSubmission(ctx, wu.Submission{
+		StationID:   stationID,
+		ReceivedAt:  receivedAt,
+		RemoteAddr:  "mqtt",
+		RawQuery:    q.Encode(),
+		Measurement: dm,
+		Fields:      wu.SubmittedFields(q, fieldErrs),
+		FieldErrors: fieldErrs,
+	})
+}
+
+// update stores the values of a station received at t, and returns the
+// station's latest values, excluding stale values.
+func (in *Input) update(stationID string, fields url.Values, t time.Time) url.Values {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	values := in.values[stationID]
+	if values == nil {
+		values = make(map[string]value)
+		in.values[stationID] = values
+	}
+	for param := range fields {
+		values[param] = value{time: t, v: fields.Get(param)}
+	}
+	q := make(url.Values, len(values)+3)
+	for param, v := range values {
+		if t.Sub(v.time) > staleAfter {
+			delete(values, param)
+			continue
+		}
+		q.Set(param, v.v)
+	}
+	return q
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mqttjson
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/mqtt"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+func TestTranslate(t *testing.T) {
+	payload := decode([]byte(`{"temp":"15","hum":80,"wind":{"speed":16.09344,"dir":213},"rain":[0,25.4],"name":"backyard"}`))
+	tests := []struct {
+		name        string
+		sub         Subscription
+		want        map[string]float64
+		wantInvalid int
+	}{
+		{
+			name: "metric",
+			sub: Subscription{Fields: map[string]string{
+				"temperature":     "temp",
+				"humidity":        "hum",
+				"wind_speed":      "wind.speed",
+				"wind_direction":  "wind.dir",
+				"rain_today":      "rain.1",
+				"solar_radiation": "solar",
+			}},
+			want: map[string]float64{
+				"tempf":        59,
+				"humidity":     80,
+				"windspeedmph": 10,
+				"winddir":      213,
+				"dailyrainin":  1,
+			},
+			wantInvalid: 1,
+		},
+		{
+			name: "imperial",
+			sub: Subscription{Units: UnitsImperial, Fields: map[string]string{
+				"temperature": "temp",
+				"wind_speed":  "wind.speed",
+				"humidity":    "name",
+			}},
+			want: map[string]float64{
+				"tempf":        15,
+				"windspeedmph": 16.09344,
+			},
+			wantInvalid: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, invalid := tt.sub.Translate(payload)
+			if len(invalid) != tt.wantInvalid {
+				t.Errorf("got invalid fields %v, want %d", invalid, tt.wantInvalid)
+			}
+			if len(q) != len(tt.want) {
+				t.Errorf("got params %v, want %d params", q, len(tt.want))
+			}
+			for param, want := range tt.want {
+				got, err := strconv.ParseFloat(q.Get(param), 64)
+				if err != nil {
+					t.Errorf("%s: %v", param, err)
+					continue
+				}
+				if math.Abs(got-want) > 0.001 {
+					t.Errorf("%s = %v, want %v", param, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStationID(t *testing.T) {
+	level := 1
+	payload := decode([]byte(`{"station":{"id":"backyard"},"num":42}`))
+	tests := []struct {
+		name    string
+		sub     Subscription
+		topic   string
+		want    string
+		wantErr bool
+	}{
+		{name: "fixed", sub: Subscription{Station: "garden"}, topic: "weather", want: "garden"},
+		{name: "field", sub: Subscription{StationField: "station.id"}, topic: "weather", want: "backyard"},
+		{name: "number field", sub: Subscription{StationField: "num"}, topic: "weather", want: "42"},
+		{name: "missing field", sub: Subscription{StationField: "id"}, topic: "weather", wantErr: true},
+		{name: "topic level", sub: Subscription{StationTopicLevel: &level}, topic: "weather/roof/state", want: "roof"},
+		{name: "missing topic level", sub: Subscription{StationTopicLevel: &level}, topic: "weather", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.sub.stationID(tt.topic, payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("stationID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("stationID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInput(t *testing.T) {
+	var got []wu.Submission
+	in := NewInput([]Subscription{
+		{
+			Topic:   "weather/backyard/temperature",
+			Station: "backyard",
+			Fields:  map[string]string{"temperature": WholePayload},
+		},
+		{
+			Topic:   "weather/backyard/humidity",
+			Station: "backyard",
+			Fields:  map[string]string{"humidity": WholePayload},
+		},
+		{
+			Topic:        "bridge/#",
+			StationField: "id",
+			Fields:       map[string]string{"barometric_pressure": "pressure"},
+		},
+	}, func(_ context.Context, s wu.Submission) {
+		got = append(got, s)
+	})
+	in.SetStationIDFunc(func(stationID string) (string, bool) {
+		return stationID, stationID != "unknown"
+	})
+	if topics := in.Topics(); len(topics) != 3 || topics[2] != "bridge/#" {
+		t.Errorf("Topics() = %v", topics)
+	}
+	publish := func(topic, payload string) {
+		in.HandleMessage(context.Background(), mqtt.Message{Topic: topic, Payload: []byte(payload)})
+	}
+
+	publish("other/topic", "15")
+	publish("weather/backyard/temperature", "warm")
+	publish("bridge/1", `{"id":"unknown","pressure":1013.25}`)
+	publish("bridge/1", `{"pressure":1013.25}`)
+	if len(got) != 0 {
+		t.Fatalf("got %d submissions from ignored messages, want 0", len(got))
+	}
+
+	publish("weather/backyard/temperature", "15")
+	publish("weather/backyard/humidity", "80\n")
+	publish("bridge/1", `{"id":"backyard","pressure":1013.25}`)
+	if len(got) != 3 {
+		t.Fatalf("got %d submissions, want 3", len(got))
+	}
+	s := got[2]
+	if s.StationID != "backyard" || s.RemoteAddr != "mqtt" {
+		t.Errorf("got station %q from %q", s.StationID, s.RemoteAddr)
+	}
+	q, err := url.ParseQuery(s.RawQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for param, want := range map[string]string{
+		"ID":       "backyard",
+		"action":   "updateraww",
+		"tempf":    "59",
+		"humidity": "80",
+		"dewptf":   "52.8",
+	} {
+		if q.Get(param) != want {
+			t.Errorf("%s = %q, want %q", param, q.Get(param), want)
+		}
+	}
+	if s.Measurement.Temperature != 15 || math.Abs(s.Measurement.Barometric-1013.25) > 0.01 {
+		t.Errorf("got measurement %+v", s.Measurement)
+	}
+}
+
+func TestStaleValues(t *testing.T) {
+	in := NewInput(nil, nil)
+	now := time.Now()
+	in.update("backyard", url.Values{"tempf": {"59"}}, now)
+
+	q := in.update("backyard", url.Values{"humidity": {"80"}}, now.Add(staleAfter/2))
+	if !q.Has("tempf") {
+		t.Errorf("temperature missing from %v", q)
+	}
+	q = in.update("backyard", url.Values{"humidity": {"80"}}, now.Add(2*staleAfter))
+	if q.Has("tempf") {
+		t.Errorf("stale temperature included in %v", q)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// muxDetectTimeout is the maximum time to wait for a client to send the
+	// first byte of a connection.
+	muxDetectTimeout = 10 * time.Second
+
+	// tlsRecordTypeHandshake is the first byte sent by TLS clients.
+	tlsRecordTypeHandshake = 0x16
+)
+
+// muxListener is a listener that accepts both plaintext and TLS connections
+// on the same port, by peeking at the first byte sent by the client. TLS
+// connections are returned as *tls.Conn.
+type muxListener struct {
+	net.Listener
+	tlsConfig *tls.Config
+
+	conns     chan net.Conn
+	errs      chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newMuxListener(ln net.Listener, tlsConfig *tls.Config) *muxListener {
+	m := &muxListener{
+		Listener:  ln,
+		tlsConfig: tlsConfig,
+		conns:     make(chan net.Conn),
+		errs:      make(chan error),
+		done:      make(chan struct{}),
+	}
+	go m.acceptLoop()
+	return m
+}
+
+// acceptLoop accepts connections from the underlying listener until it is
+// closed. Connections are detected in separate goroutines, so that slow
+// clients do not block other connections from being accepted.
+func (m *muxListener) acceptLoop() {
+	for {
+		c, err := m.Listener.Accept()
+		if err != nil {
+			select {
+			case m.errs <- err:
+			case <-m.done:
+				return
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		go m.detect(c)
+	}
+}
+
+// detect peeks at the first byte of the connection, and passes it to Accept
+// as either a plaintext or TLS connection.
+func (m *muxListener) detect(c net.Conn) {
+	_ = c.SetReadDeadline(time.Now().Add(muxDetectTimeout))
+	br := bufio.NewReader(c)
+	b, err := br.Peek(1)
+	if err != nil {
+		_ = c.Close()
+		return
+	}
+	_ = c.SetReadDeadline(time.Time{})
+
+	var conn net.Conn = &peekedConn{Conn: c, r: br}
+	if b[0] == tlsRecordTypeHandshake {
+		conn = tls.Server(conn, m.tlsConfig)
+	}
+	select {
+	case m.conns <- conn:
+	case <-m.done:
+		_ = conn.Close()
+	}
+}
+
+// Accept waits for and returns the next detected connection.
+func (m *muxListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-m.conns:
+		return c, nil
+	case err := <-m.errs:
+		return nil, err
+	case <-m.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the listener.
+func (m *muxListener) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.done)
+	})
+	return m.Listener.Close()
+}
+
+// peekedConn is a connection whose first bytes have been buffered.
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
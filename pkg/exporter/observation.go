@@ -0,0 +1,244 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// recordObservation records an observation and updates the station's
+// metrics. This is the final step of the processing chain. Observations that
+// are older than the station's latest processed observation are only stored,
+// so that they do not overwrite newer data.
+//
+// Observations from the same station must not be processed concurrently, see
+// pipeline.
+func (e *Exporter) recordObservation(ctx context.Context, o *weather.Observation) error {
+	deviceID, dm := o.StationID, o.Measurement
+
+	e.writeSinks(ctx, *o)
+	latest, ok := e.history.latest(deviceID)
+	if ok && dm.DateUTC.Before(latest.Measurement.DateUTC) {
+		slog.Debug("Received out-of-order observation",
+			slog.String("station_id", deviceID), slog.Time("date", dm.DateUTC),
+			slog.Time("latest_date", latest.Measurement.DateUTC))
+		return nil
+	}
+
+	e.history.add(*o)
+	e.stateDirty.Store(true)
+
+	m := e.metricsFor(deviceID)
+	l := prometheus.Labels{"station_id": deviceID}
+
+	setGauges(m, l, dm)
+	// A missing daily rain total must not be read as a rollover, which would
+	// count the next total again.
+	if dm.Has("dailyrainin") {
+		e.updateRain(m, l, deviceID, dm.RainToday,
+			e.newDay(deviceID, latest.Measurement.DateUTC, dm.DateUTC))
+		e.updateRainIntensity(m, l, deviceID, dm.DateUTC, dm.RainToday)
+	}
+	// Stations without a solar radiation sensor have no sunshine duration.
+	if dm.Has("solarradiation") || dm.SunshineToday != nil {
+		e.updateSunshine(m, l, deviceID, latest.Measurement.DateUTC, dm.DateUTC, dm.SolarRadiation, dm.SunshineToday)
+	}
+	if dm.Has("winddir") && dm.Has("windspeedmph") {
+		updateWindRose(m, l, latest.Measurement.DateUTC, dm.DateUTC, dm.WindDirection, dm.WindSpeed)
+	}
+	e.updateRates(m, l, deviceID, dm.DateUTC)
+	e.updateGustFactor(m, l, deviceID, dm.DateUTC)
+
+	e.hub.publish(*o)
+	if e.alerts != nil || e.annotator != nil {
+		values := fieldValues(*o)
+		if e.alerts != nil {
+			e.alerts.Observe(deviceID, dm.DateUTC, values)
+		}
+		if e.annotator != nil {
+			e.annotator.Observe(deviceID, dm.DateUTC, values)
+		}
+	}
+	return nil
+}
+
+// maxObservationGap is the maximum time between observations that is counted
+// towards durations, such as the sunshine duration, so that time a station
+// was offline is not counted.
+const maxObservationGap = 15 * time.Minute
+
+// observationInterval returns the time between a station's previous
+// observation and an observation taken at t, if it is counted towards
+// durations.
+func observationInterval(prev, t time.Time) (time.Duration, bool) {
+	elapsed := t.Sub(prev)
+	if prev.IsZero() || elapsed <= 0 || elapsed > maxObservationGap {
+		return 0, false
+	}
+	return elapsed, true
+}
+
+// setGauges sets the station's gauges from a measurement. The gauges of
+// fields that are missing from the measurement are deleted.
+func setGauges(m *Metrics, l prometheus.Labels, dm wu.DeviceMeasurement) {
+	setMeasuredGauge(m.BarometricPressure, l, dm, "baromin", dm.Barometric)
+	setMeasuredGauge(m.DewPoint, l, dm, "dewptf", dm.DewPoint)
+	if fp, ok := frostPoint(dm); ok {
+		m.FrostPoint.With(l).Set(fp)
+	} else {
+		m.FrostPoint.Delete(l)
+	}
+	if w, q, ok := moistureContent(dm); ok {
+		m.MixingRatio.With(l).Set(w)
+		m.SpecificHumidity.With(l).Set(q)
+	} else {
+		m.MixingRatio.Delete(l)
+		m.SpecificHumidity.Delete(l)
+	}
+	setMeasuredGauge(m.Humidity, l, dm, "humidity", dm.Humidity/100)
+	setMeasuredGauge(m.IndoorHumidity, l, dm, "indoorhumidity", dm.IndoorHumidity/100)
+	setMeasuredGauge(m.IndoorTemperature, l, dm, "indoortempf", dm.IndoorTemp)
+	setMeasuredGauge(m.RainPastHour, l, dm, "rainin", dm.RainPastHour)
+	setMeasuredGauge(m.SolarRadiation, l, dm, "solarradiation", dm.SolarRadiation)
+	setMeasuredGauge(m.Temperature, l, dm, "tempf", dm.Temperature)
+	setMeasuredGauge(m.WindDirection, l, dm, "winddir", dm.WindDirection)
+	setMeasuredGauge(m.WindGustSpeed, l, dm, "windgustmph", dm.WindGust)
+	setMeasuredGauge(m.WindSpeed, l, dm, "windspeedmph", dm.WindSpeed)
+	for _, c := range conditions {
+		cl := prometheus.Labels{"station_id": l["station_id"], "condition": c.name}
+		if !c.known(dm) {
+			m.Condition.Delete(cl)
+			continue
+		}
+		v := 0.0
+		if c.check(dm) {
+			v = 1
+		}
+		m.Condition.With(cl).Set(v)
+	}
+
+	// Auxiliary health and storm gauges are only exported while the station
+	// submits them.
+	if dm.HeaterOn != nil {
+		v := 0.0
+		if *dm.HeaterOn {
+			v = 1
+		}
+		m.HeaterOn.With(l).Set(v)
+	} else {
+		m.HeaterOn.Delete(l)
+	}
+	setOptionalGauge(m.SupplyVoltage, l, dm.SupplyVoltage)
+	setOptionalGauge(m.BatteryVoltage, l, dm.BatteryVoltage)
+	setOptionalGauge(m.CapacitorVoltage, l, dm.CapacitorVoltage)
+	setOptionalGauge(m.SolarVoltage, l, dm.SolarVoltage)
+	setOptionalGauge(m.StormRain, l, dm.StormRain)
+	if dm.StormStart != nil {
+		m.StormStart.With(l).Set(float64(dm.StormStart.Unix()))
+	} else {
+		m.StormStart.Delete(l)
+	}
+	setOptionalGauge(m.AbsoluteBarometricPressure, l, dm.AbsBarometric)
+	setOptionalGauge(m.SnowDepth, l, dm.SnowDepth)
+	setChannelGauges(m, l["station_id"], dm.Channels)
+}
+
+// setChannelGauges sets the gauges of the additional sensor channels, and
+// deletes the gauges of channels that were not submitted.
+func setChannelGauges(m *Metrics, stationID string, channels []wu.ChannelMeasurement) {
+	for ch := 1; ch <= wu.MaxChannels; ch++ {
+		var c wu.ChannelMeasurement
+		if i := slices.IndexFunc(channels, func(c wu.ChannelMeasurement) bool { return c.Channel == ch }); i >= 0 {
+			c = channels[i]
+		}
+		l := prometheus.Labels{"station_id": stationID, "channel": strconv.Itoa(ch)}
+		setOptionalGauge(m.ChannelTemperature, l, c.Temperature)
+		if c.Humidity != nil {
+			m.ChannelHumidity.With(l).Set(*c.Humidity / 100)
+		} else {
+			m.ChannelHumidity.Delete(l)
+		}
+	}
+}
+
+// setMeasuredGauge sets the gauge to the value of the field of the query
+// parameter, or deletes it if the field is missing from the measurement.
+func setMeasuredGauge(g *prometheus.GaugeVec, l prometheus.Labels, dm wu.DeviceMeasurement, param string, v float64) {
+	if !dm.Has(param) {
+		g.Delete(l)
+		return
+	}
+	g.With(l).Set(v)
+}
+
+// setOptionalGauge sets the gauge to the value, or deletes it if the value is
+// nil.
+func setOptionalGauge(g *prometheus.GaugeVec, l prometheus.Labels, v *float64) {
+	if v == nil {
+		g.Delete(l)
+		return
+	}
+	g.With(l).Set(*v)
+}
+
+// rainState stores the last daily rain total submitted by each station.
+type rainState struct {
+	mu    sync.Mutex
+	today map[string]float64
+}
+
+// updateRain updates the rain counter for a station from the station's daily
+// rain total. The counter is only reset when the station's day has rolled
+// over, so that the counter's created timestamp reflects the start of the
+// accumulation period. A rollover is detected when the daily total decreases,
+// or when newDay is set because the observation is on a new local day in the
+// station's time zone, which catches rollovers where rain fell between the
+// station's last submission of the previous day and its first of the new day.
+func (e *Exporter) updateRain(m *Metrics, l prometheus.Labels, stationID string, rainToday float64, newDay bool) {
+	e.rain.mu.Lock()
+	defer e.rain.mu.Unlock()
+
+	last, ok := e.rain.today[stationID]
+	if !ok || newDay || rainToday < last {
+		// Counter state is stored on the station, not in the exporter.
+		m.Rain.Delete(l)
+		last = 0
+	}
+	m.Rain.With(l).Add(rainToday - last)
+	e.rain.today[stationID] = rainToday
+}
+
+// delete removes the last daily rain total of a station.
+func (r *rainState) delete(stationID string) {
+	r.mu.Lock()
+	delete(r.today, stationID)
+	r.mu.Unlock()
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/opensensemap"
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// openSenseMapTimeout is the maximum time to wait for observations to be
+// uploaded to openSenseMap.
+const openSenseMapTimeout = 10 * time.Second
+
+// openSenseMapQueueSize is the maximum number of observations waiting to be
+// uploaded to openSenseMap.
+const openSenseMapQueueSize = 256
+
+// openSenseMapUpload is an observation waiting to be uploaded to a senseBox.
+type openSenseMapUpload struct {
+	stationID    string
+	box          config.OpenSenseMap
+	measurements []opensensemap.Measurement
+}
+
+// openSenseMapSink is a sink that uploads observations to openSenseMap
+// senseBoxes. Observations are uploaded in the background, in the order they
+// were written.
+type openSenseMapSink struct {
+	client *opensensemap.Client
+	boxes  map[string]config.OpenSenseMap
+	queue  chan openSenseMapUpload
+	done   chan struct{}
+}
+
+// newOpenSenseMapSink returns a sink that uploads the observations of each
+// station to its senseBox. The sink must be closed once it is no longer used.
+func newOpenSenseMapSink(boxes []config.OpenSenseMap) (*openSenseMapSink, error) {
+	s := &openSenseMapSink{
+		client: &opensensemap.Client{},
+		boxes:  make(map[string]config.OpenSenseMap, len(boxes)),
+		queue:  make(chan openSenseMapUpload, openSenseMapQueueSize),
+		done:   make(chan struct{}),
+	}
+	for _, b := range boxes {
+		for name := range b.Sensors {
+			if !slices.ContainsFunc(fields, func(f field) bool { return f.name == name }) {
+				return nil, fmt.Errorf("opensensemap %q: unknown field %q", b.Station, name)
+			}
+		}
+		s.boxes[b.Station] = b
+	}
+	go s.run()
+	return s, nil
+}
+
+// Write queues an observation to be uploaded to the station's senseBox, if
+// configured. An error is returned if the queue is full.
+func (s *openSenseMapSink) Write(_ context.Context, o weather.Observation) error {
+	b, ok := s.boxes[o.StationID]
+	if !ok {
+		return nil
+	}
+
+	measurements := make([]opensensemap.Measurement, 0, len(b.Sensors))
+	for _, f := range fields {
+		sensorID, ok := b.Sensors[f.name]
+		if !ok || !o.Measurement.Has(f.param) {
+			continue
+		}
+		measurements = append(measurements, opensensemap.Measurement{
+			SensorID: sensorID,
+			Value:    f.value(o),
+			Time:     o.Measurement.DateUTC,
+		})
+	}
+	if len(measurements) == 0 {
+		return nil
+	}
+
+	select {
+	case s.queue <- openSenseMapUpload{stationID: o.StationID, box: b, measurements: measurements}:
+		return nil
+	default:
+		return errors.New("opensensemap: upload queue is full")
+	}
+}
+
+// run uploads queued observations until the sink is closed.
+func (s *openSenseMapSink) run() {
+	defer close(s.done)
+	for u := range s.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), openSenseMapTimeout)
+		err := s.client.Post(ctx, u.box.BoxID, u.box.AccessToken, u.measurements)
+		cancel()
+		if err != nil {
+			slog.Error("Failed to upload observation to openSenseMap",
+				slog.String("station_id", u.stationID),
+				slog.String("box_id", u.box.BoxID),
+				slog.Any("err", err))
+		}
+	}
+}
+
+// Close waits for queued observations to be uploaded, until ctx is done.
+func (s *openSenseMapSink) Close(ctx context.Context) error {
+	close(s.queue)
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
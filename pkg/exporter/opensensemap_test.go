@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestOpenSenseMapSink(t *testing.T) {
+	release := make(chan struct{})
+	posted := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		posted <- r.URL.Path
+	}))
+	defer srv.Close()
+
+	s, err := newOpenSenseMapSink([]config.OpenSenseMap{{
+		Station: "a",
+		BoxID:   "box1",
+		Sensors: map[string]string{"temperature": "sensor1"},
+	}})
+	if err != nil {
+		t.Fatalf("new sink: %v", err)
+	}
+	s.client.URL = srv.URL
+
+	// Write must not wait for the upload.
+	o := weather.Observation{StationID: "a", Measurement: wu.DeviceMeasurement{
+		DateUTC:     time.Now(),
+		Temperature: 68,
+	}}
+	done := make(chan error, 1)
+	go func() { done <- s.Write(context.Background(), o) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("write blocked on the upload")
+	}
+
+	// Observations from stations without a senseBox are not uploaded.
+	if err := s.Write(context.Background(), weather.Observation{StationID: "b"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// Close waits for the queued upload.
+	close(release)
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := len(posted); got != 1 {
+		t.Fatalf("got %d uploads, want 1", got)
+	}
+	if got, want := <-posted, "/boxes/box1/data"; got != want {
+		t.Errorf("upload path = %q, want %q", got, want)
+	}
+}
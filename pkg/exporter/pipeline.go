@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// pipeline serializes the processing of observations for each station, so
+// that observations from the same station are never processed concurrently.
+// Observations queued while the station's previous observations were being
+// processed are processed in order of their measurement time.
+type pipeline struct {
+	process func(ctx context.Context, o weather.Observation)
+
+	// ctx is passed to process, and is cancelled if the pipeline could not
+	// be drained in time.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	queues  map[string]*stationQueue
+	pending int
+	wg      sync.WaitGroup
+}
+
+// stationQueue is the queue of observations waiting to be processed for a
+// station.
+type stationQueue struct {
+	pending []pipelineItem
+	running bool
+}
+
+// pipelineItem is a queued observation. done is called once the observation
+// has been processed, and is not called if the observation is discarded, or
+// its processing is cancelled, because the pipeline could not be drained in
+// time.
+type pipelineItem struct {
+	o    weather.Observation
+	done func()
+
+	// span is the span of the request that submitted the observation, used
+	// as the parent of the processing span.
+	span trace.SpanContext
+}
+
+func newPipeline(process func(ctx context.Context, o weather.Observation)) *pipeline {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &pipeline{
+		process: process,
+		ctx:     ctx,
+		cancel:  cancel,
+		queues:  make(map[string]*stationQueue),
+	}
+}
+
+// enqueue queues an observation to be processed. If done is not nil, it is
+// called once the observation has been processed. The span in ctx, if any, is
+// used as the parent span of the observation's processing.
+func (p *pipeline) enqueue(ctx context.Context, o weather.Observation, done func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	q, ok := p.queues[o.StationID]
+	if !ok {
+		q = &stationQueue{}
+		p.queues[o.StationID] = q
+	}
+	q.pending = append(q.pending, pipelineItem{
+		o:    o,
+		done: done,
+		span: trace.SpanContextFromContext(ctx),
+	})
+	p.pending++
+	if !q.running {
+		q.running = true
+		p.wg.Add(1)
+		go p.run(o.StationID, q)
+	}
+}
+
+// run processes queued observations for a station until the queue is empty.
+func (p *pipeline) run(stationID string, q *stationQueue) {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		batch := q.pending
+		q.pending = nil
+		if len(batch) == 0 {
+			q.running = false
+			delete(p.queues, stationID)
+			p.mu.Unlock()
+			return
+		}
+		p.mu.Unlock()
+
+		sort.SliceStable(batch, func(i, j int) bool {
+			return batch[i].o.Measurement.DateUTC.Before(batch[j].o.Measurement.DateUTC)
+		})
+		for _, item := range batch {
+			if p.ctx.Err() == nil {
+				p.process(trace.ContextWithSpanContext(p.ctx, item.span), item.o)
+				// An observation whose processing was cancelled may not
+				// have been stored.
+				if item.done != nil && p.ctx.Err() == nil {
+					item.done()
+				}
+			}
+			p.mu.Lock()
+			p.pending--
+			p.mu.Unlock()
+		}
+	}
+}
+
+// drain waits for all queued observations to be processed. If ctx is done
+// first, in-flight processing is cancelled, the remaining observations are
+// discarded, and an error is returned once the in-flight processing has
+// returned.
+func (p *pipeline) drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	pending := p.pending
+	p.mu.Unlock()
+	p.cancel()
+	<-done
+	return fmt.Errorf("%d observations were not processed: %w", pending, ctx.Err())
+}
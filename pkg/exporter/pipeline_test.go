@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestPipeline(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		processed []int
+		running   int
+	)
+	block := make(chan struct{})
+	start := time.Now()
+	p := newPipeline(func(_ context.Context, o weather.Observation) {
+		mu.Lock()
+		running++
+		if running > 1 {
+			t.Error("observations for the same station processed concurrently")
+		}
+		mu.Unlock()
+
+		if o.Measurement.DateUTC.Equal(start) {
+			// Block the first observation so that the rest are queued.
+			<-block
+		}
+
+		mu.Lock()
+		running--
+		processed = append(processed, int(o.Measurement.DateUTC.Sub(start)/time.Second))
+		mu.Unlock()
+	})
+
+	var done int
+	for _, i := range []int{0, 3, 1, 2} {
+		p.enqueue(context.Background(), weather.Observation{
+			StationID: "test",
+			Measurement: wu.DeviceMeasurement{
+				DateUTC: start.Add(time.Duration(i) * time.Second),
+			},
+		}, func() { done++ })
+	}
+	close(block)
+	if err := p.drain(context.Background()); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+
+	if done != 4 {
+		t.Errorf("done called %d times, want 4", done)
+	}
+	want := []int{0, 1, 2, 3}
+	if len(processed) != len(want) {
+		t.Fatalf("processed %v, want %v", processed, want)
+	}
+	for i := range want {
+		if processed[i] != want[i] {
+			t.Fatalf("processed %v, want %v", processed, want)
+		}
+	}
+}
+
+func TestPipelineDrainTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	var processed, done int
+	p := newPipeline(func(ctx context.Context, _ weather.Observation) {
+		select {
+		case <-ctx.Done():
+		case <-block:
+		}
+		processed++
+	})
+	for range 3 {
+		p.enqueue(context.Background(), weather.Observation{StationID: "test"}, func() { done++ })
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.drain(ctx); err == nil {
+		t.Fatal("drain of blocked pipeline should fail")
+	}
+
+	// The in-flight observation is cancelled before drain returns, and is
+	// not marked as done, and the rest are discarded.
+	if processed != 1 {
+		t.Errorf("processed %d observations, want 1", processed)
+	}
+	if done != 0 {
+		t.Errorf("done called %d times, want 0", done)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// ErrDropObservation may be returned by a Processor to stop processing an
+// observation, without it being treated as an error.
+var ErrDropObservation = errors.New("drop observation")
+
+// Processor is a step in the observation processing chain. Processors may
+// modify the observation, or stop it from being processed further by
+// returning an error.
+//
+// Observations from the same station are never processed concurrently, and
+// are processed in order of their measurement time.
+type Processor interface {
+	Process(ctx context.Context, o *weather.Observation) error
+}
+
+// ProcessorFunc is an adapter to allow the use of ordinary functions as
+// processors.
+type ProcessorFunc func(ctx context.Context, o *weather.Observation) error
+
+// Process calls f(ctx, o).
+func (f ProcessorFunc) Process(ctx context.Context, o *weather.Observation) error {
+	return f(ctx, o)
+}
+
+// Stage is a stage of the observation processing chain. Submissions are
+// authenticated and parsed into observations by the submission API, and then
+// processed by the processors of each stage in order, before the observation
+// is recorded to the metrics and sinks.
+type Stage int
+
+const (
+	// StageValidate is the stage for quality control, e.g. rejecting
+	// implausible observations.
+	StageValidate Stage = iota
+
+	// StageCalibrate is the stage for correcting measured values.
+	StageCalibrate
+
+	// StageDerive is the stage for calculating derived values.
+	StageDerive
+)
+
+// String returns the name of the stage.
+func (s Stage) String() string {
+	switch s {
+	case StageValidate:
+		return "validate"
+	case StageCalibrate:
+		return "calibrate"
+	case StageDerive:
+		return "derive"
+	default:
+		return "stage_" + strconv.Itoa(int(s))
+	}
+}
+
+// Hook is a processor that is called at a stage of the processing chain.
+type Hook struct {
+	Stage     Stage
+	Processor Processor
+}
+
+// processorChain is an ordered list of processors.
+type processorChain []Processor
+
+// newProcessorChain returns a processor chain that calls the hooks ordered by
+// stage, and then the final processor. Hooks of the same stage are called in
+// the order they are given. A span is recorded for each processor call.
+func newProcessorChain(hooks []Hook, final Processor) processorChain {
+	hooks = append([]Hook(nil), hooks...)
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].Stage < hooks[j].Stage
+	})
+	c := make(processorChain, 0, len(hooks)+1)
+	for _, h := range hooks {
+		c = append(c, tracedProcessor{name: h.Stage.String(), p: h.Processor})
+	}
+	return append(c, tracedProcessor{name: "record", p: final})
+}
+
+// Process calls each processor in order, stopping at the first error.
+func (c processorChain) Process(ctx context.Context, o *weather.Observation) error {
+	for _, p := range c {
+		if err := p.Process(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processObservation processes an observation using the processor chain.
+func (e *Exporter) processObservation(ctx context.Context, o weather.Observation) {
+	ctx, span := tracer.Start(ctx, "exporter.process",
+		trace.WithAttributes(attribute.String("station_id", o.StationID)))
+	defer span.End()
+
+	stationID := o.StationID
+	err := e.processors.Process(ctx, &o)
+	e.quality.processed(stationID, time.Now(), err != nil)
+	switch {
+	case errors.Is(err, ErrDropObservation):
+		slog.Debug("Dropped observation", slog.String("station_id", o.StationID))
+	case err != nil:
+		slog.Error("Failed to process observation",
+			slog.String("station_id", o.StationID), slog.Any("err", err))
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
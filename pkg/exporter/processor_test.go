@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestProcessorChain(t *testing.T) {
+	var calls []string
+	step := func(name string, err error) Processor {
+		return ProcessorFunc(func(context.Context, *weather.Observation) error {
+			calls = append(calls, name)
+			return err
+		})
+	}
+
+	c := newProcessorChain([]Hook{
+		{Stage: StageDerive, Processor: step("derive", nil)},
+		{Stage: StageValidate, Processor: step("validate-1", nil)},
+		{Stage: StageCalibrate, Processor: step("calibrate", nil)},
+		{Stage: StageValidate, Processor: step("validate-2", nil)},
+	}, step("record", nil))
+	if err := c.Process(context.Background(), &weather.Observation{}); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	want := []string{"validate-1", "validate-2", "calibrate", "derive", "record"}
+	if len(calls) != len(want) {
+		t.Fatalf("got calls %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("got calls %v, want %v", calls, want)
+		}
+	}
+
+	// Processing stops at the first error.
+	calls = nil
+	c = newProcessorChain([]Hook{
+		{Stage: StageValidate, Processor: step("validate", ErrDropObservation)},
+	}, step("record", nil))
+	err := c.Process(context.Background(), &weather.Observation{})
+	if !errors.Is(err, ErrDropObservation) {
+		t.Errorf("got error %v, want %v", err, ErrDropObservation)
+	}
+	if len(calls) != 1 {
+		t.Errorf("got calls %v, want [validate]", calls)
+	}
+}
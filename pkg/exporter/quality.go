@@ -0,0 +1,287 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// qualityWindow is the rolling window the data quality of a station is
+// computed over.
+const qualityWindow = time.Hour
+
+var (
+	// submissionCompletenessDesc is the description of the submission
+	// completeness metric.
+	submissionCompletenessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("weather", stationSubsystem, "submission_completeness_ratio"),
+		"Fraction of the expected submissions received over the last hour",
+		[]string{"station_id"}, nil,
+	)
+
+	// fieldCompletenessDesc is the description of the field completeness
+	// metric.
+	fieldCompletenessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("weather", stationSubsystem, "field_completeness_ratio"),
+		"Fraction of the station's fields present in submissions over the last hour",
+		[]string{"station_id"}, nil,
+	)
+
+	// qcRejectionDesc is the description of the QC rejection metric.
+	qcRejectionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("weather", stationSubsystem, "qc_rejection_ratio"),
+		"Fraction of observations rejected by the processing chain over the last hour",
+		[]string{"station_id"}, nil,
+	)
+
+	// dataQualityDesc is the description of the data quality score metric.
+	dataQualityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("weather", stationSubsystem, "data_quality_score"),
+		"Data quality score of the station over the last hour, from 0 (no usable data) to 1",
+		[]string{"station_id"}, nil,
+	)
+)
+
+// qualitySubmission is a submission received from a station.
+type qualitySubmission struct {
+	t      time.Time
+	fields []string
+}
+
+// qualityResult is the outcome of processing an observation.
+type qualityResult struct {
+	t        time.Time
+	rejected bool
+}
+
+// stationQuality is the data quality state of a station.
+type stationQuality struct {
+	firstSeen   time.Time
+	submissions []qualitySubmission
+	results     []qualityResult
+}
+
+// prune removes the submissions and results older than the rolling window.
+func (sq *stationQuality) prune(now time.Time) {
+	cutoff := now.Add(-qualityWindow)
+	i := 0
+	for i < len(sq.submissions) && sq.submissions[i].t.Before(cutoff) {
+		i++
+	}
+	sq.submissions = sq.submissions[i:]
+	i = 0
+	for i < len(sq.results) && sq.results[i].t.Before(cutoff) {
+		i++
+	}
+	sq.results = sq.results[i:]
+}
+
+// qualityState stores the data quality state of each station.
+type qualityState struct {
+	mu       sync.Mutex
+	stations map[string]*stationQuality
+}
+
+// station returns the data quality state of a station, creating it if needed.
+func (s *qualityState) station(stationID string, t time.Time) *stationQuality {
+	sq, ok := s.stations[stationID]
+	if !ok {
+		sq = &stationQuality{firstSeen: t}
+		s.stations[stationID] = sq
+	}
+	return sq
+}
+
+// submitted records a submission from a station received at t, with the
+// fields that were submitted.
+func (s *qualityState) submitted(stationID string, t time.Time, fields []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sq := s.station(stationID, t)
+	sq.submissions = append(sq.submissions, qualitySubmission{t: t, fields: fields})
+	sq.prune(t)
+}
+
+// processed records whether an observation from a station processed at t was
+// rejected.
+func (s *qualityState) processed(stationID string, t time.Time, rejected bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sq := s.station(stationID, t)
+	sq.results = append(sq.results, qualityResult{t: t, rejected: rejected})
+	sq.prune(t)
+}
+
+// delete deletes a station's data quality state.
+func (s *qualityState) delete(stationID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.stations, stationID)
+}
+
+// stationIDs returns the IDs of the stations with data quality state.
+func (s *qualityState) stationIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.stations))
+	for id := range s.stations {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// quality is the data quality of a station over the rolling window. Each
+// ratio is only valid if its ok field is set.
+type quality struct {
+	submissions   float64
+	submissionsOK bool
+	fields        float64
+	fieldsOK      bool
+	rejection     float64
+	rejectionOK   bool
+}
+
+// score returns the data quality score, the product of the submission and
+// field completeness and the fraction of observations that were accepted.
+func (q quality) score() (float64, bool) {
+	if !q.submissionsOK && !q.fieldsOK && !q.rejectionOK {
+		return 0, false
+	}
+	score := 1.0
+	if q.submissionsOK {
+		score *= q.submissions
+	}
+	if q.fieldsOK {
+		score *= q.fields
+	}
+	if q.rejectionOK {
+		score *= 1 - q.rejection
+	}
+	return score, true
+}
+
+// quality returns the data quality of a station at now. The submission
+// completeness is only computed if the station has an expected interval, and
+// the expected number of submissions is based on the time since the station
+// was first seen until it has been seen for the full window.
+func (s *qualityState) quality(stationID string, now time.Time, expectedInterval time.Duration) (quality, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sq, ok := s.stations[stationID]
+	if !ok {
+		return quality{}, false
+	}
+	sq.prune(now)
+
+	var q quality
+	if expectedInterval > 0 {
+		window := min(now.Sub(sq.firstSeen), qualityWindow)
+		expected := float64(window / expectedInterval)
+		if expected >= 1 {
+			q.submissions = min(float64(len(sq.submissions))/expected, 1)
+			q.submissionsOK = true
+		}
+	}
+
+	// Fields are expected if the station submitted them at least once
+	// within the window, as the fields vary with the station's sensors.
+	seen := make(map[string]struct{})
+	for _, sub := range sq.submissions {
+		for _, f := range sub.fields {
+			seen[f] = struct{}{}
+		}
+	}
+	if len(seen) > 0 {
+		var present int
+		for _, sub := range sq.submissions {
+			present += len(sub.fields)
+		}
+		q.fields = float64(present) / float64(len(sq.submissions)*len(seen))
+		q.fieldsOK = true
+	}
+
+	if len(sq.results) > 0 {
+		var rejected int
+		for _, r := range sq.results {
+			if r.rejected {
+				rejected++
+			}
+		}
+		q.rejection = float64(rejected) / float64(len(sq.results))
+		q.rejectionOK = true
+	}
+	return q, true
+}
+
+// qualityCollector is a prometheus.Collector that exports the data quality of
+// each station over the rolling window. Only the stations exported on the
+// given metrics are collected, so that each registry only exports its own
+// stations.
+type qualityCollector struct {
+	e       *Exporter
+	metrics *Metrics
+}
+
+// Describe implements prometheus.Collector.
+func (c *qualityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- submissionCompletenessDesc
+	ch <- fieldCompletenessDesc
+	ch <- qcRejectionDesc
+	ch <- dataQualityDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *qualityCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	for _, stationID := range c.e.quality.stationIDs() {
+		if c.e.metricsFor(stationID) != c.metrics {
+			continue
+		}
+		interval := c.e.stationConfig(stationID).expectedInterval
+		q, ok := c.e.quality.quality(stationID, now, interval)
+		if !ok {
+			continue
+		}
+		if q.submissionsOK {
+			ch <- prometheus.MustNewConstMetric(submissionCompletenessDesc,
+				prometheus.GaugeValue, q.submissions, stationID)
+		}
+		if q.fieldsOK {
+			ch <- prometheus.MustNewConstMetric(fieldCompletenessDesc,
+				prometheus.GaugeValue, q.fields, stationID)
+		}
+		if q.rejectionOK {
+			ch <- prometheus.MustNewConstMetric(qcRejectionDesc,
+				prometheus.GaugeValue, q.rejection, stationID)
+		}
+		if score, ok := q.score(); ok {
+			ch <- prometheus.MustNewConstMetric(dataQualityDesc,
+				prometheus.GaugeValue, score, stationID)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestQuality(t *testing.T) {
+	s := qualityState{stations: make(map[string]*stationQuality)}
+	start := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	full := []string{"tempf", "humidity", "windspeedmph", "rainin"}
+
+	// A station expected every minute submits every other minute for an
+	// hour, with a missing field in half of the submissions, and one in
+	// six observations rejected.
+	for i := range 30 {
+		at := start.Add(time.Duration(2*i) * time.Minute)
+		fields := full
+		if i%2 == 1 {
+			fields = full[:3]
+		}
+		s.submitted("KTEST1", at, fields)
+		s.processed("KTEST1", at, i%6 == 0)
+	}
+
+	now := start.Add(qualityWindow)
+	q, ok := s.quality("KTEST1", now, time.Minute)
+	if !ok {
+		t.Fatal("quality should be ok")
+	}
+	if !q.submissionsOK || math.Abs(q.submissions-0.5) > 0.001 {
+		t.Errorf("got submission completeness %v (ok %v), want 0.5", q.submissions, q.submissionsOK)
+	}
+	if !q.fieldsOK || math.Abs(q.fields-0.875) > 0.001 {
+		t.Errorf("got field completeness %v (ok %v), want 0.875", q.fields, q.fieldsOK)
+	}
+	if !q.rejectionOK || math.Abs(q.rejection-1.0/6) > 0.001 {
+		t.Errorf("got rejection ratio %v (ok %v), want 0.167", q.rejection, q.rejectionOK)
+	}
+	if score, ok := q.score(); !ok || math.Abs(score-0.5*0.875*5/6) > 0.001 {
+		t.Errorf("got score %v (ok %v), want 0.365", score, ok)
+	}
+
+	// Without an expected interval, submission completeness is unknown.
+	if q, _ := s.quality("KTEST1", now, 0); q.submissionsOK {
+		t.Error("submission completeness without expected interval should not be ok")
+	}
+
+	// A station that has only just been seen is not penalised for the
+	// submissions before it was first seen.
+	s.submitted("KTEST2", start, full)
+	s.submitted("KTEST2", start.Add(time.Minute), full)
+	if q, _ := s.quality("KTEST2", start.Add(2*time.Minute), time.Minute); !q.submissionsOK || q.submissions != 1 {
+		t.Errorf("got submission completeness %v (ok %v), want 1", q.submissions, q.submissionsOK)
+	}
+
+	// Submissions older than the window are pruned.
+	q, _ = s.quality("KTEST2", start.Add(2*qualityWindow), time.Minute)
+	if q.submissions != 0 || q.fieldsOK {
+		t.Errorf("got %+v, want no submissions in window", q)
+	}
+
+	s.delete("KTEST1")
+	if _, ok := s.quality("KTEST1", now, time.Minute); ok {
+		t.Error("deleted station should not have quality")
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/store"
+)
+
+const (
+	// defaultQueryRange is the default time range of a query.
+	defaultQueryRange = 24 * time.Hour
+
+	// defaultQueryStep is the default interval of aggregated query points.
+	defaultQueryStep = 5 * time.Minute
+
+	// maxQueryPoints is the maximum number of points a query may return.
+	maxQueryPoints = 11000
+)
+
+// queryResponse is the response to a time range query.
+type queryResponse struct {
+	StationID string        `json:"station_id"`
+	Metric    string        `json:"metric"`
+	From      time.Time     `json:"from"`
+	To        time.Time     `json:"to"`
+	Step      string        `json:"step"`
+	Points    []store.Point `json:"points"`
+}
+
+// handleQuery serves aggregated values of a metric over a time range from the
+// observation store.
+//
+// Query parameters:
+//   - station: the station ID (required)
+//   - metric: the observation field, e.g. "temperature" (required)
+//   - from, to: the time range (RFC 3339, default the past 24 hours)
+//   - step: the aggregation interval (Go duration, default 5m, at least
+//     1ms). Steps of whole days are aligned to midnight in the station's
+//     time zone.
+func (e *Exporter) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if e.store == nil {
+		http.Error(w, "observation store is not enabled", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	stationID, metric := q.Get("station"), q.Get("metric")
+	if stationID == "" || metric == "" {
+		http.Error(w, "station and metric parameters are required", http.StatusBadRequest)
+		return
+	}
+	if !e.authorized(r, stationID) {
+		http.NotFound(w, r)
+		return
+	}
+	if !slices.Contains(store.Fields(), metric) {
+		http.Error(w, "unknown metric", http.StatusBadRequest)
+		return
+	}
+
+	to, err := store.ParseTime(q.Get("to"))
+	if err != nil {
+		http.Error(w, "invalid to parameter", http.StatusBadRequest)
+		return
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from, err := store.ParseTime(q.Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from parameter", http.StatusBadRequest)
+		return
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultQueryRange)
+	}
+	if !from.Before(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	step := defaultQueryStep
+	if s := q.Get("step"); s != "" {
+		step, err = time.ParseDuration(s)
+		// The store aggregates into intervals of whole milliseconds.
+		if err != nil || step < time.Millisecond {
+			http.Error(w, "invalid step parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	if to.Sub(from)/step > maxQueryPoints {
+		http.Error(w, "too many points, increase step or reduce the time range",
+			http.StatusBadRequest)
+		return
+	}
+
+	points, err := e.store.Aggregate(r.Context(), stationID, metric, from, to, step,
+		e.stationConfig(stationID).location)
+	if err != nil {
+		slog.Error("Failed to query observations", slog.Any("err", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+	if points == nil {
+		points = []store.Point{}
+	}
+	writeJSON(w, http.StatusOK, queryResponse{
+		StationID: stationID,
+		Metric:    metric,
+		From:      from,
+		To:        to,
+		Step:      step.String(),
+		Points:    points,
+	})
+}
+
+// gapsResponse is the response to a gaps query.
+type gapsResponse struct {
+	StationID string      `json:"station_id"`
+	From      time.Time   `json:"from"`
+	To        time.Time   `json:"to"`
+	Interval  string      `json:"interval"`
+	Gaps      []store.Gap `json:"gaps"`
+}
+
+// handleGaps serves the gaps in a station's stored observations.
+//
+// Query parameters:
+//   - station: the station ID (required)
+//   - from, to: the time range (RFC 3339, default the past 24 hours)
+//   - interval: the maximum interval between observations (Go duration,
+//     default twice the station's expected interval)
+func (e *Exporter) handleGaps(w http.ResponseWriter, r *http.Request) {
+	if e.store == nil {
+		http.Error(w, "observation store is not enabled", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	stationID := q.Get("station")
+	if stationID == "" {
+		http.Error(w, "station parameter is required", http.StatusBadRequest)
+		return
+	}
+	if !e.authorized(r, stationID) {
+		http.NotFound(w, r)
+		return
+	}
+
+	to, err := store.ParseTime(q.Get("to"))
+	if err != nil {
+		http.Error(w, "invalid to parameter", http.StatusBadRequest)
+		return
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from, err := store.ParseTime(q.Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from parameter", http.StatusBadRequest)
+		return
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultQueryRange)
+	}
+	if !from.Before(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	interval := e.staleAfter(stationID)
+	if s := q.Get("interval"); s != "" {
+		interval, err = time.ParseDuration(s)
+		if err != nil || interval <= 0 {
+			http.Error(w, "invalid interval parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	if interval <= 0 {
+		http.Error(w, "station has no expected interval, interval parameter is required",
+			http.StatusBadRequest)
+		return
+	}
+
+	gaps, err := e.store.Gaps(r.Context(), stationID, from, to, interval)
+	if err != nil {
+		slog.Error("Failed to query observation gaps", slog.Any("err", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+	if gaps == nil {
+		gaps = []store.Gap{}
+	}
+	writeJSON(w, http.StatusOK, gapsResponse{
+		StationID: stationID,
+		From:      from,
+		To:        to,
+		Interval:  interval.String(),
+		Gaps:      gaps,
+	})
+}
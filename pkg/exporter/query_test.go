@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleQueryStep(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		StorePath:  filepath.Join(t.TempDir(), "pws.db"),
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	tts := []struct {
+		step   string
+		status int
+	}{
+		{step: "5m", status: http.StatusOK},
+		{step: "1ms", status: http.StatusOK},
+		{step: "999us", status: http.StatusBadRequest},
+		{step: "0s", status: http.StatusBadRequest},
+		{step: "-5m", status: http.StatusBadRequest},
+		{step: "five", status: http.StatusBadRequest},
+	}
+	for _, tt := range tts {
+		t.Run(tt.step, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet,
+				"/api/v1/query?station=KTEST1&metric=temperature&from=2025-01-23T00:00:00Z&to=2025-01-23T00:00:01Z&step="+tt.step, nil)
+			rec := httptest.NewRecorder()
+			e.handleQuery(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// rapidFireBuffer downsamples RapidFire observations, which may be submitted
+// every few seconds, by aggregating each station's observations over a window
+// into a single observation before it is processed.
+type rapidFireBuffer struct {
+	enqueue func(ctx context.Context, o weather.Observation, done func())
+
+	mu      sync.Mutex
+	windows map[string]*rapidFireWindow
+}
+
+// rapidFireWindow is the RapidFire observations of a station buffered in the
+// current window. done are the done functions of the buffered observations,
+// which are called once the aggregated observation has been processed.
+type rapidFireWindow struct {
+	obs   []weather.Observation
+	done  []func()
+	span  trace.SpanContext
+	timer *time.Timer
+}
+
+func newRapidFireBuffer(enqueue func(ctx context.Context, o weather.Observation, done func())) *rapidFireBuffer {
+	return &rapidFireBuffer{
+		enqueue: enqueue,
+		windows: make(map[string]*rapidFireWindow),
+	}
+}
+
+// add buffers a RapidFire observation. The station's window starts with its
+// first buffered observation, and the aggregated observation is enqueued
+// once the window has passed. If done is not nil, it is called once the
+// aggregated observation has been processed.
+func (b *rapidFireBuffer) add(ctx context.Context, o weather.Observation, window time.Duration, done func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	w, ok := b.windows[o.StationID]
+	if !ok {
+		w = &rapidFireWindow{}
+		w.timer = time.AfterFunc(window, func() {
+			b.flushWindow(o.StationID, w)
+		})
+		b.windows[o.StationID] = w
+	}
+	w.obs = append(w.obs, o)
+	if done != nil {
+		w.done = append(w.done, done)
+	}
+	w.span = trace.SpanContextFromContext(ctx)
+}
+
+// flush enqueues the aggregated observation of a station's current window
+// immediately, e.g. before a standard observation from the station is
+// enqueued, so that observations are processed in order.
+func (b *rapidFireBuffer) flush(stationID string) {
+	b.mu.Lock()
+	w, ok := b.windows[stationID]
+	b.mu.Unlock()
+	if ok {
+		b.flushWindow(stationID, w)
+	}
+}
+
+// flushAll enqueues the aggregated observations of all current windows.
+func (b *rapidFireBuffer) flushAll() {
+	b.mu.Lock()
+	windows := make(map[string]*rapidFireWindow, len(b.windows))
+	for id, w := range b.windows {
+		windows[id] = w
+	}
+	b.mu.Unlock()
+	for id, w := range windows {
+		b.flushWindow(id, w)
+	}
+}
+
+// discard discards a station's current window without processing it.
+func (b *rapidFireBuffer) discard(stationID string) {
+	b.mu.Lock()
+	w, ok := b.windows[stationID]
+	if ok {
+		w.timer.Stop()
+		delete(b.windows, stationID)
+	}
+	b.mu.Unlock()
+	if ok {
+		for _, done := range w.done {
+			done()
+		}
+	}
+}
+
+// flushWindow enqueues the aggregated observation of a station's window, if
+// it is still the station's current window.
+func (b *rapidFireBuffer) flushWindow(stationID string, w *rapidFireWindow) {
+	b.mu.Lock()
+	if b.windows[stationID] != w {
+		b.mu.Unlock()
+		return
+	}
+	w.timer.Stop()
+	delete(b.windows, stationID)
+	b.mu.Unlock()
+
+	ctx := trace.ContextWithSpanContext(context.Background(), w.span)
+	b.enqueue(ctx, aggregateRapidFire(w.obs), func() {
+		for _, done := range w.done {
+			done()
+		}
+	})
+}
+
+// aggregateRapidFire aggregates RapidFire observations into a single
+// observation. The wind speed is averaged, the wind direction is the mean of
+// the wind vectors, and the wind gust is the highest gust. All other fields
+// are taken from the latest observation.
+func aggregateRapidFire(obs []weather.Observation) weather.Observation {
+	latest := obs[0]
+	var speed, gust, x, y float64
+	for _, o := range obs {
+		if !o.Measurement.DateUTC.Before(latest.Measurement.DateUTC) {
+			latest = o
+		}
+		dm := o.Measurement
+		speed += dm.WindSpeed
+		gust = max(gust, dm.WindGust)
+		rad := dm.WindDirection * math.Pi / 180
+		x += dm.WindSpeed * math.Sin(rad)
+		y += dm.WindSpeed * math.Cos(rad)
+	}
+
+	o := latest
+	o.Measurement.WindSpeed = speed / float64(len(obs))
+	o.Measurement.WindGust = gust
+	// The direction of calm wind is meaningless, so the latest direction is
+	// kept if the wind vectors cancel out.
+	if x != 0 || y != 0 {
+		dir := math.Atan2(x, y) * 180 / math.Pi
+		if dir < 0 {
+			dir += 360
+		}
+		o.Measurement.WindDirection = dir
+	}
+	return o
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestAggregateRapidFire(t *testing.T) {
+	start := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	obs := []weather.Observation{
+		{StationID: "KTEST1", Measurement: wu.DeviceMeasurement{
+			DateUTC: start, WindSpeed: 10, WindGust: 14, WindDirection: 350, Temperature: 18,
+		}},
+		{StationID: "KTEST1", Measurement: wu.DeviceMeasurement{
+			DateUTC: start.Add(5 * time.Second), WindSpeed: 10, WindGust: 21, WindDirection: 10, Temperature: 18.5,
+		}},
+		{StationID: "KTEST1", Measurement: wu.DeviceMeasurement{
+			DateUTC: start.Add(2 * time.Second), WindSpeed: 4, WindGust: 9, WindDirection: 0, Temperature: 18.2,
+		}},
+	}
+
+	o := aggregateRapidFire(obs)
+	dm := o.Measurement
+	if !dm.DateUTC.Equal(start.Add(5*time.Second)) || dm.Temperature != 18.5 {
+		t.Errorf("got %v at %v, want the latest observation", dm.Temperature, dm.DateUTC)
+	}
+	if dm.WindSpeed != 8 {
+		t.Errorf("got wind speed %v, want 8", dm.WindSpeed)
+	}
+	if dm.WindGust != 21 {
+		t.Errorf("got wind gust %v, want 21", dm.WindGust)
+	}
+	// The mean of 350° and 10° is north, not 180°.
+	if d := math.Min(dm.WindDirection, 360-dm.WindDirection); d > 0.001 {
+		t.Errorf("got wind direction %v, want 0", dm.WindDirection)
+	}
+}
+
+func TestRapidFireBuffer(t *testing.T) {
+	var mu sync.Mutex
+	var enqueued []weather.Observation
+	b := newRapidFireBuffer(func(_ context.Context, o weather.Observation, done func()) {
+		mu.Lock()
+		enqueued = append(enqueued, o)
+		mu.Unlock()
+		done()
+	})
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(enqueued)
+	}
+
+	var acked sync.WaitGroup
+	start := time.Now()
+	acked.Add(3)
+	for i := range 3 {
+		b.add(context.Background(), weather.Observation{
+			StationID:   "KTEST1",
+			Measurement: wu.DeviceMeasurement{DateUTC: start.Add(time.Duration(i) * time.Second), WindSpeed: float64(i)},
+		}, 50*time.Millisecond, acked.Done)
+	}
+	if n := count(); n != 0 {
+		t.Fatalf("got %d observations before the window passed, want 0", n)
+	}
+
+	// All buffered observations are acknowledged once the aggregated
+	// observation has been processed.
+	acked.Wait()
+	if n := count(); n != 1 {
+		t.Fatalf("got %d observations after the window passed, want 1", n)
+	}
+	if ws := enqueued[0].Measurement.WindSpeed; ws != 1 {
+		t.Errorf("got wind speed %v, want 1", ws)
+	}
+
+	// Flushing enqueues the current window immediately.
+	b.add(context.Background(), weather.Observation{StationID: "KTEST1"}, time.Hour, nil)
+	b.flush("KTEST1")
+	b.flush("KTEST1")
+	if n := count(); n != 2 {
+		t.Errorf("got %d observations after flushing, want 2", n)
+	}
+
+	// Discarded windows are not processed, but are acknowledged.
+	var discarded bool
+	b.add(context.Background(), weather.Observation{StationID: "KTEST1"}, time.Hour, func() { discarded = true })
+	b.discard("KTEST1")
+	b.flushAll()
+	if n := count(); n != 2 || !discarded {
+		t.Errorf("got %d observations (acknowledged %v) after discarding, want 2", n, discarded)
+	}
+}
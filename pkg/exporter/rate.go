@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// defaultRateWindow is the default rolling window that rates of change are
+// computed over.
+const defaultRateWindow = 30 * time.Minute
+
+// updateRates updates the rate of change gauges of a station from the
+// observations in memory that were taken within the rate window of the
+// latest observation, skipping observations that did not measure the field.
+// Rates are deleted while there are not enough observations in the window.
+func (e *Exporter) updateRates(m *Metrics, l prometheus.Labels, stationID string, latest time.Time) {
+	var window []weather.Observation
+	for _, o := range e.history.since(stationID, time.Time{}) {
+		if !o.Measurement.DateUTC.Before(latest.Add(-e.rateWindow)) && !o.Measurement.DateUTC.After(latest) {
+			window = append(window, o)
+		}
+	}
+
+	for _, r := range []struct {
+		gauge *prometheus.GaugeVec
+		param string
+		value func(weather.Observation) float64
+	}{
+		{m.TemperatureChange, "tempf", func(o weather.Observation) float64 { return o.Measurement.Temperature }},
+		{m.HumidityChange, "humidity", func(o weather.Observation) float64 { return o.Measurement.Humidity / 100 }},
+	} {
+		measured := slices.DeleteFunc(slices.Clone(window), func(o weather.Observation) bool {
+			return !o.Measurement.Has(r.param)
+		})
+		if rate, ok := ratePerHour(measured, r.value); ok {
+			r.gauge.With(l).Set(rate)
+		} else {
+			r.gauge.Delete(l)
+		}
+	}
+}
+
+// ratePerHour returns the rate of change per hour of a value over the
+// observations, using the least squares slope of the value over time, which
+// is much less sensitive to noise than the difference between two values.
+// ok is false if there are fewer than two observations or they were all
+// taken at the same time.
+func ratePerHour(obs []weather.Observation, value func(weather.Observation) float64) (rate float64, ok bool) {
+	if len(obs) < 2 {
+		return 0, false
+	}
+
+	// Times are relative to the first observation to avoid losing precision.
+	t0 := obs[0].Measurement.DateUTC
+	var sumT, sumV float64
+	for _, o := range obs {
+		sumT += o.Measurement.DateUTC.Sub(t0).Hours()
+		sumV += value(o)
+	}
+	n := float64(len(obs))
+	meanT, meanV := sumT/n, sumV/n
+
+	var cov, variance float64
+	for _, o := range obs {
+		dt := o.Measurement.DateUTC.Sub(t0).Hours() - meanT
+		cov += dt * (value(o) - meanV)
+		variance += dt * dt
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	return cov / variance, true
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestRatePerHour(t *testing.T) {
+	temperature := func(o weather.Observation) float64 { return o.Measurement.Temperature }
+	start := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	observations := func(temps ...float64) []weather.Observation {
+		obs := make([]weather.Observation, 0, len(temps))
+		for i, temp := range temps {
+			obs = append(obs, weather.Observation{Measurement: wu.DeviceMeasurement{
+				DateUTC:     start.Add(time.Duration(i) * 15 * time.Minute),
+				Temperature: temp,
+			}})
+		}
+		return obs
+	}
+
+	// Falling 2 °C per hour, with noise that averages out.
+	rate, ok := ratePerHour(observations(10, 9.6, 8.9, 8.6, 8.1), temperature)
+	if !ok || math.Abs(rate-(-2)) > 0.1 {
+		t.Errorf("got rate %v (ok %v), want about -2", rate, ok)
+	}
+
+	if _, ok := ratePerHour(observations(10), temperature); ok {
+		t.Error("rate of a single observation should not be ok")
+	}
+	same := observations(10, 11)
+	same[1].Measurement.DateUTC = same[0].Measurement.DateUTC
+	if _, ok := ratePerHour(same, temperature); ok {
+		t.Error("rate of observations taken at the same time should not be ok")
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+// relabelGatherer is a prometheus.Gatherer that renames or drops metric
+// families and rewrites station_id label values at collection time.
+type relabelGatherer struct {
+	gatherer   prometheus.Gatherer
+	rename     map[string]string
+	drop       map[string]struct{}
+	stationIDs map[string]string
+}
+
+// newRelabelGatherer returns a gatherer that applies the relabel
+// configuration to the given gatherer. If the configuration is empty, the
+// gatherer is returned unchanged.
+func newRelabelGatherer(g prometheus.Gatherer, c config.Relabel) prometheus.Gatherer {
+	if len(c.Rename) == 0 && len(c.Drop) == 0 && len(c.StationIDs) == 0 {
+		return g
+	}
+	drop := make(map[string]struct{}, len(c.Drop))
+	for _, name := range c.Drop {
+		drop[name] = struct{}{}
+	}
+	return &relabelGatherer{
+		gatherer:   g,
+		rename:     c.Rename,
+		drop:       drop,
+		stationIDs: c.StationIDs,
+	}
+}
+
+// checkRelabel checks that the relabel configuration does not rename metrics
+// to the name of another exported metric, which would fail every scrape.
+func checkRelabel(c config.Relabel, names map[string]struct{}) error {
+	for _, from := range slices.Sorted(maps.Keys(c.Rename)) {
+		to := c.Rename[from]
+		if _, ok := names[to]; !ok {
+			continue
+		}
+		_, renamed := c.Rename[to]
+		if !renamed && !slices.Contains(c.Drop, to) {
+			return fmt.Errorf("rename %q: metric %q is already exported", from, to)
+		}
+	}
+	return nil
+}
+
+// describingRegisterer is a prometheus.Registerer that records the metrics
+// described by the collectors registered with it.
+type describingRegisterer struct {
+	prometheus.Registerer
+
+	mu    sync.Mutex
+	descs []*prometheus.Desc
+}
+
+func newDescribingRegisterer(reg prometheus.Registerer) *describingRegisterer {
+	return &describingRegisterer{Registerer: reg}
+}
+
+// Register implements prometheus.Registerer.
+func (r *describingRegisterer) Register(c prometheus.Collector) error {
+	if err := r.Registerer.Register(c); err != nil {
+		return err
+	}
+	r.describe(c)
+	return nil
+}
+
+// MustRegister implements prometheus.Registerer.
+func (r *describingRegisterer) MustRegister(cs ...prometheus.Collector) {
+	r.Registerer.MustRegister(cs...)
+	for _, c := range cs {
+		r.describe(c)
+	}
+}
+
+// describe records the metrics described by the collector.
+func (r *describingRegisterer) describe(c prometheus.Collector) {
+	ch := make(chan *prometheus.Desc)
+	go func() {
+		c.Describe(ch)
+		close(ch)
+	}()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for d := range ch {
+		r.descs = append(r.descs, d)
+	}
+}
+
+// metricNames returns the names of the metrics described by the registered
+// collectors, including metrics that have not been collected yet.
+//
+// prometheus.Desc does not export the name of a metric, so a placeholder
+// metric is gathered for each description from a separate registry, and the
+// names are read from the gathered metric families.
+func (r *describingRegisterer) metricNames() map[string]struct{} {
+	r.mu.Lock()
+	descs := slices.Clone(r.descs)
+	r.mu.Unlock()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(descCollector(descs))
+	// Metrics that share a name with an earlier metric are reported as
+	// errors, but the name is still gathered.
+	mfs, _ := reg.Gather()
+	names := make(map[string]struct{}, len(mfs))
+	for _, mf := range mfs {
+		names[mf.GetName()] = struct{}{}
+	}
+	return names
+}
+
+// descCollector is an unchecked prometheus.Collector that collects a
+// descMetric for each description.
+type descCollector []*prometheus.Desc
+
+// Describe implements prometheus.Collector.
+func (c descCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c descCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, d := range c {
+		ch <- descMetric{desc: d}
+	}
+}
+
+// descMetric is a placeholder untyped metric for a description, without
+// labels.
+type descMetric struct {
+	desc *prometheus.Desc
+}
+
+// Desc implements prometheus.Metric.
+func (m descMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+// Write implements prometheus.Metric.
+func (m descMetric) Write(out *dto.Metric) error {
+	var v float64
+	out.Untyped = &dto.Untyped{Value: &v}
+	return nil
+}
+
+// Gather implements prometheus.Gatherer.
+func (r *relabelGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := r.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(mfs))
+	out := mfs[:0]
+	for _, mf := range mfs {
+		if _, ok := r.drop[mf.GetName()]; ok {
+			continue
+		}
+		if name, ok := r.rename[mf.GetName()]; ok {
+			mf.Name = &name
+		}
+		if _, ok := names[mf.GetName()]; ok {
+			return nil, fmt.Errorf("relabel: duplicate metric family %q", mf.GetName())
+		}
+		names[mf.GetName()] = struct{}{}
+
+		for _, m := range mf.Metric {
+			for i, lp := range m.GetLabel() {
+				if lp.GetName() != "station_id" {
+					continue
+				}
+				// Label pairs are shared with the collected metric, so
+				// they are copied rather than modified.
+				if v, ok := r.stationIDs[lp.GetValue()]; ok {
+					m.Label = slices.Clone(m.Label)
+					m.Label[i] = &dto.LabelPair{Name: lp.Name, Value: &v}
+				}
+			}
+		}
+		out = append(out, mf)
+	}
+	return out, nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+// Reload reads the configuration file again and applies the tenant, station,
+// site, station ID normalization, DNS, relabel and admin configuration without
+// restarting the exporter. Running servers keep their connections, and the
+// records of the DNS servers are replaced in place. If the configuration is
+// invalid, an error is returned and none of it is applied.
+func (e *Exporter) Reload() error {
+	if e.configPath == "" {
+		return errors.New("no configuration file")
+	}
+	c, err := config.Load(e.configPath)
+	if err != nil {
+		return err
+	}
+	if err := checkRelabel(c.Relabel, e.metricNames); err != nil {
+		return fmt.Errorf("relabel: %w", err)
+	}
+
+	stations, err := newStationConfigs(c.Stations)
+	if err != nil {
+		return err
+	}
+	stationIDs, err := newStationIDNormalizer(c.NormalizeStationIDs)
+	if err != nil {
+		return err
+	}
+
+	e.setTenants(c.Tenants)
+	e.setSites(c.Sites)
+	e.dnsServers.set(c.DNS)
+	e.cfgMu.Lock()
+	e.stations = stations
+	e.stationIDs = stationIDs
+	e.relabel = c.Relabel
+	e.admin = c.Admin
+	e.ingest = c.Ingest
+	e.config.Tenants = c.Tenants
+	e.config.Stations = c.Stations
+	e.config.Sites = c.Sites
+	e.config.StationIDs = c.NormalizeStationIDs
+	e.config.Relabel = c.Relabel
+	e.config.Admin = c.Admin
+	e.config.Ingest = c.Ingest
+	e.config.DNS = c.DNS
+	e.cfgMu.Unlock()
+
+	slog.Info("Reloaded configuration", slog.String("path", e.configPath))
+	return nil
+}
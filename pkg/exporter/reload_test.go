@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1", ConfigPath: path})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	tts := []struct {
+		name        string
+		config      string
+		wantErr     bool
+		wantTenants int
+	}{
+		{
+			name: "valid",
+			config: `
+tenants:
+  - name: a
+    username: a
+    password: a
+    stations: [KCASANFR123]
+`,
+			wantTenants: 1,
+		},
+		{
+			// Nothing is applied if the station configuration is invalid,
+			// including the tenants, which are otherwise valid.
+			name: "invalid station",
+			config: `
+tenants:
+  - name: a
+    username: a
+    password: a
+    stations: [KCASANFR123]
+  - name: b
+    username: b
+    password: b
+    stations: [KCASANFR456]
+stations:
+  KCASANFR123:
+    disabled_fields: [unknown]
+`,
+			wantErr:     true,
+			wantTenants: 1,
+		},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			err := e.Reload()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reload error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			e.cfgMu.RLock()
+			defer e.cfgMu.RUnlock()
+			if got := len(e.tenants); got != tt.wantTenants {
+				t.Errorf("got %d tenants, want %d", got, tt.wantTenants)
+			}
+			if got := len(e.config.Tenants); got != tt.wantTenants {
+				t.Errorf("got %d configured tenants, want %d", got, tt.wantTenants)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/rtl433"
+)
+
+// rtl433Service returns a service that subscribes to the rtl_433 events
+// published to the MQTT broker, and submits the events of the configured
+// devices as observations of their stations. The connection is retried by the
+// supervisor if it fails.
+func (e *Exporter) rtl433Service(c config.RTL433) service {
+	devices := make([]rtl433.Device, 0, len(c.Devices))
+	for _, d := range c.Devices {
+		devices = append(devices, rtl433.Device{
+			Model:   d.Model,
+			ID:      d.ID,
+			Channel: d.Channel,
+			Station: d.Station,
+			Sensor:  rtl433.Sensor(d.Sensor),
+		})
+	}
+	input := rtl433.NewInput(devices, e.handleWUSubmission)
+	input.SetStationIDFunc(e.normalizeStationID)
+	input.SetLocationFunc(func(stationID string) *time.Location {
+		return e.stationConfig(stationID).location
+	})
+
+	topic := c.Topic
+	if topic == "" {
+		topic = rtl433.DefaultTopic
+	}
+	return mqttService("rtl_433", c.MQTT, []string{topic}, input.HandleMessage)
+}
@@ -0,0 +1,459 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package rtl433 implements an input for the events published to MQTT by
+// rtl_433, which decodes the 433MHz transmissions of wireless weather sensors
+// (e.g. Fine Offset, Acurite and LaCrosse sensors) received with a
+// software-defined radio.
+//
+// Sensors are mapped to stations, and the latest event of each sensor of a
+// station is merged into a submission translated to the Weather Underground
+// PWS Upload Protocol, so that it is processed, journaled and forwarded the
+// same way as WU submissions.
+package rtl433
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/internal/mqtt"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// tracer records spans for events. Spans are only recorded if a global
+// OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/exporter/rtl433")
+
+// DefaultTopic is the default topic filter of rtl_433 events, matching the
+// events topic of each rtl_433 instance.
+const DefaultTopic = "rtl_433/+/events"
+
+// staleAfter is the duration after which the last event of a sensor is no
+// longer included in the submissions of its station.
+const staleAfter = 15 * time.Minute
+
+// luxPerWattM2 is the approximate illuminance (lux) of sunlight per W/m² of
+// solar radiation, used to convert the illuminance reported by sensors to
+// solar radiation.
+const luxPerWattM2 = 126.7
+
+// Event is an event published by rtl_433. Only the fields used by weather
+// sensors are decoded, with the units rtl_433 reports by default.
+type Event struct {
+	Model   string    `json:"model"`
+	ID      jsonValue `json:"id"`
+	Channel jsonValue `json:"channel"`
+
+	TemperatureC *float64 `json:"temperature_C"`
+	TemperatureF *float64 `json:"temperature_F"`
+	Humidity     *float64 `json:"humidity"`
+	PressureHPA  *float64 `json:"pressure_hPa"`
+	WindDir      *float64 `json:"wind_dir_deg"`
+	WindAvgMS    *float64 `json:"wind_avg_m_s"`
+	WindMaxMS    *float64 `json:"wind_max_m_s"`
+	WindAvgKMH   *float64 `json:"wind_avg_km_h"`
+	WindMaxKMH   *float64 `json:"wind_max_km_h"`
+	WindAvgMPH   *float64 `json:"wind_avg_mi_h"`
+	WindMaxMPH   *float64 `json:"wind_max_mi_h"`
+	RainMM       *float64 `json:"rain_mm"`
+	RainIn       *float64 `json:"rain_in"`
+	LightLux     *float64 `json:"light_lux"`
+	UV           *float64 `json:"uv"`
+	UVI          *float64 `json:"uvi"`
+}
+
+// jsonValue is a JSON string or number, such as a sensor ID or channel, which
+// rtl_433 reports as a number or a string depending on the sensor.
+type jsonValue string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *jsonValue) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*v = jsonValue(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*v = jsonValue(n)
+	return nil
+}
+
+// ParseEvent parses an rtl_433 event.
+func ParseEvent(b []byte) (Event, error) {
+	var ev Event
+	if err := json.Unmarshal(b, &ev); err != nil {
+		return Event{}, err
+	}
+	return ev, nil
+}
+
+// RainTotal returns the sensor's rain counter in inches, which is the total
+// rain since the sensor was powered on, or false if the event has no rain
+// counter.
+func (ev Event) RainTotal() (float64, bool) {
+	switch {
+	case ev.RainIn != nil:
+		return *ev.RainIn, true
+	case ev.RainMM != nil:
+		return wu.MMToIn(*ev.RainMM), true
+	}
+	return 0, false
+}
+
+// Sensor is the role of a sensor in its station.
+type Sensor string
+
+const (
+	// SensorOutdoor is the station's outdoor sensor (or sensor array).
+	SensorOutdoor Sensor = "outdoor"
+
+	// SensorIndoor is the station's indoor sensor.
+	SensorIndoor Sensor = "indoor"
+)
+
+// SensorChannel returns the role of an additional temperature and humidity
+// sensor on the given channel, 1-wu.MaxChannels.
+func SensorChannel(ch int) Sensor {
+	return Sensor("channel" + strconv.Itoa(ch))
+}
+
+// channel returns the channel of an additional sensor, or false if the sensor
+// is not an additional sensor.
+func (s Sensor) channel() (int, bool) {
+	v, ok := strings.CutPrefix(string(s), "channel")
+	if !ok {
+		return 0, false
+	}
+	ch, err := strconv.Atoi(v)
+	if err != nil || ch < 1 || ch > wu.MaxChannels {
+		return 0, false
+	}
+	return ch, true
+}
+
+// Valid returns whether the sensor role is valid.
+func (s Sensor) Valid() bool {
+	_, ok := s.channel()
+	return ok || s == SensorOutdoor || s == SensorIndoor
+}
+
+// Translate translates the measurements of an event to WU query parameters,
+// for a sensor with the given role. The rain counter is not translated, as
+// the daily rain total depends on previous events (see Event.RainTotal).
+func Translate(ev Event, sensor Sensor) url.Values {
+	q := make(url.Values)
+	set := func(param string, v *float64, convert func(float64) float64) {
+		if v == nil {
+			return
+		}
+		f := *v
+		if convert != nil {
+			f = convert(f)
+		}
+		q.Set(param, strconv.FormatFloat(f, 'f', -1, 64))
+	}
+
+	tempF, tempC := ev.TemperatureF, ev.TemperatureC
+	tempParam, humidityParam := "tempf", "humidity"
+	switch ch, ok := sensor.channel(); {
+	case ok:
+		tempParam = "temp" + strconv.Itoa(ch) + "f"
+		humidityParam = "humidity" + strconv.Itoa(ch)
+	case sensor == SensorIndoor:
+		tempParam, humidityParam = "indoortempf", "indoorhumidity"
+	}
+	if tempF != nil {
+		set(tempParam, tempF, nil)
+	} else {
+		set(tempParam, tempC, wu.CToF)
+	}
+	set(humidityParam, ev.Humidity, nil)
+	if _, ok := sensor.channel(); ok {
+		return q
+	}
+
+	// rtl_433 reports the station pressure measured by the sensor.
+	set("baromabsin", ev.PressureHPA, wu.HPAToInHg)
+	if sensor == SensorIndoor {
+		return q
+	}
+
+	set("winddir", ev.WindDir, nil)
+	switch {
+	case ev.WindAvgMPH != nil:
+		set("windspeedmph", ev.WindAvgMPH, nil)
+	case ev.WindAvgKMH != nil:
+		set("windspeedmph", ev.WindAvgKMH, wu.KPHToMPH)
+	default:
+		set("windspeedmph", ev.WindAvgMS, msToMPH)
+	}
+	switch {
+	case ev.WindMaxMPH != nil:
+		set("windgustmph", ev.WindMaxMPH, nil)
+	case ev.WindMaxKMH != nil:
+		set("windgustmph", ev.WindMaxKMH, wu.KPHToMPH)
+	default:
+		set("windgustmph", ev.WindMaxMS, msToMPH)
+	}
+	set("solarradiation", ev.LightLux, luxToWattM2)
+	if ev.UVI != nil {
+		set("UV", ev.UVI, nil)
+	} else {
+		set("UV", ev.UV, nil)
+	}
+	return q
+}
+
+// msToMPH converts meters/second to miles/hour.
+func msToMPH(ms float64) float64 {
+	return wu.KPHToMPH(ms * 3.6)
+}
+
+// luxToWattM2 converts illuminance (lux) to solar radiation (W/m²).
+func luxToWattM2(lux float64) float64 {
+	return lux / luxPerWattM2
+}
+
+// Device maps the events of an rtl_433 sensor to a station.
+type Device struct {
+	// Model is the model of the sensor, as reported by rtl_433.
+	Model string
+
+	// ID and Channel are the ID and channel of the sensor, as reported by
+	// rtl_433. If empty, events with any ID or channel are matched.
+	ID      string
+	Channel string
+
+	// Station is the ID of the station that the sensor belongs to.
+	Station string
+
+	// Sensor is the role of the sensor in the station. Defaults to
+	// SensorOutdoor.
+	Sensor Sensor
+}
+
+// matches returns whether an event was sent by the device.
+func (d Device) matches(ev Event) bool {
+	return d.Model == ev.Model &&
+		(d.ID == "" || d.ID == string(ev.ID)) &&
+		(d.Channel == "" || d.Channel == string(ev.Channel))
+}
+
+// Input translates rtl_433 events to WU submissions.
+type Input struct {
+	devices          []Device
+	handleSubmission func(ctx context.Context, s wu.Submission)
+	stationID        func(stationID string) (string, bool)
+	location         func(stationID string) *time.Location
+
+	mu       sync.Mutex
+	readings map[int]reading // By device index
+}
+
+// reading is the last reading of a device.
+type reading struct {
+	time time.Time
+	q    url.Values
+
+	// rainDay is the start of the local day that the daily rain total is
+	// counted from, and rainBase is the rain counter at the start of the
+	// day. lastRain is the last rain counter reported by the device.
+	rainDay  time.Time
+	rainBase float64
+	lastRain float64
+	hasRain  bool
+}
+
+// NewInput returns a new input for the given devices. The handler is called
+// synchronously for each event sent by a device, with the readings of the
+// devices of its station merged into a WU submission.
+func NewInput(devices []Device, handler func(ctx context.Context, s wu.Submission)) *Input {
+	return &Input{
+		devices:          devices,
+		handleSubmission: handler,
+		readings:         make(map[int]reading),
+	}
+}
+
+// SetStationIDFunc sets the function used to normalize the ID of the station
+// that an event is submitted for. If the function returns false, the event is
+// dropped. It must be called before events are handled.
+func (in *Input) SetStationIDFunc(fn func(stationID string) (string, bool)) {
+	in.stationID = fn
+}
+
+// SetLocationFunc sets the function that returns the time zone of a station,
+// which the daily rain total is reset at the start of the day in. If not set,
+// or the function returns nil, UTC is used. It must be called before events
+// are handled.
+func (in *Input) SetLocationFunc(fn func(stationID string) *time.Location) {
+	in.location = fn
+}
+
+// HandleMessage handles an MQTT message containing an rtl_433 event. Events
+// from sensors that are not mapped to a station are ignored.
+func (in *Input) HandleMessage(ctx context.Context, msg mqtt.Message) {
+	ev, err := ParseEvent(msg.Payload)
+	if err != nil {
+		slog.Debug("Ignored invalid rtl_433 event",
+			slog.String("topic", msg.Topic),
+			slog.Any("err", err))
+		return
+	}
+	di := -1
+	for i, d := range in.devices {
+		if d.matches(ev) {
+			di = i
+			break
+		}
+	}
+	if di < 0 {
+		slog.Debug("Ignored rtl_433 event from unmapped sensor",
+			slog.String("topic", msg.Topic),
+			slog.String("model", ev.Model),
+			slog.String("id", string(ev.ID)),
+			slog.String("channel", string(ev.Channel)))
+		return
+	}
+	d := in.devices[di]
+
+	ctx, span := tracer.Start(ctx, "rtl433.event",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("station_id", d.Station),
+			attribute.String("model", ev.Model),
+		))
+	defer span.End()
+
+	stationID := d.Station
+	if in.stationID != nil {
+		id, ok := in.stationID(stationID)
+		if !ok {
+			slog.Warn("Dropped rtl_433 event for unknown station",
+				slog.String("station_id", stationID),
+				slog.String("model", ev.Model),
+				slog.String("outcome", "rejected"))
+			return
+		}
+		stationID = id
+	}
+	loc := time.UTC
+	if in.location != nil {
+		if l := in.location(stationID); l != nil {
+			loc = l
+		}
+	}
+
+	receivedAt := time.Now()
+	q := in.update(di, ev, receivedAt, loc)
+	q.Set("ID", stationID)
+	q.Set("action", "updateraww")
+	q.Set("dateutc", receivedAt.UTC().Format("2006-01-02 15:04:05"))
+	wu.AddDewPoint(q)
+
+	dm, fieldErrs := wu.ParseMeasurement(q, receivedAt)
+	for _, fe := range fieldErrs {
+		span.RecordError(fe)
+		slog.Warn("Ignored invalid rtl_433 event field",
+			slog.String("station_id", stationID),
+			slog.String("param", fe.Param),
+			slog.String("value", fe.Value),
+			slog.Any("err", fe.Err))
+	}
+
+	slog.Debug("Received rtl_433 event for station",
+		slog.String("station_id", stationID),
+		slog.String("model", ev.Model),
+		slog.String("id", string(ev.ID)),
+		slog.String("outcome", "accepted"))
+
+	in.handleSubmission(ctx, wu.Submission{
+		StationID:   stationID,
+		ReceivedAt:  receivedAt,
+		RemoteAddr:  "mqtt",
+		RawQuery:    q.Encode(),
+		Measurement: dm,
+		Fields:      wu.SubmittedFields(q, fieldErrs),
+		FieldErrors: fieldErrs,
+	})
+}
+
+// update stores the reading of device di from an event received at t, and
+// returns the query parameters of the latest readings of the devices of its
+// station, excluding stale readings.
+func (in *Input) update(di int, ev Event, t time.Time, loc *time.Location) url.Values {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	d := in.devices[di]
+	sensor := d.Sensor
+	if sensor == "" {
+		sensor = SensorOutdoor
+	}
+	r := in.readings[di]
+	r.time = t
+	r.q = Translate(ev, sensor)
+	if total, ok := ev.RainTotal(); ok {
+		y, m, day := t.In(loc).Date()
+		today := time.Date(y, m, day, 0, 0, 0, 0, loc)
+		switch {
+		case !r.hasRain:
+			// Rain that fell before the first event is unknown.
+			r.rainDay, r.rainBase = today, total
+		case !today.Equal(r.rainDay):
+			r.rainDay, r.rainBase = today, r.lastRain
+		}
+		if total < r.lastRain && r.hasRain {
+			// The counter was reset, e.g. by replacing the batteries.
+			r.rainBase -= r.lastRain
+		}
+		r.lastRain, r.hasRain = total, true
+		r.q.Set("dailyrainin", strconv.FormatFloat(max(total-r.rainBase, 0), 'f', -1, 64))
+	}
+	in.readings[di] = r
+
+	q := make(url.Values)
+	for i, od := range in.devices {
+		or, ok := in.readings[i]
+		if !ok || od.Station != d.Station || t.Sub(or.time) > staleAfter {
+			continue
+		}
+		for param, values := range or.q {
+			// The reading of the device that sent the event takes precedence.
+			if i == di || !q.Has(param) {
+				q[param] = values
+			}
+		}
+	}
+	return q
+}
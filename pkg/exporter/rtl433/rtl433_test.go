@@ -0,0 +1,247 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package rtl433
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/mqtt"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+func TestParseEvent(t *testing.T) {
+	ev, err := ParseEvent([]byte(`{"time":"2025-01-23 10:00:00","model":"Acurite-Tower","id":11338,"channel":"A","battery_ok":1,"temperature_C":12.5,"humidity":81}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Model != "Acurite-Tower" || ev.ID != "11338" || ev.Channel != "A" {
+		t.Errorf("got model %q, id %q, channel %q", ev.Model, ev.ID, ev.Channel)
+	}
+	if ev.TemperatureC == nil || *ev.TemperatureC != 12.5 || ev.Humidity == nil || *ev.Humidity != 81 {
+		t.Errorf("got temperature %v, humidity %v", ev.TemperatureC, ev.Humidity)
+	}
+
+	if _, err := ParseEvent([]byte(`{"model":"x","id":true}`)); err == nil {
+		t.Error("ParseEvent succeeded with invalid ID")
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	outdoor, err := ParseEvent([]byte(`{"model":"Fineoffset-WH65B","id":60,"temperature_C":15,"humidity":80,"wind_dir_deg":213,"wind_avg_m_s":2.5,"wind_max_m_s":4,"rain_mm":120.3,"light_lux":12670,"uvi":3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	indoor, err := ParseEvent([]byte(`{"model":"Fineoffset-WH32B","id":146,"temperature_F":70.5,"humidity":45,"pressure_hPa":1013.25}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		ev     Event
+		sensor Sensor
+		want   map[string]float64
+	}{
+		{
+			name:   "outdoor",
+			ev:     outdoor,
+			sensor: SensorOutdoor,
+			want: map[string]float64{
+				"tempf":          59,
+				"humidity":       80,
+				"winddir":        213,
+				"windspeedmph":   5.592,
+				"windgustmph":    8.948,
+				"solarradiation": 100,
+				"UV":             3,
+			},
+		},
+		{
+			name:   "indoor",
+			ev:     indoor,
+			sensor: SensorIndoor,
+			want: map[string]float64{
+				"indoortempf":    70.5,
+				"indoorhumidity": 45,
+				"baromabsin":     29.921,
+			},
+		},
+		{
+			name:   "channel",
+			ev:     outdoor,
+			sensor: SensorChannel(3),
+			want: map[string]float64{
+				"temp3f":    59,
+				"humidity3": 80,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Translate(tt.ev, tt.sensor)
+			if len(q) != len(tt.want) {
+				t.Errorf("got params %v, want %d params", q, len(tt.want))
+			}
+			for param, want := range tt.want {
+				got, err := strconv.ParseFloat(q.Get(param), 64)
+				if err != nil {
+					t.Errorf("%s: %v", param, err)
+					continue
+				}
+				if math.Abs(got-want) > 0.001 {
+					t.Errorf("%s = %v, want %v", param, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSensorValid(t *testing.T) {
+	for sensor, want := range map[Sensor]bool{
+		SensorOutdoor: true,
+		SensorIndoor:  true,
+		"channel1":    true,
+		"channel8":    true,
+		"channel0":    false,
+		"channel9":    false,
+		"channelx":    false,
+		"garage":      false,
+	} {
+		if got := sensor.Valid(); got != want {
+			t.Errorf("Sensor(%q).Valid() = %v, want %v", sensor, got, want)
+		}
+	}
+}
+
+func TestInput(t *testing.T) {
+	var got []wu.Submission
+	in := NewInput([]Device{
+		{Model: "Fineoffset-WH65B", Station: "backyard"},
+		{Model: "Fineoffset-WH32B", ID: "146", Station: "backyard", Sensor: SensorIndoor},
+		{Model: "Acurite-Tower", Channel: "A", Station: "backyard", Sensor: SensorChannel(1)},
+		{Model: "LaCrosse-TX141THBv2", Station: "unknown"},
+	}, func(_ context.Context, s wu.Submission) {
+		got = append(got, s)
+	})
+	in.SetStationIDFunc(func(stationID string) (string, bool) {
+		return stationID, stationID != "unknown"
+	})
+	publish := func(payload string) {
+		in.HandleMessage(context.Background(), mqtt.Message{
+			Topic:   "rtl_433/sdr/events",
+			Payload: []byte(payload),
+		})
+	}
+
+	publish(`not json`)
+	publish(`{"model":"Fineoffset-WH32B","id":147,"temperature_C":21}`)
+	publish(`{"model":"LaCrosse-TX141THBv2","id":5,"temperature_C":21}`)
+	if len(got) != 0 {
+		t.Fatalf("got %d submissions from ignored events, want 0", len(got))
+	}
+
+	publish(`{"model":"Fineoffset-WH65B","id":60,"temperature_C":15,"humidity":80,"rain_mm":100}`)
+	publish(`{"model":"Fineoffset-WH32B","id":146,"temperature_C":21,"humidity":45}`)
+	publish(`{"model":"Acurite-Tower","id":11338,"channel":"A","temperature_C":5,"humidity":90}`)
+	if len(got) != 3 {
+		t.Fatalf("got %d submissions, want 3", len(got))
+	}
+	s := got[2]
+	if s.StationID != "backyard" || s.RemoteAddr != "mqtt" {
+		t.Errorf("got station %q from %q", s.StationID, s.RemoteAddr)
+	}
+	q, err := url.ParseQuery(s.RawQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for param, want := range map[string]string{
+		"ID":          "backyard",
+		"action":      "updateraww",
+		"tempf":       "59",
+		"indoortempf": "69.8",
+		"temp1f":      "41",
+		"humidity1":   "90",
+		"dailyrainin": "0",
+		"dewptf":      "52.8",
+	} {
+		if q.Get(param) != want {
+			t.Errorf("%s = %q, want %q", param, q.Get(param), want)
+		}
+	}
+	if s.Measurement.Temperature != 15 || len(s.Measurement.Channels) != 1 {
+		t.Errorf("got measurement %+v", s.Measurement)
+	}
+}
+
+func TestDailyRain(t *testing.T) {
+	in := NewInput([]Device{{Model: "Fineoffset-WH65B", Station: "backyard"}}, nil)
+	loc := time.FixedZone("UTC+10", 10*60*60)
+	start := time.Date(2025, 1, 23, 10, 0, 0, 0, loc)
+
+	for _, tt := range []struct {
+		name   string
+		after  time.Duration
+		rainMM float64
+		want   float64 // mm
+	}{
+		{"first event", 0, 100, 0},
+		{"rain", time.Hour, 102.54, 2.54},
+		{"counter reset", 2 * time.Hour, 2.54, 5.08},
+		// Rain since the last event of the previous day is counted on the
+		// new day.
+		{"next day", 14 * time.Hour, 7.62, 5.08},
+		{"rain next day", 15 * time.Hour, 10.16, 7.62},
+	} {
+		ev := Event{Model: "Fineoffset-WH65B", RainMM: &tt.rainMM}
+		q := in.update(0, ev, start.Add(tt.after), loc)
+		got, err := strconv.ParseFloat(q.Get("dailyrainin"), 64)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if want := wu.MMToIn(tt.want); math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: dailyrainin = %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestStaleReadings(t *testing.T) {
+	in := NewInput([]Device{
+		{Model: "Fineoffset-WH65B", Station: "backyard"},
+		{Model: "Fineoffset-WH32B", Station: "backyard", Sensor: SensorIndoor},
+	}, nil)
+	temp := 20.0
+	now := time.Now()
+	in.update(1, Event{Model: "Fineoffset-WH32B", TemperatureC: &temp}, now, time.UTC)
+
+	q := in.update(0, Event{Model: "Fineoffset-WH65B", TemperatureC: &temp}, now.Add(staleAfter/2), time.UTC)
+	if !q.Has("indoortempf") {
+		t.Errorf("indoor reading missing from %v", q)
+	}
+	q = in.update(0, Event{Model: "Fineoffset-WH65B", TemperatureC: &temp}, now.Add(2*staleAfter), time.UTC)
+	if q.Has("indoortempf") {
+		t.Errorf("stale indoor reading included in %v", q)
+	}
+}
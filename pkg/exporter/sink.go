@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// Sink receives the observations recorded by the exporter, e.g. to write them
+// to an external database.
+//
+// Sinks that buffer observations or hold resources may also implement
+// SinkCloser, which is called when the exporter shuts down.
+type Sink interface {
+	// Write writes an observation to the sink. Write is called from the
+	// observation processing pipeline, and should not block for long.
+	Write(ctx context.Context, o weather.Observation) error
+}
+
+// SinkCloser is implemented by sinks that need to flush or release resources
+// when the exporter shuts down.
+type SinkCloser interface {
+	// Close is called once the queued observations have been processed, in
+	// the order the sinks were registered. The context is cancelled when the
+	// shutdown timeout expires.
+	Close(ctx context.Context) error
+}
+
+// sinks are the sinks registered using RegisterSink.
+type sinks struct {
+	mu    sync.RWMutex
+	sinks []*registeredSink
+}
+
+// registeredSink is a sink registered using RegisterSink.
+type registeredSink struct {
+	name   string
+	sink   Sink
+	health sinkHealth
+}
+
+// RegisterSink registers a sink that all observations are written to, using
+// the given name in the exporter status and admin API.
+func (e *Exporter) RegisterSink(name string, s Sink) error {
+	if name == "" {
+		return errors.New("sink name is required")
+	}
+	e.sinks.mu.Lock()
+	defer e.sinks.mu.Unlock()
+
+	if e.sinkHealth(name) != nil || e.registeredSink(name) != nil {
+		return fmt.Errorf("sink %q already exists", name)
+	}
+	e.sinks.sinks = append(e.sinks.sinks, &registeredSink{name: name, sink: s})
+	return nil
+}
+
+// closeSinks closes the registered sinks that implement SinkCloser, in the
+// order they were registered.
+func (e *Exporter) closeSinks(ctx context.Context) error {
+	e.sinks.mu.RLock()
+	defer e.sinks.mu.RUnlock()
+
+	var errs []error
+	for _, rs := range e.sinks.sinks {
+		c, ok := rs.sink.(SinkCloser)
+		if !ok {
+			continue
+		}
+		if err := c.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("close sink %q: %w", rs.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// registeredSink returns the registered sink with the given name, or nil.
+// The caller must hold e.sinks.mu.
+func (e *Exporter) registeredSink(name string) *registeredSink {
+	for _, rs := range e.sinks.sinks {
+		if rs.name == name {
+			return rs
+		}
+	}
+	return nil
+}
+
+// writeSinks writes an observation to the store, if enabled, and all
+// registered sinks.
+func (e *Exporter) writeSinks(ctx context.Context, o weather.Observation) {
+	if e.store != nil && e.storeHealth.enabled() {
+		sctx, span := tracer.Start(ctx, "sink.write",
+			trace.WithAttributes(attribute.String("sink", "store")))
+		err := e.store.Insert(sctx, o)
+		endSpan(span, err)
+		e.storeHealth.record(err)
+		if err != nil {
+			slog.Error("Failed to store observation",
+				slog.String("station_id", o.StationID), slog.Any("err", err))
+		}
+	}
+
+	e.sinks.mu.RLock()
+	defer e.sinks.mu.RUnlock()
+	for _, rs := range e.sinks.sinks {
+		if !rs.health.enabled() {
+			continue
+		}
+		sctx, span := tracer.Start(ctx, "sink.write",
+			trace.WithAttributes(attribute.String("sink", rs.name)))
+		err := rs.sink.Write(sctx, o)
+		endSpan(span, err)
+		rs.health.record(err)
+		if err != nil {
+			slog.Error("Failed to write observation to sink",
+				slog.String("sink", rs.name), slog.String("station_id", o.StationID),
+				slog.Any("err", err))
+		}
+	}
+}
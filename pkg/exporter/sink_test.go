@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// testSink is a sink that records the observations written to it.
+type testSink struct {
+	observations []weather.Observation
+}
+
+func (s *testSink) Write(_ context.Context, o weather.Observation) error {
+	s.observations = append(s.observations, o)
+	return nil
+}
+
+func TestRegisterSink(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	s := &testSink{}
+	if err := e.RegisterSink("test", s); err != nil {
+		t.Fatalf("register sink: %v", err)
+	}
+	if err := e.RegisterSink("test", s); err == nil {
+		t.Error("registering a duplicate sink should fail")
+	}
+
+	e.processObservation(context.Background(), weather.Observation{StationID: "a"})
+	if len(s.observations) != 1 || s.observations[0].StationID != "a" {
+		t.Errorf("sink got %v, want one observation from station a", s.observations)
+	}
+
+	// Disabled sinks are not written to.
+	e.sinks.sinks[0].health.disabled.Store(true)
+	e.processObservation(context.Background(), weather.Observation{StationID: "a"})
+	if len(s.observations) != 1 {
+		t.Errorf("disabled sink got %d observations, want 1", len(s.observations))
+	}
+}
+
+// closingSink is a sink that records when it is closed.
+type closingSink struct {
+	testSink
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (s *closingSink) Close(context.Context) error {
+	*s.closed = append(*s.closed, s.name)
+	return s.err
+}
+
+func TestCloseSinks(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+
+	var closed []string
+	closeErr := errors.New("flush failed")
+	sinks := []Sink{
+		&closingSink{name: "a", closed: &closed},
+		&testSink{},
+		&closingSink{name: "b", closed: &closed, err: closeErr},
+	}
+	for i, s := range sinks {
+		if err := e.RegisterSink(fmt.Sprintf("sink%d", i), s); err != nil {
+			t.Fatalf("register sink: %v", err)
+		}
+	}
+
+	err = e.Close()
+	if !errors.Is(err, closeErr) {
+		t.Errorf("close error = %v, want %v", err, closeErr)
+	}
+	if want := []string{"a", "b"}; !slices.Equal(closed, want) {
+		t.Errorf("closed sinks = %v, want %v", closed, want)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+// siteSubsystem is the subsystem of the site-level metrics.
+const siteSubsystem = "site"
+
+var (
+	// siteStationsDesc is the description of the site stations metric.
+	siteStationsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("weather", siteSubsystem, "stations"),
+		"Number of stations at the site that are included in the site aggregates",
+		[]string{"site"}, nil,
+	)
+
+	// siteTemperatureDesc is the description of the site temperature metric.
+	siteTemperatureDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("weather", siteSubsystem, "temperature_celsius"),
+		"Mean outdoor temperature of the stations at the site in Celsius",
+		[]string{"site"}, nil,
+	)
+
+	// siteWindGustDesc is the description of the site wind gust metric.
+	siteWindGustDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("weather", siteSubsystem, "wind_gust_kph"),
+		"Highest wind gust speed of the stations at the site in KM/h",
+		[]string{"site"}, nil,
+	)
+
+	// siteRainDesc is the description of the site rain metric.
+	siteRainDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("weather", siteSubsystem, "rain_today_mm"),
+		"Total amount of rain since midnight of the stations at the site in millimeters",
+		[]string{"site"}, nil,
+	)
+)
+
+// setSites replaces the exporter's site configuration.
+func (e *Exporter) setSites(sites []config.Site) {
+	sites = slices.Clone(sites)
+	e.cfgMu.Lock()
+	e.sites = sites
+	e.cfgMu.Unlock()
+}
+
+// siteAggregate is the aggregate of the latest observations of the stations
+// at a site. Each value only includes the stations that measured it, and the
+// number of those stations is counted separately.
+type siteAggregate struct {
+	stations            int
+	temperature         float64
+	temperatureStations int
+	windGust            float64
+	windGustStations    int
+	rainToday           float64
+	rainStations        int
+}
+
+// siteAggregate returns the aggregate of the latest observations of the
+// stations at a site at now. Stations that have not submitted yet, or that
+// are down, are not included.
+func (e *Exporter) siteAggregate(site config.Site, now time.Time) (siteAggregate, bool) {
+	var a siteAggregate
+	for _, stationID := range site.Stations {
+		o, ok := e.history.latest(stationID)
+		if !ok {
+			continue
+		}
+		if after := e.staleAfter(stationID); after > 0 && now.Sub(o.ReceivedAt) > after {
+			continue
+		}
+		dm := o.Measurement
+		if dm.Has("tempf") {
+			a.temperature += dm.Temperature
+			a.temperatureStations++
+		}
+		if dm.Has("windgustmph") {
+			a.windGust = max(a.windGust, dm.WindGust)
+			a.windGustStations++
+		}
+		if dm.Has("dailyrainin") {
+			a.rainToday += dm.RainToday
+			a.rainStations++
+		}
+		a.stations++
+	}
+	if a.stations == 0 {
+		return siteAggregate{}, false
+	}
+	if a.temperatureStations > 0 {
+		a.temperature /= float64(a.temperatureStations)
+	}
+	return a, true
+}
+
+// siteCollector is a prometheus.Collector that exports the aggregates of each
+// site. Only the sites whose stations are exported on the given metrics are
+// collected, so that each registry only exports its own sites.
+type siteCollector struct {
+	e       *Exporter
+	metrics *Metrics
+}
+
+// Describe implements prometheus.Collector.
+func (c *siteCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- siteStationsDesc
+	ch <- siteTemperatureDesc
+	ch <- siteWindGustDesc
+	ch <- siteRainDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *siteCollector) Collect(ch chan<- prometheus.Metric) {
+	c.e.cfgMu.RLock()
+	sites := c.e.sites
+	c.e.cfgMu.RUnlock()
+
+	now := time.Now()
+	for _, site := range sites {
+		if c.e.metricsFor(site.Stations[0]) != c.metrics {
+			continue
+		}
+		a, ok := c.e.siteAggregate(site, now)
+		if !ok {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(siteStationsDesc,
+			prometheus.GaugeValue, float64(a.stations), site.Name)
+		// Values that no station at the site measured are not exported.
+		if a.temperatureStations > 0 {
+			ch <- prometheus.MustNewConstMetric(siteTemperatureDesc,
+				prometheus.GaugeValue, a.temperature, site.Name)
+		}
+		if a.windGustStations > 0 {
+			ch <- prometheus.MustNewConstMetric(siteWindGustDesc,
+				prometheus.GaugeValue, a.windGust, site.Name)
+		}
+		if a.rainStations > 0 {
+			ch <- prometheus.MustNewConstMetric(siteRainDesc,
+				prometheus.GaugeValue, a.rainToday, site.Name)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestSiteAggregate(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Stations: map[string]config.Station{
+			"down": {ExpectedInterval: time.Minute},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	now := time.Now()
+	for id, dm := range map[string]wu.DeviceMeasurement{
+		"north": {Temperature: 18.5, WindGust: 32, RainToday: 4.2},
+		"south": {Temperature: 20.5, WindGust: 41, RainToday: 3.8},
+		"down":  {Temperature: 30, WindGust: 90, RainToday: 50},
+		// A station without a temperature sensor is not included in the
+		// mean temperature.
+		"shade": {WindGust: 12, RainToday: 1, Missing: []string{"tempf"}},
+	} {
+		receivedAt := now
+		if id == "down" {
+			receivedAt = now.Add(-time.Hour)
+		}
+		e.history.add(weather.Observation{StationID: id, ReceivedAt: receivedAt, Measurement: dm})
+	}
+
+	site := config.Site{Name: "home", Stations: []string{"north", "south", "down", "shade", "unknown"}}
+	a, ok := e.siteAggregate(site, now)
+	if !ok {
+		t.Fatal("site aggregate should be ok")
+	}
+	if a.stations != 3 {
+		t.Errorf("got %d stations, want 3", a.stations)
+	}
+	if a.temperature != 19.5 || a.temperatureStations != 2 {
+		t.Errorf("got temperature %v from %d stations, want 19.5 from 2", a.temperature, a.temperatureStations)
+	}
+	if a.windGust != 41 {
+		t.Errorf("got wind gust %v, want 41", a.windGust)
+	}
+	if math.Abs(a.rainToday-9) > 0.001 {
+		t.Errorf("got rain %v, want 9", a.rainToday)
+	}
+
+	if _, ok := e.siteAggregate(config.Site{Name: "empty", Stations: []string{"unknown"}}, now); ok {
+		t.Error("site without observations should not be ok")
+	}
+}
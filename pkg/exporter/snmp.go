@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/joshuasing/pws_exporter/internal/snmp"
+)
+
+// defaultSNMPRootOID is the default root of the SNMP MIB subtree, in the
+// experimental branch.
+const defaultSNMPRootOID = "1.3.6.1.3.9452"
+
+// snmpValueScale is the scale of field values exposed over SNMP, which only
+// supports integers.
+const snmpValueScale = 100
+
+// SNMP station table columns. Field columns start at snmpFieldColumn, in the
+// order of the fields exposed by the APIs.
+const (
+	snmpIndexColumn     = 1
+	snmpStationIDColumn = 2
+	snmpTimeColumn      = 3
+	snmpFieldColumn     = 4
+)
+
+// snmpService returns a service that serves the latest observation of each
+// station using a read-only SNMP agent.
+func (e *Exporter) snmpService(address, community string, root snmp.OID) service {
+	agent := &snmp.Agent{
+		Community: community,
+		Variables: func() []snmp.Variable {
+			return e.snmpVariables(root)
+		},
+	}
+	return service{
+		name:    "snmp",
+		address: address,
+		serve: func(ctx context.Context, lc listenConfig, ready func(addr string)) error {
+			pc, err := lc.ListenPacket(ctx, "udp", address)
+			if err != nil {
+				return err
+			}
+			ready(pc.LocalAddr().String())
+			return serveUntilDone(ctx, func() error {
+				return agent.Serve(pc)
+			}, func(context.Context) error {
+				return pc.Close()
+			})
+		},
+	}
+}
+
+// snmpVariables returns the SNMP MIB view of the latest observation of each
+// station, sorted by OID:
+//
+//	<root>.1.0                  number of stations
+//	<root>.2.1.1.<index>        station index
+//	<root>.2.1.2.<index>        station ID
+//	<root>.2.1.3.<index>        observation time, in seconds since the epoch
+//	<root>.2.1.<4+i>.<index>    value of field i, multiplied by 100
+//
+// Stations are indexed from 1 in order of station ID. Values of fields that a
+// station did not measure are absent.
+func (e *Exporter) snmpVariables(root snmp.OID) []snmp.Variable {
+	stationIDs := e.history.stationIDs()
+	slices.Sort(stationIDs)
+
+	columns := snmpFieldColumn + len(fields) - 1
+	vars := make([]snmp.Variable, 0, 1+len(stationIDs)*columns)
+	vars = append(vars, snmp.Variable{OID: root.Append(1, 0), Value: len(stationIDs)})
+	entry := root.Append(2, 1)
+	for col := snmpIndexColumn; col <= columns; col++ {
+		for i, stationID := range stationIDs {
+			o, ok := e.history.latest(stationID)
+			if !ok {
+				continue
+			}
+			if col >= snmpFieldColumn && !o.Measurement.Has(fields[col-snmpFieldColumn].param) {
+				// Fields the station did not measure are not in the view.
+				continue
+			}
+			oid := entry.Append(uint32(col), uint32(i+1)) //nolint:gosec
+			var v any
+			switch col {
+			case snmpIndexColumn:
+				v = i + 1
+			case snmpStationIDColumn:
+				v = stationID
+			case snmpTimeColumn:
+				v = snmp.Gauge32(max(o.Measurement.DateUTC.Unix(), 0)) //nolint:gosec
+			default:
+				v = int64(math.Round(fields[col-snmpFieldColumn].value(o) * snmpValueScale))
+			}
+			vars = append(vars, snmp.Variable{OID: oid, Value: v})
+		}
+	}
+	return vars
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/snmp"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestSNMPVariables(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	ts := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	e.history.add(weather.Observation{
+		StationID:   "b",
+		Measurement: wu.DeviceMeasurement{DateUTC: ts, Temperature: -1.234},
+	})
+	e.history.add(weather.Observation{
+		StationID:   "a",
+		Measurement: wu.DeviceMeasurement{DateUTC: ts, Missing: []string{"tempf"}},
+	})
+
+	root := snmp.OID{1, 3, 6, 1, 3, 9452}
+	vars := e.snmpVariables(root)
+	if !slices.IsSortedFunc(vars, func(a, b snmp.Variable) int { return a.OID.Compare(b.OID) }) {
+		t.Error("variables are not sorted by OID")
+	}
+	want := map[string]any{
+		"1.3.6.1.3.9452.1.0":     2,
+		"1.3.6.1.3.9452.2.1.2.1": "a",
+		"1.3.6.1.3.9452.2.1.2.2": "b",
+		"1.3.6.1.3.9452.2.1.3.1": snmp.Gauge32(ts.Unix()),
+		"1.3.6.1.3.9452.2.1.4.2": int64(-123), // temperature
+	}
+	for _, v := range vars {
+		if v.OID.String() == "1.3.6.1.3.9452.2.1.4.1" {
+			t.Errorf("missing temperature of station a is in the view: %v", v.Value)
+		}
+		if w, ok := want[v.OID.String()]; ok {
+			if v.Value != w {
+				t.Errorf("%s = %v, want %v", v.OID, v.Value, w)
+			}
+			delete(want, v.OID.String())
+		}
+	}
+	for oid := range want {
+		t.Errorf("missing variable %s", oid)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// stateSaveInterval is the interval at which the exporter state is saved to
+// the state file, if it has changed.
+const stateSaveInterval = 30 * time.Second
+
+// state is the derived exporter state that is persisted across restarts.
+type state struct {
+	Stations map[string]stationState `json:"stations"`
+}
+
+// stationState is the persisted state of a single station.
+type stationState struct {
+	// RainToday is the last daily rain total submitted by the station, used
+	// to restore the rain counter.
+	RainToday float64 `json:"rain_today_mm"`
+
+	// SunshineToday is the sunshine duration of the station's current day,
+	// used to restore the sunshine duration counter.
+	SunshineToday float64 `json:"sunshine_today_seconds"`
+
+	// Observations are the observations kept in memory for the station. The
+	// last observation contains the time the station was last seen.
+	Observations []weather.Observation `json:"observations"`
+}
+
+// snapshotState returns a snapshot of the current exporter state.
+func (e *Exporter) snapshotState() state {
+	s := state{Stations: make(map[string]stationState)}
+
+	e.rain.mu.Lock()
+	for stationID, rainToday := range e.rain.today {
+		ss := s.Stations[stationID]
+		ss.RainToday = rainToday
+		s.Stations[stationID] = ss
+	}
+	e.rain.mu.Unlock()
+
+	e.sunshine.mu.Lock()
+	for stationID, sunshineToday := range e.sunshine.today {
+		ss := s.Stations[stationID]
+		ss.SunshineToday = sunshineToday
+		s.Stations[stationID] = ss
+	}
+	e.sunshine.mu.Unlock()
+
+	for _, stationID := range e.history.stationIDs() {
+		ss := s.Stations[stationID]
+		ss.Observations = e.history.since(stationID, time.Time{})
+		s.Stations[stationID] = ss
+	}
+	return s
+}
+
+// restoreState restores the exporter state from a snapshot, and sets the
+// gauges of each station from its latest restored observation. Observations
+// that are not newer than a station's latest observation are skipped, and
+// the rain and sunshine counters are only restored for stations without
+// observations, so that restoring into a running exporter does not overwrite
+// newer state.
+func (e *Exporter) restoreState(s state) {
+	for stationID, ss := range s.Stations {
+		latest, seen := e.history.latest(stationID)
+		restored := false
+		for _, o := range ss.Observations {
+			if seen && !o.Measurement.DateUTC.After(latest.Measurement.DateUTC) {
+				continue
+			}
+			e.history.add(o)
+			restored = true
+		}
+
+		m := e.metricsFor(stationID)
+		l := prometheus.Labels{"station_id": stationID}
+		if restored {
+			latest, _ = e.history.latest(stationID)
+			setGauges(m, l, latest.Measurement)
+			e.updateRates(m, l, stationID, latest.Measurement.DateUTC)
+			e.updateGustFactor(m, l, stationID, latest.Measurement.DateUTC)
+		}
+		if !seen {
+			e.updateRain(m, l, stationID, ss.RainToday, false)
+			e.restoreSunshine(m, l, stationID, ss.SunshineToday)
+		}
+	}
+}
+
+// loadState loads the exporter state from the state file, if it exists.
+func (e *Exporter) loadState() error {
+	b, err := os.ReadFile(e.statePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var s state
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("decode %s: %w", e.statePath, err)
+	}
+	e.restoreState(s)
+	slog.Info("Restored exporter state",
+		slog.String("path", e.statePath),
+		slog.Int("stations", len(s.Stations)))
+	return nil
+}
+
+// saveState atomically writes the current exporter state to the state file.
+func (e *Exporter) saveState() error {
+	b, err := json.Marshal(e.snapshotState())
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(e.statePath), ".pws_exporter-state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), e.statePath)
+}
+
+// saveStateLoop periodically saves the exporter state if it has changed, until
+// the exporter is closed.
+func (e *Exporter) saveStateLoop() {
+	defer e.stateWG.Done()
+
+	ticker := time.NewTicker(stateSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stateQuit:
+			return
+		case <-ticker.C:
+		}
+
+		if !e.stateHealth.enabled() || !e.stateDirty.CompareAndSwap(true, false) {
+			continue
+		}
+		err := e.saveState()
+		e.stateHealth.record(err)
+		if err != nil {
+			slog.Error("Failed to save exporter state", slog.Any("err", err))
+		}
+	}
+}
+
+// closeState stops the state save loop and saves the final state.
+func (e *Exporter) closeState() error {
+	close(e.stateQuit)
+	e.stateWG.Wait()
+	if !e.stateHealth.enabled() {
+		return nil
+	}
+	if err := e.saveState(); err != nil {
+		return fmt.Errorf("save exporter state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	src, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer src.Close()
+
+	ts := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	for i, temp := range []float64{10, 12} {
+		o := weather.Observation{
+			StationID:  "a",
+			ReceivedAt: ts.Add(time.Duration(i) * time.Minute),
+			Measurement: wu.DeviceMeasurement{
+				DateUTC:     ts.Add(time.Duration(i) * time.Minute),
+				Temperature: temp,
+				RainToday:   1.5,
+			},
+		}
+		if err := src.recordObservation(context.Background(), &o); err != nil {
+			t.Fatalf("record observation: %v", err)
+		}
+	}
+
+	dst, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer dst.Close()
+
+	// Restoring the same snapshot twice must not duplicate observations.
+	s := src.snapshotState()
+	dst.restoreState(s)
+	dst.restoreState(s)
+
+	if got := len(dst.history.since("a", time.Time{})); got != 2 {
+		t.Errorf("got %d observations, want 2", got)
+	}
+	var temp, rain dto.Metric
+	if err := dst.metrics.Temperature.WithLabelValues("a").Write(&temp); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if got := temp.GetGauge().GetValue(); got != 12 {
+		t.Errorf("got temperature %v, want 12", got)
+	}
+	if err := dst.metrics.Rain.WithLabelValues("a").Write(&rain); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if got := rain.GetCounter().GetValue(); got != 1.5 {
+		t.Errorf("got rain %v, want 1.5", got)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// stationConfig is the parsed configuration of a single station.
+type stationConfig struct {
+	// location is the time zone used for the station's daily resets, or nil
+	// if the station resets at UTC midnight.
+	location *time.Location
+
+	// receiveTime replaces the observation time reported by the station
+	// with the time the submission was received.
+	receiveTime bool
+
+	// expectedInterval is the interval the station is expected to submit
+	// at, or zero if the station is not watched.
+	expectedInterval time.Duration
+
+	// response is the response sent to the station for accepted
+	// submissions, or nil to send the default response.
+	response *wu.Response
+
+	// rapidFireResponse is the response sent to the station for accepted
+	// RapidFire submissions, or nil to send response.
+	rapidFireResponse *wu.Response
+
+	// rapidFireWindow is the window RapidFire observations are aggregated
+	// over, or zero if they are not downsampled.
+	rapidFireWindow time.Duration
+
+	// disabledMetrics are the names of the metrics of the station's disabled
+	// fields, which are not exported.
+	disabledMetrics map[string]struct{}
+}
+
+// staleIntervals is the number of expected intervals without a submission
+// after which a station is considered down.
+const staleIntervals = 2
+
+// setStations replaces the exporter's per-station configuration.
+func (e *Exporter) setStations(scs map[string]config.Station) error {
+	stations, err := newStationConfigs(scs)
+	if err != nil {
+		return err
+	}
+	e.cfgMu.Lock()
+	e.stations = stations
+	e.cfgMu.Unlock()
+	return nil
+}
+
+// newStationConfigs returns the exporter's per-station configuration.
+func newStationConfigs(scs map[string]config.Station) (map[string]stationConfig, error) {
+	stations := make(map[string]stationConfig, len(scs))
+	for id, sc := range scs {
+		loc, err := sc.Location()
+		if err != nil {
+			return nil, fmt.Errorf("station %q: timezone: %w", id, err)
+		}
+		c := stationConfig{
+			location:         loc,
+			receiveTime:      sc.ReceiveTime,
+			expectedInterval: sc.ExpectedInterval,
+			rapidFireWindow:  sc.RapidFireWindow,
+		}
+		for _, name := range sc.DisabledFields {
+			i := slices.IndexFunc(fields, func(f field) bool { return f.name == name })
+			if i < 0 {
+				return nil, fmt.Errorf("station %q: unknown disabled field %q", id, name)
+			}
+			if c.disabledMetrics == nil {
+				c.disabledMetrics = make(map[string]struct{})
+			}
+			for _, metric := range fields[i].metrics {
+				c.disabledMetrics[metric] = struct{}{}
+			}
+		}
+		c.response = newResponse(sc.Response)
+		c.rapidFireResponse = newResponse(sc.RapidFireResponse)
+		stations[id] = c
+	}
+	return stations, nil
+}
+
+// stationConfig returns the configuration of a station.
+func (e *Exporter) stationConfig(stationID string) stationConfig {
+	e.cfgMu.RLock()
+	defer e.cfgMu.RUnlock()
+	return e.stations[stationID]
+}
+
+// staleAfter returns the duration since a station's last submission after
+// which it is considered down, or zero if the station has no expected
+// interval.
+func (e *Exporter) staleAfter(stationID string) time.Duration {
+	return staleIntervals * e.stationConfig(stationID).expectedInterval
+}
+
+// newResponse returns the WU response for a configured response, or nil if the
+// response is not configured.
+func newResponse(r *config.StationResponse) *wu.Response {
+	if r == nil {
+		return nil
+	}
+	resp := &wu.Response{Status: r.Status, Body: r.Body}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	return resp
+}
+
+// stationResponse returns the response sent to a station for accepted
+// standard or RapidFire submissions, if the station has a custom response.
+func (e *Exporter) stationResponse(stationID string, rapidFire bool) (wu.Response, bool) {
+	sc := e.stationConfig(stationID)
+	r := sc.response
+	if rapidFire && sc.rapidFireResponse != nil {
+		r = sc.rapidFireResponse
+	}
+	if r == nil {
+		return wu.Response{}, false
+	}
+	return *r, true
+}
+
+// newDay returns whether t is on a later local day than prev for a station
+// with a configured time zone. It always returns false for stations without
+// a time zone, or if prev is zero.
+func (e *Exporter) newDay(stationID string, prev, t time.Time) bool {
+	loc := e.stationConfig(stationID).location
+	if loc == nil || prev.IsZero() {
+		return false
+	}
+	py, pm, pd := prev.In(loc).Date()
+	y, m, d := t.In(loc).Date()
+	return y != py || m != pm || d != pd
+}
+
+// setObservationTime replaces the observation time with the time the
+// observation was received, if the station is configured to ignore the time
+// it reports. This must be done before the observation is processed, so that
+// ordering, staleness and timestamps all use the receive time.
+func (e *Exporter) setObservationTime(o *weather.Observation) {
+	if e.stationConfig(o.StationID).receiveTime {
+		o.Measurement.DateUTC = o.ReceivedAt.UTC()
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestRainRolloverTimezone(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Stations: map[string]config.Station{
+			"local": {Timezone: "Pacific/Auckland"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	// 11:00 UTC is midnight in Auckland (UTC+13 in January). Both stations
+	// report a higher daily total after the rollover, because rain fell
+	// between the two submissions, so the rollover can only be detected
+	// from the station's time zone.
+	ts := time.Date(2025, 1, 23, 10, 55, 0, 0, time.UTC)
+	record := func(stationID string, ts time.Time, rain float64) {
+		o := weather.Observation{
+			StationID:   stationID,
+			Measurement: wu.DeviceMeasurement{DateUTC: ts, RainToday: rain},
+		}
+		if err := e.recordObservation(context.Background(), &o); err != nil {
+			t.Fatalf("record observation: %v", err)
+		}
+	}
+
+	tts := []struct {
+		stationID string
+		wantReset bool
+	}{
+		{stationID: "local", wantReset: true},
+		{stationID: "utc", wantReset: false},
+	}
+	for _, tt := range tts {
+		record(tt.stationID, ts, 5)
+		before := e.metrics.Rain.WithLabelValues(tt.stationID)
+		record(tt.stationID, ts.Add(10*time.Minute), 7)
+		after := e.metrics.Rain.WithLabelValues(tt.stationID)
+
+		// The counter is replaced when it is reset.
+		if reset := before != after; reset != tt.wantReset {
+			t.Errorf("station %s: got reset %v, want %v", tt.stationID, reset, tt.wantReset)
+		}
+		var m dto.Metric
+		if err := after.Write(&m); err != nil {
+			t.Fatalf("write metric: %v", err)
+		}
+		if got := m.GetCounter().GetValue(); got != 7 {
+			t.Errorf("station %s: got rain counter %v, want 7", tt.stationID, got)
+		}
+	}
+}
+
+func TestMissingValuesGauges(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	ts := time.Date(2025, 1, 23, 10, 0, 0, 0, time.UTC)
+	record := func(query string) {
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dm, _ := wu.ParseMeasurement(q, ts)
+		ts = ts.Add(5 * time.Minute)
+		o := weather.Observation{StationID: "station", Measurement: dm}
+		if err := e.recordObservation(context.Background(), &o); err != nil {
+			t.Fatalf("record observation: %v", err)
+		}
+	}
+
+	record("tempf=50&humidity=80&dailyrainin=0.5")
+	rain := e.metrics.Rain.WithLabelValues("station")
+	record("tempf=-9999&humidity=255&dailyrainin=-9999")
+
+	// Missing values are not exported, and do not match conditions.
+	for name, g := range map[string]*prometheus.GaugeVec{
+		"temperature": e.metrics.Temperature,
+		"humidity":    e.metrics.Humidity,
+		"condition":   e.metrics.Condition,
+	} {
+		if n := testutil.CollectAndCount(g); n != 0 {
+			t.Errorf("got %d %s gauges, want none", n, name)
+		}
+	}
+
+	// A missing daily rain total does not reset the rain counter, so the
+	// next total is not counted twice.
+	record("tempf=50&humidity=80&dailyrainin=0.6")
+	if e.metrics.Rain.WithLabelValues("station") != rain {
+		t.Error("rain counter was reset")
+	}
+	if got, want := testutil.ToFloat64(rain), 0.6*25.4; math.Abs(got-want) > 1e-9 {
+		t.Errorf("got rain counter %v, want %v", got, want)
+	}
+}
+
+func TestSetObservationTime(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Stations: map[string]config.Station{
+			"bogus": {ReceiveTime: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	reported := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	received := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	for id, want := range map[string]time.Time{"bogus": received, "other": reported} {
+		o := weather.Observation{
+			StationID:   id,
+			ReceivedAt:  received,
+			Measurement: wu.DeviceMeasurement{DateUTC: reported},
+		}
+		e.setObservationTime(&o)
+		if !o.Measurement.DateUTC.Equal(want) {
+			t.Errorf("station %s: got time %v, want %v", id, o.Measurement.DateUTC, want)
+		}
+	}
+}
+
+func TestSubmissionLatency(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	received := time.Date(2025, 1, 23, 23, 0, 30, 0, time.UTC)
+	for id, reported := range map[string]time.Time{
+		"buffered": received.Add(-5 * time.Minute),
+		"ahead":    received.Add(10 * time.Second),
+		"now":      received,
+	} {
+		e.setSubmissionLatency(wu.Submission{
+			StationID:   id,
+			ReceivedAt:  received,
+			Measurement: wu.DeviceMeasurement{DateUTC: reported},
+		})
+	}
+
+	for id, want := range map[string]float64{"buffered": 300, "ahead": -10} {
+		var m dto.Metric
+		if err := e.metrics.SubmissionLatency.WithLabelValues(id).Write(&m); err != nil {
+			t.Fatalf("write metric: %v", err)
+		}
+		if got := m.GetGauge().GetValue(); got != want {
+			t.Errorf("station %s: got latency %v, want %v", id, got, want)
+		}
+	}
+	if e.metrics.SubmissionLatency.DeleteLabelValues("now") {
+		t.Error("latency should not be set without a reported observation time")
+	}
+}
+
+func TestNormalizeStationID(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Tenants: []config.Tenant{
+			{Name: "a", Username: "a", Password: "a", Stations: []string{"garden"}},
+		},
+		Stations: map[string]config.Station{
+			"ktest1":   {},
+			"backyard": {},
+		},
+		StationIDs: config.StationIDNormalization{
+			Lowercase: true,
+			Rewrite: []config.StationIDRewrite{
+				{Match: "wh65-[0-9a-f]+", Replacement: "backyard"},
+				{Match: "wh31-([0-9])-[0-9a-f]+", Replacement: "garden"},
+				{Match: "wh65", Replacement: "ignored"},
+			},
+			RejectUnknown: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	tts := []struct {
+		stationID string
+		want      string
+		wantOK    bool
+	}{
+		{stationID: "KTEST1", want: "ktest1", wantOK: true},
+		{stationID: "WH65-3F9A", want: "backyard", wantOK: true},
+		{stationID: "WH31-1-07", want: "garden", wantOK: true},
+		{stationID: "xWH65-3F9A", want: "xwh65-3f9a", wantOK: false},
+		{stationID: "KTEST2", want: "ktest2", wantOK: false},
+	}
+	for _, tt := range tts {
+		got, ok := e.normalizeStationID(tt.stationID)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: got %q (ok %v), want %q (ok %v)", tt.stationID, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestStationUp(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Stations: map[string]config.Station{
+			"up":   {ExpectedInterval: time.Minute},
+			"down": {ExpectedInterval: time.Minute},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	now := time.Now()
+	for id, receivedAt := range map[string]time.Time{
+		"up":        now.Add(-90 * time.Second),
+		"down":      now.Add(-3 * time.Minute),
+		"unwatched": now.Add(-time.Hour),
+	} {
+		e.history.add(weather.Observation{StationID: id, ReceivedAt: receivedAt})
+	}
+
+	mfs, err := e.registry.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	got := make(map[string]float64)
+	for _, mf := range mfs {
+		if mf.GetName() != "weather_station_up" {
+			continue
+		}
+		for _, m := range mf.Metric {
+			got[labelValue(m, "station_id")] = m.GetGauge().GetValue()
+		}
+	}
+	want := map[string]float64{"up": 1, "down": 0}
+	if len(got) != len(want) || got["up"] != want["up"] || got["down"] != want["down"] {
+		t.Errorf("got station up %v, want %v", got, want)
+	}
+}
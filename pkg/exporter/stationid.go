@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+// stationIDRewrite replaces station IDs matching a regular expression.
+type stationIDRewrite struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// stationIDNormalizer normalizes the IDs of submitting stations.
+type stationIDNormalizer struct {
+	lowercase     bool
+	rewrite       []stationIDRewrite
+	rejectUnknown bool
+}
+
+// setStationIDNormalization replaces the exporter's station ID normalization.
+func (e *Exporter) setStationIDNormalization(c config.StationIDNormalization) error {
+	n, err := newStationIDNormalizer(c)
+	if err != nil {
+		return err
+	}
+	e.cfgMu.Lock()
+	e.stationIDs = n
+	e.cfgMu.Unlock()
+	return nil
+}
+
+// newStationIDNormalizer returns a station ID normalizer for the given
+// configuration.
+func newStationIDNormalizer(c config.StationIDNormalization) (stationIDNormalizer, error) {
+	n := stationIDNormalizer{
+		lowercase:     c.Lowercase,
+		rejectUnknown: c.RejectUnknown,
+	}
+	for i, r := range c.Rewrite {
+		// The expression must match the whole station ID.
+		re, err := regexp.Compile("^(?:" + r.Match + ")$")
+		if err != nil {
+			return stationIDNormalizer{}, fmt.Errorf("normalize station IDs: rewrite[%d]: %w", i, err)
+		}
+		n.rewrite = append(n.rewrite, stationIDRewrite{re: re, replacement: r.Replacement})
+	}
+	return n, nil
+}
+
+// normalizeStationID returns the normalized ID of a submitting station, and
+// false if submissions from the station should be rejected because it is
+// unknown.
+func (e *Exporter) normalizeStationID(stationID string) (string, bool) {
+	e.cfgMu.RLock()
+	defer e.cfgMu.RUnlock()
+
+	n := e.stationIDs
+	if n.lowercase {
+		stationID = strings.ToLower(stationID)
+	}
+	for _, r := range n.rewrite {
+		if r.re.MatchString(stationID) {
+			stationID = r.re.ReplaceAllString(stationID, r.replacement)
+			break
+		}
+	}
+	if n.rejectUnknown {
+		_, configured := e.stations[stationID]
+		_, inTenant := e.stationTenants[stationID]
+		if !configured && !inTenant {
+			return stationID, false
+		}
+	}
+	return stationID, true
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// submissionRateWindow is the time window used to calculate station
+// submission rates.
+const submissionRateWindow = 10 * time.Minute
+
+// Status is a snapshot of the exporter status.
+type Status struct {
+	Maintenance bool             `json:"maintenance"`
+	Listeners   []ListenerStatus `json:"listeners"`
+	Sinks       []SinkStatus     `json:"sinks"`
+	Stations    []StationStatus  `json:"stations"`
+}
+
+// ListenerStatus is the status of a server listener.
+type ListenerStatus struct {
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	Listening bool   `json:"listening"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SinkStatus is the health of a component that observations are written to.
+type SinkStatus struct {
+	Name        string    `json:"name"`
+	Enabled     bool      `json:"enabled"`
+	LastSuccess time.Time `json:"last_success"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at"`
+}
+
+// StationStatus is the status of a weather station.
+type StationStatus struct {
+	StationID string    `json:"station_id"`
+	LastSeen  time.Time `json:"last_seen"`
+
+	// SubmissionsPerMinute is the average submission rate over the past
+	// ten minutes.
+	SubmissionsPerMinute float64 `json:"submissions_per_minute"`
+
+	Latest weather.Observation `json:"latest"`
+}
+
+// listeners tracks the status of the exporter's listeners.
+type listeners struct {
+	mu       sync.Mutex
+	statuses map[string]*ListenerStatus
+}
+
+// set updates the status of the named listener.
+func (l *listeners) set(name, address string, listening bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.statuses == nil {
+		l.statuses = make(map[string]*ListenerStatus)
+	}
+	s := &ListenerStatus{Name: name, Address: address, Listening: listening}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	l.statuses[name] = s
+}
+
+// snapshot returns the status of all listeners, ordered by name.
+func (l *listeners) snapshot() []ListenerStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]ListenerStatus, 0, len(l.statuses))
+	for _, s := range l.statuses {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// sinkHealth tracks the result of writes to a sink, and whether the sink has
+// been disabled at runtime.
+type sinkHealth struct {
+	mu       sync.Mutex
+	status   SinkStatus
+	disabled atomic.Bool
+}
+
+// enabled returns whether the sink is enabled.
+func (h *sinkHealth) enabled() bool {
+	return !h.disabled.Load()
+}
+
+// record records the result of a write to the sink.
+func (h *sinkHealth) record(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err != nil {
+		h.status.LastError = err.Error()
+		h.status.LastErrorAt = time.Now()
+		return
+	}
+	h.status.LastSuccess = time.Now()
+}
+
+// snapshot returns the sink status.
+func (h *sinkHealth) snapshot(name string) SinkStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.status
+	s.Name = name
+	s.Enabled = h.enabled()
+	return s
+}
+
+// Status returns the status of the exporter. Only stations that the request
+// is authorized to access are included.
+func (e *Exporter) Status(r *http.Request) Status {
+	return e.status(func(stationID string) bool {
+		return e.authorized(r, stationID)
+	})
+}
+
+// status returns the status of the exporter, including only the stations for
+// which include returns true.
+func (e *Exporter) status(include func(stationID string) bool) Status {
+	s := Status{
+		Maintenance: e.maintenance.Load(),
+		Listeners:   e.listeners.snapshot(),
+	}
+	if e.store != nil {
+		s.Sinks = append(s.Sinks, e.storeHealth.snapshot("store"))
+	}
+	if e.journal != nil {
+		s.Sinks = append(s.Sinks, e.journalHealth.snapshot("journal"))
+	}
+	if e.statePath != "" {
+		s.Sinks = append(s.Sinks, e.stateHealth.snapshot("state"))
+	}
+	e.sinks.mu.RLock()
+	for _, rs := range e.sinks.sinks {
+		s.Sinks = append(s.Sinks, rs.health.snapshot(rs.name))
+	}
+	e.sinks.mu.RUnlock()
+
+	now := time.Now()
+	for _, stationID := range e.history.stationIDs() {
+		if !include(stationID) {
+			continue
+		}
+		latest, ok := e.history.latest(stationID)
+		if !ok {
+			continue
+		}
+		recent := e.history.since(stationID, now.Add(-submissionRateWindow))
+		s.Stations = append(s.Stations, StationStatus{
+			StationID:            stationID,
+			LastSeen:             latest.ReceivedAt,
+			SubmissionsPerMinute: float64(len(recent)) / submissionRateWindow.Minutes(),
+			Latest:               latest,
+		})
+	}
+	sort.Slice(s.Stations, func(i, j int) bool {
+		return s.Stations[i].StationID < s.Stations[j].StationID
+	})
+	return s
+}
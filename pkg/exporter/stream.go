@@ -0,0 +1,173 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// streamWriteTimeout is the maximum time to wait for a stream message to be
+// written to a client.
+const streamWriteTimeout = 10 * time.Second
+
+// streamHeartbeatInterval is the interval between heartbeats sent to idle
+// Server-Sent Events clients.
+const streamHeartbeatInterval = 30 * time.Second
+
+// apiStreamObservation is an observation sent to live stream clients.
+type apiStreamObservation struct {
+	StationID   string         `json:"station_id"`
+	Observation apiObservation `json:"observation"`
+}
+
+// handleStream streams new observations to a client, using a WebSocket if the
+// client requested a connection upgrade, otherwise Server-Sent Events. The
+// stream can be limited to a single station using the "station" query
+// parameter.
+func (e *Exporter) handleStream(w http.ResponseWriter, r *http.Request) {
+	stationID := r.URL.Query().Get("station")
+	if stationID != "" && !e.authorized(r, stationID) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="pws_exporter"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		e.streamWebSocket(w, r, stationID)
+		return
+	}
+	e.streamEvents(w, r, stationID)
+}
+
+// streamWebSocket streams new observations to a WebSocket client.
+func (e *Exporter) streamWebSocket(w http.ResponseWriter, r *http.Request, stationID string) {
+	websocket.Server{Handshake: checkSameOrigin, Handler: func(ws *websocket.Conn) {
+		// Detect the client closing the connection. Messages sent by the
+		// client are ignored.
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			var msg []byte
+			for websocket.Message.Receive(ws, &msg) == nil {
+			}
+		}()
+
+		e.stream(r, stationID, closed, nil, func(o *apiStreamObservation) error {
+			_ = ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			return websocket.JSON.Send(ws, o)
+		})
+	}}.ServeHTTP(w, r)
+}
+
+// checkSameOrigin rejects WebSocket handshakes from browsers on other origins,
+// which would otherwise be able to read the stream using the credentials that
+// the browser has cached for the exporter. Clients that do not send an Origin
+// header (i.e. not browsers) are allowed.
+func checkSameOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(u.Host, r.Host) {
+		return fmt.Errorf("cross-origin request from %s", origin)
+	}
+	config.Origin = u
+	return nil
+}
+
+// streamEvents streams new observations to a Server-Sent Events client.
+func (e *Exporter) streamEvents(w http.ResponseWriter, r *http.Request, stationID string) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	write := func(b []byte) error {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	e.stream(r, stationID, r.Context().Done(), heartbeat.C, func(o *apiStreamObservation) error {
+		if o == nil {
+			// Comment lines keep idle connections open through proxies.
+			return write([]byte(": heartbeat\n\n"))
+		}
+		b, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+		return write(fmt.Appendf(nil, "event: observation\ndata: %s\n\n", b))
+	})
+}
+
+// stream sends new observations from the hub using send until closed is
+// closed, the exporter is shut down, or send returns an error. Observations
+// from stations the request is not authorized to access are skipped. If
+// heartbeat is not nil, send is called with nil each time it receives a value.
+func (e *Exporter) stream(r *http.Request, stationID string, closed <-chan struct{}, heartbeat <-chan time.Time, send func(*apiStreamObservation) error) {
+	sub := e.hub.subscribe(stationID)
+	defer e.hub.unsubscribe(sub)
+
+	for {
+		var msg *apiStreamObservation
+		select {
+		case <-closed:
+			return
+		case <-e.supervisor.ctx.Done():
+			// The exporter is shutting down.
+			return
+		case <-heartbeat:
+		case o := <-sub.c:
+			if !e.authorized(r, o.StationID) {
+				continue
+			}
+			msg = &apiStreamObservation{
+				StationID:   o.StationID,
+				Observation: newAPIObservation(o),
+			}
+		}
+		if err := send(msg); err != nil {
+			slog.Debug("Failed to write to stream",
+				slog.String("remote_addr", r.RemoteAddr), slog.Any("err", err))
+			return
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// publishWhenSubscribed waits for a stream client to subscribe to the hub,
+// then publishes an observation.
+func publishWhenSubscribed(t *testing.T, e *Exporter, o weather.Observation) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		e.hub.mu.Lock()
+		n := len(e.hub.subs)
+		e.hub.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("client was not subscribed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	e.hub.publish(o)
+}
+
+func TestStreamWebSocket(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+	srv := httptest.NewServer(http.HandlerFunc(e.handleStream))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	tts := []struct {
+		name    string
+		origin  string
+		wantErr bool
+	}{
+		{name: "same origin", origin: srv.URL},
+		{name: "cross origin", origin: "http://example.com", wantErr: true},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, err := websocket.Dial(wsURL, "", tt.origin)
+			if tt.wantErr {
+				if err == nil {
+					ws.Close()
+					t.Fatal("dial succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer ws.Close()
+
+			publishWhenSubscribed(t, e, weather.Observation{
+				StationID:   "a",
+				Measurement: wu.DeviceMeasurement{Temperature: 17.5},
+			})
+			_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+			var got apiStreamObservation
+			if err := websocket.JSON.Receive(ws, &got); err != nil {
+				t.Fatalf("receive: %v", err)
+			}
+			if got.StationID != "a" {
+				t.Errorf("got station %q, want a", got.StationID)
+			}
+		})
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+	srv := httptest.NewServer(http.HandlerFunc(e.handleStream))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?station=a")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("got content type %q, want text/event-stream", ct)
+	}
+
+	// Observations from other stations are not streamed.
+	publishWhenSubscribed(t, e, weather.Observation{StationID: "b"})
+	e.hub.publish(weather.Observation{StationID: "a"})
+
+	r := bufio.NewReader(resp.Body)
+	event, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	if event != "event: observation\n" {
+		t.Fatalf("got event %q, want observation", event)
+	}
+	data, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("read data: %v", err)
+	}
+	var got apiStreamObservation
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", data, err)
+	}
+	if got.StationID != "a" {
+		t.Errorf("got station %q, want a", got.StationID)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sunshineThreshold is the WMO threshold of direct solar radiation, in W/m²,
+// above which the sun is considered to be shining.
+const sunshineThreshold = 120
+
+// sunshineState stores the sunshine duration of the current day of each
+// station.
+type sunshineState struct {
+	mu    sync.Mutex
+	today map[string]float64
+}
+
+// updateSunshine updates the sunshine duration counter of a station from an
+// observation taken at t with the given solar radiation, and the time of the
+// station's previous observation. The time since the previous observation is
+// counted as sunshine if the solar radiation is at or above the WMO threshold.
+// The counter is reset on the first observation of a new day in the station's
+// time zone, or UTC if it has none.
+//
+// If the station measures the sunshine duration itself and reports the day's
+// total, the reported total is used instead. The counter is also reset if the
+// reported total decreases, as the station's day has rolled over.
+func (e *Exporter) updateSunshine(m *Metrics, l prometheus.Labels, stationID string, prev, t time.Time, radiation float64, reported *float64) {
+	e.sunshine.mu.Lock()
+	defer e.sunshine.mu.Unlock()
+
+	loc := e.stationConfig(stationID).location
+	if loc == nil {
+		loc = time.UTC
+	}
+	today, ok := e.sunshine.today[stationID]
+	if !ok || prev.IsZero() || !sameDay(prev, t, loc) {
+		m.Sunshine.Delete(l)
+		today = 0
+	}
+
+	if reported != nil && *reported < today {
+		m.Sunshine.Delete(l)
+		today = 0
+	}
+
+	var sunshine float64
+	switch elapsed, ok := observationInterval(prev, t); {
+	case reported != nil:
+		sunshine = *reported - today
+	case ok && radiation >= sunshineThreshold:
+		sunshine = elapsed.Seconds()
+	}
+	m.Sunshine.With(l).Add(sunshine)
+	e.sunshine.today[stationID] = today + sunshine
+}
+
+// restoreSunshine restores the sunshine duration counter of a station.
+func (e *Exporter) restoreSunshine(m *Metrics, l prometheus.Labels, stationID string, today float64) {
+	e.sunshine.mu.Lock()
+	defer e.sunshine.mu.Unlock()
+
+	m.Sunshine.Delete(l)
+	m.Sunshine.With(l).Add(today)
+	e.sunshine.today[stationID] = today
+}
+
+// delete removes the sunshine duration of a station.
+func (s *sunshineState) delete(stationID string) {
+	s.mu.Lock()
+	delete(s.today, stationID)
+	s.mu.Unlock()
+}
+
+// sameDay returns whether a and b are on the same day in loc.
+func sameDay(a, b time.Time, loc *time.Location) bool {
+	ay, am, ad := a.In(loc).Date()
+	by, bm, bd := b.In(loc).Date()
+	return ay == by && am == bm && ad == bd
+}
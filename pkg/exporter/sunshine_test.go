@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestSunshine(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	start := time.Date(2025, 6, 21, 23, 0, 0, 0, time.UTC)
+	tts := []struct {
+		minutes   int
+		radiation float64
+		want      float64
+	}{
+		{minutes: 0, radiation: 500, want: 0},
+		{minutes: 5, radiation: 500, want: 300},
+		{minutes: 10, radiation: 80, want: 300},  // Below the threshold
+		{minutes: 40, radiation: 800, want: 300}, // Station was offline
+		{minutes: 45, radiation: 800, want: 600},
+		{minutes: 65, radiation: 800, want: 0}, // New day
+		{minutes: 70, radiation: 120, want: 300},
+	}
+	for _, tt := range tts {
+		o := weather.Observation{
+			StationID: "KTEST1",
+			Measurement: wu.DeviceMeasurement{
+				DateUTC:        start.Add(time.Duration(tt.minutes) * time.Minute),
+				SolarRadiation: tt.radiation,
+			},
+		}
+		if err := e.recordObservation(context.Background(), &o); err != nil {
+			t.Fatalf("record observation: %v", err)
+		}
+		var m dto.Metric
+		if err := e.metrics.Sunshine.WithLabelValues("KTEST1").Write(&m); err != nil {
+			t.Fatalf("write metric: %v", err)
+		}
+		if got := m.GetCounter().GetValue(); got != tt.want {
+			t.Errorf("after %d minutes: got sunshine %v, want %v", tt.minutes, got, tt.want)
+		}
+	}
+}
+
+func TestSunshineNoRadiation(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	start := time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		o := weather.Observation{
+			StationID: "KTEST1",
+			Measurement: wu.DeviceMeasurement{
+				DateUTC:     start.Add(time.Duration(i) * 5 * time.Minute),
+				Temperature: 68,
+				Missing:     []string{"solarradiation"},
+			},
+		}
+		if err := e.recordObservation(context.Background(), &o); err != nil {
+			t.Fatalf("record observation: %v", err)
+		}
+	}
+	if n := testutil.CollectAndCount(e.metrics.Sunshine); n != 0 {
+		t.Errorf("got %d sunshine series, want 0", n)
+	}
+	if _, ok := e.sunshine.today["KTEST1"]; ok {
+		t.Error("sunshine duration recorded for a station without solar radiation")
+	}
+}
+
+func TestReportedSunshine(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	start := time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC)
+	tts := []struct {
+		minutes  int
+		reported float64
+		want     float64
+	}{
+		{minutes: 0, reported: 3600, want: 3600},
+		{minutes: 5, reported: 3900, want: 3900},
+		{minutes: 10, reported: 3900, want: 3900}, // Radiation is ignored
+		{minutes: 15, reported: 0, want: 0},       // Station's day rolled over
+		{minutes: 20, reported: 300, want: 300},
+	}
+	for _, tt := range tts {
+		reported := tt.reported
+		o := weather.Observation{
+			StationID: "KTEST1",
+			Measurement: wu.DeviceMeasurement{
+				DateUTC:        start.Add(time.Duration(tt.minutes) * time.Minute),
+				SolarRadiation: 800,
+				SunshineToday:  &reported,
+			},
+		}
+		if err := e.recordObservation(context.Background(), &o); err != nil {
+			t.Fatalf("record observation: %v", err)
+		}
+		var m dto.Metric
+		if err := e.metrics.Sunshine.WithLabelValues("KTEST1").Write(&m); err != nil {
+			t.Fatalf("write metric: %v", err)
+		}
+		if got := m.GetCounter().GetValue(); got != tt.want {
+			t.Errorf("after %d minutes: got sunshine %v, want %v", tt.minutes, got, tt.want)
+		}
+	}
+}
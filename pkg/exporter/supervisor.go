@@ -0,0 +1,278 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/netutil"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+const (
+	// shutdownTimeout is the maximum time to wait for a listener to shut
+	// down gracefully.
+	shutdownTimeout = 3 * time.Second
+
+	// minRestartBackoff and maxRestartBackoff are the bounds of the
+	// exponential backoff used when restarting failed listeners.
+	minRestartBackoff = time.Second
+	maxRestartBackoff = time.Minute
+)
+
+// listenConfig creates the listeners of services. It is implemented by
+// net.ListenConfig, and by activatedSockets for listeners passed by systemd.
+type listenConfig interface {
+	Listen(ctx context.Context, network, address string) (net.Listener, error)
+	ListenPacket(ctx context.Context, network, address string) (net.PacketConn, error)
+}
+
+// service is a listener managed by the supervisor.
+type service struct {
+	name    string
+	address string
+
+	// serve listens on the address and serves until ctx is done, in which
+	// case it shuts down gracefully and returns nil, or the listener fails.
+	// The listener is created using lc. ready is called once the listener is
+	// listening.
+	serve func(ctx context.Context, lc listenConfig, ready func(addr string)) error
+}
+
+// supervisor starts and stops the exporter's listeners independently, and
+// restarts listeners that fail with an exponential backoff.
+type supervisor struct {
+	listeners    *listeners
+	listenConfig net.ListenConfig
+	sockets      *activatedSockets
+	up           *prometheus.GaugeVec
+	restarts     *prometheus.CounterVec
+
+	// minBackoff and maxBackoff are the bounds of the restart backoff.
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newSupervisor(namespace string, l *listeners, lc net.ListenConfig, reg prometheus.Registerer) *supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &supervisor{
+		listeners:    l,
+		listenConfig: lc,
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "listener",
+			Name:      "up",
+			Help:      "Whether the listener is listening (1) or not (0)",
+		}, []string{"listener"}),
+		restarts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "listener",
+			Name:      "restarts_total",
+			Help:      "Total number of times the listener has been restarted after failing",
+		}, []string{"listener"}),
+		minBackoff: minRestartBackoff,
+		maxBackoff: maxRestartBackoff,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	reg.MustRegister(s.up, s.restarts)
+	return s
+}
+
+// start starts the service in a new goroutine.
+func (s *supervisor) start(svc service) {
+	s.listeners.set(svc.name, svc.address, false, nil)
+	s.up.WithLabelValues(svc.name).Set(0)
+	s.restarts.WithLabelValues(svc.name)
+
+	s.wg.Add(1)
+	go s.run(svc)
+}
+
+// run runs the service until the supervisor is stopped, restarting it when it
+// fails.
+func (s *supervisor) run(svc service) {
+	defer s.wg.Done()
+
+	up := s.up.WithLabelValues(svc.name)
+	backoff := s.minBackoff
+	for {
+		started := time.Now()
+		addr := svc.address
+		var lc listenConfig = &s.listenConfig
+		if s.sockets != nil {
+			lc = s.sockets.listenConfig(svc.name, &s.listenConfig)
+		}
+		err := svc.serve(s.ctx, lc, func(a string) {
+			addr = a
+			slog.Info("Listener started",
+				slog.String("listener", svc.name), slog.String("address", a))
+			s.listeners.set(svc.name, a, true, nil)
+			up.Set(1)
+		})
+		up.Set(0)
+		if s.ctx.Err() != nil {
+			s.listeners.set(svc.name, addr, false, nil)
+			return
+		}
+		if err == nil {
+			err = errors.New("listener stopped unexpectedly")
+		}
+		s.listeners.set(svc.name, addr, false, err)
+
+		// Reset the backoff if the listener was running for a while.
+		if time.Since(started) > s.maxBackoff {
+			backoff = s.minBackoff
+		}
+		slog.Error("Listener failed, restarting",
+			slog.String("listener", svc.name), slog.Duration("backoff", backoff),
+			slog.Any("err", err))
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		s.restarts.WithLabelValues(svc.name).Inc()
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+}
+
+// wait waits until all services have stopped.
+func (s *supervisor) wait() {
+	s.wg.Wait()
+}
+
+// stop stops all services and waits for them to shut down. Activated sockets
+// are closed.
+func (s *supervisor) stop() {
+	s.cancel()
+	s.wg.Wait()
+	if s.sockets != nil {
+		if err := s.sockets.close(); err != nil {
+			slog.Warn("Failed to close activated sockets", slog.Any("err", err))
+		}
+	}
+}
+
+// serveUntilDone calls serve, and calls shutdown once ctx is done. If serve
+// returns before ctx is done, its error is returned.
+func serveUntilDone(ctx context.Context, serve func() error, shutdown func(ctx context.Context) error) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- serve()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := shutdown(sctx); err != nil {
+		slog.Warn("Failed to shut down listener gracefully", slog.Any("err", err))
+	}
+	<-errc
+	return nil
+}
+
+// httpService returns a service that serves the handler over HTTP, or HTTPS
+// if tlsConfig is not nil. If plaintext is true, plaintext HTTP is also served
+// on the same listener as HTTPS. Zero limits are not applied.
+func httpService(name, address string, handler http.Handler, tlsConfig *tls.Config, plaintext bool, limits config.HTTPServer) service {
+	if limits.MaxBodyBytes > 0 {
+		handler = http.MaxBytesHandler(handler, limits.MaxBodyBytes)
+	}
+	return service{
+		name:    name,
+		address: address,
+		serve: func(ctx context.Context, lc listenConfig, ready func(addr string)) error {
+			ln, err := lc.Listen(ctx, "tcp", address)
+			if err != nil {
+				return err
+			}
+			if limits.MaxConnections > 0 {
+				ln = netutil.LimitListener(ln, limits.MaxConnections)
+			}
+			switch {
+			case tlsConfig != nil && plaintext:
+				ln = newMuxListener(ln, tlsConfig)
+			case tlsConfig != nil:
+				ln = tls.NewListener(ln, tlsConfig)
+			}
+			srv := &http.Server{
+				Handler:           handler,
+				ReadHeaderTimeout: 5 * time.Second,
+				ReadTimeout:       limits.ReadTimeout,
+				WriteTimeout:      limits.WriteTimeout,
+				IdleTimeout:       limits.IdleTimeout,
+				MaxHeaderBytes:    limits.MaxHeaderBytes,
+			}
+			ready(ln.Addr().String())
+			return serveUntilDone(ctx, func() error {
+				return srv.Serve(ln)
+			}, srv.Shutdown)
+		},
+	}
+}
+
+// dnsService returns a service that serves the DNS server for the listener.
+// The server is added to servers while it is running, so that its records are
+// updated when the DNS configuration is reloaded.
+func dnsService(l dnsListener, servers *dnsServers) service {
+	return service{
+		name:    l.name,
+		address: l.address,
+		serve: func(ctx context.Context, lc listenConfig, ready func(addr string)) error {
+			pc, err := lc.ListenPacket(ctx, "udp", l.address)
+			if err != nil {
+				return err
+			}
+			srv := servers.add(l, func() {
+				ready(pc.LocalAddr().String())
+			})
+			defer servers.remove(srv)
+			return serveUntilDone(ctx, func() error {
+				return srv.Serve(pc)
+			}, func(ctx context.Context) error {
+				if err := srv.Shutdown(ctx); err != nil {
+					// The server may not have started yet.
+					return pc.Close()
+				}
+				return nil
+			})
+		},
+	}
+}
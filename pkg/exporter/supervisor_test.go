@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSupervisorRestart(t *testing.T) {
+	s := newSupervisor("test", &listeners{}, net.ListenConfig{}, prometheus.NewRegistry())
+	s.minBackoff = 20 * time.Millisecond
+	s.maxBackoff = 80 * time.Millisecond
+
+	// The service fails immediately 4 times, then fails after running for
+	// longer than the maximum backoff, then keeps running.
+	const failures = 5
+	var (
+		mu      sync.Mutex
+		starts  []time.Time
+		stops   []time.Time
+		running = make(chan struct{})
+	)
+	s.start(service{
+		name: "test",
+		serve: func(ctx context.Context, _ listenConfig, ready func(addr string)) error {
+			mu.Lock()
+			starts = append(starts, time.Now())
+			n := len(starts)
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				stops = append(stops, time.Now())
+				mu.Unlock()
+			}()
+
+			ready("127.0.0.1:0")
+			switch {
+			case n < failures:
+				return errors.New("listen failed")
+			case n == failures:
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(s.maxBackoff + 20*time.Millisecond):
+				}
+				return errors.New("serve failed")
+			}
+			close(running)
+			<-ctx.Done()
+			return nil
+		},
+	})
+
+	select {
+	case <-running:
+	case <-time.After(5 * time.Second):
+		t.Fatal("service was not restarted")
+	}
+	if got := testutil.ToFloat64(s.restarts.WithLabelValues("test")); got != failures {
+		t.Errorf("restarts got %v, want %d", got, failures)
+	}
+	if got := testutil.ToFloat64(s.up.WithLabelValues("test")); got != 1 {
+		t.Errorf("up got %v, want 1", got)
+	}
+
+	// The backoff doubles up to the maximum, and is reset once the service
+	// has run for longer than the maximum backoff.
+	mu.Lock()
+	backoffs := []time.Duration{20, 40, 80, 80, 20}
+	for i, want := range backoffs {
+		want *= time.Millisecond
+		got := starts[i+1].Sub(stops[i])
+		if got < want || got >= 2*want {
+			t.Errorf("restart %d: got backoff %v, want %v", i+1, got, want)
+		}
+	}
+	mu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop did not return")
+	}
+	if got := testutil.ToFloat64(s.up.WithLabelValues("test")); got != 0 {
+		t.Errorf("up got %v after stop, want 0", got)
+	}
+}
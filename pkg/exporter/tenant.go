@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+// tenant is an isolated group of stations, with its own registry and scrape
+// credentials.
+type tenant struct {
+	name     string
+	username string
+	password string
+
+	registry *prometheus.Registry
+	metrics  *Metrics
+}
+
+// newTenant creates a new tenant with a separate registry.
+func newTenant(t config.Tenant) *tenant {
+	reg := prometheus.NewRegistry()
+	return &tenant{
+		name:     t.Name,
+		username: t.Username,
+		password: t.Password,
+		registry: reg,
+		metrics:  newMetrics("weather", reg),
+	}
+}
+
+// authenticate returns whether the given credentials match the tenant's.
+func (t *tenant) authenticate(username, password string) bool {
+	return credentialsMatch(username, password, t.username, t.password)
+}
+
+// credentialsMatch returns whether the given credentials match the wanted
+// credentials, using constant time comparisons.
+func credentialsMatch(username, password, wantUsername, wantPassword string) bool {
+	userMatch := subtle.ConstantTimeCompare([]byte(username), []byte(wantUsername))
+	passMatch := subtle.ConstantTimeCompare([]byte(password), []byte(wantPassword))
+	return userMatch&passMatch == 1
+}
+
+// setTenants replaces the exporter's tenants. Existing tenants with the same
+// name keep their registry, and the series of stations that move to another
+// registry are deleted from the registry they were exported on.
+func (e *Exporter) setTenants(tcs []config.Tenant) {
+	e.cfgMu.Lock()
+	defer e.cfgMu.Unlock()
+
+	existing := make(map[string]*tenant, len(e.tenants))
+	for _, t := range e.tenants {
+		existing[t.name] = t
+	}
+
+	tenants := make([]*tenant, 0, len(tcs))
+	stationTenants := make(map[string]*tenant)
+	for _, tc := range tcs {
+		t, ok := existing[tc.Name]
+		if ok {
+			t.username, t.password = tc.Username, tc.Password
+		} else {
+			t = newTenant(tc)
+			t.registry.MustRegister(&upCollector{e: e, metrics: t.metrics})
+			t.registry.MustRegister(&qualityCollector{e: e, metrics: t.metrics})
+			t.registry.MustRegister(&siteCollector{e: e, metrics: t.metrics})
+		}
+		tenants = append(tenants, t)
+		for _, stationID := range tc.Stations {
+			stationTenants[stationID] = t
+		}
+	}
+
+	for _, stationID := range e.history.stationIDs() {
+		from, to := e.metrics, e.metrics
+		if t, ok := e.stationTenants[stationID]; ok {
+			from = t.metrics
+		}
+		if t, ok := stationTenants[stationID]; ok {
+			to = t.metrics
+		}
+		if from != to {
+			from.deleteStation(stationID)
+			e.rain.delete(stationID)
+			e.sunshine.delete(stationID)
+			e.rainIntensity.delete(stationID)
+		}
+	}
+
+	e.tenants = tenants
+	e.stationTenants = stationTenants
+}
+
+// metricsFor returns the metrics that the given station should be exported
+// on, which is either the metrics of the tenant that the station belongs to,
+// or the default metrics.
+func (e *Exporter) metricsFor(stationID string) *Metrics {
+	e.cfgMu.RLock()
+	defer e.cfgMu.RUnlock()
+
+	if t, ok := e.stationTenants[stationID]; ok {
+		return t.metrics
+	}
+	return e.metrics
+}
+
+// gathererFor returns the gatherer that the request is allowed to scrape.
+// Requests without credentials may scrape the default registry, and requests
+// with tenant credentials may only scrape the tenant registry. If stationID is
+// not empty, only the metrics of the station are gathered. The station ID is
+// the ID the station submits, before the station_id label is relabelled.
+func (e *Exporter) gathererFor(r *http.Request, stationID string) (prometheus.Gatherer, bool) {
+	e.cfgMu.RLock()
+	defer e.cfgMu.RUnlock()
+
+	var g prometheus.Gatherer
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		g = newDisabledFieldsGatherer(e.registry, e.stations)
+		if e.federation != nil {
+			g = &federatedGatherer{gatherer: g, federation: e.federation}
+		}
+	} else {
+		for _, t := range e.tenants {
+			if t.authenticate(username, password) {
+				g = newDisabledFieldsGatherer(t.registry, e.stations)
+				break
+			}
+		}
+		if g == nil {
+			return nil, false
+		}
+	}
+	if stationID != "" {
+		g = &stationGatherer{gatherer: g, stationID: stationID}
+	}
+	return newRelabelGatherer(g, e.relabel), true
+}
+
+// authorized returns whether the request is allowed to access the data for the
+// given station. Stations that belong to a tenant may only be accessed using
+// the tenant's credentials.
+func (e *Exporter) authorized(r *http.Request, stationID string) bool {
+	e.cfgMu.RLock()
+	defer e.cfgMu.RUnlock()
+
+	t, ok := e.stationTenants[stationID]
+	if !ok {
+		return true
+	}
+	username, password, ok := r.BasicAuth()
+	return ok && t.authenticate(username, password)
+}
+
+// MetricsHandler returns a HTTP handler that serves the metrics the request
+// is allowed to scrape. If the request path contains an "id" value, only the
+// metrics for that station are served, identified by the ID the station
+// submits like the other APIs, rather than its relabelled station_id. The
+// metrics of a tenant's station require the tenant's credentials.
+func (e *Exporter) MetricsHandler(opts promhttp.HandlerOpts) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stationID := r.PathValue("id")
+		g, ok := e.gathererFor(r, stationID)
+		if !ok || (stationID != "" && !e.authorized(r, stationID)) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="pws_exporter"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		promhttp.HandlerFor(g, opts).ServeHTTP(w, r)
+	})
+}
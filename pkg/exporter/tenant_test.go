@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+func TestMetricsHandlerTenants(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		Tenants: []config.Tenant{
+			{Name: "a", Username: "a", Password: "pass-a", Stations: []string{"KA1"}},
+			{Name: "b", Username: "b", Password: "pass-b", Stations: []string{"KB1"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+	for _, id := range []string{"KA1", "KB1", "KDEFAULT"} {
+		e.metricsFor(id).Temperature.WithLabelValues(id).Set(10)
+	}
+
+	type creds struct{ username, password string }
+	tts := []struct {
+		name      string
+		stationID string
+		creds     *creds
+		status    int
+		want      []string
+		notWant   []string
+	}{
+		{
+			name:    "unauthenticated",
+			status:  http.StatusOK,
+			want:    []string{"KDEFAULT"},
+			notWant: []string{"KA1", "KB1"},
+		},
+		{
+			name:    "tenant",
+			creds:   &creds{"a", "pass-a"},
+			status:  http.StatusOK,
+			want:    []string{"KA1"},
+			notWant: []string{"KB1", "KDEFAULT"},
+		},
+		{
+			name:   "wrong password",
+			creds:  &creds{"a", "pass-b"},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "unknown user",
+			creds:  &creds{"c", "pass-a"},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:      "tenant station",
+			stationID: "KA1",
+			creds:     &creds{"a", "pass-a"},
+			status:    http.StatusOK,
+			want:      []string{"KA1"},
+		},
+		{
+			name:      "tenant station without credentials",
+			stationID: "KA1",
+			status:    http.StatusUnauthorized,
+		},
+		{
+			name:      "tenant station with other tenant credentials",
+			stationID: "KA1",
+			creds:     &creds{"b", "pass-b"},
+			status:    http.StatusUnauthorized,
+		},
+		{
+			name:      "default station with tenant credentials",
+			stationID: "KDEFAULT",
+			creds:     &creds{"a", "pass-a"},
+			status:    http.StatusOK,
+			notWant:   []string{"KDEFAULT", "KA1"},
+		},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/metrics"
+			if tt.stationID != "" {
+				path += "/station/" + tt.stationID
+			}
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.SetPathValue("id", tt.stationID)
+			if tt.creds != nil {
+				req.SetBasicAuth(tt.creds.username, tt.creds.password)
+			}
+			rec := httptest.NewRecorder()
+			e.MetricsHandler(promhttp.HandlerOpts{}).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status == http.StatusUnauthorized {
+				if rec.Header().Get("WWW-Authenticate") == "" {
+					t.Error("missing WWW-Authenticate header")
+				}
+				return
+			}
+			body := rec.Body.String()
+			for _, id := range tt.want {
+				if !strings.Contains(body, `station_id="`+id+`"`) {
+					t.Errorf("missing metrics for %s:\n%s", id, body)
+				}
+			}
+			for _, id := range tt.notWant {
+				if strings.Contains(body, `station_id="`+id+`"`) {
+					t.Errorf("unexpected metrics for %s", id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// upDesc is the description of the station up metric.
+var upDesc = prometheus.NewDesc(
+	prometheus.BuildFQName("weather", stationSubsystem, "up"),
+	"Whether the station has submitted within twice its expected interval (1) or not (0)",
+	[]string{"station_id"}, nil,
+)
+
+// upCollector is a prometheus.Collector that exports whether each station
+// with an expected interval is up, based on the time it was last seen. Only
+// the stations exported on the given metrics are collected, so that each
+// registry only exports its own stations.
+type upCollector struct {
+	e       *Exporter
+	metrics *Metrics
+}
+
+// Describe implements prometheus.Collector.
+func (c *upCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- upDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *upCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	for _, stationID := range c.e.history.stationIDs() {
+		after := c.e.staleAfter(stationID)
+		if after <= 0 || c.e.metricsFor(stationID) != c.metrics {
+			continue
+		}
+		latest, ok := c.e.history.latest(stationID)
+		if !ok {
+			continue
+		}
+		var up float64
+		if now.Sub(latest.ReceivedAt) <= after {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, up, stationID)
+	}
+}
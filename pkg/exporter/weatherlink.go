@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/vantage"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// handleWeatherLinkIPObservation handles the current conditions of a Davis
+// Vantage console polled from a WeatherLink IP data logger, translated to a WU
+// submission. The data logger's address is used as the remote address.
+func (e *Exporter) handleWeatherLinkIPObservation(ctx context.Context, l vantage.Logger, q url.Values) {
+	stationID, ok := e.normalizeStationID(l.StationID)
+	if !ok {
+		slog.Warn("Dropped WeatherLink IP observation from unknown station",
+			slog.String("station_id", stationID),
+			slog.String("outcome", "rejected"))
+		return
+	}
+
+	remoteAddr, _, err := net.SplitHostPort(l.Client.Address)
+	if err != nil {
+		remoteAddr = l.Client.Address
+	}
+	receivedAt := time.Now()
+	q.Set("ID", stationID)
+	wu.AddDewPoint(q)
+	dm, fieldErrs := wu.ParseMeasurement(q, receivedAt)
+	for _, fe := range fieldErrs {
+		slog.Warn("Ignored invalid WeatherLink IP observation field",
+			slog.String("station_id", stationID),
+			slog.String("param", fe.Param),
+			slog.String("value", fe.Value),
+			slog.Any("err", fe.Err))
+	}
+
+	slog.Debug("Polled weather data for station from WeatherLink IP",
+		slog.String("station_id", stationID),
+		slog.String("outcome", "accepted"))
+
+	e.handleWUSubmission(ctx, wu.Submission{
+		StationID:   stationID,
+		ReceivedAt:  receivedAt,
+		RemoteAddr:  remoteAddr,
+		RawQuery:    q.Encode(),
+		Measurement: dm,
+		Fields:      wu.SubmittedFields(q, fieldErrs),
+		FieldErrors: fieldErrs,
+	})
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/joshuasing/pws_exporter/internal/vantage"
+	"github.com/joshuasing/pws_exporter/pkg/config"
+)
+
+func TestHandleWeatherLinkIPObservation(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		StationIDs: config.StationIDNormalization{Lowercase: true},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	q := url.Values{
+		"action":   {"updateraww"},
+		"dateutc":  {"now"},
+		"tempf":    {"59.7"},
+		"humidity": {"80"},
+	}
+	l := vantage.Logger{StationID: "Vantage", Client: &vantage.Client{Address: "192.0.2.20:22222"}}
+	e.handleWeatherLinkIPObservation(context.Background(), l, q)
+
+	s, ok := e.lastSubmissions.get("vantage")
+	if !ok {
+		t.Fatal("no submission for normalized station ID")
+	}
+	if s.RemoteAddr != "192.0.2.20" {
+		t.Errorf("remote address = %q, want 192.0.2.20", s.RemoteAddr)
+	}
+	if got, want := s.Measurement.Temperature, (59.7-32)*5/9; got != want {
+		t.Errorf("temperature = %v, want %v", got, want)
+	}
+	if s.Measurement.DewPoint == 0 {
+		t.Error("dew point was not derived from temperature and humidity")
+	}
+}
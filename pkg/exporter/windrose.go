@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// calmSpeedKPH is the wind speed below which the wind is calm, and has no
+// direction.
+const calmSpeedKPH = 1
+
+// windRoseSpeedStep is the width of the wind rose speed ranges, in km/h, and
+// windRoseSpeedRanges is the number of ranges. The last range has no upper
+// bound.
+const (
+	windRoseSpeedStep   = 10
+	windRoseSpeedRanges = 6
+)
+
+// compassPoints are the 16 wind rose direction sectors, clockwise from north.
+var compassPoints = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// windRoseLabels returns the direction sector and speed range labels of the
+// wind rose for the wind direction in degrees and speed in km/h.
+func windRoseLabels(direction, speed float64) (string, string) {
+	if speed < calmSpeedKPH {
+		return "calm", "calm"
+	}
+	sectorWidth := 360.0 / float64(len(compassPoints))
+	sector := int(math.Mod(math.Mod(direction+sectorWidth/2, 360)+360, 360) / sectorWidth)
+
+	r := min(int(speed/windRoseSpeedStep), windRoseSpeedRanges-1)
+	lower := strconv.Itoa(r * windRoseSpeedStep)
+	if r == windRoseSpeedRanges-1 {
+		return compassPoints[sector], lower + "+"
+	}
+	return compassPoints[sector], lower + "-" + strconv.Itoa((r+1)*windRoseSpeedStep)
+}
+
+// updateWindRose adds the time since a station's previous observation to the
+// wind rose counter of the direction sector and speed range of an observation
+// taken at t.
+func updateWindRose(m *Metrics, l prometheus.Labels, prev, t time.Time, direction, speed float64) {
+	elapsed, ok := observationInterval(prev, t)
+	if !ok {
+		return
+	}
+	sector, speedRange := windRoseLabels(direction, speed)
+	m.WindRose.WithLabelValues(l["station_id"], sector, speedRange).Add(elapsed.Seconds())
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+func TestWindRoseLabels(t *testing.T) {
+	tts := []struct {
+		direction, speed float64
+		wantSector       string
+		wantSpeed        string
+	}{
+		{direction: 0, speed: 5, wantSector: "N", wantSpeed: "0-10"},
+		{direction: 350, speed: 12, wantSector: "N", wantSpeed: "10-20"},
+		{direction: 348.7, speed: 12, wantSector: "NNW", wantSpeed: "10-20"},
+		{direction: 90, speed: 49.9, wantSector: "E", wantSpeed: "40-50"},
+		{direction: 225, speed: 50, wantSector: "SW", wantSpeed: "50+"},
+		{direction: 360, speed: 120, wantSector: "N", wantSpeed: "50+"},
+		{direction: 180, speed: 0.5, wantSector: "calm", wantSpeed: "calm"},
+	}
+	for _, tt := range tts {
+		sector, speed := windRoseLabels(tt.direction, tt.speed)
+		if sector != tt.wantSector || speed != tt.wantSpeed {
+			t.Errorf("windRoseLabels(%v, %v) = %s, %s, want %s, %s",
+				tt.direction, tt.speed, sector, speed, tt.wantSector, tt.wantSpeed)
+		}
+	}
+}
+
+func TestWindRose(t *testing.T) {
+	e, err := NewExporter(Config{ExporterIP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	start := time.Date(2025, 1, 23, 12, 0, 0, 0, time.UTC)
+	for _, o := range []struct {
+		minutes          int
+		direction, speed float64
+	}{
+		{0, 270, 15},
+		{1, 270, 15},
+		{2, 275, 18},
+		{3, 90, 5},
+		{30, 270, 15}, // Station was offline
+	} {
+		o := weather.Observation{
+			StationID: "KTEST1",
+			Measurement: wu.DeviceMeasurement{
+				DateUTC:       start.Add(time.Duration(o.minutes) * time.Minute),
+				WindDirection: o.direction,
+				WindSpeed:     o.speed,
+			},
+		}
+		if err := e.recordObservation(context.Background(), &o); err != nil {
+			t.Fatalf("record observation: %v", err)
+		}
+	}
+
+	for _, tt := range []struct {
+		sector, speed string
+		want          float64
+	}{
+		{sector: "W", speed: "10-20", want: 120},
+		{sector: "E", speed: "0-10", want: 60},
+	} {
+		var m dto.Metric
+		if err := e.metrics.WindRose.WithLabelValues("KTEST1", tt.sector, tt.speed).Write(&m); err != nil {
+			t.Fatalf("write metric: %v", err)
+		}
+		if got := m.GetCounter().GetValue(); got != tt.want {
+			t.Errorf("got %v seconds from %s at %s km/h, want %v", got, tt.sector, tt.speed, tt.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package windy implements the Windy.com PWS upload API, which accepts the
+// parameters of the Weather Underground PWS Upload Protocol, and equivalent
+// parameters in metric units.
+//
+// Uploads are translated to WU submissions, so that they are processed,
+// journaled and forwarded the same way as WU submissions.
+package windy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+// tracer records spans for uploads. Spans are only recorded if a global
+// OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/exporter/windy")
+
+// UpdatePath is the path that stations send uploads to, followed by the
+// station's Windy API key.
+const UpdatePath = "/pws/update/"
+
+// UpdateAPI implements the Windy.com PWS upload API.
+type UpdateAPI struct {
+	handleSubmission func(ctx context.Context, s wu.Submission)
+	stationID        func(stationID string) (string, bool)
+}
+
+// NewUpdateAPI returns a new upload API. The handler is called synchronously
+// for each accepted upload, translated to a WU submission, before the
+// response is sent to the station.
+func NewUpdateAPI(handler func(ctx context.Context, s wu.Submission)) *UpdateAPI {
+	return &UpdateAPI{
+		handleSubmission: handler,
+	}
+}
+
+// SetStationIDFunc sets the function used to normalize the ID of the station
+// that sent an upload. If the function returns false, the upload is rejected.
+// It must be called before the API is served.
+func (api *UpdateAPI) SetStationIDFunc(fn func(stationID string) (string, bool)) {
+	api.stationID = fn
+}
+
+func (api *UpdateAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	apiKey := strings.Trim(strings.TrimPrefix(req.URL.Path, UpdatePath), "/")
+	remoteAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remoteAddr = req.RemoteAddr
+	}
+
+	ctx, span := tracer.Start(req.Context(), "windy.upload",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("client.address", remoteAddr)))
+	defer span.End()
+	if apiKey == "" {
+		slog.Warn("Rejected Windy upload with missing API key",
+			slog.String("remote_addr", remoteAddr),
+			slog.String("outcome", "rejected"))
+		span.SetStatus(codes.Error, "missing parameters")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	stationID := StationID(apiKey, q)
+	span.SetAttributes(attribute.String("station_id", stationID))
+	if api.stationID != nil {
+		id, ok := api.stationID(stationID)
+		if !ok {
+			slog.Warn("Rejected Windy upload from unknown station",
+				slog.String("station_id", stationID),
+				slog.String("remote_addr", remoteAddr),
+				slog.String("outcome", "rejected"))
+			span.SetStatus(codes.Error, "unknown station")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		stationID = id
+	}
+
+	receivedAt := time.Now()
+	_, parseSpan := tracer.Start(ctx, "windy.parse")
+	wq := Translate(stationID, q)
+	dm, fieldErrs := wu.ParseMeasurement(wq, receivedAt)
+	parseSpan.End()
+	for _, fe := range fieldErrs {
+		span.RecordError(fe)
+		slog.Warn("Ignored invalid Windy upload field",
+			slog.String("station_id", stationID),
+			slog.String("remote_addr", remoteAddr),
+			slog.String("param", fe.Param),
+			slog.String("value", fe.Value),
+			slog.Any("err", fe.Err))
+	}
+
+	slog.Info("Received Windy weather data from station",
+		slog.String("station_id", stationID),
+		slog.String("remote_addr", remoteAddr),
+		slog.String("outcome", "accepted"))
+
+	api.handleSubmission(ctx, wu.Submission{
+		StationID:   stationID,
+		ReceivedAt:  receivedAt,
+		RemoteAddr:  remoteAddr,
+		RawQuery:    wq.Encode(),
+		Measurement: dm,
+		Fields:      wu.SubmittedFields(wq, fieldErrs),
+		FieldErrors: fieldErrs,
+	})
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, "SUCCESS\n")
+}
+
+// StationID returns the ID of the station that sent an upload. Consoles that
+// use the WU protocol to upload to Windy submit their station ID, which is
+// used if set. Otherwise, the ID is derived from a hash of the API key, as the
+// key is a secret, and the Windy station number if it is not 0, e.g.
+// "windy-1a2b3c4d" or "windy-1a2b3c4d-1".
+func StationID(apiKey string, q url.Values) string {
+	if id := q.Get("ID"); id != "" {
+		return id
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	id := "windy-" + hex.EncodeToString(sum[:4])
+	if station := q.Get("station"); station != "" && station != "0" {
+		id += "-" + station
+	}
+	return id
+}
+
+// metricParams maps the metric parameters of the Windy API to the equivalent
+// parameters of the WU PWS Upload Protocol, and the conversion of the metric
+// value to the units of the WU parameter. The WU parameter is used if both
+// are submitted.
+var metricParams = []struct {
+	param, wuParam string
+	convert        func(float64) float64
+}{
+	{"temp", "tempf", wu.CToF},
+	{"dewpoint", "dewptf", wu.CToF},
+	{"wind", "windspeedmph", msToMPH},
+	{"gust", "windgustmph", msToMPH},
+	{"pressure", "baromin", paToInHg},
+	{"mbar", "baromin", wu.HPAToInHg},
+	{"precip", "rainin", wu.MMToIn},
+}
+
+// Translate translates the query parameters of a Windy upload to the query
+// parameters of an equivalent WU submission from the station. The upload
+// time may be submitted as a Unix timestamp (ts), which is translated to the
+// WU submission date.
+//
+// Values that cannot be parsed are passed through unconverted, so that they
+// are reported as field errors. Missing sentinel values (e.g. -9999) are
+// dropped, as they are no longer recognizable once converted.
+func Translate(stationID string, q url.Values) url.Values {
+	wq := make(url.Values, len(q)+2)
+	for param, values := range q {
+		wq[param] = values
+	}
+	for _, p := range metricParams {
+		if !q.Has(p.param) {
+			continue
+		}
+		wq.Del(p.param)
+		if wq.Has(p.wuParam) {
+			continue
+		}
+		v := q.Get(p.param)
+		if wu.IsMissing(v) {
+			continue
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			v = strconv.FormatFloat(p.convert(f), 'f', -1, 64)
+		}
+		wq.Set(p.wuParam, v)
+	}
+	if ts := q.Get("ts"); ts != "" && !q.Has("dateutc") {
+		wq.Del("ts")
+		if secs, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			ts = time.Unix(secs, 0).UTC().Format("2006-01-02 15:04:05")
+		}
+		wq.Set("dateutc", ts)
+	}
+	wq.Set("ID", stationID)
+	wq.Set("action", "updateraww")
+	wu.AddDewPoint(wq)
+	return wq
+}
+
+// msToMPH converts meters/second to miles/hour.
+func msToMPH(ms float64) float64 {
+	return wu.KPHToMPH(ms * 3.6)
+}
+
+// paToInHg converts pressure from pascals (Pa) to inches of mercury (inHg).
+func paToInHg(pa float64) float64 {
+	return wu.HPAToInHg(pa / 100)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package windy
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+const testAPIKey = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.test"
+
+func TestUpload(t *testing.T) {
+	var got *wu.Submission
+	api := NewUpdateAPI(func(_ context.Context, s wu.Submission) {
+		got = &s
+	})
+	api.SetStationIDFunc(func(stationID string) (string, bool) {
+		return stationID, stationID != "unknown"
+	})
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		name string
+		path string
+		want int
+	}{
+		{"missing api key", UpdatePath + "?tempf=59.7", http.StatusBadRequest},
+		{"unknown station", UpdatePath + testAPIKey + "?ID=unknown&tempf=59.7", http.StatusUnauthorized},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ts.Client().Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = res.Body.Close()
+			if res.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", res.StatusCode, tt.want)
+			}
+		})
+	}
+	if got != nil {
+		t.Fatalf("unexpected submission from rejected upload: %+v", got)
+	}
+
+	// Metric parameters, from a station without a WU station ID.
+	res, err := ts.Client().Get(ts.URL + UpdatePath + testAPIKey +
+		"?station=1&ts=1737626400&temp=15.4&humidity=80&wind=2.5&gust=4&winddir=213&pressure=101250&precip=0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	if got == nil {
+		t.Fatal("upload was not handled")
+	}
+	if want := StationID(testAPIKey, url.Values{"station": {"1"}}); got.StationID != want {
+		t.Errorf("got station ID %q, want %q", got.StationID, want)
+	}
+	if len(got.FieldErrors) != 0 {
+		t.Errorf("unexpected field errors: %v", got.FieldErrors)
+	}
+	if strings.Contains(got.RawQuery, testAPIKey) {
+		t.Errorf("raw query contains the API key: %s", got.RawQuery)
+	}
+
+	dm := got.Measurement
+	if want := time.Unix(1737626400, 0).UTC(); !dm.DateUTC.Equal(want) {
+		t.Errorf("got date %v, want %v", dm.DateUTC, want)
+	}
+	for _, tt := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"temperature", dm.Temperature, 15.4},
+		{"humidity", dm.Humidity, 80},
+		{"dew point", dm.DewPoint, 11.9444}, // Derived, rounded to 0.1 °F
+		{"wind speed", dm.WindSpeed, 9},
+		{"wind gust", dm.WindGust, 14.4},
+		{"wind direction", dm.WindDirection, 213},
+		{"pressure", dm.Barometric, 1012.5},
+		{"rain past hour", dm.RainPastHour, 0.5},
+	} {
+		if math.Abs(tt.got-tt.want) > 0.001 {
+			t.Errorf("got %s %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// WU parameters, from a console using the WU protocol.
+	got = nil
+	res, err = ts.Client().Get(ts.URL + UpdatePath + testAPIKey + "?ID=KTEST1&PASSWORD=" + testAPIKey + "&tempf=59.7&dewptf=50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if got == nil || got.StationID != "KTEST1" {
+		t.Fatalf("got submission %+v, want station KTEST1", got)
+	}
+	if math.Abs(got.Measurement.DewPoint-10) > 0.001 {
+		t.Errorf("got dew point %v, want 10", got.Measurement.DewPoint)
+	}
+}
+
+func TestStationID(t *testing.T) {
+	id := StationID(testAPIKey, url.Values{})
+	if !strings.HasPrefix(id, "windy-") || len(id) != len("windy-")+8 {
+		t.Errorf("got station ID %q, want windy- and 8 hex digits", id)
+	}
+	if got := StationID(testAPIKey, url.Values{"station": {"0"}}); got != id {
+		t.Errorf("got station ID %q for station 0, want %q", got, id)
+	}
+	if got := StationID(testAPIKey, url.Values{"station": {"2"}}); got != id+"-2" {
+		t.Errorf("got station ID %q for station 2, want %q", got, id+"-2")
+	}
+	if got := StationID("another key", url.Values{}); got == id {
+		t.Errorf("got the same station ID %q for different API keys", got)
+	}
+	if got := StationID(testAPIKey, url.Values{"ID": {"KTEST1"}}); got != "KTEST1" {
+		t.Errorf("got station ID %q, want KTEST1", got)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	q, err := url.ParseQuery("temp=15&tempf=70&mbar=1012.5&ts=soon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wq := Translate("KTEST1", q)
+	if wq.Get("tempf") != "70" || wq.Has("temp") {
+		t.Errorf("got tempf %q, want the submitted WU value", wq.Get("tempf"))
+	}
+	if math.Abs(wu.HPAToInHg(1012.5)-mustFloat(t, wq.Get("baromin"))) > 1e-9 {
+		t.Errorf("got baromin %q, want %v", wq.Get("baromin"), wu.HPAToInHg(1012.5))
+	}
+	if wq.Get("dateutc") != "soon" || wq.Has("ts") {
+		t.Errorf("got dateutc %q, want the unconverted timestamp", wq.Get("dateutc"))
+	}
+
+	// Missing sentinel values are dropped instead of converted.
+	q, err = url.ParseQuery("temp=-9999&mbar=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wq = Translate("KTEST1", q)
+	if wq.Has("tempf") || wq.Has("temp") || wq.Has("baromin") {
+		t.Errorf("got %q, want missing values dropped", wq.Encode())
+	}
+}
+
+func mustFloat(t *testing.T, s string) float64 {
+	t.Helper()
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+
+	"github.com/joshuasing/pws_exporter/internal/journal"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+	"github.com/joshuasing/pws_exporter/pkg/weather"
+)
+
+// handleWUSubmission handles a submission received by the WU submission API,
+// or an Ecowitt report translated to a WU submission.
+// If the journal is enabled, the raw submission is written to the journal
+// before it is processed, and acknowledged once processing has completed.
+// Submissions are discarded while the exporter is in maintenance mode. The
+// most recent raw submission from each station is kept for debugging.
+// Observations polled from the WU API are not forwarded to WU or upstreams,
+// as they were already uploaded by the station.
+func (e *Exporter) handleWUSubmission(ctx context.Context, s wu.Submission) {
+	e.lastSubmissions.set(s)
+	e.quality.submitted(s.StationID, s.ReceivedAt, s.Fields)
+	if e.alerts != nil {
+		e.alerts.Seen(s.StationID, s.RemoteAddr, s.ReceivedAt)
+	}
+	if e.annotator != nil {
+		e.annotator.Seen(s.StationID, s.ReceivedAt)
+	}
+	if len(s.FieldErrors) > 0 {
+		fieldErrors := e.metricsFor(s.StationID).FieldErrors
+		for _, fe := range s.FieldErrors {
+			fieldErrors.WithLabelValues(s.StationID, fe.Param).Inc()
+		}
+	}
+	e.setSubmissionLatency(s)
+	e.countSubmission(s)
+	if e.maintenance.Load() {
+		slog.Debug("Discarding submission in maintenance mode",
+			slog.String("station_id", s.StationID),
+			slog.String("outcome", "discarded"))
+		return
+	}
+
+	if e.weewx != nil && !e.weewx.Forward(s.RawQuery) {
+		slog.Warn("WeeWX forwarding queue is full, dropping submission",
+			slog.String("station_id", s.StationID))
+	}
+	if e.wuForward != nil && !s.Polled {
+		if dropped := e.wuForward.Forward(s.StationID, s.RawQuery); dropped > 0 {
+			slog.Warn("WU forwarding queue is full, dropping submission",
+				slog.String("station_id", s.StationID), slog.Int("targets", dropped))
+		}
+	}
+	if e.upstreams != nil && !s.Polled {
+		if dropped := e.upstreams.Forward(s.StationID, s.RawQuery, s.ReceivedAt); dropped > 0 {
+			slog.Warn("Upstream queue is full, dropping submission",
+				slog.String("station_id", s.StationID), slog.Int("upstreams", dropped))
+		}
+	}
+
+	var seq uint64
+	if e.journal != nil && e.journalHealth.enabled() {
+		var err error
+		_, span := tracer.Start(ctx, "journal.append")
+		seq, err = e.journal.Append(s.ReceivedAt, s.RawQuery)
+		endSpan(span, err)
+		e.journalHealth.record(err)
+		if err != nil {
+			slog.Error("Failed to write submission to journal",
+				slog.String("station_id", s.StationID), slog.Any("err", err))
+		}
+	}
+
+	o := weather.Observation{
+		StationID:   s.StationID,
+		ReceivedAt:  s.ReceivedAt,
+		Measurement: s.Measurement,
+	}
+	e.setObservationTime(&o)
+	done := func() {
+		e.ackJournal(seq)
+	}
+	if window := e.stationConfig(o.StationID).rapidFireWindow; s.RapidFire && window > 0 {
+		e.rapidFire.add(ctx, o, window, done)
+		return
+	}
+	e.rapidFire.flush(o.StationID)
+	e.pipeline.enqueue(ctx, o, done)
+}
+
+// setSubmissionLatency sets the time between the observation time reported by
+// the station and the time the submission was received, which exposes
+// buffering gateways and clock drift. It is negative if the station's clock
+// is ahead. Submissions without a reported observation time are ignored, as
+// it defaults to the receive time (e.g. with dateutc=now).
+func (e *Exporter) setSubmissionLatency(s wu.Submission) {
+	if s.Measurement.DateUTC.Equal(s.ReceivedAt.UTC()) {
+		return
+	}
+	e.metricsFor(s.StationID).SubmissionLatency.WithLabelValues(s.StationID).
+		Set(s.ReceivedAt.Sub(s.Measurement.DateUTC).Seconds())
+}
+
+// countSubmission counts a submission by type, and sets whether the station's
+// last submission was a RapidFire submission and its reported frequency.
+func (e *Exporter) countSubmission(s wu.Submission) {
+	m := e.metricsFor(s.StationID)
+	typ, realTime := "standard", 0.0
+	if s.RapidFire {
+		typ, realTime = "rapidfire", 1
+	}
+	m.Submissions.WithLabelValues(s.StationID, typ).Inc()
+	m.RealTime.WithLabelValues(s.StationID).Set(realTime)
+	if s.RapidFire && s.Measurement.RealTimeFreq > 0 {
+		m.RealTimeFrequency.WithLabelValues(s.StationID).Set(s.Measurement.RealTimeFreq)
+	} else {
+		m.RealTimeFrequency.DeleteLabelValues(s.StationID)
+	}
+}
+
+// replayJournal processes submissions from the journal that were accepted but
+// not processed before the exporter last stopped.
+func (e *Exporter) replayJournal(entries []journal.Entry) {
+	for _, entry := range entries {
+		q, err := url.ParseQuery(entry.RawQuery)
+		if err != nil {
+			slog.Warn("Discarding invalid journal entry",
+				slog.Uint64("seq", entry.Seq), slog.Any("err", err))
+			e.ackJournal(entry.Seq)
+			continue
+		}
+		stationID, ok := e.normalizeStationID(q.Get("ID"))
+		if !ok {
+			slog.Debug("Discarding journal entry from unknown station",
+				slog.Uint64("seq", entry.Seq), slog.String("station_id", stationID))
+			e.ackJournal(entry.Seq)
+			continue
+		}
+		dm, _ := wu.ParseMeasurement(q, entry.ReceivedAt)
+		o := weather.Observation{
+			StationID:   stationID,
+			ReceivedAt:  entry.ReceivedAt,
+			Measurement: dm,
+		}
+		e.setObservationTime(&o)
+		e.processObservation(context.Background(), o)
+		e.ackJournal(entry.Seq)
+	}
+	if len(entries) > 0 {
+		slog.Info("Replayed submissions from journal", slog.Int("count", len(entries)))
+	}
+}
+
+// ackJournal acknowledges that the journal entry has been processed.
+func (e *Exporter) ackJournal(seq uint64) {
+	if e.journal == nil || seq == 0 {
+		return
+	}
+	if err := e.journal.Ack(seq); err != nil {
+		slog.Error("Failed to acknowledge journal entry",
+			slog.Uint64("seq", seq), slog.Any("err", err))
+	}
+}
@@ -0,0 +1,767 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package wu implements the Weather Underground data submission API, as
+// specified by https://support.weather.com/s/article/PWS-Upload-Protocol.
+//
+// The submitted data uses imperial values, which are immediately converted to
+// metric values for compatibility with other systems.
+package wu
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records spans for submissions. Spans are only recorded if a global
+// OpenTelemetry tracer provider has been set.
+var tracer = otel.Tracer("github.com/joshuasing/pws_exporter/pkg/exporter/wu")
+
+const SubmissionPath = "/weatherstation/updateweatherstation.php"
+
+// RapidFireHost is the host of the WU RapidFire (real-time) submission API.
+const RapidFireHost = "rtupdate.wunderground.com"
+
+// SubmissionAPI implements the "PWS Upload Protocol", as documented at
+// https://support.weather.com/s/article/PWS-Upload-Protocol.
+type SubmissionAPI struct {
+	handleSubmission func(ctx context.Context, s Submission)
+	response         func(stationID string, rapidFire bool) (Response, bool)
+	stationID        func(stationID string) (string, bool)
+}
+
+// Response is the HTTP response sent to a station for an accepted submission.
+type Response struct {
+	Status int
+	Body   string
+}
+
+// DefaultResponse is the response sent by the WU API for accepted
+// submissions.
+var DefaultResponse = Response{Status: http.StatusOK, Body: "success\n"}
+
+// Submission is a data submission received by the API.
+type Submission struct {
+	StationID   string            // Station ID
+	ReceivedAt  time.Time         // Time the submission was received
+	RemoteAddr  string            // Address of the station
+	RapidFire   bool              // Whether the submission is a RapidFire (real-time) submission
+	Polled      bool              // Whether the submission was polled from WU, instead of being sent by the station
+	RawQuery    string            // Raw submission query string
+	Measurement DeviceMeasurement // Parsed measurement
+	Fields      []string          // Measurement fields that were submitted and parsed
+	FieldErrors []FieldError      // Fields that could not be parsed and were ignored
+}
+
+// FieldError is a submitted field that could not be parsed.
+type FieldError struct {
+	Param string // Query parameter name
+	Value string // Submitted value
+	Err   error  // Parse error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("parse %s %q: %v", e.Param, e.Value, e.Err)
+}
+
+// NewSubmissionAPI returns a new submission API. The handler is called
+// synchronously for each accepted submission, before the response is sent to
+// the station, and should not block for long. The context contains the
+// submission's trace span, and is cancelled once the response has been sent.
+func NewSubmissionAPI(handler func(ctx context.Context, s Submission)) *SubmissionAPI {
+	return &SubmissionAPI{
+		handleSubmission: handler,
+	}
+}
+
+// SetResponseFunc sets the function used to look up the response sent to a
+// station for accepted standard or RapidFire submissions. If the function
+// returns false, DefaultResponse is sent. It must be called before the API is
+// served.
+func (wu *SubmissionAPI) SetResponseFunc(fn func(stationID string, rapidFire bool) (Response, bool)) {
+	wu.response = fn
+}
+
+// SetStationIDFunc sets the function used to normalize the ID of the station
+// that sent a submission. If the function returns false, the submission is
+// rejected. It must be called before the API is served.
+func (wu *SubmissionAPI) SetStationIDFunc(fn func(stationID string) (string, bool)) {
+	wu.stationID = fn
+}
+
+func (wu *SubmissionAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	remoteAddr, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		remoteAddr = req.RemoteAddr
+	}
+
+	ctx, span := tracer.Start(req.Context(), "wu.submission",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("station_id", q.Get("ID")),
+			attribute.String("client.address", remoteAddr),
+		))
+	defer span.End()
+	if q.Get("action") != "updateraww" || !q.Has("ID") || !q.Has("PASSWORD") {
+		slog.Warn("Rejected WU submission with missing parameters",
+			slog.String("station_id", q.Get("ID")),
+			slog.String("remote_addr", remoteAddr),
+			slog.String("outcome", "rejected"))
+		span.SetStatus(codes.Error, "missing parameters")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	stationID := q.Get("ID")
+	if wu.stationID != nil {
+		id, ok := wu.stationID(stationID)
+		if !ok {
+			slog.Warn("Rejected WU submission from unknown station",
+				slog.String("station_id", stationID),
+				slog.String("remote_addr", remoteAddr),
+				slog.String("outcome", "rejected"))
+			span.SetStatus(codes.Error, "unknown station")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		stationID = id
+	}
+
+	proto := req.Proto
+	if req.TLS != nil {
+		proto += " " + tls.VersionName(req.TLS.Version)
+	}
+
+	// TODO: maybe implement password check?
+
+	receivedAt := time.Now()
+	_, parseSpan := tracer.Start(ctx, "wu.parse")
+	dm, fieldErrs := ParseMeasurement(q, receivedAt)
+	parseSpan.End()
+	for _, fe := range fieldErrs {
+		span.RecordError(fe)
+		slog.Warn("Ignored invalid WU submission field",
+			slog.String("station_id", stationID),
+			slog.String("remote_addr", remoteAddr),
+			slog.String("param", fe.Param),
+			slog.String("value", fe.Value),
+			slog.Any("err", fe.Err))
+	}
+
+	// RapidFire submissions are sent every few seconds, and would flood the
+	// log at the info level.
+	rapidFire := isRapidFire(req, q)
+	level := slog.LevelInfo
+	if rapidFire {
+		level = slog.LevelDebug
+	}
+	slog.Log(ctx, level, "Received WU weather data from station",
+		slog.String("station_id", stationID),
+		slog.String("remote_addr", remoteAddr),
+		slog.String("proto", proto),
+		slog.Bool("rapidfire", rapidFire),
+		slog.String("outcome", "accepted"))
+
+	wu.handleSubmission(ctx, Submission{
+		StationID:   stationID,
+		ReceivedAt:  receivedAt,
+		RemoteAddr:  remoteAddr,
+		RapidFire:   rapidFire,
+		RawQuery:    req.URL.RawQuery,
+		Measurement: dm,
+		Fields:      SubmittedFields(q, fieldErrs),
+		FieldErrors: fieldErrs,
+	})
+
+	resp := DefaultResponse
+	if wu.response != nil {
+		if r, ok := wu.response(stationID, rapidFire); ok {
+			resp = r
+		}
+	}
+	w.WriteHeader(resp.Status)
+	_, _ = io.WriteString(w, resp.Body)
+}
+
+// isRapidFire returns whether the submission is a RapidFire submission, either
+// because it is flagged as real-time or because it was sent to the RapidFire
+// API, which some firmware does without setting the realtime parameter.
+func isRapidFire(req *http.Request, q url.Values) bool {
+	host, _, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		host = req.Host
+	}
+	return q.Get("realtime") == "1" || strings.EqualFold(host, RapidFireHost)
+}
+
+// DeviceMeasurement stores sensor data submitted to the API.
+type DeviceMeasurement struct {
+	DateUTC      time.Time `json:"date_utc"`                   // Submission time.
+	RealTime     bool      `json:"realtime"`                   // Whether the data is real-time
+	RealTimeFreq float64   `json:"realtime_frequency_seconds"` // Submission frequency in seconds
+
+	// TODO: add remaining data fields.
+
+	WindDirection  float64 `json:"wind_direction_degrees"`     // Instantaneous wind direction, 0-360, degrees
+	WindSpeed      float64 `json:"wind_speed_kph"`             // Instantaneous wind speed, KM/h
+	WindGust       float64 `json:"wind_gust_speed_kph"`        // Current wind gust, KM/h (software-specific time period)
+	Humidity       float64 `json:"humidity_percent"`           // Outdoor humidity percentage
+	DewPoint       float64 `json:"dew_point_celsius"`          // Dew point, in Celsius
+	Temperature    float64 `json:"temperature_celsius"`        // Temperature in Celsius
+	RainPastHour   float64 `json:"rain_past_hour_mm"`          // Rain over past hour, millimeters
+	RainToday      float64 `json:"rain_today_mm"`              // Rain over the past 24 hours, millimeters
+	Barometric     float64 `json:"barometric_pressure_hpa"`    // Barometric pressure, hPA
+	IndoorTemp     float64 `json:"indoor_temperature_celsius"` // Indoor temperature in Celsius
+	IndoorHumidity float64 `json:"indoor_humidity_percent"`    // Indoor humidity, percentage
+	SolarRadiation float64 `json:"solar_radiation_wm2"`        // Solar radiation, W/m²
+
+	// Missing are the query parameters of the weather data fields above that
+	// were not submitted, were submitted as a sentinel value for an absent
+	// sensor, or could not be parsed. Their fields are 0, and must not be
+	// used as measurements. Measurements stored before this was recorded
+	// have no missing fields.
+	Missing []string `json:"missing,omitempty"`
+
+	// Sensor hardware health. Unlike the weather data fields, these are nil
+	// if not submitted, as most stations do not report them.
+	HeaterOn         *bool    `json:"heater_on,omitempty"`               // Whether the sensor heater is on
+	SupplyVoltage    *float64 `json:"supply_voltage_volts,omitempty"`    // Console supply voltage, volts
+	BatteryVoltage   *float64 `json:"battery_voltage_volts,omitempty"`   // Sensor battery voltage, volts
+	CapacitorVoltage *float64 `json:"capacitor_voltage_volts,omitempty"` // Sensor super-capacitor voltage, volts
+	SolarVoltage     *float64 `json:"solar_voltage_volts,omitempty"`     // Sensor solar panel voltage, volts
+
+	// Davis storm data, submitted by WeatherLink collectors and software. A
+	// storm starts once rain has fallen after 24 hours without rain. These
+	// are nil if not submitted, and the storm start is nil if there is no
+	// storm in progress.
+	StormRain  *float64   `json:"storm_rain_mm,omitempty"` // Rain since the start of the storm, millimeters
+	StormStart *time.Time `json:"storm_start,omitempty"`   // Start of the storm
+
+	// Ecowitt data, submitted by Ecowitt consoles. The absolute pressure is
+	// nil if not submitted, and only the channels of additional sensors that
+	// were submitted are included, ordered by channel.
+	AbsBarometric *float64             `json:"absolute_barometric_pressure_hpa,omitempty"` // Absolute (station) pressure, hPa
+	Channels      []ChannelMeasurement `json:"channels,omitempty"`                         // Additional sensor channels
+
+	// AWEKAS data, submitted by stations that upload to AWEKAS. These are nil
+	// if not submitted.
+	SnowDepth     *float64 `json:"snow_depth_mm,omitempty"`          // Snow depth, millimeters
+	SunshineToday *float64 `json:"sunshine_today_seconds,omitempty"` // Measured sunshine duration since midnight, seconds
+}
+
+// MaxChannels is the number of additional temperature and humidity sensor
+// channels supported by Ecowitt consoles.
+const MaxChannels = 8
+
+// ChannelMeasurement stores the data of an additional temperature and
+// humidity sensor, such as an Ecowitt WH31. Fields are nil if not submitted.
+type ChannelMeasurement struct {
+	Channel     int      `json:"channel"`                       // Sensor channel, 1-MaxChannels
+	Temperature *float64 `json:"temperature_celsius,omitempty"` // Temperature in Celsius
+	Humidity    *float64 `json:"humidity_percent,omitempty"`    // Humidity percentage
+}
+
+// Auxiliary health fields are not part of the PWS Upload Protocol, so their
+// parameter names vary by manufacturer. The first parameter that is set is
+// used.
+var (
+	heaterParams           = []string{"heater", "ws90heater"}
+	supplyVoltageParams    = []string{"supplyvolt", "consolevolt"}
+	batteryVoltageParams   = []string{"battvolt", "trans_battery_volt", "wh90batt"}
+	capacitorVoltageParams = []string{"supercap_volt", "ws90cap_volt"}
+	solarVoltageParams     = []string{"solar_volt"}
+	stormRainInParams      = []string{"stormrainin", "rain_storm_in"}
+	stormRainMMParams      = []string{"rain_storm_mm"}
+	stormStartParams       = []string{"stormstart", "rain_storm_start_at"}
+	snowDepthCMParams      = []string{"snow_depth_cm"}
+	sunshineHoursParams    = []string{"sunshine_hours"}
+)
+
+// Sentinel values that consoles and software such as WeeWX submit for sensors
+// that are not present.
+const (
+	// missingSentinel is submitted for any absent numeric value.
+	missingSentinel = -9999
+
+	// missingHumidity is submitted for absent humidity sensors, as the
+	// largest value of the byte the humidity is encoded in.
+	missingHumidity = 255
+)
+
+// missingValues are the non-numeric values that are submitted for absent
+// sensors, compared case-insensitively.
+var missingValues = []string{"", "-", "--", "---", "n/a", "na", "null", "none"}
+
+// IsMissing reports whether a submitted value is a sentinel value for an
+// absent sensor, such as an empty string or -9999, rather than a measurement.
+// Protocols that convert values before they are parsed use it to pass absent
+// values through as missing.
+func IsMissing(v string) bool {
+	v = strings.TrimSpace(v)
+	if slices.ContainsFunc(missingValues, func(m string) bool { return strings.EqualFold(v, m) }) {
+		return true
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	return err == nil && f == missingSentinel
+}
+
+// isMissingParam reports whether the value of a query parameter is a sentinel
+// value for an absent sensor. Humidity parameters are also missing if they are
+// 255.
+func isMissingParam(param, v string) bool {
+	if IsMissing(v) {
+		return true
+	}
+	if !strings.HasPrefix(param, "humidity") && param != "indoorhumidity" {
+		return false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	return err == nil && f == missingHumidity
+}
+
+// measurementParams are the query parameters of the weather data fields of
+// the PWS Upload Protocol that are parsed.
+var measurementParams = []string{
+	"winddir", "windspeedmph", "windgustmph", "humidity", "dewptf", "tempf",
+	"rainin", "dailyrainin", "baromin", "indoortempf", "indoorhumidity",
+	"solarradiation",
+}
+
+// SubmittedFields returns the weather data query parameters that were
+// submitted and parsed, given the field errors returned by ParseMeasurement.
+// Parameters with a missing sentinel value are not submitted.
+func SubmittedFields(q url.Values, errs []FieldError) []string {
+	fields := make([]string, 0, len(measurementParams))
+	for _, param := range measurementParams {
+		if q.Has(param) && !isMissingParam(param, q.Get(param)) && !slices.ContainsFunc(errs, func(fe FieldError) bool { return fe.Param == param }) {
+			fields = append(fields, param)
+		}
+	}
+	return fields
+}
+
+// Has reports whether the weather data field of the query parameter, such as
+// "tempf", was measured, rather than missing.
+func (dm DeviceMeasurement) Has(param string) bool {
+	return !slices.Contains(dm.Missing, param)
+}
+
+// ParseMeasurement parses the measurement data from submission URL query
+// values. If the submission does not include a date, or the date is "now", the
+// receivedAt time is used.
+//
+// A malformed field does not reject the whole submission: fields that cannot
+// be parsed are ignored, and returned as field errors. A malformed date is
+// replaced with the receivedAt time.
+func ParseMeasurement(q url.Values, receivedAt time.Time) (DeviceMeasurement, []FieldError) {
+	var dm DeviceMeasurement
+	errs := dm.fromQuery(q, receivedAt)
+	return dm, errs
+}
+
+// RedactQuery returns the raw query string with the value of the PASSWORD
+// parameter replaced, preserving the order of the parameters.
+func RedactQuery(rawQuery string) string {
+	params := strings.Split(rawQuery, "&")
+	for i, p := range params {
+		key, _, _ := strings.Cut(p, "=")
+		if strings.EqualFold(key, "PASSWORD") {
+			params[i] = key + "=REDACTED"
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// fromQuery reads the measurement data from URL query values, and returns
+// the fields that could not be parsed.
+func (dm *DeviceMeasurement) fromQuery(q url.Values, receivedAt time.Time) []FieldError {
+	p := fieldParser{q: q}
+
+	// Submission date
+	dm.DateUTC = receivedAt.UTC()
+	switch v := q.Get("dateutc"); v {
+	case "", "now":
+	default:
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", v, time.UTC)
+		if err != nil {
+			p.errs = append(p.errs, FieldError{Param: "dateutc", Value: v, Err: err})
+			break
+		}
+		dm.DateUTC = t
+	}
+
+	// RapidFire / real-time data
+	if q.Has("realtime") {
+		dm.RealTime = q.Get("realtime") == "1"
+	}
+	if rtFreq, ok := p.float("rtfreq", nil); ok {
+		dm.RealTimeFreq = rtFreq
+	}
+
+	// Parse data
+	if windDir, ok := p.float("winddir", nil); ok {
+		dm.WindDirection = windDir
+	}
+	if windSpeed, ok := p.float("windspeedmph", mphToKPH); ok {
+		dm.WindSpeed = windSpeed
+	}
+	if windGust, ok := p.float("windgustmph", mphToKPH); ok {
+		dm.WindGust = windGust
+	}
+	if humidity, ok := p.float("humidity", nil); ok {
+		dm.Humidity = humidity
+	}
+	if dewPoint, ok := p.float("dewptf", ftoc); ok {
+		dm.DewPoint = dewPoint
+	}
+	if temp, ok := p.float("tempf", ftoc); ok {
+		dm.Temperature = temp
+	}
+	if rain, ok := p.float("rainin", inToMM); ok {
+		dm.RainPastHour = rain
+	}
+	if dailyRain, ok := p.float("dailyrainin", inToMM); ok {
+		dm.RainToday = dailyRain
+	}
+	if barom, ok := p.float("baromin", inHgToHPA); ok {
+		dm.Barometric = barom
+	}
+	if indoorTemp, ok := p.float("indoortempf", ftoc); ok {
+		dm.IndoorTemp = indoorTemp
+	}
+	if indoorHumidity, ok := p.float("indoorhumidity", nil); ok {
+		dm.IndoorHumidity = indoorHumidity
+	}
+	if solarRadiation, ok := p.float("solarradiation", nil); ok {
+		dm.SolarRadiation = solarRadiation
+	}
+
+	// Auxiliary health data
+	if heater, ok := p.bool(heaterParams...); ok {
+		dm.HeaterOn = &heater
+	}
+	if v, ok := p.float(p.first(supplyVoltageParams...), nil); ok {
+		dm.SupplyVoltage = &v
+	}
+	if v, ok := p.float(p.first(batteryVoltageParams...), nil); ok {
+		dm.BatteryVoltage = &v
+	}
+	if v, ok := p.float(p.first(capacitorVoltageParams...), nil); ok {
+		dm.CapacitorVoltage = &v
+	}
+	if v, ok := p.float(p.first(solarVoltageParams...), nil); ok {
+		dm.SolarVoltage = &v
+	}
+
+	// Davis storm data
+	if v, ok := p.float(p.first(stormRainInParams...), inToMM); ok {
+		dm.StormRain = &v
+	} else if v, ok := p.float(p.first(stormRainMMParams...), nil); ok {
+		dm.StormRain = &v
+	}
+	if t, ok := p.time(stormStartParams...); ok {
+		dm.StormStart = &t
+	}
+
+	// Ecowitt data
+	if v, ok := p.float("baromabsin", inHgToHPA); ok {
+		dm.AbsBarometric = &v
+	}
+	for ch := 1; ch <= MaxChannels; ch++ {
+		c := ChannelMeasurement{Channel: ch}
+		if v, ok := p.float("temp"+strconv.Itoa(ch)+"f", ftoc); ok {
+			c.Temperature = &v
+		}
+		if v, ok := p.float("humidity"+strconv.Itoa(ch), nil); ok {
+			c.Humidity = &v
+		}
+		if c.Temperature != nil || c.Humidity != nil {
+			dm.Channels = append(dm.Channels, c)
+		}
+	}
+
+	// AWEKAS data
+	if v, ok := p.float(p.first(snowDepthCMParams...), cmToMM); ok {
+		dm.SnowDepth = &v
+	}
+	if v, ok := p.float(p.first(sunshineHoursParams...), hoursToSeconds); ok {
+		dm.SunshineToday = &v
+	}
+
+	submitted := SubmittedFields(q, p.errs)
+	for _, param := range measurementParams {
+		if !slices.Contains(submitted, param) {
+			dm.Missing = append(dm.Missing, param)
+		}
+	}
+	return p.errs
+}
+
+// errNotFinite is the error of a field whose value is NaN or infinite.
+var errNotFinite = errors.New("value is not finite")
+
+// fieldParser parses numeric query parameters, and records the parameters
+// that could not be parsed.
+type fieldParser struct {
+	q    url.Values
+	errs []FieldError
+}
+
+// float parses the query parameter as a float, and converts it to metric
+// units using convert, if not nil. If the parameter is not set, or is a
+// sentinel value for an absent sensor, 0, false is returned. If the parameter
+// cannot be parsed, or the converted value is not finite, a field error is
+// recorded and 0, false is returned.
+func (p *fieldParser) float(param string, convert func(float64) float64) (float64, bool) {
+	if !p.q.Has(param) {
+		return 0, false
+	}
+	v := p.q.Get(param)
+	if isMissingParam(param, v) {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err == nil && convert != nil {
+		f = convert(f)
+	}
+	if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		err = errNotFinite
+	}
+	if err != nil {
+		p.errs = append(p.errs, FieldError{Param: param, Value: v, Err: err})
+		return 0, false
+	}
+	return f, true
+}
+
+// first returns the first of the query parameters that is set, or an empty
+// string if none are set.
+func (p *fieldParser) first(params ...string) string {
+	for _, param := range params {
+		if p.q.Has(param) {
+			return param
+		}
+	}
+	return ""
+}
+
+// bool parses the first of the query parameters that is set as a boolean,
+// accepting the values accepted by strconv.ParseBool, and "on" and "off". If
+// none of the parameters are set, or the parameter is a sentinel value for an
+// absent sensor, false, false is returned. If the parameter cannot be parsed, a
+// field error is recorded and false, false is returned.
+func (p *fieldParser) bool(params ...string) (bool, bool) {
+	param := p.first(params...)
+	if param == "" {
+		return false, false
+	}
+	v := p.q.Get(param)
+	if IsMissing(v) {
+		return false, false
+	}
+	switch strings.ToLower(v) {
+	case "on":
+		return true, true
+	case "off":
+		return false, true
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		p.errs = append(p.errs, FieldError{Param: param, Value: v, Err: err})
+		return false, false
+	}
+	return b, true
+}
+
+// time parses the first of the query parameters that is set as a time, either
+// in seconds since the Unix epoch, or as a UTC date with an optional time
+// ("2006-01-02" or "2006-01-02 15:04:05"). If none of the parameters are set,
+// or the time is zero or missing, the zero time and false are returned. If the
+// parameter cannot be parsed, a field error is recorded and the zero time and
+// false are returned.
+func (p *fieldParser) time(params ...string) (time.Time, bool) {
+	param := p.first(params...)
+	if param == "" {
+		return time.Time{}, false
+	}
+	v := p.q.Get(param)
+	if v == "0" || IsMissing(v) {
+		return time.Time{}, false
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), true
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, v, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	p.errs = append(p.errs, FieldError{Param: param, Value: v, Err: errors.New("invalid time")})
+	return time.Time{}, false
+}
+
+// Conversion factors. These are exact by definition, except for inHgHPA which
+// is the conventional value for mercury at 0 °C and standard gravity.
+const (
+	inMM    = 25.4
+	mphKPH  = 1.609344
+	inHgHPA = 33.863886
+)
+
+// ftoc converts Fahrenheit to Celsius.
+func ftoc(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// cmToMM converts centimeters to millimeters.
+func cmToMM(f float64) float64 {
+	return f * 10
+}
+
+// hoursToSeconds converts hours to seconds.
+func hoursToSeconds(f float64) float64 {
+	return f * 3600
+}
+
+// inToMM converts inches to millimeters.
+func inToMM(f float64) float64 {
+	return f * inMM
+}
+
+// mphToKPH converts miles/hour to kilometers/hour.
+func mphToKPH(f float64) float64 {
+	return f * mphKPH
+}
+
+// inHgToHPA converts pressure from inches of mercury (inHg) to hectopascals
+// (hPa).
+func inHgToHPA(inHg float64) float64 {
+	return inHg * inHgHPA
+}
+
+// CToF converts Celsius to Fahrenheit. It is used to translate metric
+// submissions of other protocols to WU submissions, as are the other
+// conversions from metric units.
+func CToF(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// MMToIn converts millimeters to inches.
+func MMToIn(mm float64) float64 {
+	return mm / inMM
+}
+
+// KPHToMPH converts kilometers/hour to miles/hour.
+func KPHToMPH(kph float64) float64 {
+	return kph / mphKPH
+}
+
+// HPAToInHg converts pressure from hectopascals (hPa) to inches of mercury
+// (inHg).
+func HPAToInHg(hpa float64) float64 {
+	return hpa / inHgHPA
+}
+
+// Magnus formula coefficients for the saturation vapour pressure over water,
+// from WMO-No. 8 (2018), Annex 4.B.
+const (
+	magnusA = 17.62
+	magnusB = 243.12
+)
+
+// NamedField is the WU parameter of a measurement field that is submitted by
+// name, e.g. "temperature", by protocols that are not based on WU.
+type NamedField struct {
+	// Param is the WU query parameter of the field.
+	Param string
+
+	// FromMetric converts a metric value (e.g. °C, hPa, km/h or mm) to the
+	// unit of the WU parameter. Nil if the units are the same.
+	FromMetric func(float64) float64
+}
+
+// namedFields maps the names of measurement fields to WU parameters.
+var namedFields = map[string]NamedField{
+	"temperature":                  {"tempf", CToF},
+	"dew_point":                    {"dewptf", CToF},
+	"humidity":                     {"humidity", nil},
+	"indoor_temperature":           {"indoortempf", CToF},
+	"indoor_humidity":              {"indoorhumidity", nil},
+	"barometric_pressure":          {"baromin", HPAToInHg},
+	"absolute_barometric_pressure": {"baromabsin", HPAToInHg},
+	"wind_speed":                   {"windspeedmph", KPHToMPH},
+	"wind_gust_speed":              {"windgustmph", KPHToMPH},
+	"wind_direction":               {"winddir", nil},
+	"rain_past_hour":               {"rainin", MMToIn},
+	"rain_today":                   {"dailyrainin", MMToIn},
+	"solar_radiation":              {"solarradiation", nil},
+}
+
+// LookupField returns the WU parameter of a named measurement field.
+func LookupField(name string) (NamedField, bool) {
+	nf, ok := namedFields[name]
+	return nf, ok
+}
+
+// AddDewPoint sets the dew point (dewptf) parameter of a submission, derived
+// from the temperature and humidity, if the dew point was not submitted. It is
+// used for protocols that do not report the dew point. The dew point is not set
+// if the temperature or humidity is missing or invalid.
+func AddDewPoint(q url.Values) {
+	if q.Has("dewptf") {
+		return
+	}
+	if IsMissing(q.Get("tempf")) {
+		return
+	}
+	tempF, err := strconv.ParseFloat(q.Get("tempf"), 64)
+	if err != nil {
+		return
+	}
+	rh, err := strconv.ParseFloat(q.Get("humidity"), 64)
+	if err != nil || rh <= 0 || rh > 100 {
+		return
+	}
+	tempC := ftoc(tempF)
+	gamma := math.Log(rh/100) + magnusA*tempC/(magnusB+tempC)
+	dewPointC := magnusB * gamma / (magnusA - gamma)
+	if math.IsNaN(dewPointC) || math.IsInf(dewPointC, 0) {
+		return
+	}
+	q.Set("dewptf", strconv.FormatFloat(dewPointC*9/5+32, 'f', 1, 64))
+}
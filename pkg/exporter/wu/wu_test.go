@@ -0,0 +1,592 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package wu
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testQuery = SubmissionPath + "?ID=test&PASSWORD=testtest&action=updateraww&realtime=1&rtfreq=5&dateutc=now&baromin=29.65&tempf=63.5&dewptf=51.2&humidity=64&windspeedmph=4.4&windgustmph=4.9&winddir=270&rainin=0.0&dailyrainin=0.0&indoortempf=73.5&indoorhumidity=44"
+
+func TestSubmission(t *testing.T) {
+	var (
+		stationID       string
+		lastMeasurement *DeviceMeasurement
+	)
+	sapi := NewSubmissionAPI(func(_ context.Context, s Submission) {
+		stationID = s.StationID
+		lastMeasurement = &s.Measurement
+	})
+
+	ts := httptest.NewTLSServer(sapi)
+	defer ts.Close()
+
+	client := ts.Client()
+
+	// Test bad empty GET request
+	res, err := client.Get(ts.URL + SubmissionPath)
+	if err != nil {
+		t.Errorf("bad empty request failed: %v", err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("empty request got %d, want %d", res.StatusCode, http.StatusBadRequest)
+	}
+	if lastMeasurement != nil {
+		t.Errorf("measurement should be empty after bad request")
+	}
+
+	// Test submission
+	res, err = client.Get(ts.URL + testQuery)
+	if err != nil {
+		t.Errorf("good submission request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("submission status got %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		t.Errorf("failed to read response body: %v", err)
+	}
+	if string(body) != "success\n" {
+		t.Errorf("response body got %q, want %q", string(body), "success\n")
+	}
+	if stationID != "test" {
+		t.Errorf("stationID got %s, want %s", stationID, "test")
+	}
+	if lastMeasurement == nil {
+		t.Errorf("measurement should contain one measurement")
+	}
+	if lastMeasurement.Temperature != 17.5 {
+		t.Errorf("temperature got %f, want %f", lastMeasurement.Temperature, 17.5)
+	}
+}
+
+func TestSubmissionResponse(t *testing.T) {
+	sapi := NewSubmissionAPI(func(context.Context, Submission) {})
+	sapi.SetResponseFunc(func(stationID string, _ bool) (Response, bool) {
+		if stationID != "legacy" {
+			return Response{}, false
+		}
+		return Response{Status: http.StatusAccepted, Body: "OK"}, true
+	})
+	ts := httptest.NewServer(sapi)
+	defer ts.Close()
+
+	tests := []struct {
+		stationID  string
+		wantStatus int
+		wantBody   string
+	}{
+		{stationID: "test", wantStatus: http.StatusOK, wantBody: "success\n"},
+		{stationID: "legacy", wantStatus: http.StatusAccepted, wantBody: "OK"},
+	}
+	for _, tt := range tests {
+		res, err := ts.Client().Get(ts.URL + strings.Replace(testQuery, "ID=test", "ID="+tt.stationID, 1))
+		if err != nil {
+			t.Fatalf("submission request failed: %v", err)
+		}
+		body, err := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to read response body: %v", err)
+		}
+		if res.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+			t.Errorf("%s: response got %d %q, want %d %q",
+				tt.stationID, res.StatusCode, body, tt.wantStatus, tt.wantBody)
+		}
+	}
+}
+
+func TestRapidFire(t *testing.T) {
+	var got []bool
+	sapi := NewSubmissionAPI(func(_ context.Context, s Submission) {
+		got = append(got, s.RapidFire)
+	})
+	sapi.SetResponseFunc(func(_ string, rapidFire bool) (Response, bool) {
+		return Response{Status: http.StatusOK, Body: "rapidfire"}, rapidFire
+	})
+	ts := httptest.NewServer(sapi)
+	defer ts.Close()
+
+	standard := strings.Replace(testQuery, "realtime=1&rtfreq=5&", "", 1)
+	tests := []struct {
+		name     string
+		query    string
+		host     string
+		wantBody string
+	}{
+		{name: "realtime", query: testQuery, wantBody: "rapidfire"},
+		{name: "standard", query: standard, wantBody: "success\n"},
+		{name: "rapidfire host", query: standard, host: RapidFireHost, wantBody: "rapidfire"},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+tt.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.host != "" {
+			req.Host = tt.host
+		}
+		res, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("submission request failed: %v", err)
+		}
+		body, err := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to read response body: %v", err)
+		}
+		if string(body) != tt.wantBody {
+			t.Errorf("%s: response got %q, want %q", tt.name, body, tt.wantBody)
+		}
+	}
+	if !slices.Equal(got, []bool{true, false, true}) {
+		t.Errorf("got rapidfire %v, want [true false true]", got)
+	}
+}
+
+func TestSubmissionStationID(t *testing.T) {
+	var got []string
+	sapi := NewSubmissionAPI(func(_ context.Context, s Submission) {
+		got = append(got, s.StationID)
+	})
+	sapi.SetStationIDFunc(func(stationID string) (string, bool) {
+		id := strings.ToLower(stationID)
+		return id, id != "unknown"
+	})
+	ts := httptest.NewServer(sapi)
+	defer ts.Close()
+
+	tests := []struct {
+		stationID  string
+		wantStatus int
+	}{
+		{stationID: "KTEST1", wantStatus: http.StatusOK},
+		{stationID: "Unknown", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		res, err := ts.Client().Get(ts.URL + strings.Replace(testQuery, "ID=test", "ID="+tt.stationID, 1))
+		if err != nil {
+			t.Fatalf("submission request failed: %v", err)
+		}
+		_ = res.Body.Close()
+		if res.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status got %d, want %d", tt.stationID, res.StatusCode, tt.wantStatus)
+		}
+	}
+	if !slices.Equal(got, []string{"ktest1"}) {
+		t.Errorf("got submissions from %v, want [ktest1]", got)
+	}
+}
+
+func TestFtoC(t *testing.T) {
+	tts := []struct {
+		F float64
+		C float64
+	}{
+		{F: 0.0, C: -17.7778}, // Below freezing
+		{F: 32, C: 0},         // Freezing point of water
+		{F: 77.5, C: 25.2778}, // Average temperature in Summer for Melbourne, AU
+		{F: 98.6, C: 37},      // Normal body temperature
+		{F: 212, C: 100},      // Boiling point of water
+	}
+	for _, tt := range tts {
+		if c := ftoc(tt.F); round(c, 4) != round(tt.C, 4) {
+			t.Errorf("ftoc(%f) = %f, want %f", tt.F, c, tt.C)
+		}
+	}
+}
+
+func TestInToMM(t *testing.T) {
+	tts := []struct {
+		In float64
+		Mm float64
+	}{
+		{In: 0, Mm: 0},
+		{In: 1, Mm: 25.4},
+		{In: 2.5, Mm: 63.5},
+		{In: 10, Mm: 254},
+	}
+	for _, tt := range tts {
+		if mm := inToMM(tt.In); round(mm, 4) != round(tt.Mm, 4) {
+			t.Errorf("intomm(%f) = %f, want %f", tt.In, mm, tt.Mm)
+		}
+	}
+}
+
+func TestMPHToKPH(t *testing.T) {
+	tts := []struct {
+		MPH float64
+		KPH float64
+	}{
+		{MPH: 0, KPH: 0},
+		{MPH: 1, KPH: 1.609344},
+		{MPH: 45, KPH: 72.42048},
+		{MPH: 60, KPH: 96.56064},
+	}
+	for _, tt := range tts {
+		if c := mphToKPH(tt.MPH); round(c, 6) != round(tt.KPH, 6) {
+			t.Errorf("mphToKPH(%f) = %f, want %f", tt.MPH, c, tt.KPH)
+		}
+	}
+}
+
+func TestInHGToHPA(t *testing.T) {
+	tts := []struct {
+		InHG float64
+		HPA  float64
+	}{
+		{InHG: 0, HPA: 0},
+		{InHG: 1, HPA: 33.863886},
+		{InHG: 5, HPA: 169.31943},
+		{InHG: 29.92, HPA: 1013.207469},
+	}
+	for _, tt := range tts {
+		if c := inHgToHPA(tt.InHG); round(c, 6) != round(tt.HPA, 6) {
+			t.Errorf("inHgToHPA(%f) = %f, want %f", tt.InHG, c, tt.HPA)
+		}
+	}
+}
+
+func TestMetricConversions(t *testing.T) {
+	// Conversions from metric units are the inverse of the conversions to
+	// metric units.
+	for _, v := range []float64{-40, 0, 1, 12.5, 1013.25} {
+		for _, tt := range []struct {
+			name string
+			got  float64
+		}{
+			{"CToF", ftoc(CToF(v))},
+			{"MMToIn", inToMM(MMToIn(v))},
+			{"KPHToMPH", mphToKPH(KPHToMPH(v))},
+			{"HPAToInHg", inHgToHPA(HPAToInHg(v))},
+		} {
+			if round(tt.got, 9) != round(v, 9) {
+				t.Errorf("%s(%v) round trip = %v", tt.name, v, tt.got)
+			}
+		}
+	}
+	if f := CToF(100); f != 212 {
+		t.Errorf("CToF(100) = %f, want 212", f)
+	}
+}
+
+func round(v float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(v*factor) / factor
+}
+
+func TestFieldErrors(t *testing.T) {
+	q, err := url.ParseQuery("tempf=63.5&humidity=wet&baromin=3O.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, errs := ParseMeasurement(q, time.Now())
+	if len(errs) != 2 {
+		t.Fatalf("got %d field errors, want 2: %v", len(errs), errs)
+	}
+	if errs[0].Param != "humidity" || errs[0].Value != "wet" {
+		t.Errorf("got field error %v, want humidity", errs[0])
+	}
+	if errs[1].Param != "baromin" || errs[1].Value != "3O.1" {
+		t.Errorf("got field error %v, want baromin", errs[1])
+	}
+	if dm.Temperature != 17.5 {
+		t.Errorf("got temperature %v, want 17.5", dm.Temperature)
+	}
+	if fields := SubmittedFields(q, errs); !slices.Equal(fields, []string{"tempf"}) {
+		t.Errorf("got submitted fields %v, want [tempf]", fields)
+	}
+
+	// A malformed date is replaced with the receive time, and non-finite
+	// values are ignored.
+	receivedAt := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+	q, err = url.ParseQuery("dateutc=2025-13-45+99:00:00&tempf=NaN&humidity=Inf&windspeedmph=4.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, errs = ParseMeasurement(q, receivedAt)
+	if len(errs) != 3 {
+		t.Fatalf("got %d field errors, want 3: %v", len(errs), errs)
+	}
+	if !dm.DateUTC.Equal(receivedAt) || dm.Temperature != 0 || dm.WindSpeed == 0 {
+		t.Errorf("unexpected measurement: %+v", dm)
+	}
+}
+
+func TestMissingValues(t *testing.T) {
+	q, err := url.ParseQuery("tempf=-9999&humidity=255&dewptf=-9999.0&baromin=&winddir=N/A&windspeedmph=--" +
+		"&indoortempf=70.1&indoorhumidity=255&temp1f=68.0&humidity1=255&heater=&stormstart=-9999")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, errs := ParseMeasurement(q, time.Now())
+	if len(errs) != 0 {
+		t.Fatalf("unexpected field errors: %v", errs)
+	}
+	for _, param := range []string{"tempf", "humidity", "dewptf", "baromin", "winddir", "windspeedmph", "indoorhumidity", "dailyrainin"} {
+		if dm.Has(param) {
+			t.Errorf("got %s, want missing", param)
+		}
+	}
+	if !dm.Has("indoortempf") || dm.IndoorTemp == 0 {
+		t.Errorf("got indoor temperature %v, want measurement", dm.IndoorTemp)
+	}
+	if dm.HeaterOn != nil || dm.StormStart != nil {
+		t.Errorf("got missing auxiliary fields %+v, want nil", dm)
+	}
+	if len(dm.Channels) != 1 || dm.Channels[0].Humidity != nil || dm.Channels[0].Temperature == nil {
+		t.Errorf("got channels %+v, want channel 1 without humidity", dm.Channels)
+	}
+	if fields := SubmittedFields(q, errs); !slices.Equal(fields, []string{"indoortempf"}) {
+		t.Errorf("got submitted fields %v, want [indoortempf]", fields)
+	}
+
+	// 255 is only missing for humidity, and -9999 is only missing exactly.
+	q, err = url.ParseQuery("solarradiation=255&tempf=-999.9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, _ = ParseMeasurement(q, time.Now())
+	if dm.SolarRadiation != 255 || dm.Temperature == 0 {
+		t.Errorf("got solar radiation %v and temperature %v, want measurements", dm.SolarRadiation, dm.Temperature)
+	}
+}
+
+func TestAuxiliaryFields(t *testing.T) {
+	q, err := url.ParseQuery("tempf=63.5&heater=on&supplyvolt=4.98&ws90cap_volt=5.2&wh90batt=3.06")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, errs := ParseMeasurement(q, time.Now())
+	if len(errs) != 0 {
+		t.Fatalf("unexpected field errors: %v", errs)
+	}
+	if dm.HeaterOn == nil || !*dm.HeaterOn {
+		t.Errorf("got heater %v, want on", dm.HeaterOn)
+	}
+	for _, tt := range []struct {
+		name string
+		got  *float64
+		want float64
+	}{
+		{"supply voltage", dm.SupplyVoltage, 4.98},
+		{"capacitor voltage", dm.CapacitorVoltage, 5.2},
+		{"battery voltage", dm.BatteryVoltage, 3.06},
+	} {
+		if tt.got == nil || *tt.got != tt.want {
+			t.Errorf("got %s %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if dm.SolarVoltage != nil {
+		t.Errorf("got solar voltage %v, want nil", *dm.SolarVoltage)
+	}
+
+	// Fields that are not submitted are nil, and malformed fields are
+	// ignored.
+	q, err = url.ParseQuery("tempf=63.5&heater=maybe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, errs = ParseMeasurement(q, time.Now())
+	if len(errs) != 1 || errs[0].Param != "heater" {
+		t.Errorf("got field errors %v, want heater", errs)
+	}
+	if dm.HeaterOn != nil || dm.SupplyVoltage != nil {
+		t.Errorf("unexpected auxiliary fields: %+v", dm)
+	}
+}
+
+func TestChannelFields(t *testing.T) {
+	q, err := url.ParseQuery("tempf=63.5&baromabsin=29.61&temp1f=68.0&humidity1=51&humidity4=92&temp9f=50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, errs := ParseMeasurement(q, time.Now())
+	if len(errs) != 0 {
+		t.Fatalf("unexpected field errors: %v", errs)
+	}
+	if dm.AbsBarometric == nil || math.Abs(*dm.AbsBarometric-inHgToHPA(29.61)) > 0.0001 {
+		t.Errorf("got absolute pressure %v, want %v", dm.AbsBarometric, inHgToHPA(29.61))
+	}
+	if len(dm.Channels) != 2 {
+		t.Fatalf("got channels %+v, want 1 and 4", dm.Channels)
+	}
+	ch1, ch4 := dm.Channels[0], dm.Channels[1]
+	if ch1.Channel != 1 || ch1.Temperature == nil || *ch1.Temperature != 20 || ch1.Humidity == nil || *ch1.Humidity != 51 {
+		t.Errorf("got channel 1 %+v, want 20 °C and 51%%", ch1)
+	}
+	if ch4.Channel != 4 || ch4.Temperature != nil || ch4.Humidity == nil || *ch4.Humidity != 92 {
+		t.Errorf("got channel 4 %+v, want 92%% without temperature", ch4)
+	}
+
+	// Fields that are not submitted are nil.
+	q, err = url.ParseQuery("tempf=63.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, _ = ParseMeasurement(q, time.Now())
+	if dm.AbsBarometric != nil || dm.Channels != nil {
+		t.Errorf("unexpected Ecowitt fields: %+v", dm)
+	}
+}
+
+func TestAWEKASFields(t *testing.T) {
+	q, err := url.ParseQuery("tempf=30.2&snow_depth_cm=12.5&sunshine_hours=3.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, errs := ParseMeasurement(q, time.Now())
+	if len(errs) != 0 {
+		t.Fatalf("unexpected field errors: %v", errs)
+	}
+	if dm.SnowDepth == nil || *dm.SnowDepth != 125 {
+		t.Errorf("got snow depth %v, want 125 mm", dm.SnowDepth)
+	}
+	if dm.SunshineToday == nil || *dm.SunshineToday != 12600 {
+		t.Errorf("got sunshine %v, want 12600 seconds", dm.SunshineToday)
+	}
+}
+
+func TestAddDewPoint(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{"tempf=59.7&humidity=80", "53.5"},
+		{"tempf=59.7&humidity=80&dewptf=50.0", "50.0"}, // Submitted dew point is kept
+		{"tempf=59.7", ""},
+		{"tempf=59.7&humidity=0", ""},
+		{"humidity=80", ""},
+	} {
+		q, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		AddDewPoint(q)
+		if got := q.Get("dewptf"); got != tt.want {
+			t.Errorf("AddDewPoint(%q): got dewptf %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestStormFields(t *testing.T) {
+	tts := []struct {
+		query     string
+		wantRain  float64
+		wantStart time.Time
+	}{
+		{
+			query:     "stormrainin=0.5&stormstart=2025-01-22",
+			wantRain:  12.7,
+			wantStart: time.Date(2025, 1, 22, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			query:     "rain_storm_mm=3.2&rain_storm_start_at=1737590400",
+			wantRain:  3.2,
+			wantStart: time.Date(2025, 1, 23, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			query:    "rain_storm_in=0&rain_storm_start_at=0",
+			wantRain: 0,
+		},
+	}
+	for _, tt := range tts {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dm, errs := ParseMeasurement(q, time.Now())
+			if len(errs) != 0 {
+				t.Fatalf("unexpected field errors: %v", errs)
+			}
+			if dm.StormRain == nil || round(*dm.StormRain, 2) != tt.wantRain {
+				t.Errorf("got storm rain %v, want %v", dm.StormRain, tt.wantRain)
+			}
+			switch {
+			case tt.wantStart.IsZero() && dm.StormStart != nil:
+				t.Errorf("got storm start %v, want nil", *dm.StormStart)
+			case !tt.wantStart.IsZero() && (dm.StormStart == nil || !dm.StormStart.Equal(tt.wantStart)):
+				t.Errorf("got storm start %v, want %v", dm.StormStart, tt.wantStart)
+			}
+		})
+	}
+
+	q, err := url.ParseQuery("stormstart=yesterday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dm, errs := ParseMeasurement(q, time.Now()); len(errs) != 1 || dm.StormStart != nil {
+		t.Errorf("got storm start %v and field errors %v, want an error", dm.StormStart, errs)
+	}
+}
+
+func FuzzParseMeasurement(f *testing.F) {
+	f.Add(testQuery[len(SubmissionPath)+1:])
+	f.Add("dateutc=2025-01-23+23:00:00&tempf=NaN&humidity=--&baromin=")
+	f.Add("tempf=1e400&winddir=-0&dailyrainin=0x1p-2")
+	f.Add("windspeedmph=1.7e308&baromin=-1e308")
+	f.Fuzz(func(t *testing.T, rawQuery string) {
+		q, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			t.Skip()
+		}
+		receivedAt := time.Date(2025, 1, 23, 23, 0, 0, 0, time.UTC)
+		dm, errs := ParseMeasurement(q, receivedAt)
+		if dm.DateUTC.IsZero() {
+			t.Error("measurement has no date")
+		}
+		for _, v := range []float64{
+			dm.RealTimeFreq, dm.WindDirection, dm.WindSpeed, dm.WindGust, dm.Humidity,
+			dm.DewPoint, dm.Temperature, dm.RainPastHour, dm.RainToday, dm.Barometric,
+			dm.IndoorTemp, dm.IndoorHumidity, dm.SolarRadiation,
+		} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				t.Errorf("measurement has non-finite value: %+v", dm)
+			}
+		}
+		for _, fe := range errs {
+			if !q.Has(fe.Param) || fe.Err == nil {
+				t.Errorf("unexpected field error: %v", fe)
+			}
+		}
+	})
+}
+
+func TestRedactQuery(t *testing.T) {
+	got := RedactQuery("ID=test&PASSWORD=secret&tempf=63.5&password=other")
+	want := "ID=test&PASSWORD=REDACTED&tempf=63.5&password=REDACTED"
+	if got != want {
+		t.Errorf("RedactQuery() = %q, want %q", got, want)
+	}
+}
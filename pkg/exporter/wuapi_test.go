@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Joshua Sing <joshua@joshuasing.dev>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package exporter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshuasing/pws_exporter/internal/wuapi"
+	"github.com/joshuasing/pws_exporter/pkg/config"
+	"github.com/joshuasing/pws_exporter/pkg/exporter/wu"
+)
+
+func TestHandleWUAPIObservation(t *testing.T) {
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		StationIDs: config.StationIDNormalization{Lowercase: true},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	defer e.Close()
+
+	temp := 53.0
+	o := wuapi.Observation{StationID: "KNCCARY89", Epoch: 1549291983}
+	o.Imperial.Temp = &temp
+	e.handleWUAPIObservation(context.Background(), o)
+
+	s, ok := e.lastSubmissions.get("knccary89")
+	if !ok {
+		t.Fatal("no submission for normalized station ID")
+	}
+	if !s.Polled || s.RemoteAddr != "" {
+		t.Errorf("submission polled = %v, remote address = %q, want polled without an address", s.Polled, s.RemoteAddr)
+	}
+	if got, want := s.Measurement.Temperature, (53.0-32)*5/9; got != want {
+		t.Errorf("temperature = %v, want %v", got, want)
+	}
+	if got := s.Measurement.DateUTC.Unix(); got != 1549291983 {
+		t.Errorf("observation time = %d, want 1549291983", got)
+	}
+}
+
+func TestWUAPIObservationNotForwarded(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests = make(map[string]int)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		_, _ = w.Write([]byte("success\n"))
+	}))
+	defer srv.Close()
+
+	e, err := NewExporter(Config{
+		ExporterIP: "127.0.0.1",
+		WUForward: []config.WUForward{{
+			Station: "KNCCARY89",
+			Targets: []config.WUTarget{{ID: "KNEW1", Password: "key", URL: srv.URL + "/wu"}},
+		}},
+		Upstreams: []config.Upstream{{
+			Name: "archive", Service: "custom", URL: srv.URL + "/upstream?{{.Query}}",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+
+	temp := 53.0
+	o := wuapi.Observation{StationID: "KNCCARY89", Epoch: 1549291983}
+	o.Imperial.Temp = &temp
+	e.handleWUAPIObservation(context.Background(), o)
+
+	// Submissions received from the station are still forwarded.
+	q := "ID=KNCCARY89&PASSWORD=key&action=updateraww&dateutc=now&tempf=53"
+	e.handleWUSubmission(context.Background(), wu.Submission{
+		StationID:  "KNCCARY89",
+		ReceivedAt: time.Now(),
+		RemoteAddr: "192.0.2.10",
+		RawQuery:   q,
+	})
+	e.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["/wu"] != 1 || requests["/upstream"] != 1 {
+		t.Errorf("got requests %v, want one forwarded submission each", requests)
+	}
+}